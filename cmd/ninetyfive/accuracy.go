@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/accuracy"
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// signalAccuracy evaluates the signals in the ledger's signal audit against the cached bars that followed them and
+// prints their hit rates per grid level and filter combination, for tuning the grid and its filters
+func signalAccuracy(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("accuracy", flag.ContinueOnError)
+	horizonList := fs.String("horizons", "1,5,20", "comma-separated forward horizons, in bars")
+	suppressed := fs.Bool("suppressed", false, "evaluate the signals filters and risk limits suppressed instead of the emitted ones")
+	asJson := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var horizons []int
+	for _, h := range strings.Split(*horizonList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(h))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("horizon %q is not a positive number of bars", h)
+		}
+		horizons = append(horizons, n)
+	}
+	if cfg.CandleCacheDir == "" {
+		return fmt.Errorf("accuracy needs candle_cache_dir for the bars following the signals")
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	cfg.AttachSecretManager(sm)
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	pair, err := common.NewPairFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	cache, err := candles.Open(cfg.CandleCacheDir)
+	if err != nil {
+		return err
+	}
+	bars, err := cache.Load(pair, candles.TimeframeFromConfig(cfg))
+	if err != nil {
+		return err
+	}
+
+	var signals []ledger.SignalRecord
+	for _, s := range l.Signals() {
+		if s.Pair == pair.String() && s.Suppressed == *suppressed {
+			signals = append(signals, s)
+		}
+	}
+	r := accuracy.Evaluate(signals, bars, horizons)
+
+	if *asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	fmt.Printf("%d signals, %d with bars after them\n", r.Signals, r.Evaluated)
+	printGroup("overall", r.Overall)
+	for _, g := range r.ByLevel {
+		printGroup("grid level", g)
+	}
+	for _, g := range r.ByFilters {
+		printGroup("filters", g)
+	}
+	return nil
+}
+
+// printGroup prints a group's hit rate and mean return at each horizon on one line
+func printGroup(kind string, g accuracy.Group) {
+	cols := make([]string, 0, len(g.Horizons))
+	for _, h := range g.Horizons {
+		cols = append(cols, fmt.Sprintf("%d bars: %.0f%% of %d, %+.1f bps", h.Bars, h.HitRate*100, h.Count,
+			h.MeanReturnBps))
+	}
+	fmt.Printf("%-11s %s (%d signals) - %s\n", kind+":", g.Key, g.Signals, strings.Join(cols, " | "))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/attribution"
+)
+
+// pnlAttribution prints the realized PnL of the ledger's round trips per grid level that opened them, telling which
+// levels make or lose money for tuning custom grid levels
+func pnlAttribution(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("attribution", flag.ContinueOnError)
+	since := fs.Duration("since", 0, "only count round trips closed within this long, 0 for the whole ledger")
+	pairFlag := fs.String("pair", "", "pair as BASE_MINT/QUOTE_MINT, defaults to the configured pair")
+	asJson := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	cfg.AttachSecretManager(sm)
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	pair, err := parsePair(cfg, *pairFlag)
+	if err != nil {
+		return err
+	}
+
+	var from time.Time
+	if *since > 0 {
+		from = time.Now().Add(-*since)
+	}
+	r := attribution.Attribute(pair, l.Trades(), from)
+
+	if *asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	fmt.Printf("%s realized %+f\n", pair, r.RealizedPnl)
+	for _, level := range r.Levels {
+		fmt.Println(level)
+	}
+	return nil
+}
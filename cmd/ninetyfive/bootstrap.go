@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+)
+
+// bootstrapPrompts are the keys a new setup is asked for when they are not given as arguments - everything else keeps
+// the embedded default until the config is edited
+var bootstrapPrompts = []struct {
+	key      string
+	question string
+}{
+	{"environment", "deployment environment (develop, staging, production)"},
+	{"base_currency", "mint of the base asset"},
+	{"quote_currency", "mint of the quote asset"},
+	{"signal_only", "only compute and publish signals, without a wallet (true or false)"},
+	{"secret_key_file", "keyfile holding the wallet key, empty to use the Secret Manager"},
+	{"gcp_project_id", "GCP project of the Secret Manager and logs, empty when neither is used"},
+}
+
+// bootstrap takes a new setup from the bare binary to a validated config - it writes the config from the embedded
+// defaults and the key=value arguments, prompting in a terminal for the essentials left out, then checks the bot can
+// reach its RPC node, the price API, and its secrets - an existing config is checked but never overwritten
+func bootstrap(ctx context.Context, path string, args []string) error {
+	if path == "" {
+		path = configs.DefaultConfigFile
+	}
+	values := make(map[string]string)
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("usage: ninetyfive -bootstrap [key=value ...], got %q", arg)
+		}
+		values[key] = value
+	}
+
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if len(values) > 0 {
+			return fmt.Errorf("%s already exists - edit it instead of passing values", path)
+		}
+		fmt.Printf("using the existing %s\n", path)
+	case errors.Is(err, os.ErrNotExist):
+		if isTerminal(os.Stdin) {
+			if err = prompt(os.Stdin, os.Stdout, values); err != nil {
+				return err
+			}
+		}
+		if err = writeBootstrapConfig(path, values); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s\n", path)
+	default:
+		return err
+	}
+
+	if err = configs.ValidateFile(path); err != nil {
+		return fmt.Errorf("%s is invalid - edit it and run -bootstrap again:\n%w", path, err)
+	}
+	fmt.Println("ok    config is valid")
+	return checkConnectivity(ctx, path)
+}
+
+// prompt asks for every bootstrap key not already given, keeping the default on an empty answer
+func prompt(in io.Reader, out io.Writer, values map[string]string) error {
+	defaults := make(map[string]string)
+	for _, line := range strings.Split(string(configs.Example()), "\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok && !strings.HasPrefix(key, "#") {
+			defaults[key] = strings.Trim(value, "'")
+		}
+	}
+
+	r := bufio.NewReader(in)
+	for _, p := range bootstrapPrompts {
+		if _, ok := values[p.key]; ok {
+			continue
+		}
+		fmt.Fprintf(out, "%s [%s]: ", p.question, defaults[p.key])
+		answer, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			values[p.key] = answer
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// writeBootstrapConfig renders the config and writes it, creating its directory - an existing file is never
+// overwritten
+func writeBootstrapConfig(path string, values map[string]string) error {
+	data, err := configs.Render(values)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// checkConnectivity loads the config and checks every external service the bot depends on, reporting each - it
+// fails when any check does
+func checkConnectivity(ctx context.Context, path string) error {
+	cfg, err := configs.NewConfig(path)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	failed := 0
+	report := func(name string, err error) {
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL  %s: %s\n", name, err)
+			return
+		}
+		fmt.Printf("ok    %s\n", name)
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		report("secret manager", err)
+		return fmt.Errorf("the Secret Manager is unreachable")
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	cfg.AttachSecretManager(sm)
+	if cfg.NeedsSecretKey() {
+		if err = cfg.LoadSecrets(ctx, sm); err != nil {
+			report("wallet key", err)
+			return fmt.Errorf("the wallet key is not accessible")
+		}
+		report("wallet key", nil)
+	}
+
+	j, err := jupiter.NewJupiter(cfg)
+	report("client", err)
+	if err != nil {
+		return fmt.Errorf("the Jupiter client could not be built")
+	}
+	report("rpc", j.CheckRpc(ctx))
+	_, err = j.GetPrice(ctx, cfg.BaseCurrency)
+	report("price api", err)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of the connectivity checks failed", failed)
+	}
+	fmt.Println("ready - start the bot with: ninetyfive -config " + path)
+	return nil
+}
+
+// isTerminal reports whether the file is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
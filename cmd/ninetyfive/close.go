@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/position"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+)
+
+// closePosition flattens the position the ledger tracks for a pair at market - a long is sold and a short-like
+// imbalance is bought back - printing the quote and only sending the swap when confirmed with -yes
+func closePosition(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("close-position", flag.ContinueOnError)
+	pairFlag := fs.String("pair", "", "pair as BASE_MINT/QUOTE_MINT, defaults to the configured pair")
+	slippageBps := fs.Int("slippage-bps", 0, "fixed slippage tolerance in basis points, 0 for automatic")
+	yes := fs.Bool("yes", false, "send the swap instead of only quoting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pair, err := parsePair(cfg, *pairFlag)
+	if err != nil {
+		return err
+	}
+
+	j, l, closeFn, err := openTrading(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	pos := position.FromTrades(pair, l.Trades())
+	side, base := pos.Flatten()
+	if side == common.DoNothingSignal {
+		fmt.Printf("no open position in %s\n", pair)
+		return nil
+	}
+
+	// SELL sizes are in the base asset already, BUY sizes are in the quote asset so the buy-back is sized at market
+	ps, err := pricing.NewCompositeFromConfig(cfg, pricing.Timed(j.PriceSample))
+	if err != nil {
+		return err
+	}
+	pq, err := ps.PairPrice(ctx, pair.Base, pair.Quote)
+	if err != nil {
+		return err
+	}
+	price := pq.Price
+	size := base
+	if side == common.BuySignal {
+		size = base * price
+	}
+
+	q, err := j.Quote(ctx, pair, side, size)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("position %f base in %s - closing with %s %f %s -> %f %s at %f (market %f, impact %.4f%%)\n",
+		pos.Base, pair, side, q.InAmount, q.InputMint, q.OutAmount, q.OutputMint, q.Price, price,
+		q.PriceImpactPct*100)
+	if !*yes {
+		fmt.Println("not sent - re-run with -yes to execute")
+		return nil
+	}
+
+	return sendManualSwap(ctx, cfg, j, l, "close", pair, side, size, q.Price, *slippageBps)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/logging"
+	secretmanager "cloud.google.com/go/secretmanager/apiv1beta2"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/birdeye"
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+	"github.com/josephawallace/ninetyfive/internal/secure"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// runCommand dispatches a subcommand by name
+func runCommand(ctx context.Context, cfg *configs.Config, name string, args []string) error {
+	switch name {
+	case "seal-keyfile":
+		return sealKeyfile(ctx, cfg, args)
+	case "status":
+		return status(ctx, cfg, args)
+	case "quote":
+		return quote(ctx, cfg, args)
+	case "swap":
+		return swap(ctx, cfg, args)
+	case "close-position":
+		return closePosition(ctx, cfg, args)
+	case "tick":
+		return tick(ctx, cfg, args)
+	case "push":
+		return push(ctx, cfg, args)
+	case "accuracy":
+		return signalAccuracy(ctx, cfg, args)
+	case "attribution":
+		return pnlAttribution(ctx, cfg, args)
+	case "tune":
+		return tuneGrids(ctx, cfg, args)
+	case "export-state":
+		return exportState(ctx, cfg, args)
+	case "import-state":
+		return importState(ctx, cfg, args)
+	case "import-trades":
+		return importTrades(ctx, cfg, args)
+	case "remap-mint":
+		return remapMint(ctx, cfg, args)
+	case "gen-fixtures":
+		return genFixtures(ctx, cfg, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// newSecretManager creates a Secret Manager client when the config sources any secret from it, and nil otherwise
+func newSecretManager(ctx context.Context, cfg *configs.Config) (*secretmanager.Client, error) {
+	if !cfg.UsesSecretManager() {
+		return nil, nil
+	}
+	return secretmanager.NewClient(ctx)
+}
+
+// openTrading loads the secrets and opens the Jupiter client and the ledger for commands that trade - the returned
+// function releases them
+func openTrading(ctx context.Context, cfg *configs.Config) (*jupiter.Jupiter, *ledger.Ledger, func(), error) {
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	closeFn := func() {
+		if sm != nil {
+			sm.Close()
+		}
+	}
+	if err = cfg.LoadSecrets(ctx, sm); err != nil {
+		closeFn()
+		return nil, nil, nil, err
+	}
+	j, err := jupiter.NewJupiter(cfg)
+	if err != nil {
+		closeFn()
+		return nil, nil, nil, err
+	}
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		closeFn()
+		return nil, nil, nil, err
+	}
+	return j, l, closeFn, nil
+}
+
+// openLedger opens the configured ledger, sealing it with the encryption passphrase when one is available
+func openLedger(ctx context.Context, cfg *configs.Config) (*ledger.Ledger, error) {
+	var passphrase []byte
+	if cfg.EncryptState {
+		p, err := cfg.Passphrase(ctx)
+		if err != nil {
+			return nil, err
+		}
+		passphrase = p
+	}
+	l, err := ledger.Open(cfg.LedgerPath, passphrase, common.NewLabels(cfg))
+	if err != nil {
+		return nil, err
+	}
+	l.LimitSignals(cfg.SignalAuditMax)
+	return l, nil
+}
+
+// newLogger creates the logger for the config's deployment, to Google Cloud Logging through the client or locally
+// when it is nil - wallet addresses, secret names, and API keys are masked unless log redaction is disabled, with the
+// pair's mints kept readable
+func newLogger(cfg *configs.Config, lc *logging.Client) logger.Logger {
+	log := logger.NewLogger(lc, common.NewLabels(cfg).Map())
+	if !cfg.LogRedaction {
+		return log
+	}
+	return logger.NewRedactedLogger(log, logger.NewRedactor(logger.Redaction{
+		Secrets: cfg.SecretValues(),
+		Names:   []string{cfg.SmSecretKeyName, cfg.SmPassphraseName},
+		Keep:    append([]string{cfg.BaseCurrency, cfg.QuoteCurrency}, cfg.DustIgnoreMints...),
+	}))
+}
+
+// warmUp caches every complete bar, filling the bars missed while offline from Birdeye when it serves the bar
+// interval, and warms the strategies up on the cache before trading - a no-op without a candle cache
+func warmUp(ctx context.Context, cfg *configs.Config, t *trader.Trader) error {
+	if cfg.CandleCacheDir == "" {
+		return nil
+	}
+	cache, err := candles.Open(cfg.CandleCacheDir)
+	if err != nil {
+		return err
+	}
+	var feed candles.Feed
+	if cfg.BirdeyeApiKey != "" && birdeye.SupportsInterval(cfg.BarInterval) {
+		feed = birdeye.NewClient(cfg.BirdeyeApiKey)
+		// The feed prices in USD, the strategies in the quote asset
+		if cfg.PairPricing == pricing.PricingQuote {
+			feed = candles.NewCross(feed, t.Pair().Quote)
+		}
+	}
+	return t.WarmUp(ctx, cache, feed)
+}
+
+// sealKeyfile encrypts a plaintext keyfile in place of (or next to) the original using the configured passphrase
+func sealKeyfile(ctx context.Context, cfg *configs.Config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: ninetyfive seal-keyfile <plaintext-keyfile> <sealed-keyfile>")
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	cfg.AttachSecretManager(sm)
+
+	plaintext, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	defer secure.Zero(plaintext)
+
+	passphrase, err := cfg.Passphrase(ctx)
+	if err != nil {
+		return err
+	}
+	defer secure.Zero(passphrase)
+
+	sealed, err := secure.Seal(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(args[1], sealed, 0600)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// configCommand runs the config subcommands, which work on config files without loading them for trading
+func configCommand(path string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: ninetyfive config init [file] | ninetyfive config validate [file]")
+	}
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	switch args[0] {
+	case "init":
+		return configInit(path)
+	case "validate":
+		// Without a path the default file is optional, so an environment-only config can be validated too
+		name := path
+		if name == "" {
+			name = "the config"
+		}
+		if err := configs.ValidateFile(path); err != nil {
+			return fmt.Errorf("%s is invalid:\n%w", name, err)
+		}
+		fmt.Printf("%s is valid\n", name)
+		return nil
+	default:
+		return fmt.Errorf("unknown config command %q", args[0])
+	}
+}
+
+// configInit writes the example config to the path, or to stdout when no path is given - an existing file is never
+// overwritten
+func configInit(path string) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(configs.Example())
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(configs.Example()); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", path)
+	return nil
+}
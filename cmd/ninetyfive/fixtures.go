@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/parity"
+)
+
+// genFixtures runs every configured strategy over a series of bars and records the signal and indicator value on
+// each bar as a fixture, so check-fixtures can prove later refactors of the strategy math change nothing
+func genFixtures(_ context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("gen-fixtures", flag.ContinueOnError)
+	barsPath := fs.String("bars", "", "CSV of bars with time and close columns, and optionally open, high, and low, or a candle cache file")
+	out := fs.String("out", "./testdata/fixtures", "directory the fixtures are written to, one per strategy")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *barsPath == "" {
+		return fmt.Errorf("-bars is required")
+	}
+
+	bars, err := parity.LoadBars(*barsPath)
+	if err != nil {
+		return err
+	}
+
+	// The Grid Manager narrates every bar, which would bury the output
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	log := logger.NewLogger(nil, nil)
+	for _, s := range cfg.Strategies {
+		f, err := parity.Generate(s, bars, log)
+		if err != nil {
+			return fmt.Errorf("strategy %s: %w", s.Name, err)
+		}
+		path, err := parity.WriteFixture(*out, f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s (%d bars)\n", path, len(f.Bars))
+	}
+	return nil
+}
+
+// checkFixtures replays every fixture in a directory and exits non-zero when any bar's signal or indicator value
+// differs from the recording
+func checkFixtures(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("check-fixtures", flag.ContinueOnError)
+	dir := fs.String("dir", "./testdata/fixtures", "directory holding the fixtures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fixtures, err := parity.LoadFixtures(*dir)
+	if err != nil {
+		return err
+	}
+	if len(fixtures) == 0 {
+		return fmt.Errorf("no fixtures in %s", *dir)
+	}
+
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	log := logger.NewLogger(nil, nil)
+	failed := 0
+	for _, f := range fixtures {
+		mismatches, err := parity.Replay(f, log)
+		if err != nil {
+			return fmt.Errorf("fixture %s: %w", f.Strategy.Name, err)
+		}
+		if len(mismatches) == 0 {
+			fmt.Printf("ok    %s (%d bars)\n", f.Strategy.Name, len(f.Bars))
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s (%d of %d bars differ)\n", f.Strategy.Name, len(mismatches), len(f.Bars))
+		for _, m := range mismatches {
+			fmt.Printf("      bar %d %s: expected %s rsi=%.6f, got %s rsi=%.6f\n", m.Index, m.Time.Format(time.RFC3339),
+				m.Expected, m.ExpectedRsi, m.Got, m.State.Rsi)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, len(fixtures))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/importer"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/position"
+)
+
+// importTrades brings the pair's trades from before the bot into the ledger - the swaps of a wallet read on chain,
+// or the rows of a CSV - so the position's cost basis and PnL include them - the bot must be stopped while it runs
+// since both write the ledger
+func importTrades(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("import-trades", flag.ContinueOnError)
+	wallet := fs.String("wallet", "", "address whose on-chain swaps of the pair to import")
+	csvPath := fs.String("csv", "", "CSV of trades with time, side, base, and quote columns, and optionally tx_id")
+	since := fs.String("since", "", "import swaps from this RFC3339 time on, all within -limit when empty")
+	limit := fs.Int("limit", 1000, "most of the wallet's most recent transactions to read")
+	anySwap := fs.Bool("any-swap", false, "import swaps of the pair that did not go through the Jupiter aggregator too")
+	dryRun := fs.Bool("dry-run", false, "print the trades found without importing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*wallet == "") == (*csvPath == "") {
+		return fmt.Errorf("pass either -wallet or -csv")
+	}
+	var from time.Time
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("invalid -since: %w", err)
+		}
+		from = t
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("-limit must be positive")
+	}
+	pair, err := common.NewPairFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+
+	cfg.AttachSecretManager(sm)
+	var trades []ledger.Trade
+	if *csvPath != "" {
+		if trades, err = importer.LoadCsv(*csvPath, pair); err != nil {
+			return err
+		}
+	} else {
+		// The wallet's history is public, so the client reads it as an observer of the wallet, without the key
+		observer := *cfg
+		observer.ObserveWallet = *wallet
+		j, err := jupiter.NewJupiter(&observer)
+		if err != nil {
+			return err
+		}
+		history, err := j.History(ctx, *wallet, []string{pair.Base, pair.Quote}, from, *limit)
+		if err != nil {
+			return err
+		}
+		trades = importer.FromHistory(pair, history, !*anySwap)
+	}
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	// Paper ledgers take simulated trades only, so history imported into one counts as simulated
+	for i := range trades {
+		trades[i].Simulated = common.NewLabels(cfg).Paper()
+	}
+
+	for _, t := range trades {
+		fmt.Printf("%s %s %f %s -> %f %s at %f (%s)\n", t.Time.Format(time.RFC3339), t.Side, t.InputAmount,
+			t.InputMint, t.OutputAmount, t.OutputMint, t.Price, t.TxId)
+	}
+	if *dryRun {
+		fmt.Printf("%d trades found, none imported (dry run)\n", len(trades))
+		return nil
+	}
+	n, err := l.ImportTrades(trades)
+	if err != nil {
+		return err
+	}
+	pos := position.FromTrades(pair, l.Trades())
+	fmt.Printf("%d trades found, %d imported - the position now holds %f base for %f quote\n", len(trades), n,
+		pos.Base, pos.Quote)
+	return nil
+}
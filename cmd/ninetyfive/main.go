@@ -2,32 +2,101 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/logging"
-	secretmanager "cloud.google.com/go/secretmanager/apiv1beta2"
 
 	"github.com/josephawallace/ninetyfive/configs"
-	"github.com/josephawallace/ninetyfive/internal/common"
-	"github.com/josephawallace/ninetyfive/internal/gridmanager"
+	"github.com/josephawallace/ninetyfive/internal/admin"
+	"github.com/josephawallace/ninetyfive/internal/broadcast"
+	"github.com/josephawallace/ninetyfive/internal/buildinfo"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
 	"github.com/josephawallace/ninetyfive/internal/jupiter"
-	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/supervisor"
+	"github.com/josephawallace/ninetyfive/internal/trader"
 )
 
 func main() {
-	ctx := context.Background()
+	// Cancel the context on SIGINT/SIGTERM so the loop can stop and log its shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Initialize the GCP Secret Manager
-	sm, err := secretmanager.NewClient(ctx)
+	configFile := flag.String("config", os.Getenv("NF_CONFIG"), "path to the base config file, merged with its environment overlay")
+	resume := flag.Bool("resume", false, "carry the runtime state saved in state_file by the previous process over")
+	bootstrapFlag := flag.Bool("bootstrap", false, "create the config from the embedded defaults and key=value arguments, prompting for the essentials, then check connectivity")
+	flag.Parse()
+
+	// Bootstrapping creates the config the rest of the binary needs
+	if *bootstrapFlag {
+		if err := bootstrap(ctx, *configFile, flag.Args()); err != nil {
+			fatal(exitFailure, err)
+		}
+		return
+	}
+
+	// Config subcommands work on files that may not exist or load yet, and the version, parity check, and fixture
+	// replay need no config at all
+	switch flag.Arg(0) {
+	case "config":
+		if err := configCommand(*configFile, flag.Args()[1:]); err != nil {
+			fatal(exitFailure, err)
+		}
+		return
+	case "version":
+		fmt.Println(buildinfo.Get())
+		return
+	case "parity":
+		if err := parityCheck(ctx, flag.Args()[1:]); err != nil {
+			fatal(exitFailure, err)
+		}
+		return
+	case "check-fixtures":
+		if err := checkFixtures(ctx, flag.Args()[1:]); err != nil {
+			fatal(exitFailure, err)
+		}
+		return
+	}
+
+	// Initialize the configuration loaded from the YAML, refusing to trade on a config that does not validate
+	cfg, err := configs.NewConfig(*configFile)
 	if err != nil {
-		panic(err)
+		fatal(exitConfig, err)
+	}
+	if err = cfg.Validate(); err != nil {
+		fatal(exitConfig, err)
+	}
+
+	// Subcommands run instead of the trading loop
+	if flag.NArg() > 0 {
+		if err = runCommand(ctx, cfg, flag.Arg(0), flag.Args()[1:]); err != nil {
+			fatal(exitFailure, err)
+		}
+		return
 	}
-	defer sm.Close()
 
-	// Initialize the configuration loaded from the YAML
-	cfg, err := configs.NewConfig(ctx, sm)
+	// Initialize the GCP Secret Manager only when a secret lives there, so local keyfile setups don't need GCP
+	// credentials
+	sm, err := newSecretManager(ctx, cfg)
 	if err != nil {
-		panic(err)
+		fatal(exitTempFail, err)
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+
+	// Load the secret key from the keyfile or the Secret Manager, unless only publishing signals
+	if cfg.NeedsSecretKey() {
+		if err = cfg.LoadSecrets(ctx, sm); err != nil {
+			fatal(exitTempFail, err)
+		}
+	} else {
+		cfg.AttachSecretManager(sm)
 	}
 
 	// Conditionally create a logging client for Google Cloud Logging for production environments
@@ -35,71 +104,146 @@ func main() {
 	if cfg.Environment == configs.ProductionEnvironment {
 		lc, err = logging.NewClient(ctx, cfg.GcpProjectId)
 		if err != nil {
-			panic(err)
+			fatal(exitTempFail, err)
 		}
+		// Flush buffered entries, including the shutdown banner, on the way out
+		defer lc.Close()
 	}
 
 	// Initialize our custom Jupiter client that essentially wraps other Jupiter libs and exposes a few specialty
 	// functions for our purposes
 	j, err := jupiter.NewJupiter(cfg)
 	if err != nil {
-		panic(err)
+		fatal(exitTempFail, err)
 	}
 
 	// Initialize our custom logger that intelligently uses either `zerolog` or `gcp.logging`
-	log := logger.NewLogger(lc)
+	log := newLogger(cfg, lc)
+	build := buildinfo.Get()
+	startedAt := time.Now()
+	log.Info().Msg("ninetyfive %s starting in the %s environment", build, cfg.Environment)
+	if cfg.ChaosRate > 0 {
+		log.Warn().Msg("chaos mode is on - each call has a %.0f%% chance of an injected fault", cfg.ChaosRate*100)
+	}
 
-	// Initialize the Grid Manager responsible for generating BUY/SELL/DO_NOTHING signals based on the grid strategy
-	gm := gridmanager.NewGridManager(7, 10, "neutral", "35-65", "low", "rsx", log)
+	// Initialize the ensemble of Grid Managers responsible for generating BUY/SELL/DO_NOTHING signals based on the grid
+	// strategy, one per configured set of inputs, tuned to the cached bars first when configured
+	if cfg.GridAutoTune {
+		autoTune(cfg, log)
+	}
+	e, err := ensemble.NewEnsembleFromConfig(cfg, log)
+	if err != nil {
+		fatal(exitConfig, err)
+	}
 	log.Info().Msg("setup successfully completed initializing system configuration, logging, Secret Manager, and Jupiter Client")
 
-	// Enter the main loop for feeding price data into the Grid Manager
-	for {
-		// Sleep at the top of the loop to allow a log and a `continue` statement for errors while maintaining the
-		// configured data interval
-		time.Sleep(time.Duration(cfg.IntervalSeconds) * time.Second)
+	// Open the ledger of fills and order outcomes, sealed at rest when a passphrase is configured
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		fatal(exitTempFail, err)
+	}
 
-		// Retrieve the price for the quote asset, to be used as the next data point in our grid strategy
-		var price float64
-		price, err = j.GetPrice(cfg.QuoteCurrency)
+	// Publish signals to the configured webhook and Pub/Sub topic for other execution systems
+	pub, err := broadcast.NewPublisher(ctx, cfg)
+	if err != nil {
+		fatal(exitTempFail, err)
+	}
+
+	// Initialize the trading loop, which owns execution and the state exposed to operators
+	n := notifier.NewNotifier(cfg)
+	t, err := trader.NewTrader(cfg, j, e, l, n, pub, log)
+	if err != nil {
+		fatal(exitConfig, err)
+	}
+
+	// Cache every complete bar and warm the strategies up on the cache before trading
+	if err = warmUp(ctx, cfg, t); err != nil {
+		fatal(exitTempFail, err)
+	}
+
+	// Carry the previous process's runtime state over when resuming, so a restart does not reset the loop
+	if cfg.StateFile != "" {
+		h, err := trader.LoadHandoff(cfg.StateFile)
+		switch {
+		case err != nil && *resume:
+			fatal(exitConfig, fmt.Errorf("could not resume from %s: %w", cfg.StateFile, err))
+		case err != nil:
+			log.Warn().Err(err).Msg("ignoring the unreadable state in %s", cfg.StateFile)
+		case h != nil && *resume:
+			t.Restore(*h)
+			log.Info().Msg("resumed the state saved at %s from %s", h.SavedAt.Format(time.RFC3339), cfg.StateFile)
+		case h != nil:
+			log.Warn().Msg("ignoring the state saved in %s - start with -resume to carry it over", cfg.StateFile)
+		}
+	}
+
+	// Record the process ID for service managers
+	if cfg.PidFile != "" {
+		removePid, err := writePidFile(cfg.PidFile)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to get quote currency price")
-			continue
+			fatal(exitTempFail, err)
 		}
-		log.Info().Msg("quote currency price - $%f", price)
+		defer removePid()
+	}
 
-		// Receive a signal from the Grid Manager to dictate the bot's action
-		var signal common.Signal
-		signal, err = gm.Process(price)
+	// Report the loop's health through a file for watchdogs, marking it stopped before exiting
+	healthDone := make(chan struct{})
+	if cfg.HealthFile != "" {
+		go func() {
+			defer close(healthDone)
+			writeHealth(ctx, cfg.HealthFile, cfg.Interval, t, log)
+		}()
+	} else {
+		close(healthDone)
+	}
+
+	// Serve the authenticated admin endpoints when an address is configured
+	if cfg.AdminAddr != "" {
+		var srv *admin.Server
+		srv, err = admin.NewServer(cfg, t, log)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to process interval")
-			continue
+			fatal(exitConfig, err)
 		}
-		log.Info().Msg("%s signal received", signal)
-
-		// Swap the configured fixed amount of the assets - since this is an LP and not an orderbook, there aren't
-		// technically buy/sell order, but instead only swaps - the order of the parameters to the `SubmitSwap`
-		// function dictate the order type
-		var txId string
-		switch signal {
-		case common.BuySignal:
-			txId, err = j.SubmitSwap(ctx, cfg.BaseCurrency, cfg.QuoteCurrency, cfg.BuyOrderSize)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to submit swap")
-				continue
-			}
-		case common.SellSignal:
-			txId, err = j.SubmitSwap(ctx, cfg.QuoteCurrency, cfg.BaseCurrency, cfg.SellOrderSize)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to submit swap")
-				continue
+		go func() {
+			if err := srv.ListenAndServe(ctx); err != nil {
+				log.Error().Err(err).Msg("admin server stopped")
 			}
-		default:
-			log.Info().Msg("no action taken this interval")
-			continue
-		}
+		}()
+	}
 
-		log.Info().Msg("submitted swap %s", txId)
-		go j.MonitorTx(ctx, txId, log)
+	// Run the recurring jobs - maintenance windows, reports, wallet reconciliation, secret key rotation, config
+	// refresh, dust sweeps, and the spreadsheet export - on their schedules
+	sch, err := newScheduler(ctx, *configFile, cfg, t, j, log)
+	if err != nil {
+		fatal(exitConfig, err)
+	}
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		sch.Run(ctx)
+	}()
+
+	// Open the connections to Jupiter and the RPC before the first signal and keep them warm, so executing one does
+	// not wait on DNS lookups and TLS handshakes
+	if took, err := j.Warm(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to warm some connections to Jupiter and the RPC after %s",
+			took.Round(time.Millisecond))
+	} else if cfg.HttpPrewarm {
+		log.Info().Msg("warmed the connections to Jupiter and the RPC in %s", took.Round(time.Millisecond))
+	}
+	go j.KeepWarm(ctx)
+
+	// Enter the main loop for feeding price data into the Grid Manager, restarting it if it panics
+	sv := supervisor.NewSupervisor(n, log)
+	sv.Run(ctx, t.Pair().String(), t.Run)
+
+	// Hand the runtime state to the next process
+	if cfg.StateFile != "" {
+		if err = trader.SaveHandoff(cfg.StateFile, t.Handoff()); err != nil {
+			log.Error().Err(err).Msg("failed to save the state to %s", cfg.StateFile)
+		}
 	}
+	<-healthDone
+	<-schedulerDone
+	log.Info().Msg("ninetyfive %s shutting down after %s", build, time.Since(startedAt).Round(time.Second))
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/buildinfo"
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/state"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// exportState writes the pair's runtime state, the ledger, and the cached bars to a bundle another deployment can
+// import - the bot should be stopped first so the bundle is consistent
+func exportState(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("export-state", flag.ContinueOnError)
+	out := fs.String("out", "ninetyfive-state.json", "file to write the bundle to")
+	fromFirestore := fs.Bool("firestore", false, "read the runtime state and bars from Firestore, as push mode "+
+		"keeps them, instead of state_file and the candle cache")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pair, l, cache, err := openState(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	b := state.Bundle{
+		SchemaVersion: state.SchemaVersion,
+		ExportedAt:    time.Now(),
+		Build:         buildinfo.Get().String(),
+		Labels:        common.NewLabels(cfg),
+		Pair:          pair,
+	}
+	if *fromFirestore {
+		store, err := state.NewFirestore(ctx, cfg.GcpProjectId, cfg.FirestoreCollection)
+		if err != nil {
+			return err
+		}
+		rec, err := store.Load(ctx, pair)
+		if err != nil {
+			return err
+		}
+		// Push mode keeps the bars in Firestore rather than in the candle cache
+		if rec != nil {
+			b.Handoff, b.Timeframe, b.Bars = &rec.Handoff, rec.Timeframe, rec.Bars
+		}
+	} else if b.Handoff, err = trader.LoadHandoff(cfg.StateFile); err != nil {
+		return err
+	}
+	if b.Ledger, err = l.Export(); err != nil {
+		return err
+	}
+	if cache != nil && len(b.Bars) == 0 {
+		b.Timeframe = candles.TimeframeFromConfig(cfg)
+		if b.Bars, err = cache.Load(pair, b.Timeframe); err != nil {
+			return err
+		}
+	}
+	if err = state.WriteBundle(*out, b); err != nil {
+		return err
+	}
+	fmt.Printf("exported %s state with %d trade(s) and %d bar(s) to %s - the ledger is unsealed in the bundle, "+
+		"keep it safe\n", pair, len(l.Trades()), len(b.Bars), *out)
+	return nil
+}
+
+// importState loads a bundle exported by another deployment - the ledger must belong to this deployment's
+// environment and trading mode, and an existing ledger is only replaced with -force
+func importState(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("import-state", flag.ContinueOnError)
+	in := fs.String("in", "ninetyfive-state.json", "file to read the bundle from")
+	toFirestore := fs.Bool("firestore", false, "write the runtime state and bars to Firestore, as push mode "+
+		"keeps them, instead of state_file")
+	force := fs.Bool("force", false, "replace a ledger that already holds records")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	b, err := state.ReadBundle(*in)
+	if err != nil {
+		return err
+	}
+	pair, l, cache, err := openState(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if b.Pair != pair {
+		return fmt.Errorf("the bundle holds the state of %s, not the configured %s", b.Pair, pair)
+	}
+	if !l.Empty() && !*force {
+		return fmt.Errorf("ledger %s already holds records - pass -force to replace it", cfg.LedgerPath)
+	}
+
+	// Check everything before writing anything, so a bundle that does not fit leaves the deployment as it was
+	if err = l.CheckImport(b.Ledger); err != nil {
+		return err
+	}
+	importBars := cache != nil && len(b.Bars) > 0
+	if (importBars || *toFirestore && len(b.Bars) > 0) && b.Timeframe != candles.TimeframeFromConfig(cfg) {
+		return fmt.Errorf("the bundle's bars are %s bars, not the configured %s bars", b.Timeframe,
+			candles.TimeframeFromConfig(cfg))
+	}
+	var store *state.Firestore
+	if b.Handoff != nil && *toFirestore {
+		if store, err = state.NewFirestore(ctx, cfg.GcpProjectId, cfg.FirestoreCollection); err != nil {
+			return err
+		}
+	}
+
+	if err = l.Import(b.Ledger); err != nil {
+		return err
+	}
+	if b.Handoff != nil {
+		if store != nil {
+			err = store.Save(ctx, pair, state.Record{Handoff: *b.Handoff, Timeframe: b.Timeframe, Bars: b.Bars})
+		} else {
+			err = trader.SaveHandoff(cfg.StateFile, *b.Handoff)
+		}
+		if err != nil {
+			return fmt.Errorf("could not save the runtime state: %w", err)
+		}
+	}
+	added := 0
+	if importBars {
+		if added, err = cache.Import(pair, b.Timeframe, b.Bars); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("imported %s state exported by %s at %s - %d trade(s), %d new bar(s)\n", pair, b.Build,
+		b.ExportedAt.Format(time.RFC3339), len(l.Trades()), added)
+	return nil
+}
+
+// openState opens the configured pair's ledger and candle cache - the cache is nil when it is disabled
+func openState(ctx context.Context, cfg *configs.Config) (common.Pair, *ledger.Ledger, *candles.Cache, error) {
+	pair, err := common.NewPairFromConfig(cfg)
+	if err != nil {
+		return common.Pair{}, nil, nil, err
+	}
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return common.Pair{}, nil, nil, err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	cfg.AttachSecretManager(sm)
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		return common.Pair{}, nil, nil, err
+	}
+	var cache *candles.Cache
+	if cfg.CandleCacheDir != "" {
+		if cache, err = candles.Open(cfg.CandleCacheDir); err != nil {
+			return common.Pair{}, nil, nil, err
+		}
+	}
+	return pair, l, cache, nil
+}
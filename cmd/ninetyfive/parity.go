@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/josephawallace/ninetyfive/internal/gridmanager"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/parity"
+)
+
+// parityCheck replays a TradingView chart export through the Grid Manager and compares its signals with the ones the
+// Pine Script fired on the same chart - it exits non-zero on any divergence so it can gate strategy changes
+func parityCheck(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("parity", flag.ContinueOnError)
+	barsPath := fs.String("bars", "", "CSV of bars with time and close columns, and optionally open, high, and low, or a candle cache file")
+	signalsPath := fs.String("signals", "", "CSV of the Pine Script's signals with time and signal (BUY/SELL) columns")
+	rsiLength := fs.Int("rsi-length", 7, "RSI length input of the script")
+	grids := fs.Int("grids", 10, "number of grids input of the script")
+	direction := fs.String("direction", "neutral", "market direction input: up, neutral, or down")
+	noTradeZone := fs.String("no-trade-zone", "35-65", "no trade zone input, e.g. 35-65")
+	aggression := fs.String("aggression", "low", "aggression input: low, med, or high")
+	rsiType := fs.String("rsi-type", "rsx", "RSI type input: rsi, rsx, laguerre, or connors")
+	gamma := fs.Float64("laguerre-gamma", 0, "damping of the Laguerre RSI, 0 for 0.5")
+	source := fs.String("source", "close", "RSI source input: close, hl2, hlc3, or ohlc4")
+	smoothing := fs.Int("smoothing", 0, "EMA bars pre-smoothing the source, 0 for none")
+	asJson := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *barsPath == "" || *signalsPath == "" {
+		return fmt.Errorf("-bars and -signals are required")
+	}
+
+	bars, err := parity.LoadBars(*barsPath)
+	if err != nil {
+		return err
+	}
+	signals, err := parity.LoadSignals(*signalsPath)
+	if err != nil {
+		return err
+	}
+
+	// The Grid Manager narrates every bar, which would bury the report
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	gm := gridmanager.NewGridManager(*rsiLength, *grids, *direction, *noTradeZone, *aggression, *rsiType, logger.NewLogger(nil, nil))
+	gm.LaguerreGamma = *gamma
+	if err = gm.SetSource(*source, *smoothing); err != nil {
+		return err
+	}
+
+	r, err := parity.Run(gm, bars, signals)
+	if err != nil {
+		return err
+	}
+
+	if *asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(r); err != nil {
+			return err
+		}
+	} else {
+		for _, m := range r.Mismatches {
+			fmt.Printf("bar %d %s close=%g: expected %s, got %s (rsi=%.4f prev_rsi=%.4f signal_line=%.4f last_signal=%g@%d)\n",
+				m.Index, m.Time.Format(time.RFC3339), m.Close, m.Expected, m.Got,
+				m.State.Rsi, m.PrevRsi, m.State.SignalLine, m.State.LastSignal, m.State.LastSignalIndex)
+		}
+		fmt.Printf("%d bars, %d script signals, %d matched, %d divergences\n", r.Bars, r.Signals, r.Matched, len(r.Mismatches))
+	}
+
+	if !r.Ok() {
+		return fmt.Errorf("grid manager diverged from the script on %d bars", len(r.Mismatches))
+	}
+	return nil
+}
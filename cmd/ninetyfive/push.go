@@ -0,0 +1,193 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/state"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// pushEnvelope is the body a Pub/Sub push subscription posts for each message
+type pushEnvelope struct {
+	Message struct {
+		Data        []byte            `json:"data"`
+		Attributes  map[string]string `json:"attributes"`
+		MessageId   string            `json:"messageId"`
+		PublishTime time.Time         `json:"publishTime"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// pushRequest is the message data - the pair to evaluate as BASE/QUOTE mints, the configured pair when empty - the
+// pair may also be given as the "pair" attribute
+type pushRequest struct {
+	Pair string `json:"pair"`
+}
+
+// push serves a Pub/Sub push endpoint that ticks the pair each message carries once, keeping every pair's state and
+// bars in Firestore between messages, so the bot can run as a scale-to-zero service (Cloud Run) woken by a scheduled topic -
+// pushes are authenticated by the platform, e.g. an OIDC token checked by Cloud Run, not by the endpoint itself
+func push(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	addr := fs.String("addr", ":"+cmp.Or(os.Getenv("PORT"), "8080"), "address to serve the push endpoint on")
+	settle := fs.Duration("settle", cfg.OrderTimeout, "time allowed for a tick's orders to settle before acknowledging")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cfg.GcpProjectId == "" {
+		return fmt.Errorf("push needs gcp_project_id for the Firestore state")
+	}
+
+	store, err := state.NewFirestore(ctx, cfg.GcpProjectId, cfg.FirestoreCollection)
+	if err != nil {
+		return err
+	}
+	// The bars are kept in Firestore and restored into the instance's own cache before each tick
+	if cfg.CandleCacheDir == "" {
+		if cfg.CandleCacheDir, err = os.MkdirTemp("", "ninetyfive-candles"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(cfg.CandleCacheDir)
+	}
+	cache, err := candles.Open(cfg.CandleCacheDir)
+	if err != nil {
+		return err
+	}
+	o, closeFn, err := openOneShot(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	// One tick at a time, so two deliveries for a pair never race on its state
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		var env pushEnvelope
+		if err := json.NewDecoder(r.Body).Decode(&env); err != nil {
+			http.Error(w, "invalid push message: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		pairCfg, pair, err := pushConfig(cfg, env)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		rec, err := store.Load(r.Context(), pair)
+		if err != nil {
+			o.log.Error().Err(err).Msg("failed to load the state of %s", pair)
+			http.Error(w, "could not load the state", http.StatusInternalServerError)
+			return
+		}
+		var h *trader.Handoff
+		if rec != nil {
+			h = &rec.Handoff
+			restoreBars(cache, pairCfg, pair, *rec, o.log)
+		}
+		// Pub/Sub delivers at least once - a tick that ran after the message was published already covered it
+		if h != nil && !env.Message.PublishTime.IsZero() && h.LastTick.After(env.Message.PublishTime) {
+			o.log.Info().Msg("skipping message %s for %s - already ticked since it was published",
+				env.Message.MessageId, pair)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Once the tick ran it is acknowledged whatever happened, as a redelivery could trade the signal twice
+		next, err := o.run(ctx, pairCfg, h, *settle)
+		if err != nil {
+			o.log.Error().Err(err).Msg("failed to tick %s for message %s", pair, env.Message.MessageId)
+		}
+		if next != nil {
+			saved := state.Record{Handoff: *next, Timeframe: candles.TimeframeFromConfig(pairCfg)}
+			if saved.Bars, err = cache.Load(pair, saved.Timeframe); err != nil {
+				o.log.Error().Err(err).Msg("failed to read the bars of %s - saving the state without them", pair)
+			}
+			if err = store.Save(ctx, pair, saved); err != nil {
+				o.log.Error().Err(err).Msg("failed to save the state of %s", pair)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *settle)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	o.log.Info().Msg("serving Pub/Sub pushes on %s", *addr)
+	if err = srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// restoreBars merges the bars saved with the pair's state into the candle cache the tick warms the strategies up on -
+// bars of another timeframe, saved before the bar interval or pricing changed, are left out
+func restoreBars(cache *candles.Cache, cfg *configs.Config, pair common.Pair, rec state.Record, log logger.Logger) {
+	if len(rec.Bars) == 0 {
+		return
+	}
+	if timeframe := candles.TimeframeFromConfig(cfg); rec.Timeframe != timeframe {
+		log.Warn().Msg("ignoring the saved %s bars of %s - the strategies run on %s bars", rec.Timeframe, pair, timeframe)
+		return
+	}
+	if _, err := cache.Import(pair, rec.Timeframe, rec.Bars); err != nil {
+		log.Error().Err(err).Msg("failed to restore the bars of %s - the strategies may start cold", pair)
+	}
+}
+
+// pushConfig returns the config trading the pair the message carries - a copy of the base config with the pair's
+// mints in place of the configured currencies
+func pushConfig(cfg *configs.Config, env pushEnvelope) (*configs.Config, common.Pair, error) {
+	var req pushRequest
+	if len(env.Message.Data) > 0 {
+		if err := json.Unmarshal(env.Message.Data, &req); err != nil {
+			return nil, common.Pair{}, fmt.Errorf("invalid message data: %w", err)
+		}
+	}
+	req.Pair = cmp.Or(req.Pair, env.Message.Attributes["pair"])
+	if req.Pair == "" {
+		pair, err := common.NewPairFromConfig(cfg)
+		return cfg, pair, err
+	}
+
+	base, quote, ok := strings.Cut(req.Pair, "/")
+	if !ok {
+		return nil, common.Pair{}, fmt.Errorf("pair %q is not BASE/QUOTE", req.Pair)
+	}
+	pair, err := common.NewPair(base, quote)
+	if err != nil {
+		return nil, common.Pair{}, err
+	}
+	c := *cfg
+	if c.PairOrientation == common.OrientationConventional {
+		c.BaseCurrency, c.QuoteCurrency = pair.Base, pair.Quote
+	} else {
+		c.BaseCurrency, c.QuoteCurrency = pair.Quote, pair.Base
+	}
+	// Each pair's equity curve is a file of its own
+	if ext := filepath.Ext(c.EquityFile); c.EquityFile != "" {
+		c.EquityFile = fmt.Sprintf("%s_%s_%s%s", strings.TrimSuffix(c.EquityFile, ext), pair.Base, pair.Quote, ext)
+	}
+	return &c, pair, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+	"github.com/josephawallace/ninetyfive/internal/risk"
+)
+
+// quote prints what Jupiter offers for a swap right now - price, impact, route, and expiration - and how far it sits
+// from the oracle price the strategies trade on, without sending anything
+func quote(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("quote", flag.ContinueOnError)
+	pairFlag := fs.String("pair", "", "pair as BASE_MINT/QUOTE_MINT, defaults to the configured pair")
+	sideFlag := fs.String("side", "", "buy (spend quote for base) or sell (spend base for quote)")
+	amountFlag := fs.String("amount", "", "amount to spend, in the quote asset for buy and the base asset for sell")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pair, err := parsePair(cfg, *pairFlag)
+	if err != nil {
+		return err
+	}
+	side, err := parseSide(*sideFlag)
+	if err != nil {
+		return err
+	}
+	amount, err := configs.ParseAmount(*amountFlag)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	if err = cfg.LoadSecrets(ctx, sm); err != nil {
+		return err
+	}
+	j, err := jupiter.NewJupiter(cfg)
+	if err != nil {
+		return err
+	}
+
+	q, err := j.Quote(ctx, pair, side, amount.Float())
+	if err != nil {
+		return err
+	}
+	ps, err := pricing.NewCompositeFromConfig(cfg, pricing.Timed(j.PriceSample))
+	if err != nil {
+		return err
+	}
+	pq, err := ps.PairPrice(ctx, pair.Base, pair.Quote)
+	if err != nil {
+		return err
+	}
+	oracle := pq.Price
+
+	fmt.Printf("%s %f %s -> %f %s\n", side, q.InAmount, q.InputMint, q.OutAmount, q.OutputMint)
+	fmt.Printf("price    %f (oracle %f, %+.1f bps)\n", q.Price, oracle, (q.Price-oracle)/oracle*10_000)
+	fmt.Printf("impact   %.4f%%, slippage %d bps\n", q.PriceImpactPct*100, q.SlippageBps)
+	fmt.Printf("route    %s\n", q.RouteSummary())
+	fmt.Printf("expires  %s (in %s)\n", q.ExpiresAt.Format(time.RFC3339), time.Until(q.ExpiresAt).Round(time.Second))
+	if err = risk.NewGuard(cfg).CheckQuote(side, oracle, q.Price); err != nil {
+		fmt.Printf("rejected %s\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// refreshConfig re-reads the config file and applies the order sizes when they changed, logging the other changed
+// keys as waiting on a restart - it is run by the scheduler, and returns the config now applied, the current one being
+// kept when the file no longer loads or validates
+func refreshConfig(path string, applied *configs.Config, t *trader.Trader, log logger.Logger) (*configs.Config, error) {
+	next, err := configs.NewConfig(path)
+	if err != nil {
+		return applied, fmt.Errorf("could not re-read the config - keeping the current one: %w", err)
+	}
+	if err = next.Validate(); err != nil {
+		return applied, fmt.Errorf("the refreshed config does not validate - keeping the current one: %w", err)
+	}
+
+	if next.BuyOrderSize != applied.BuyOrderSize || next.SellOrderSize != applied.SellOrderSize {
+		if err = t.SetOrderSizes(next.BuyOrderSize.Float(), next.SellOrderSize.Float()); err != nil {
+			return applied, err
+		}
+	}
+
+	was, now := applied.Redacted(), next.Redacted()
+	var pending []string
+	for _, key := range slices.Sorted(maps.Keys(now)) {
+		if key != "buy_order_size" && key != "sell_order_size" && !reflect.DeepEqual(was[key], now[key]) {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) > 0 {
+		log.Warn().Msg("config changed - %s only take effect after a restart", strings.Join(pending, ", "))
+	}
+	return next, nil
+}
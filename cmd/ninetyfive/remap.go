@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// remapMint carries the ledger over from a mint that migrated to its new mint, so positions survive the migration,
+// or accepts a mint's changed metadata as its new baseline - either lifts the halt the change caused once the bot
+// restarts, and the bot must be stopped while it runs since both write the ledger
+func remapMint(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("remap-mint", flag.ContinueOnError)
+	from := fs.String("from", "", "mint the token migrated from")
+	to := fs.String("to", "", "mint the token migrated to")
+	ratio := fs.Float64("ratio", 1, "units of the new mint each unit of the old one became")
+	accept := fs.String("accept", "", "mint whose changed metadata is expected, recorded again on the next check")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*accept == "") == (*from == "" && *to == "") {
+		return fmt.Errorf("pass either -from and -to, or -accept")
+	}
+	for _, mint := range []string{*from, *to, *accept} {
+		if _, err := solana.PublicKeyFromBase58(mint); mint != "" && err != nil {
+			return fmt.Errorf("invalid mint %q: %w", mint, err)
+		}
+	}
+
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if sm != nil {
+		defer sm.Close()
+	}
+	cfg.AttachSecretManager(sm)
+	l, err := openLedger(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if *accept != "" {
+		if err = l.ForgetMint(*accept); err != nil {
+			return err
+		}
+		fmt.Printf("the metadata of %s will be recorded again on the next check\n", *accept)
+		return nil
+	}
+	if *from == "" || *to == "" || *from == *to {
+		return fmt.Errorf("-from and -to must be two different mints")
+	}
+	n, err := l.RemapMint(*from, *to, *ratio)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("remapped %d ledger record(s) from %s to %s at %g new per old\n", n, *from, *to, *ratio)
+	for _, c := range []struct{ key, mint string }{
+		{"base_currency", cfg.BaseCurrency}, {"quote_currency", cfg.QuoteCurrency},
+	} {
+		if c.mint == *from {
+			fmt.Printf("set %s to %s before restarting the bot\n", c.key, *to)
+		}
+	}
+	if cfg.CandleCacheDir != "" {
+		fmt.Printf("cached bars of the old mint in %s are not carried over - backfill the new mint's\n",
+			cfg.CandleCacheDir)
+	}
+	return nil
+}
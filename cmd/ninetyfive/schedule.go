@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/scheduler"
+	"github.com/josephawallace/ninetyfive/internal/sheets"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// The recurring jobs, the keys of their cron expressions in the schedules config - there is no profit sweep, as the
+// bot only ever swaps within its own wallet and has nowhere configured to send profits to
+const (
+	jobConfigRefresh    = "config_refresh"
+	jobDustSweep        = "dust_sweep"
+	jobMaintenance      = "maintenance"
+	jobReconciliation   = "reconciliation"
+	jobReport           = "report"
+	jobSecretKeyRefresh = "secret_key_refresh"
+	jobSheetsExport     = "sheets_export"
+)
+
+// newScheduler registers the recurring jobs the config enables, the config being refreshed from path
+func newScheduler(ctx context.Context, path string, cfg *configs.Config, t *trader.Trader, j *jupiter.Jupiter,
+	log logger.Logger) (*scheduler.Scheduler, error) {
+	sch := scheduler.NewScheduler(log)
+
+	maintenance := time.Duration(0)
+	if len(cfg.MaintenanceWindows) > 0 {
+		maintenance = time.Minute
+	}
+	if err := sch.Add(jobMaintenance, schedule(cfg, jobMaintenance, maintenance), true, t.CheckMaintenance); err != nil {
+		return nil, err
+	}
+
+	rn := notifier.NewReportNotifier(cfg)
+	if err := sch.Add(jobReport, schedule(cfg, jobReport, cfg.ReportInterval), false, func(ctx context.Context) error {
+		return t.Report(ctx, rn)
+	}); err != nil {
+		return nil, err
+	}
+
+	refresh := time.Duration(0)
+	if cfg.NeedsSecretKey() {
+		refresh = cfg.SecretKeyTtl
+	}
+	if err := sch.Add(jobSecretKeyRefresh, schedule(cfg, jobSecretKeyRefresh, refresh), false,
+		func(ctx context.Context) error {
+			return refreshSecretKey(ctx, cfg, j, log)
+		}); err != nil {
+		return nil, err
+	}
+
+	reconcile := time.Duration(0)
+	if cfg.NeedsSecretKey() {
+		reconcile = cfg.WalletActivityInterval
+	}
+	if err := sch.Add(jobReconciliation, schedule(cfg, jobReconciliation, reconcile), true, t.ReconcileWallet); err != nil {
+		return nil, err
+	}
+
+	applied := cfg
+	if err := sch.Add(jobConfigRefresh, schedule(cfg, jobConfigRefresh, cfg.ConfigRefreshInterval), false,
+		func(context.Context) error {
+			var err error
+			applied, err = refreshConfig(path, applied, t, log)
+			return err
+		}); err != nil {
+		return nil, err
+	}
+
+	sweep := time.Duration(0)
+	if cfg.NeedsSecretKey() {
+		sweep = cfg.DustSweepInterval
+	}
+	if err := sch.Add(jobDustSweep, schedule(cfg, jobDustSweep, sweep), false, t.SweepDust); err != nil {
+		return nil, err
+	}
+
+	if cfg.SheetsSpreadsheetId != "" {
+		ss, err := sheets.NewSpreadsheet(ctx, cfg.SheetsSpreadsheetId)
+		if err != nil {
+			return nil, err
+		}
+		if err = sch.Add(jobSheetsExport, schedule(cfg, jobSheetsExport, cfg.SheetsExportInterval), true,
+			func(ctx context.Context) error {
+				return t.ExportSheets(ctx, ss)
+			}); err != nil {
+			return nil, err
+		}
+	}
+	return sch, nil
+}
+
+// schedule returns the cron expression the job runs on - the one configured for it, empty to disable it, or else
+// every interval, the job being disabled when that is zero
+func schedule(cfg *configs.Config, job string, interval time.Duration) string {
+	if spec, ok := cfg.Schedules[job]; ok {
+		return spec
+	}
+	if interval <= 0 {
+		return ""
+	}
+	return "@every " + interval.String()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// refreshSecretKey re-fetches the secret key and rebuilds the signer when it has been rotated - it is run by the
+// scheduler, and a failed fetch keeps the current signer until the next run
+func refreshSecretKey(ctx context.Context, cfg *configs.Config, j *jupiter.Jupiter, log logger.Logger) error {
+	changed, err := cfg.ReloadSecretKey(ctx)
+	if err != nil {
+		return fmt.Errorf("could not refresh the secret key - keeping the current signer: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+
+	previous := j.Signer()
+	pk, err := j.ReloadSigner()
+	if err != nil {
+		return fmt.Errorf("could not rebuild the signer from the rotated secret key - keeping the current signer: %w", err)
+	}
+	if pk != previous {
+		log.Warn().Msg("secret key rotated - swaps are now signed by %s instead of %s, move funds accordingly", pk, previous)
+	} else {
+		log.Info().Msg("secret key rotated")
+	}
+	return nil
+}
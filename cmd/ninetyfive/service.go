@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// Exit codes follow sysexits, so service managers can tell a config that will never work - which restarting cannot
+// fix, e.g. systemd's RestartPreventExitStatus=78 - from a failure worth retrying
+const (
+	exitFailure  = 1  // a subcommand failed
+	exitTempFail = 75 // EX_TEMPFAIL - a dependency was unavailable, restarting may help
+	exitConfig   = 78 // EX_CONFIG - the config is invalid or incomplete, fix it before restarting
+)
+
+// fatal reports the error and exits with the code - deferred calls do not run, so it is only used before the loop
+// has anything to clean up
+func fatal(code int, err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(code)
+}
+
+// writePidFile records the process ID for service managers, returning a function that removes it again
+func writePidFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return nil, err
+	}
+	return func() { _ = os.Remove(path) }, nil
+}
+
+// health is what the health file reports about the loop
+type health struct {
+	Pid       int       `json:"pid"`
+	Status    string    `json:"status"` // running, paused, or stopped
+	LastTick  time.Time `json:"last_tick"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// writeHealth rewrites the health file every interval until the context is cancelled, then marks it stopped - a
+// watchdog treats a file whose last tick is stale as a stalled loop
+func writeHealth(ctx context.Context, path string, interval time.Duration, t *trader.Trader, log logger.Logger) {
+	write := func(status string) {
+		s := t.Status()
+		if status == "" {
+			status = "running"
+			if s.Paused {
+				status = "paused"
+			}
+		}
+		data, _ := json.Marshal(health{Pid: os.Getpid(), Status: status, LastTick: s.LastTick, UpdatedAt: time.Now()})
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+			log.Error().Err(err).Msg("failed to write the health file")
+			return
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			log.Error().Err(err).Msg("failed to write the health file")
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		write("")
+		select {
+		case <-ctx.Done():
+			write("stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/orders"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// status queries the admin API of a running instance and prints a concise summary - it exits non-zero when the
+// instance is unreachable or its loop has stalled so it can back cron health checks
+func status(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	addr := fs.String("addr", defaultAdminURL(cfg), "base URL of the running instance's admin API")
+	key := fs.String("key", os.Getenv("NF_ADMIN_API_KEY"), "admin API key with at least read access")
+	stale := fs.Duration("stale", 3*cfg.Interval, "age of the last tick considered stalled")
+	asJson := fs.Bool("json", false, "print the raw status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(*addr, "/")+"/api/status", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", *key)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status request failed with %s", res.Status)
+	}
+
+	var s trader.Status
+	if err = json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return err
+	}
+
+	if *asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(s); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("instance:    %s (%s, strategy %s, %s)\n", s.Labels.Instance, s.Labels.Environment, s.Labels.Strategy,
+			s.Labels.Mode)
+		fmt.Printf("uptime:      %s\n", s.Uptime)
+		fmt.Printf("paused:      %t\n", s.Paused)
+		fmt.Printf("last tick:   %s (%s ago)\n", s.LastTick.Format(time.RFC3339), time.Since(s.LastTick).Round(time.Second))
+		fmt.Printf("last price:  %f\n", s.LastPrice)
+		fmt.Printf("rsi:         %.2f\n", s.Rsi)
+		fmt.Printf("regime:      %s (realized vol %.4f, atr %f, rsi std %.2f)\n", s.Metrics.Regime,
+			s.Metrics.RealizedVol, s.Metrics.Atr, s.Metrics.RsiStd)
+		fmt.Printf("last signal: %s\n", s.LastSignal)
+		for _, m := range s.Strategies {
+			fmt.Printf("strategy:    %s rsi=%.2f (prev %.2f) signal line=%.2f last=%s@%d pinned=%d\n", m.Name,
+				m.Snapshot.Rsi, m.Snapshot.PrevRsi, m.Snapshot.SignalLine, m.Snapshot.LastSignal,
+				m.Snapshot.LastSignalIndex, m.Snapshot.PinnedBars)
+		}
+		for _, d := range s.Dependencies {
+			fmt.Printf("dependency:  %s p50=%.0fms p90=%.0fms p99=%.0fms errors=%.1f%% of %d\n", d.Name, d.P50Ms, d.P90Ms,
+				d.P99Ms, d.ErrorRate*100, d.Calls)
+		}
+		fmt.Printf("order sizes: buy=%f sell=%f\n", s.BuySize, s.SellSize)
+		if s.CapitalBudget > 0 {
+			fmt.Printf("budget:      %f deployed of %f\n", max(s.Position.Quote, 0), s.CapitalBudget)
+		}
+		fmt.Printf("in-flight:   %d %s\n", len(s.InFlight), strings.Join(s.InFlight, " "))
+		for _, o := range s.Orders {
+			for _, sl := range o.Slices {
+				if sl.Status == orders.StatusApproval {
+					fmt.Printf("approval:    slice %d of order %s proposed as %s %s ago\n", sl.Index+1, o.Id, sl.Proposal,
+						time.Since(sl.SentAt).Round(time.Second))
+				}
+			}
+		}
+		if s.ProfitLock != nil {
+			fmt.Printf("profit lock: floor %f since the %f milestone\n", s.ProfitLock.Floor, s.ProfitLock.Milestone)
+		}
+		if s.BreakerUntil != nil {
+			fmt.Printf("breaker:     open until %s\n", s.BreakerUntil.Format(time.RFC3339))
+		}
+		if s.MintHalt != "" {
+			fmt.Printf("mint halt:   %s\n", s.MintHalt)
+		}
+		for _, d := range s.Delegations {
+			fmt.Printf("delegation:  %f of %s spendable from %s (allowance %f, balance %f)\n", d.Spendable, d.Mint,
+				d.Account, d.Allowance, d.Balance)
+		}
+		for _, tr := range s.Triggers {
+			fmt.Printf("trigger:     %s\n", tr)
+		}
+		if s.Outage != nil {
+			fmt.Printf("outage:      %s\n", s.Outage)
+		}
+		if s.StalePrices > 0 {
+			fmt.Printf("stale:       %d ticks skipped on a stale price\n", s.StalePrices)
+		}
+		if len(s.Congestion) > 0 {
+			fmt.Printf("congestion:  %s\n", strings.Join(s.Congestion, ", "))
+		}
+		if s.Benchmark != nil {
+			fmt.Printf("benchmark:   %s\n", s.Benchmark)
+		}
+		if s.Equity != nil {
+			fmt.Printf("equity:      %s\n", s.Equity)
+		}
+		if s.Reporting != nil {
+			fmt.Printf("%-12s %s\n", "in "+s.Reporting.Currency+":", s.Reporting)
+		}
+		for _, mint := range slices.Sorted(maps.Keys(s.PlatformFees)) {
+			fmt.Printf("fees:        %f %s collected\n", s.PlatformFees[mint], mint)
+		}
+		for _, mint := range slices.Sorted(maps.Keys(s.ExternalFlows)) {
+			fmt.Printf("external:    %+f %s moved outside the bot\n", s.ExternalFlows[mint], mint)
+		}
+		for _, d := range s.Dust {
+			outcome := "converted"
+			if d.Skipped != "" {
+				outcome = "kept - " + d.Skipped
+			}
+			fmt.Printf("dust:        %f %s worth $%f %s\n", d.Amount, d.Mint, d.ValueUsd, outcome)
+		}
+		if s.AbTest != nil {
+			fmt.Printf("A/B test:    %s\n", s.AbTest)
+		}
+		if s.Wallet != nil {
+			fmt.Printf("wallet:      %s worth $%f (%f base, %f quote)\n", s.Wallet.Address, s.Wallet.ValueUsd,
+				s.Wallet.Base, s.Wallet.Quote)
+		}
+	}
+
+	if !s.LastTick.IsZero() && time.Since(s.LastTick) > *stale {
+		return fmt.Errorf("last tick is older than %s", *stale)
+	}
+	return nil
+}
+
+// defaultAdminURL derives a local URL from the configured listen address
+func defaultAdminURL(cfg *configs.Config) string {
+	addr := cfg.AdminAddr
+	if addr == "" {
+		addr = ":8080"
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	return "http://" + addr
+}
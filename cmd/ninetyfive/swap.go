@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/anomaly"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/position"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+	"github.com/josephawallace/ninetyfive/internal/risk"
+)
+
+// swap executes a single manual swap with the bot's wallet - it applies the same pre-trade checks as the loop, prints
+// the quote, and only sends the swap when confirmed with -yes
+func swap(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("swap", flag.ContinueOnError)
+	pairFlag := fs.String("pair", "", "pair as BASE_MINT/QUOTE_MINT, defaults to the configured pair")
+	sideFlag := fs.String("side", "", "buy (spend quote for base) or sell (spend base for quote)")
+	amountFlag := fs.String("amount", "", "amount to spend, in the quote asset for buy and the base asset for sell")
+	slippageBps := fs.Int("slippage-bps", 0, "fixed slippage tolerance in basis points, 0 for automatic")
+	yes := fs.Bool("yes", false, "send the swap instead of only quoting it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pair, err := parsePair(cfg, *pairFlag)
+	if err != nil {
+		return err
+	}
+	side, err := parseSide(*sideFlag)
+	if err != nil {
+		return err
+	}
+	amount, err := configs.ParseAmount(*amountFlag)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if *slippageBps < 0 {
+		return fmt.Errorf("slippage must not be negative")
+	}
+
+	j, l, closeFn, err := openTrading(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	// The manual swap is held to the same checks as the loop's orders
+	if cfg.MinSolBalance > 0 {
+		balance, err := j.SolBalance(ctx)
+		if err != nil {
+			return err
+		}
+		if balance < cfg.MinSolBalance.Float() {
+			return fmt.Errorf("SOL balance %f is below the %f minimum", balance, cfg.MinSolBalance.Float())
+		}
+	}
+	guard := risk.NewGuard(cfg)
+	pos := position.FromTrades(pair, l.Trades())
+	if err = guard.CheckInventory(side, amount.Float(), pos.Base); err != nil {
+		return err
+	}
+
+	ps, err := pricing.NewCompositeFromConfig(cfg, pricing.Timed(j.PriceSample))
+	if err != nil {
+		return err
+	}
+	pq, err := ps.PairPrice(ctx, pair.Base, pair.Quote)
+	if err != nil {
+		return err
+	}
+	price := pq.Price
+	q, err := j.Quote(ctx, pair, side, amount.Float())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %f %s -> %f %s at %f (market %f, impact %.4f%%, %s position)\n", side, q.InAmount,
+		q.InputMint, q.OutAmount, q.OutputMint, q.Price, price, q.PriceImpactPct*100,
+		pos.Classify(side, amount.Float(), price))
+	if err = guard.CheckQuote(side, price, q.Price); err != nil {
+		return err
+	}
+	if !*yes {
+		fmt.Println("not sent - re-run with -yes to execute")
+		return nil
+	}
+
+	return sendManualSwap(ctx, cfg, j, l, "manual", pair, side, amount.Float(), q.Price, *slippageBps)
+}
+
+// sendManualSwap sends the swap, or proposes it and waits for the multisig to execute it, follows it to finalization,
+// records the fill in the ledger, and notifies - kind labels the order id and the notification
+func sendManualSwap(ctx context.Context, cfg *configs.Config, j *jupiter.Jupiter, l *ledger.Ledger, kind string,
+	pair common.Pair, side common.Signal, amount float64, price float64, slippageBps int) error {
+	log := newLogger(cfg, nil)
+	n := notifier.NewNotifier(cfg)
+
+	orderId := fmt.Sprintf("%s-%s-%d", kind, side, time.Now().UnixNano())
+	txId, err := j.ExecuteWithSlippage(ctx, pair, side, amount, slippageBps, orderId)
+	if err != nil {
+		return err
+	}
+	for _, u := range j.TakeUntaggedSwaps() {
+		log.Warn().Err(u.Err).Msg("swap for order %s was sent without its memo", u.Tag)
+	}
+	// A proposal to the multisig is only a swap once its members approved and executed it
+	if j.Proposing() {
+		fmt.Printf("proposed %s - waiting for the multisig to approve and execute it\n", txId)
+		if txId, err = j.AwaitExecution(ctx, txId); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("submitted %s\n", txId)
+	if err = j.MonitorTx(ctx, txId, log, nil); err != nil {
+		return err
+	}
+
+	var compute *common.ComputeBudget
+	if budget, ok := j.ComputeBudget(txId); ok {
+		compute = &budget
+	}
+	inputMint, outputMint, _ := pair.Mints(side)
+	trade := ledger.Trade{
+		TxId:        txId,
+		OrderId:     orderId,
+		Side:        side,
+		InputMint:   inputMint,
+		OutputMint:  outputMint,
+		InputAmount: amount,
+		Price:       price,
+		Time:        time.Now(),
+		Compute:     compute,
+		Snapshot:    &ledger.Snapshot{SignalTime: time.Now(), Price: price, Config: cfg.Redacted()},
+	}
+	if fill, err := j.Fill(ctx, txId); err != nil {
+		log.Warn().Err(err).Msg("failed to read the fill of %s", txId)
+	} else {
+		trade.OutputAmount = fill.OutAmount
+		trade.FeeLamports = fill.FeeLamports
+		// A one-off swap has no fees of its own to compare with, so it is judged against the ledger's recorded ones
+		d := anomaly.NewDetector(cfg, n, log)
+		var fees []uint64
+		for _, t := range l.Trades() {
+			if t.FeeLamports > 0 {
+				fees = append(fees, t.FeeLamports)
+			}
+		}
+		d.SeedFees(fees)
+		d.CheckFill(ctx, txId, fill)
+	}
+	if err = l.RecordTrade(trade); err != nil {
+		return fmt.Errorf("swap %s filled but could not be recorded in the ledger: %w", txId, err)
+	}
+
+	alert := notifier.Alert{
+		Severity: notifier.SeverityInfo,
+		Title:    fmt.Sprintf("%s %s executed", kind, side),
+		Body:     fmt.Sprintf("%s %f of %s at %f - transaction %s", side, amount, pair, price, txId),
+	}
+	if err = n.Notify(ctx, alert); err != nil {
+		log.Error().Err(err).Msg("failed to send notification")
+	}
+	fmt.Printf("finalized %s\n", txId)
+	return nil
+}
+
+// parsePair reads a BASE_MINT/QUOTE_MINT pair, falling back to the configured pair when empty
+func parsePair(cfg *configs.Config, s string) (common.Pair, error) {
+	if s == "" {
+		return common.NewPairFromConfig(cfg)
+	}
+	base, quote, ok := strings.Cut(s, "/")
+	if !ok {
+		return common.Pair{}, fmt.Errorf("pair must be BASE_MINT/QUOTE_MINT, got %q", s)
+	}
+	return common.NewPair(base, quote)
+}
+
+// parseSide reads buy or sell
+func parseSide(s string) (common.Signal, error) {
+	switch strings.ToUpper(s) {
+	case string(common.BuySignal):
+		return common.BuySignal, nil
+	case string(common.SellSignal):
+		return common.SellSignal, nil
+	default:
+		return "", fmt.Errorf("side must be buy or sell, got %q", s)
+	}
+}
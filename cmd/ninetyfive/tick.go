@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/broadcast"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// tick performs exactly one iteration of the loop and exits - it loads the state the previous tick saved, fetches and
+// processes the price, trades on the signal, waits for the orders to settle, and saves the state for the next tick,
+// so a scheduler (Cloud Scheduler, cron) can drive the bot in place of the long-running loop - the state file and the
+// candle cache must live on storage that outlasts the process, and the interval should match the schedule so the
+// cached bars are not mistaken for a gap
+func tick(ctx context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("tick", flag.ContinueOnError)
+	settle := fs.Duration("settle", cfg.OrderTimeout, "time allowed for the tick's orders to settle before exiting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cfg.StateFile == "" {
+		return fmt.Errorf("tick needs a state_file to carry the state from one tick to the next")
+	}
+
+	o, closeFn, err := openOneShot(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	h, err := trader.LoadHandoff(cfg.StateFile)
+	if err != nil {
+		return fmt.Errorf("could not load the state from %s: %w", cfg.StateFile, err)
+	}
+	// Save the state even when the tick fails, so a failed price fetch does not lose the tick before it
+	next, tickErr := o.run(ctx, cfg, h, *settle)
+	if next == nil {
+		return tickErr
+	}
+	if err = trader.SaveHandoff(cfg.StateFile, *next); err != nil {
+		return fmt.Errorf("could not save the state to %s: %w", cfg.StateFile, err)
+	}
+	return tickErr
+}
+
+// oneShot is what one-shot ticks share - everything but the trader of the pair being ticked
+type oneShot struct {
+	log logger.Logger
+	j   *jupiter.Jupiter
+	l   *ledger.Ledger
+	n   *notifier.Aggregator
+	pub broadcast.Publisher
+}
+
+// openOneShot loads the secrets and opens the clients a one-shot tick trades through - the returned function releases
+// them
+func openOneShot(ctx context.Context, cfg *configs.Config) (*oneShot, func(), error) {
+	sm, err := newSecretManager(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	var lc *logging.Client
+	closeFn := func() {
+		if lc != nil {
+			lc.Close()
+		}
+		if sm != nil {
+			sm.Close()
+		}
+	}
+	fail := func(err error) (*oneShot, func(), error) {
+		closeFn()
+		return nil, nil, err
+	}
+
+	if cfg.NeedsSecretKey() {
+		if err = cfg.LoadSecrets(ctx, sm); err != nil {
+			return fail(err)
+		}
+	} else {
+		cfg.AttachSecretManager(sm)
+	}
+	if cfg.Environment == configs.ProductionEnvironment {
+		if lc, err = logging.NewClient(ctx, cfg.GcpProjectId); err != nil {
+			return fail(err)
+		}
+	}
+
+	o := &oneShot{log: newLogger(cfg, lc), n: notifier.NewNotifier(cfg)}
+	if o.j, err = jupiter.NewJupiter(cfg); err != nil {
+		return fail(err)
+	}
+	if o.l, err = openLedger(ctx, cfg); err != nil {
+		return fail(err)
+	}
+	if o.pub, err = broadcast.NewPublisher(ctx, cfg); err != nil {
+		return fail(err)
+	}
+	return o, closeFn, nil
+}
+
+// run ticks the pair the config trades once - the strategies are tuned when configured and rebuilt from the candle
+// cache, and the runtime state carried over from the previous tick - and returns the state to hand to the next tick, nil when the trader could not
+// be set up
+func (o *oneShot) run(ctx context.Context, cfg *configs.Config, h *trader.Handoff,
+	settle time.Duration) (*trader.Handoff, error) {
+	if cfg.GridAutoTune {
+		autoTune(cfg, o.log)
+	}
+	e, err := ensemble.NewEnsembleFromConfig(cfg, o.log)
+	if err != nil {
+		return nil, err
+	}
+	t, err := trader.NewTrader(cfg, o.j, e, o.l, o.n, o.pub, o.log)
+	if err != nil {
+		return nil, err
+	}
+	if err = warmUp(ctx, cfg, t); err != nil {
+		return nil, err
+	}
+	if h != nil {
+		t.Restore(*h)
+	}
+
+	tickErr := t.Tick(ctx)
+	if cfg.WalletActivityInterval > 0 {
+		if err = t.ReconcileWallet(ctx); err != nil {
+			o.log.Warn().Err(err).Msg("failed to reconcile the wallet's activity")
+		}
+	}
+	settleCtx, cancel := context.WithTimeout(ctx, settle)
+	defer cancel()
+	if err = t.Settle(settleCtx); err != nil {
+		o.log.Warn().Err(err).Msg("exiting with orders still open - they are recorded as in flight")
+	}
+	next := t.Handoff()
+	return &next, tickErr
+}
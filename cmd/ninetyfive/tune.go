@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/autotune"
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// tuneGrids recommends each strategy's grid count and no-trade zone from the RSI distribution of the cached bars, so
+// about the targeted crossings per week are expected
+func tuneGrids(_ context.Context, cfg *configs.Config, args []string) error {
+	fs := flag.NewFlagSet("tune", flag.ContinueOnError)
+	perWeek := fs.Float64("per-week", cfg.GridAutoTunePerWeek, "crossings per week to aim for")
+	asJson := fs.Bool("json", false, "print the reports as JSON, with every candidate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *perWeek <= 0 {
+		return fmt.Errorf("-per-week must be positive")
+	}
+
+	bars, err := tuningBars(cfg)
+	if err != nil {
+		return err
+	}
+	var (
+		reports    []autotune.Report
+		configured []configs.Strategy
+	)
+	for _, s := range cfg.Strategies {
+		r, err := autotune.Tune(s, bars, *perWeek)
+		if errors.Is(err, autotune.ErrAsymmetric) {
+			fmt.Fprintf(os.Stderr, "strategy %s: %v - its sell side is configured apart from its buy side\n", s.Name, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("strategy %s: %w", s.Name, err)
+		}
+		reports = append(reports, r)
+		configured = append(configured, s)
+	}
+
+	if *asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	}
+	for i, r := range reports {
+		s := configured[i]
+		fmt.Println(r)
+		fmt.Printf("  configured: %d grids, no-trade zone %s\n", s.Grids, s.NoTradeZone)
+	}
+	return nil
+}
+
+// autoTune sets each strategy's grid count and no-trade zone from the cached bars before the ensemble is built, leaving
+// a strategy as configured when there is nothing to tune it on
+func autoTune(cfg *configs.Config, log logger.Logger) {
+	bars, err := tuningBars(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("grid auto-tune skipped - keeping the configured grids")
+		return
+	}
+	for i := range cfg.Strategies {
+		s := &cfg.Strategies[i]
+		r, err := autotune.Tune(*s, bars, cfg.GridAutoTunePerWeek)
+		if err != nil {
+			log.Warn().Err(err).Msg("grid auto-tune skipped strategy %s - keeping its configured grids", s.Name)
+			continue
+		}
+		log.Info().Msg("grid auto-tune set %s (was %d grids, no-trade zone %s)", r, s.Grids, s.NoTradeZone)
+		r.Apply(s)
+	}
+}
+
+// tuningBars loads the pair's cached bars at the bar interval
+func tuningBars(cfg *configs.Config) ([]common.Bar, error) {
+	if cfg.CandleCacheDir == "" {
+		return nil, fmt.Errorf("tuning needs candle_cache_dir for the bars to analyze")
+	}
+	pair, err := common.NewPairFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := candles.Open(cfg.CandleCacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return cache.Load(pair, candles.TimeframeFromConfig(cfg))
+}
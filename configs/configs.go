@@ -1,13 +1,23 @@
 package configs
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1beta2"
 	"cloud.google.com/go/secretmanager/apiv1beta2/secretmanagerpb"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+
+	"github.com/josephawallace/ninetyfive/internal/secure"
 )
 
 const (
@@ -16,78 +26,437 @@ const (
 
 // Config defines the parameters for the application and is sourced via a YAML file and environment variables
 type Config struct {
-	BaseCurrency             string  `mapstructure:"base_currency"`
-	BuyOrderSize             float64 `mapstructure:"buy_order_size"`
-	CommitmentTimeoutSeconds int     `mapstructure:"commitment_timeout_seconds"`
-	Environment              string  `mapstructure:"environment"`
-	GcpProjectId             string  `mapstructure:"gcp_project_id"`
-	IntervalSeconds          int     `mapstructure:"interval_seconds"`
-	MaxRetriesTxMonitor      int     `mapstructure:"max_retries_tx_monitor"`
-	QuoteCurrency            string  `mapstructure:"quote_currency"`
-	SellOrderSize            float64 `mapstructure:"sell_order_size"`
-	SmSecretKeyName          string  `mapstructure:"sm_secret_key_name"`
-	SmSecretKeyVersion       int     `mapstructure:"sm_secret_key_version"`
-
-	secrets map[string]string
-	sm      *secretmanager.Client
+	AbMinBars                    int                      `mapstructure:"ab_min_bars" default:"30" usage:"bars the A/B test needs before it judges which configuration is better"`
+	AbSignificance               float64                  `mapstructure:"ab_significance" default:"0.05" usage:"p-value under which the A/B test calls a difference significant"`
+	AbStrategies                 []Strategy               `mapstructure:"ab_strategies" default:"[]" usage:"challenger Grid Managers, configured like strategies, paper-traded beside the live ones on the same bars to compare their PnL - empty disables A/B testing"`
+	AdminAddr                    string                   `mapstructure:"admin_addr" default:"" usage:"address the admin API listens on, e.g. ':8080' - empty disables it"`
+	AdminControlApiKeys          []string                 `mapstructure:"admin_control_api_keys" secret:"true" default:"[]" usage:"API keys (X-API-Key header) allowed to pause, resume, and resize orders"`
+	AdminControlEmails           []string                 `mapstructure:"admin_control_emails" default:"[]" usage:"Google identities allowed to pause, resume, and resize orders"`
+	AdminOidcAudience            string                   `mapstructure:"admin_oidc_audience" default:"" usage:"audience expected in Google identity tokens sent to the admin API"`
+	AdminReadApiKeys             []string                 `mapstructure:"admin_read_api_keys" secret:"true" default:"[]" usage:"API keys (X-API-Key header) allowed to read status"`
+	AdminReadEmails              []string                 `mapstructure:"admin_read_emails" default:"[]" usage:"Google identities allowed to read status"`
+	AmountRounding               string                   `mapstructure:"amount_rounding" default:"floor" usage:"how order amounts are rounded to the token's base units: floor, ceil, or bankers (half to even)"`
+	AnomalyBalanceInterval       time.Duration            `mapstructure:"anomaly_balance_interval" default:"5m" usage:"time between checks that the wallet's balances only change with the bot's trades - 0 disables"`
+	AnomalyBalanceTolerancePct   float64                  `mapstructure:"anomaly_balance_tolerance_pct" default:"1" usage:"percentage a balance may drift without a trade before it is flagged"`
+	AnomalyFeeMultiple           float64                  `mapstructure:"anomaly_fee_multiple" default:"5" usage:"flag a swap fee above this multiple of the recent median fee - 0 disables"`
+	AnomalyFillDeviationBps      float64                  `mapstructure:"anomaly_fill_deviation_bps" default:"100" usage:"flag a fill worse than its quote by more than this many basis points - 0 disables"`
+	AnomalyMaxFeeLamports        int                      `mapstructure:"anomaly_max_fee_lamports" default:"10000000" usage:"flag a swap fee above this many lamports - 0 disables"`
+	AnomalySignalsWithoutFill    int                      `mapstructure:"anomaly_signals_without_fill" default:"5" usage:"flag this many orders in a row settling without a fill - 0 disables"`
+	BarInterval                  time.Duration            `mapstructure:"bar_interval" default:"0s" usage:"length of the bars ticks are aggregated into - 0 makes every tick a bar of its own"`
+	BaseCurrency                 string                   `mapstructure:"base_currency" default:"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" usage:"base mint of the pair - see pair_orientation for how it is traded"`
+	BenchmarkCapital             float64                  `mapstructure:"benchmark_capital" default:"0" usage:"capital in the quote asset of the buy-and-hold benchmark the PnL is reported against - 0 uses the capital budget, else the wallet's holdings of the pair at start"`
+	BirdeyeApiKey                string                   `mapstructure:"birdeye_api_key" secret:"true" default:"" usage:"Birdeye API key used by the volume filter"`
+	BuyOrderSize                 Amount                   `mapstructure:"buy_order_size" default:"7" usage:"amount spent on each BUY, in the asset BUY spends"`
+	CandleBackfill               time.Duration            `mapstructure:"candle_backfill" default:"0s" usage:"on startup, fetch the bars missing from the candle cache over this trailing period from Birdeye - needs bar_interval and birdeye_api_key, 0 disables"`
+	CandleCacheDir               string                   `mapstructure:"candle_cache_dir" default:"./data/candles" usage:"directory every complete bar is cached in, one file per pair and timeframe - empty disables"`
+	CandleGapFill                bool                     `mapstructure:"candle_gap_fill" default:"true" usage:"on startup, fetch the bars missed since the newest cached bar from Birdeye and replay them before trading - without Birdeye, a stale cache is not replayed"`
+	CandleWarmupBars             int                      `mapstructure:"candle_warmup_bars" default:"100" usage:"cached bars replayed into the strategies on startup so they trade warm - 0 starts cold"`
+	CapitalAllocation            string                   `mapstructure:"capital_allocation" default:"" usage:"how strategies sharing the wallet split its capital - fixed keeps each strategy's budget, sharpe redistributes the budgets' total monthly by realized Sharpe, empty disables budgets"`
+	CapitalBudgets               map[string]float64       `mapstructure:"capital_budgets" default:"{}" keycase:"true" usage:"capital budgets in the quote asset keyed by strategy_id - BUYs are capped so a strategy never deploys more than its budget"`
+	CapitalLedgers               map[string]string        `mapstructure:"capital_ledgers" default:"{}" keycase:"true" usage:"ledger paths of the other strategies sharing the wallet keyed by strategy_id, read for their Sharpe ratios"`
+	CapitalMinShare              float64                  `mapstructure:"capital_min_share" default:"0.1" usage:"share of the budgets' total every strategy keeps under sharpe allocation, however it performed"`
+	ChaosConfirmDelay            time.Duration            `mapstructure:"chaos_confirm_delay" default:"30s" usage:"how late a confirmation arrives when chaos mode delays it"`
+	ChaosFaults                  []string                 `mapstructure:"chaos_faults" default:"[]" usage:"faults chaos mode injects: price_drop, confirm_delay, rpc_429, blockhash_expiry - empty injects all of them"`
+	ChaosRate                    float64                  `mapstructure:"chaos_rate" default:"0" usage:"chance, from 0 to 1, that each call injects a fault, to exercise retries and alerting - 0 disables chaos mode, which is refused in production"`
+	CircuitBreakerCooldown       time.Duration            `mapstructure:"circuit_breaker_cooldown" default:"5m" usage:"how long execution stays suspended after the volatility circuit breaker trips - indicators keep updating"`
+	CircuitBreakerMaxDrawdownPct float64                  `mapstructure:"circuit_breaker_max_drawdown_pct" default:"0" usage:"trip the circuit breaker when the equity falls more than this percentage below its peak within equity_stats_window - 0 disables"`
+	CircuitBreakerMaxMoveBps     float64                  `mapstructure:"circuit_breaker_max_move_bps" default:"0" usage:"trip the circuit breaker when the price moves more than this within circuit_breaker_window - 0 disables"`
+	CircuitBreakerMaxVol         float64                  `mapstructure:"circuit_breaker_max_vol" default:"0" usage:"trip the circuit breaker when the standard deviation of per-tick log returns within circuit_breaker_window exceeds this - 0 disables"`
+	CircuitBreakerWindow         time.Duration            `mapstructure:"circuit_breaker_window" default:"1m" usage:"window of ticks the circuit breaker measures price moves and volatility over"`
+	CommitmentTimeout            time.Duration            `mapstructure:"commitment_timeout" default:"30s" usage:"how long to follow a transaction's commitment status"`
+	ComputeUnitMargin            float64                  `mapstructure:"compute_unit_margin" default:"10" usage:"headroom, in percent, added to the compute units a swap's simulation consumed when tuning its limit"`
+	ComputeUnitPriceMax          int                      `mapstructure:"compute_unit_price_max" default:"1000000" usage:"most micro-lamports per compute unit a tuned swap pays for priority"`
+	ComputeUnitPricePercentile   float64                  `mapstructure:"compute_unit_price_percentile" default:"75" usage:"percentile of the priority fees recently paid on a swap's accounts that its compute unit price is set to"`
+	ComputeUnitTuning            bool                     `mapstructure:"compute_unit_tuning" default:"false" usage:"size each swap's compute unit limit from a simulation and price it from recent priority fees instead of Jupiter's dynamic values"`
+	ConfigRefreshInterval        time.Duration            `mapstructure:"config_refresh_interval" default:"0s" usage:"time between re-reads of the config file, applying changed order sizes and logging the other changed keys as needing a restart - 0 disables"`
+	CongestionActions            []string                 `mapstructure:"congestion_actions" default:"[]" usage:"what to do while the network is congested: raise_fees, widen_slippage, pause_entries - empty disables congestion monitoring"`
+	CongestionCheckInterval      time.Duration            `mapstructure:"congestion_check_interval" default:"1m" usage:"time between checks of the network's slot times, priority fees, and the bot's own swap failures"`
+	CongestionFailureMinSwaps    int                      `mapstructure:"congestion_failure_min_swaps" default:"3" usage:"fewest swaps in the window before their failure rate is taken as a congestion signal"`
+	CongestionFailureRatePct     float64                  `mapstructure:"congestion_failure_rate_pct" default:"30" usage:"share of the bot's recent swaps failing that signals congestion - 0 ignores failures"`
+	CongestionFailureWindow      time.Duration            `mapstructure:"congestion_failure_window" default:"15m" usage:"trailing window the bot's swap failure rate is measured over"`
+	CongestionFeeMultiplier      float64                  `mapstructure:"congestion_fee_multiplier" default:"2" usage:"multiplier of the priority fee while raise_fees is in effect"`
+	CongestionPriorityFee        int                      `mapstructure:"congestion_priority_fee" default:"0" usage:"median recent priority fee, in micro-lamports per compute unit, that signals congestion - 0 ignores fees"`
+	CongestionSlippageMaxBps     int                      `mapstructure:"congestion_slippage_max_bps" default:"1000" usage:"most dynamic slippage allowed while widen_slippage is in effect, up from 500 bps"`
+	CongestionSlotTimeMs         float64                  `mapstructure:"congestion_slot_time_ms" default:"600" usage:"average recent slot time, in milliseconds, that signals congestion - 0 ignores slot times"`
+	CongestionUnitPriceMax       int                      `mapstructure:"congestion_unit_price_max" default:"5000000" usage:"most micro-lamports per compute unit a tuned swap pays while raise_fees is in effect"`
+	DelegationCheckInterval      time.Duration            `mapstructure:"delegation_check_interval" default:"1m" usage:"how often the allowances the funding_wallet delegated to the bot are read - orders beyond what is left are vetoed, and 0 stops checking"`
+	DivergenceFilter             bool                     `mapstructure:"divergence_filter" default:"false" usage:"require RSI divergence to confirm signals"`
+	DivergenceLookback           int                      `mapstructure:"divergence_lookback" default:"14" usage:"bars searched for the prior low/high the divergence filter compares against"`
+	DriftHold                    bool                     `mapstructure:"drift_hold" default:"false" usage:"after a drift reset, hold signals until RSI leaves the extreme"`
+	DriftResetBars               int                      `mapstructure:"drift_reset_bars" default:"0" usage:"reset a strategy's signal memory after RSI stays in an extreme for this many consecutive bars - 0 disables"`
+	DriftRsiExtreme              float64                  `mapstructure:"drift_rsi_extreme" default:"10" usage:"distance from 0 or 100 within which RSI counts as pinned by the drift reset"`
+	DustConvert                  bool                     `mapstructure:"dust_convert" default:"false" usage:"swap balances of tokens outside the pair, such as airdrops, to the quote asset"`
+	DustConvertMinUsd            float64                  `mapstructure:"dust_convert_min_usd" default:"1" usage:"smallest value in USD of a token balance worth converting"`
+	DustIgnoreMints              []string                 `mapstructure:"dust_ignore_mints" default:"[]" usage:"mints of tokens outside the pair the wallet holds on purpose, never converted"`
+	DustMaxPriceImpactPct        float64                  `mapstructure:"dust_max_price_impact_pct" default:"5" usage:"highest price impact percentage a dust conversion may have, since thin markets are a sign of a scam token"`
+	DustRequireVerified          bool                     `mapstructure:"dust_require_verified" default:"true" usage:"only convert tokens verified by Jupiter's token list"`
+	DustSweepInterval            time.Duration            `mapstructure:"dust_sweep_interval" default:"1h" usage:"time between looks for balances of tokens outside the pair - 0 disables"`
+	EncryptState                 bool                     `mapstructure:"encrypt_state" default:"false" usage:"seal the ledger at rest with the encryption passphrase"`
+	EncryptionPassphraseEnv      string                   `mapstructure:"encryption_passphrase_env" default:"NF_PASSPHRASE" usage:"environment variable holding the encryption passphrase"`
+	EnsembleRule                 string                   `mapstructure:"ensemble_rule" default:"majority" usage:"how the strategies' votes combine: unanimous, majority, or any_buy_vetoes_sell - orders are sized by the share of votes agreeing"`
+	Environment                  string                   `mapstructure:"environment" default:"develop" usage:"deployment environment - also selects the config.<environment>.yaml overlay"`
+	EquityFile                   string                   `mapstructure:"equity_file" default:"./data/equity.jsonl" usage:"file recording an equity snapshot per bar, served by /api/equity - empty disables"`
+	EquityRetention              time.Duration            `mapstructure:"equity_retention" default:"2160h" usage:"age past which equity snapshots are rotated out of equity_file, at least equity_stats_window - 0 keeps them all"`
+	EquityStatsWindow            time.Duration            `mapstructure:"equity_stats_window" default:"720h" usage:"window of equity snapshots the rolling Sharpe, Sortino, and drawdown are computed over"`
+	ExecutionAlgo                string                   `mapstructure:"execution_algo" default:"immediate" usage:"default execution algorithm: immediate, twap, or quote_refresh"`
+	ExecutionAlgoByPair          map[string]string        `mapstructure:"execution_algo_by_pair" default:"{}" usage:"execution algorithm overrides keyed by BASE/QUOTE pair"`
+	FirestoreCollection          string                   `mapstructure:"firestore_collection" default:"ninetyfive" usage:"Firestore collection in gcp_project_id the push mode keeps each pair's state and bars in"`
+	FundingAccounts              map[string]string        `mapstructure:"funding_accounts" default:"{}" keycase:"true" usage:"token accounts of the funding wallet keyed by mint, for mints whose funds are not in the wallet's associated token account - e.g. one account per strategy, as an account has a single delegate"`
+	FundingWallet                string                   `mapstructure:"funding_wallet" default:"" usage:"address of the wallet funding the bot by delegation - the bot wallet is approved as delegate of its token accounts up to an allowance, pulls each swap's input from them, and swaps the output straight back, so the bot key alone can never move more than the allowance - empty trades the bot wallet's own funds"`
+	FxRateTtl                    time.Duration            `mapstructure:"fx_rate_ttl" default:"1h" usage:"how long an exchange rate for the reporting currency is used before it is fetched again"`
+	FxRateUrl                    string                   `mapstructure:"fx_rate_url" default:"https://api.frankfurter.dev/v1" usage:"Frankfurter-compatible API the reporting currency's exchange rate is fetched from"`
+	GcpProjectId                 string                   `mapstructure:"gcp_project_id" default:"" usage:"GCP project holding the Secret Manager secrets and logs"`
+	GridAutoTune                 bool                     `mapstructure:"grid_auto_tune" default:"false" usage:"at startup, and before each tick of the tick command, set each strategy's grids and no_trade_zone from the cached bars' RSI distribution so about grid_auto_tune_per_week crossings per week are expected - strategies with a separately configured sell side are left as configured"`
+	GridAutoTunePerWeek          float64                  `mapstructure:"grid_auto_tune_per_week" default:"5" usage:"crossings per week grid_auto_tune and the tune command aim for"`
+	GridLevelSizeWeights         []float64                `mapstructure:"grid_level_size_weights" default:"[]" usage:"order size multipliers by the index of the grid level a signal is taken at, e.g. larger at the extreme levels - levels past the end of the list keep the configured size"`
+	HealthFile                   string                   `mapstructure:"health_file" default:"" usage:"file rewritten every interval with the loop's health as JSON, for watchdogs without HTTP access - empty disables it"`
+	HealthOutageFailures         int                      `mapstructure:"health_outage_failures" default:"3" usage:"failed price fetches in a row taken as an outage, after which execution is held until health_recovery_intervals clean intervals pass and the indicators look sane - 0 disables the gate"`
+	HealthRecoveryIntervals      int                      `mapstructure:"health_recovery_intervals" default:"3" usage:"successful price fetches in a row required after a price outage before execution resumes"`
+	HttpDnsRefresh               time.Duration            `mapstructure:"http_dns_refresh" default:"5m" usage:"how often the hosts of the price, quote, swap, and RPC endpoints are resolved ahead of time, so dialing a new connection skips the DNS lookup - 0 resolves on every dial"`
+	HttpIdleTimeout              time.Duration            `mapstructure:"http_idle_timeout" default:"90s" usage:"how long an idle connection to Jupiter or the RPC is kept open for reuse"`
+	HttpKeepAlive                time.Duration            `mapstructure:"http_keep_alive" default:"30s" usage:"interval of the TCP keep-alive probes on connections to Jupiter and the RPC"`
+	HttpMaxConnsPerHost          int                      `mapstructure:"http_max_conns_per_host" default:"16" usage:"most connections kept to each Jupiter or RPC host - HTTP/2 multiplexes requests, so few are needed"`
+	HttpPrewarm                  bool                     `mapstructure:"http_prewarm" default:"true" usage:"open the connections to the price, quote, swap, and RPC endpoints at start and keep them warm, so a signal is not slowed by a fresh TLS handshake"`
+	HttpPrewarmInterval          time.Duration            `mapstructure:"http_prewarm_interval" default:"30s" usage:"how often the warm connections are touched so neither side closes them as idle - keep it below http_idle_timeout"`
+	InstanceId                   string                   `mapstructure:"instance_id" default:"" usage:"identifier of this instance, recorded with every trade, metric, and log entry - empty uses the host name"`
+	Interval                     time.Duration            `mapstructure:"interval" default:"30s" usage:"initial polling interval"`
+	IntervalMax                  time.Duration            `mapstructure:"interval_max" default:"2m" usage:"longest polling interval volatility may stretch to"`
+	IntervalMin                  time.Duration            `mapstructure:"interval_min" default:"10s" usage:"shortest polling interval volatility may shrink to"`
+	IntrabarConfirmTicks         int                      `mapstructure:"intrabar_confirm_ticks" default:"1" usage:"consecutive ticks an intrabar signal must persist before it is acted on"`
+	InventorySkew                float64                  `mapstructure:"inventory_skew" default:"0" usage:"RSI points the buy and sell thresholds shift by when holding twice (or none of) inventory_target - buys get harder and sells easier as base inventory grows, 0 disables"`
+	InventoryTarget              Amount                   `mapstructure:"inventory_target" default:"0" usage:"base inventory the skew steers toward"`
+	LatencyWindow                int                      `mapstructure:"latency_window" default:"500" usage:"most recent calls of each external dependency its latency percentiles and error rate are computed over"`
+	LedgerPath                   string                   `mapstructure:"ledger_path" default:"./data/ledger.json" usage:"file recording fills and order outcomes"`
+	LogRedaction                 bool                     `mapstructure:"log_redaction" default:"true" usage:"mask wallet addresses (partially), secret names, and API keys in every log sink - disable for local debugging only"`
+	MaintenanceWindows           []MaintenanceWindow      `mapstructure:"maintenance_windows" default:"[]" usage:"planned pauses as {start, end, flatten, reason} with RFC3339 times - flatten sells or buys back the position at the start"`
+	MaxQuoteDeviationBps         float64                  `mapstructure:"max_quote_deviation_bps" default:"50" usage:"veto signals whose quoted price is worse than the signal price by more than this - 0 disables"`
+	MaxSignalLatency             time.Duration            `mapstructure:"max_signal_latency" default:"15s" usage:"skip orders not sent within this long of the signal - 0 disables"`
+	MinOrderSizes                map[string]float64       `mapstructure:"min_order_sizes" default:"{}" keycase:"true" usage:"smallest amount an order may spend keyed by mint, in the mint's units - orders below it are refused as dust, as are orders rounding to no base units at all"`
+	MinSolBalance                Amount                   `mapstructure:"min_sol_balance" default:"0.05" usage:"stop trading and alert below this SOL balance - 0 disables"`
+	MintCheckInterval            time.Duration            `mapstructure:"mint_check_interval" default:"1h" usage:"time between checks of the pair's mints against the metadata first recorded for them, halting trading when one migrated or changed decimals - 0 disables"`
+	NotifyCooldown               time.Duration            `mapstructure:"notify_cooldown" default:"15m" usage:"default cooldown between repeated alerts for the same condition"`
+	NotifyEventCooldown          map[string]time.Duration `mapstructure:"notify_event_cooldown" default:"{price_fetch: 10m, swap_submit: 5m}" usage:"cooldown overrides keyed by alert event"`
+	NotifySuppressedSignals      bool                     `mapstructure:"notify_suppressed_signals" default:"false" usage:"send an informational notification for every signal a strategy filter or risk limit suppresses - they are audited in the ledger either way"`
+	ObserveWallet                string                   `mapstructure:"observe_wallet" default:"" usage:"public address of a wallet to mark to market while recording the trades the bot would make instead of making them - no wallet key is needed"`
+	OrderSliceInterval           time.Duration            `mapstructure:"order_slice_interval" default:"20s" usage:"delay between TWAP slices"`
+	OrderSlices                  int                      `mapstructure:"order_slices" default:"1" usage:"slices a TWAP order is split into"`
+	OrderTimeout                 time.Duration            `mapstructure:"order_timeout" default:"10m" usage:"time allowed to work an order before unsent slices are abandoned"`
+	OrderWebhookEvents           []string                 `mapstructure:"order_webhook_events" default:"[]" usage:"order lifecycle events posted to order_webhook_urls: order.submitted, order.proposed, order.confirmed, order.finalized, order.failed - empty posts all of them"`
+	OrderWebhookRetries          int                      `mapstructure:"order_webhook_retries" default:"3" usage:"times an order lifecycle event is posted again when its webhook fails"`
+	OrderWebhookRetryDelay       time.Duration            `mapstructure:"order_webhook_retry_delay" default:"2s" usage:"delay before the first retry of an order lifecycle event, doubling with each one"`
+	OrderWebhookSecret           string                   `mapstructure:"order_webhook_secret" secret:"true" default:"" usage:"secret signing order lifecycle webhook bodies (X-Ninetyfive-Signature HMAC-SHA256) - empty sends them unsigned"`
+	OrderWebhookUrls             []string                 `mapstructure:"order_webhook_urls" secret:"true" default:"[]" usage:"URLs order lifecycle events are POSTed to as JSON, e.g. accounting or Discord bots - empty disables them"`
+	PairOrientation              string                   `mapstructure:"pair_orientation" default:"legacy" usage:"legacy (BUY spends base_currency) or conventional (BUY acquires base_currency)"`
+	PairPricing                  string                   `mapstructure:"pair_pricing" default:"usd" usage:"usd feeds the strategies the base's USD price, right when the quote is a USD stablecoin - quote feeds them the base priced in the quote asset, e.g. SOL, and converts PnL and values to USD through the quote's USD price"`
+	PaperConfirmDelay            time.Duration            `mapstructure:"paper_confirm_delay" default:"2s" usage:"how long each synthetic commitment stage of a paper swap takes when paper_confirmations is on"`
+	PaperConfirmations           bool                     `mapstructure:"paper_confirmations" default:"false" usage:"in paper mode, work simulated orders through the order pipeline with synthetic confirmations instead of recording them as filled at once, exercising notifications, ledger updates, and stuck swap recovery"`
+	PaperFailureRate             float64                  `mapstructure:"paper_failure_rate" default:"0" usage:"chance, from 0 to 1, that a paper swap fails or is dropped instead of landing when paper_confirmations is on"`
+	PidFile                      string                   `mapstructure:"pid_file" default:"" usage:"file the process ID is written to while the loop runs, for service managers - empty disables it"`
+	PlatformFeeBps               int                      `mapstructure:"platform_fee_bps" default:"0" usage:"Jupiter platform fee charged on every swap, taken out of the output token - 0 disables"`
+	PlatformReferralAccount      string                   `mapstructure:"platform_referral_account" default:"" usage:"Jupiter referral account the platform fee is paid to - its token account for each output mint must exist"`
+	PriceAggregation             string                   `mapstructure:"price_aggregation" default:"median" usage:"how price sources are combined: median or weighted"`
+	PriceImpactMaxBps            float64                  `mapstructure:"price_impact_max_bps" default:"0" usage:"largest price impact an order may have - larger orders are shrunk and re-quoted until they fit, 0 disables"`
+	PriceImpactMinSizePct        float64                  `mapstructure:"price_impact_min_size_pct" default:"10" usage:"smallest share of its size an order is shrunk to for price_impact_max_bps - an order that would need to shrink further is skipped"`
+	PriceMaxAge                  time.Duration            `mapstructure:"price_max_age" default:"1m" usage:"drop each source's price last updated longer ago than this before combining them, failing the fetch when too few are left - silent per source, see price_max_staleness for the alerting check on the combined price, 0s disables"`
+	PriceMaxDeviationBps         float64                  `mapstructure:"price_max_deviation_bps" default:"100" usage:"drop source prices further than this from the median of all sources - 0 disables"`
+	PriceMaxStaleness            time.Duration            `mapstructure:"price_max_staleness" default:"0s" usage:"skip signal processing and alert when the combined price, dated by its oldest source, was produced longer ago than this, as when the price API serves cached values, counting the tick as a failed price fetch - dated to the second by the API's response headers, must be under price_max_age when both are on since older sources are already dropped, 0s disables"`
+	PriceMinSources              int                      `mapstructure:"price_min_sources" default:"1" usage:"sources that must agree for a price to be used"`
+	PricePrecision               []PricePrecision         `mapstructure:"price_precision" default:"[]" usage:"per-pair price conditioning as {pair (BASE/QUOTE), decimals (places prices are rounded to), min_change (quoted decimal - smaller moves keep the previous price)}, for pegged pairs"`
+	PriceSourceWeights           map[string]float64       `mapstructure:"price_source_weights" default:"{}" usage:"weights of the price sources for the weighted aggregation, 1 when unset"`
+	PriceSources                 []string                 `mapstructure:"price_sources" default:"[jupiter]" usage:"price sources feeding the strategy: jupiter, birdeye"`
+	PriceTriggerPoll             time.Duration            `mapstructure:"price_trigger_poll" default:"1s" usage:"how often the price is polled between ticks while price triggers are armed"`
+	PriceTriggerRangePct         float64                  `mapstructure:"price_trigger_range_pct" default:"5" usage:"how far from the last price, in percent, the grid crossings price triggers are armed at are searched for"`
+	PriceTriggers                bool                     `mapstructure:"price_triggers" default:"false" usage:"after every tick, arm triggers at the prices where the forming bar would cross a grid line and act the moment the price touches one, instead of on the next tick"`
+	ProfitLockGiveback           float64                  `mapstructure:"profit_lock_giveback" default:"50" usage:"percent of a reached profit milestone the bot may give back before it flattens and pauses"`
+	ProfitLockMilestones         []Amount                 `mapstructure:"profit_lock_milestones" default:"[]" usage:"PnL levels, in the quote asset, that each ratchet a floor under the gains once reached - empty disables"`
+	QuoteCurrency                string                   `mapstructure:"quote_currency" default:"4k3Dyjzvzp8eMZWUXbBCjEvwSkkk59S5iCNLY3QrkX6R" usage:"quote mint of the pair - see pair_orientation for how it is traded"`
+	QuoteMaxRequotes             int                      `mapstructure:"quote_max_requotes" default:"2" usage:"times a swap is re-quoted when its quote expires before the transaction is sent"`
+	QuotePrefetch                bool                     `mapstructure:"quote_prefetch" default:"false" usage:"quote both sides for the swap a signal would send in parallel alongside each bar's price fetch, so the signal goes straight to the swap without a quote round trip after the decision - skipped when the order size depends on the ensemble's vote share or grid_level_size_weights"`
+	QuotePrefetchMaxAge          time.Duration            `mapstructure:"quote_prefetch_max_age" default:"10s" usage:"how long a prefetched quote may be used, which also bounds how long prefetching waits for Jupiter"`
+	QuoteRefreshImprovementBps   float64                  `mapstructure:"quote_refresh_improvement_bps" default:"10" usage:"quote improvement that fires a quote_refresh order early"`
+	QuoteRefreshPoll             time.Duration            `mapstructure:"quote_refresh_poll" default:"2s" usage:"re-quote interval of quote_refresh orders"`
+	QuoteRefreshWindow           time.Duration            `mapstructure:"quote_refresh_window" default:"20s" usage:"how long quote_refresh waits for a better quote"`
+	QuoteTtl                     time.Duration            `mapstructure:"quote_ttl" default:"30s" usage:"how long a quote may be acted on - swaps whose quote is older when they would be sent are re-quoted"`
+	RegimeBlock                  []string                 `mapstructure:"regime_block" default:"[]" usage:"volatility regimes signals are suppressed in: warmup, calm, normal, volatile"`
+	RegimeCalmRsiStd             float64                  `mapstructure:"regime_calm_rsi_std" default:"5" usage:"standard deviation of RSI below which the regime is calm"`
+	RegimeVolatileRsiStd         float64                  `mapstructure:"regime_volatile_rsi_std" default:"20" usage:"standard deviation of RSI above which the regime is volatile"`
+	RegimeWindow                 int                      `mapstructure:"regime_window" default:"20" usage:"bars the realized volatility, ATR, and regime are measured over"`
+	ReportEmailBackend           string                   `mapstructure:"report_email_backend" default:"smtp" usage:"email backend for periodic reports: smtp or sendgrid"`
+	ReportEmailFrom              string                   `mapstructure:"report_email_from" default:"" usage:"sender of report emails"`
+	ReportEmailTo                []string                 `mapstructure:"report_email_to" default:"[]" usage:"recipients of report emails - empty disables email reports"`
+	ReportInterval               time.Duration            `mapstructure:"report_interval" default:"24h" usage:"time between periodic reports - 0 disables"`
+	ReportingCurrency            string                   `mapstructure:"reporting_currency" default:"USD" usage:"ISO 4217 currency PnL and values are also reported in, e.g. EUR or GBP, converted from the quote asset taken as USD"`
+	ResidualMaxRetries           int                      `mapstructure:"residual_max_retries" default:"2" usage:"times the unfilled remainder of an order is retried"`
+	ResidualPolicy               string                   `mapstructure:"residual_policy" default:"cancel" usage:"what happens to an order's unfilled remainder: chase, rest, or cancel"`
+	ResidualRest                 time.Duration            `mapstructure:"residual_rest" default:"1m" usage:"pause before retrying a remainder under the rest policy"`
+	RpcBroadcastEndpoints        []string                 `mapstructure:"rpc_broadcast_endpoints" secret:"true" default:"[]" usage:"extra RPC endpoints, e.g. priority or staked providers, every signed swap is broadcast to alongside the primary - empty sends to the primary only"`
+	Schedules                    map[string]string        `mapstructure:"schedules" default:"{}" usage:"cron expressions in UTC keyed by job (config_refresh, dust_sweep, maintenance, reconciliation, report, secret_key_refresh, sheets_export) overriding their intervals - five fields, @daily and the like, or @every <duration>, empty disables"`
+	SecretKeyFile                string                   `mapstructure:"secret_key_file" default:"" usage:"local (optionally sealed) keyfile holding the wallet key - empty uses the Secret Manager"`
+	SecretKeyTtl                 time.Duration            `mapstructure:"secret_key_ttl" default:"0s" usage:"re-fetch the secret key this often to pick up rotations - 0 disables"`
+	SellOrderSize                Amount                   `mapstructure:"sell_order_size" default:"1" usage:"amount sold on each SELL, in the asset SELL spends"`
+	SendGridApiKey               string                   `mapstructure:"sendgrid_api_key" secret:"true" default:"" usage:"SendGrid API key for the sendgrid report backend"`
+	SheetsExportInterval         time.Duration            `mapstructure:"sheets_export_interval" default:"5m" usage:"how often the position, risk state, and recent trades are exported to the spreadsheet"`
+	SheetsSpreadsheetId          string                   `mapstructure:"sheets_spreadsheet_id" default:"" usage:"Google Sheet the position, risk state, and recent trades are exported to for stakeholders - empty disables"`
+	SheetsTradeRows              int                      `mapstructure:"sheets_trade_rows" default:"50" usage:"how many of the latest trades are exported to the spreadsheet"`
+	SignalAuditMax               int                      `mapstructure:"signal_audit_max" default:"10000" usage:"newest signal audit entries the ledger keeps - older ones are dropped so recording a signal does not rewrite an ever-growing ledger, 0 keeps every one"`
+	SignalEvaluation             string                   `mapstructure:"signal_evaluation" default:"close" usage:"when signals are acted on: close, once a bar is complete, or intrabar, on every tick against the forming bar - intrabar needs bar_interval"`
+	SignalOnly                   bool                     `mapstructure:"signal_only" default:"false" usage:"compute and publish signals without trading - no wallet key is needed"`
+	SignalPubsubTopic            string                   `mapstructure:"signal_pubsub_topic" default:"" usage:"Pub/Sub topic signals are published to, a name in gcp_project_id or a full path - empty disables it"`
+	SignalWebhookSecret          string                   `mapstructure:"signal_webhook_secret" secret:"true" default:"" usage:"secret signing signal webhook bodies (X-Ninetyfive-Signature HMAC-SHA256) - empty sends them unsigned"`
+	SignalWebhookUrl             string                   `mapstructure:"signal_webhook_url" default:"" usage:"URL signals are POSTed to as JSON - empty disables it"`
+	SmPassphraseName             string                   `mapstructure:"sm_passphrase_name" default:"" usage:"Secret Manager secret holding the encryption passphrase"`
+	SmPassphraseVersion          string                   `mapstructure:"sm_passphrase_version" default:"latest" usage:"version of the passphrase secret, a number or latest"`
+	SmSecretKeyName              string                   `mapstructure:"sm_secret_key_name" default:"secret_key" usage:"Secret Manager secret holding the wallet key"`
+	SmSecretKeyVersion           string                   `mapstructure:"sm_secret_key_version" default:"latest" usage:"version of the wallet key secret, a number or latest"`
+	SmtpHost                     string                   `mapstructure:"smtp_host" default:"" usage:"SMTP relay for the smtp report backend"`
+	SmtpPassword                 string                   `mapstructure:"smtp_password" secret:"true" default:"" usage:"SMTP password"`
+	SmtpPort                     int                      `mapstructure:"smtp_port" default:"587" usage:"SMTP relay port"`
+	SmtpUsername                 string                   `mapstructure:"smtp_username" default:"" usage:"SMTP username - empty skips authentication"`
+	SpotOnly                     bool                     `mapstructure:"spot_only" default:"false" usage:"forbid selling more than the bot has bought"`
+	SquadsApprovalTimeout        time.Duration            `mapstructure:"squads_approval_timeout" default:"10m" usage:"how long a swap proposed to the squads_multisig may wait for its members to approve and execute it before its slice is failed - the proposal itself stays open until a member rejects it"`
+	SquadsMultisig               string                   `mapstructure:"squads_multisig" default:"" usage:"address of a Squads v4 multisig whose vault holds the funds - swaps are proposed to it and approved by the bot key as one member, and only execute once enough other members approve, so no single key can move the funds - empty trades a wallet the bot key owns"`
+	SquadsVaultIndex             int                      `mapstructure:"squads_vault_index" default:"0" usage:"index of the squads_multisig vault the funds are held in"`
+	StateFile                    string                   `mapstructure:"state_file" default:"./data/state.json" usage:"file the runtime state is handed over through on restarts - saved on shutdown and loaded with -resume"`
+	Strategies                   []Strategy               `mapstructure:"strategies" default:"[{name: grid, rsi_length: 7, grids: 10, direction: neutral, no_trade_zone: 35-65, aggression: low, rsi_type: rsx, laguerre_gamma: 0, source: close, smoothing: 0}]" usage:"Grid Managers voting on each bar as {name, rsi_length, grids, direction (up, neutral, down), no_trade_zone (45-55, 40-60, 35-65, 30-70, n/a), aggression (low, med, high), rsi_type (rsi, rsx, laguerre, connors - connors uses rsi_length for its price RSI), laguerre_gamma (laguerre damping in (0, 1), 0 for 0.5), source (close, hl2, hlc3, ohlc4 - live ticks are single prices, so only OHLC bars tell them apart), smoothing (EMA bars applied to the source, 0 for none)}"`
+	StrategyId                   string                   `mapstructure:"strategy_id" default:"default" usage:"identifier of the strategy configuration, recorded with every trade, metric, and log entry"`
+	StuckTxAction                string                   `mapstructure:"stuck_tx_action" default:"resubmit" usage:"what to do with a swap found dropped after its confirmation was lost: resubmit or fail"`
+	StuckTxMaxResubmits          int                      `mapstructure:"stuck_tx_max_resubmits" default:"1" usage:"most times a slice is resubmitted after its swap was dropped"`
+	StuckTxTimeout               time.Duration            `mapstructure:"stuck_tx_timeout" default:"2m" usage:"how long a swap whose confirmation was lost is resolved for before it is marked failed - 0 fails it right away"`
+	SwapMemo                     bool                     `mapstructure:"swap_memo" default:"false" usage:"append a memo to every swap naming the strategy and order it fills, to reconcile on-chain history with the ledger"`
+	TelegramBotToken             string                   `mapstructure:"telegram_bot_token" secret:"true" default:"" usage:"Telegram bot token for real-time alerts"`
+	TelegramChatId               string                   `mapstructure:"telegram_chat_id" default:"" usage:"Telegram chat receiving real-time alerts"`
+	TokenCacheFile               string                   `mapstructure:"token_cache_file" default:"./data/tokens.json" usage:"file the token list's metadata (decimals, symbols) is cached in, read through and served when the token list is unavailable - empty caches in memory only"`
+	TokenCacheTtl                time.Duration            `mapstructure:"token_cache_ttl" default:"24h" usage:"how long cached token metadata is served before the token list is asked again - stale metadata is still served while the token list is down"`
+	TokenOffline                 bool                     `mapstructure:"token_offline" default:"false" usage:"serve token metadata from token_cache_file only, never asking the token list - the decimals of tokens not cached yet are taken from the precision of their price"`
+	TxStatusPoll                 time.Duration            `mapstructure:"tx_status_poll" default:"2s" usage:"how often the commitment of every transaction in flight is read, with one batched status call"`
+	VolatilityThreshold          float64                  `mapstructure:"volatility_threshold" default:"0.004" usage:"realized volatility above which the polling interval halves - 0 disables adaptation"`
+	VolatilityWindow             int                      `mapstructure:"volatility_window" default:"20" usage:"prices used to measure realized volatility"`
+	VolumeMinUsd                 float64                  `mapstructure:"volume_min_usd" default:"0" usage:"suppress signals while recent USD volume is below this - 0 disables"`
+	VolumeWindow                 string                   `mapstructure:"volume_window" default:"1h" usage:"trailing window of the volume filter: 30m, 1h, 2h, 4h, 8h, or 24h"`
+	WalletActivityInterval       time.Duration            `mapstructure:"wallet_activity_interval" default:"5m" usage:"time between reads of the wallet's history for transactions the bot did not send, recorded in the ledger as external adjustments - 0 disables"`
+
+	secrets     map[string][]byte
+	keyDigest   [sha256.Size]byte
+	sm          *secretmanager.Client
+	unknownKeys []string // keys set in the file or environment that no field took
 }
 
-// NewConfig generated a configuration object
-func NewConfig(ctx context.Context, sm *secretmanager.Client) (*Config, error) {
+// DefaultConfigFile is the base config used when no path is given
+const DefaultConfigFile = "./configs/config.yaml"
+
+// NewConfig generated a configuration object from the YAML file at path (DefaultConfigFile when empty), merged with
+// the overlay for its environment when one exists next to it - e.g. config.production.yaml over config.yaml - so
+// per-environment differences live in their own files - secrets are not available until LoadSecrets is called
+//
+// Every key can also be set through an NF_-prefixed environment variable (e.g. NF_INTERVAL=30s, lists as
+// comma-separated values and maps as JSON), and when no path is given the default file is optional, so containers
+// can be configured purely through the environment
+func NewConfig(path string) (*Config, error) {
+	required := path != ""
+	if path == "" {
+		path = DefaultConfigFile
+	}
+
 	// Source the YAML file
-	viper.SetConfigName("config")
+	viper.SetConfigFile(path)
 	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./configs")
+
+	// Fall back to the defaults from the struct tags for keys the YAML leaves out
+	if err := setDefaults(); err != nil {
+		return nil, err
+	}
 
 	// Source environment variables prefixed by "NF_"
 	viper.SetEnvPrefix("nf")
 	viper.AutomaticEnv()
 
-	// Read from the sources
-	if err := viper.ReadInConfig(); err != nil {
+	// Read from the sources - a missing default file leaves the defaults and the environment
+	var sources []string
+	if _, err := os.Stat(path); err == nil || required {
+		if err = viper.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		sources = append(sources, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
 		return nil, err
 	}
 
-	// Unmarshal into the struct for easier handling
-	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, err
+	// Merge the environment's overlay on top, with NF_ENVIRONMENT taking precedence over the base file's value
+	if env := viper.GetString("environment"); env != "" {
+		ext := filepath.Ext(path)
+		overlay := strings.TrimSuffix(path, ext) + "." + env + ext
+		if _, err := os.Stat(overlay); err == nil {
+			viper.SetConfigFile(overlay)
+			if err = viper.MergeInConfig(); err != nil {
+				return nil, fmt.Errorf("could not merge %s: %w", overlay, err)
+			}
+			sources = append(sources, overlay)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	// Viper only sees environment variables for leaf keys, so map keys set as a whole are applied explicitly
+	for _, key := range mapKeys() {
+		if v, ok := os.LookupEnv("NF_" + strings.ToUpper(key)); ok {
+			viper.Set(key, v)
+		}
 	}
-	cfg.sm = sm // Attach the secret manager
 
-	// Cache the secret key in a map for quicker access during trading
-	cfg.secrets = make(map[string]string)
-	sk, err := cfg.getSecret(ctx, cfg.SmSecretKeyName, cfg.SmSecretKeyVersion)
+	// Unmarshal into the struct for easier handling
+	var (
+		cfg Config
+		md  mapstructure.Metadata
+	)
+	err := viper.Unmarshal(&cfg, viper.DecodeHook(decodeHook()), func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = &md
+	})
 	if err != nil {
 		return nil, err
 	}
-	cfg.secrets[cfg.SmSecretKeyName] = sk
+	cfg.secrets = make(map[string][]byte)
+	// Keys no field took, usually typos or keys a release removed, are reported by Validate
+	cfg.unknownKeys = md.Unused
+	sort.Strings(cfg.unknownKeys)
+	if err = restoreKeyCase(&cfg, sources); err != nil {
+		return nil, err
+	}
 
 	// Return a filled config for consistent parameters across the application
 	return &cfg, nil
 }
 
-// SecretKey returns the private key for the Solana wallet
-func (c *Config) SecretKey() (string, error) {
+// UsesSecretManager reports whether any secret is sourced from the Secret Manager, so callers can skip creating a
+// client (and needing GCP credentials) when everything is local
+func (c *Config) UsesSecretManager() bool {
+	return (c.NeedsSecretKey() && c.SecretKeyFile == "") || c.SmPassphraseName != ""
+}
+
+// NeedsSecretKey reports whether the bot signs transactions and so needs the wallet's secret key
+func (c *Config) NeedsSecretKey() bool {
+	return !c.SignalOnly && c.ObserveWallet == ""
+}
+
+// LoadSecrets caches the secret key in a map for quicker access during trading - it is read from the local keyfile
+// when one is configured and from the Secret Manager otherwise
+func (c *Config) LoadSecrets(ctx context.Context, sm *secretmanager.Client) error {
+	c.AttachSecretManager(sm)
+
+	sk, err := c.fetchSecretKey(ctx)
+	if err != nil {
+		return err
+	}
+	c.secrets[c.SmSecretKeyName] = sk
+	c.keyDigest = sha256.Sum256(sk)
+	return nil
+}
+
+// ReloadSecretKey fetches the secret key again - resolving "latest" to whatever version is current - and caches it
+// only when it differs from the key loaded before, reporting whether it changed so callers can rebuild the signer
+func (c *Config) ReloadSecretKey(ctx context.Context) (bool, error) {
+	sk, err := c.fetchSecretKey(ctx)
+	if err != nil {
+		return false, err
+	}
+	digest := sha256.Sum256(sk)
+	if digest == c.keyDigest {
+		secure.Zero(sk)
+		return false, nil
+	}
+	c.WipeSecretKey()
+	c.secrets[c.SmSecretKeyName] = sk
+	c.keyDigest = digest
+	return true, nil
+}
+
+// fetchSecretKey reads the secret key from its configured source
+func (c *Config) fetchSecretKey(ctx context.Context) ([]byte, error) {
+	if c.SecretKeyFile == "" {
+		return c.getSecret(ctx, c.SmSecretKeyName, c.SmSecretKeyVersion)
+	}
+	return c.readKeyfile(ctx)
+}
+
+// AttachSecretManager sets the client used to fetch secrets - it may be nil when no secret lives in the Secret Manager
+func (c *Config) AttachSecretManager(sm *secretmanager.Client) {
+	c.sm = sm
+}
+
+// SecretKey returns the private key for the Solana wallet - callers should use WipeSecretKey once the signer has been
+// constructed
+func (c *Config) SecretKey() ([]byte, error) {
 	sk, ok := c.secrets[c.SmSecretKeyName]
 	if !ok {
-		return "", fmt.Errorf("secret key not found")
+		return nil, fmt.Errorf("secret key not found")
 	}
 	return sk, nil
 }
 
+// WipeSecretKey zeroes the cached private key so it does not linger in memory after the signer holds it
+func (c *Config) WipeSecretKey() {
+	if sk, ok := c.secrets[c.SmSecretKeyName]; ok {
+		secure.Zero(sk)
+		delete(c.secrets, c.SmSecretKeyName)
+	}
+}
+
+// Passphrase returns the passphrase used for encryption at rest, sourced from the configured environment variable or
+// the Secret Manager (in that order)
+func (c *Config) Passphrase(ctx context.Context) ([]byte, error) {
+	if c.EncryptionPassphraseEnv != "" {
+		if p, ok := os.LookupEnv(c.EncryptionPassphraseEnv); ok && p != "" {
+			return []byte(p), nil
+		}
+	}
+	if c.SmPassphraseName != "" && c.sm != nil {
+		return c.getSecret(ctx, c.SmPassphraseName, c.SmPassphraseVersion)
+	}
+	return nil, fmt.Errorf("no encryption passphrase configured")
+}
+
+// readKeyfile loads the private key from the local keyfile, decrypting it when it was sealed with a passphrase
+func (c *Config) readKeyfile(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(c.SecretKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if !secure.IsSealed(data) {
+		return bytes.TrimSpace(data), nil
+	}
+	defer secure.Zero(data)
+
+	passphrase, err := c.Passphrase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer secure.Zero(passphrase)
+
+	sk, err := secure.Open(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(sk), nil
+}
+
 // getSecret fetches a secret from the Secret Manager using its shorthand name and version (not the full path of the
-// secret)
-func (c *Config) getSecret(ctx context.Context, name string, version int) (string, error) {
-	path := "projects/" + c.GcpProjectId + "/secrets/" + name + "/versions/" + strconv.Itoa(version)
+// secret) - the version may be a number or "latest"
+func (c *Config) getSecret(ctx context.Context, name string, version string) ([]byte, error) {
+	if c.sm == nil {
+		return nil, fmt.Errorf("secret manager is not configured")
+	}
+	if version == "" {
+		version = "latest"
+	}
+
+	path := "projects/" + c.GcpProjectId + "/secrets/" + name + "/versions/" + version
 	req := &secretmanagerpb.AccessSecretVersionRequest{
 		Name: path,
 	}
 
 	res, err := c.sm.AccessSecretVersion(ctx, req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(res.Payload.Data), nil
+	return res.Payload.Data, nil
 }
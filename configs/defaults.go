@@ -0,0 +1,198 @@
+package configs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Example renders a fully-populated config in YAML with every key set to its default and commented with its usage,
+// both taken from the Config struct tags
+func Example() []byte {
+	b, _ := Render(nil)
+	return b
+}
+
+// Render is Example with some keys set to the given values instead of their defaults - lists are given as
+// comma-separated values, while maps and lists of objects can only be set by editing the file
+func Render(values map[string]string) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("# ninetyfive configuration - generated from the defaults, edit before use\n")
+	t := reflect.TypeOf(Config{})
+	seen := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n# %s\n", f.Tag.Get("usage"))
+		value, ok := values[key]
+		seen[key] = ok
+		switch {
+		case !ok:
+			value = f.Tag.Get("default")
+		case f.Type.Kind() == reflect.Map || (f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct):
+			return nil, fmt.Errorf("%s can only be set by editing the config", key)
+		case f.Type.Kind() == reflect.Slice:
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, quote(item, f.Type.Elem()))
+				}
+			}
+			value = "[" + strings.Join(items, ", ") + "]"
+		}
+		if f.Type.Kind() != reflect.Slice && f.Type.Kind() != reflect.Map {
+			value = quote(value, f.Type)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+	for key := range values {
+		if !seen[key] {
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// quote single-quotes a YAML scalar of a string, duration, or amount type, leaving other types as they are
+func quote(value string, t reflect.Type) string {
+	if t.Kind() == reflect.String || t == reflect.TypeOf(time.Duration(0)) || t == reflect.TypeOf(Amount(0)) {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
+
+// Keys returns every key the config understands, sorted
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mapKeys returns the keys of map fields and lists of objects, which viper cannot read from the environment itself
+func mapKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type.Kind() == reflect.Map || (f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct) {
+			keys = append(keys, f.Tag.Get("mapstructure"))
+		}
+	}
+	return keys
+}
+
+// Redacted returns every config value keyed like the YAML, with the non-empty values of fields tagged as secret (all
+// strings or lists of them) masked so the result can be logged or stored - durations are rendered as in the YAML
+func (c *Config) Redacted() map[string]interface{} {
+	values := make(map[string]interface{})
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		value := v.Field(i).Interface()
+		switch {
+		case f.Tag.Get("secret") == "true":
+			if v.Field(i).Len() > 0 {
+				value = "REDACTED"
+			}
+		case f.Type == reflect.TypeOf(time.Duration(0)):
+			value = value.(time.Duration).String()
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// SecretValues returns the non-empty values of the fields tagged as secret, each element of a list on its own, so
+// they can be masked wherever they would be logged
+func (c *Config) SecretValues() []string {
+	var values []string
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("secret") != "true" {
+			continue
+		}
+		switch value := v.Field(i).Interface().(type) {
+		case string:
+			values = append(values, value)
+		case []string:
+			values = append(values, value...)
+		}
+	}
+	return slices.DeleteFunc(values, func(s string) bool { return s == "" })
+}
+
+// setDefaults registers the defaults from the struct tags with viper, so keys missing from the YAML still get sane
+// values - the example is parsed back rather than the tags so both always agree
+func setDefaults() error {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(Example())); err != nil {
+		return fmt.Errorf("invalid config defaults: %w", err)
+	}
+	for _, key := range v.AllKeys() {
+		viper.SetDefault(key, v.Get(key))
+	}
+	return nil
+}
+
+// keyToken matches the words a config file could spell a map key with
+var keyToken = regexp.MustCompile(`[A-Za-z0-9_.-]+`)
+
+// restoreKeyCase puts back the case viper folds out of the keys of map fields tagged keycase, such as mints and
+// strategy ids, spelling each key as the config files did - keys set through the environment keep their case already
+func restoreKeyCase(cfg *Config, sources []string) error {
+	spelled := make(map[string]string)
+	for _, path := range sources {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, word := range keyToken.FindAllString(string(data), -1) {
+			if lower := strings.ToLower(word); lower != word {
+				spelled[lower] = word
+			}
+		}
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("keycase") != "true" || v.Field(i).Len() == 0 {
+			continue
+		}
+		m := v.Field(i)
+		restored := reflect.MakeMapWithSize(m.Type(), m.Len())
+		for _, key := range m.MapKeys() {
+			name := key.String()
+			if word, ok := spelled[name]; ok {
+				name = word
+			}
+			restored.SetMapIndex(reflect.ValueOf(name), m.MapIndex(key))
+		}
+		m.Set(restored)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// MaintenanceWindow is a planned period during which the bot stops trading, optionally flattening its position first
+type MaintenanceWindow struct {
+	Start   time.Time `mapstructure:"start" json:"start"`
+	End     time.Time `mapstructure:"end" json:"end"`
+	Flatten bool      `mapstructure:"flatten" json:"flatten"`
+	Reason  string    `mapstructure:"reason" json:"reason"`
+}
+
+// Contains reports whether the time falls inside the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Strategy is one Grid Manager voting in the ensemble, configured with the TradingView script's inputs
+type Strategy struct {
+	Name        string `mapstructure:"name" json:"name"`
+	RsiLength   int    `mapstructure:"rsi_length" json:"rsi_length"`
+	Grids       int    `mapstructure:"grids" json:"grids"`
+	Direction   string `mapstructure:"direction" json:"direction"`
+	NoTradeZone string `mapstructure:"no_trade_zone" json:"no_trade_zone"`
+	Aggression  string `mapstructure:"aggression" json:"aggression"`
+	RsiType     string `mapstructure:"rsi_type" json:"rsi_type"`
+	Source      string `mapstructure:"source" json:"source"`
+	Smoothing   int    `mapstructure:"smoothing" json:"smoothing"`
+	// LaguerreGamma is the damping of the laguerre rsi_type, in (0, 1) - 0 uses 0.5
+	LaguerreGamma float64 `mapstructure:"laguerre_gamma" json:"laguerre_gamma"`
+	// The sell side's grids, no_trade_zone, and aggression, for accumulation- or distribution-biased operation - each
+	// left unset takes the buy side's, which the fields above configure
+	SellGrids       int    `mapstructure:"sell_grids" json:"sell_grids,omitempty"`
+	SellNoTradeZone string `mapstructure:"sell_no_trade_zone" json:"sell_no_trade_zone,omitempty"`
+	SellAggression  string `mapstructure:"sell_aggression" json:"sell_aggression,omitempty"`
+}
+
+// Asymmetric reports whether the strategy configures its sell side apart from its buy side
+func (s Strategy) Asymmetric() bool {
+	return s.SellGrids != 0 || s.SellNoTradeZone != "" || s.SellAggression != ""
+}
+
+// PricePrecision conditions the prices of a pair - tightly pegged pairs such as stablecoins move in fractions of a
+// cent that float noise and jitter would otherwise drown
+type PricePrecision struct {
+	Pair      string `mapstructure:"pair" json:"pair"`         // BASE/QUOTE, as in execution_algo_by_pair
+	Decimals  int    `mapstructure:"decimals" json:"decimals"` // places prices are rounded to
+	MinChange Amount `mapstructure:"min_change" json:"min_change"`
+}
+
+// Amount is a token amount written in the config as a decimal string, e.g. '7.5'
+type Amount float64
+
+// Float returns the amount as a plain float
+func (a Amount) Float() float64 {
+	return float64(a)
+}
+
+// decimal matches the accepted spelling of amounts - no signs, exponents, or thousands separators
+var decimal = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ParseAmount parses a decimal string into an amount
+func ParseAmount(s string) (Amount, error) {
+	if !decimal.MatchString(s) {
+		return 0, fmt.Errorf("invalid amount %q, expected a decimal like '7.5'", s)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return Amount(f), nil
+}
+
+// decodeHook parses typed fields strictly - durations and amounts must be strings, since a bare number has no unit
+// (is 30 seconds or milliseconds?) and would otherwise silently decode as nanoseconds - comma-separated strings still
+// decode into lists, as with viper's default hooks, so list values can come from environment variables
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(typedHook, mapstructure.StringToTimeHookFunc(time.RFC3339),
+		mapstructure.StringToSliceHookFunc(","))
+}
+
+// typedHook converts config values into durations, amounts, maps, and lists of objects
+func typedHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	switch to {
+	case reflect.TypeOf(time.Duration(0)):
+		s, ok := data.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid duration %v, expected a string with a unit like '30s' or '5m'", data)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid duration %q, must not be negative", s)
+		}
+		return d, nil
+	case reflect.TypeOf(Amount(0)):
+		s, ok := data.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %v, expected a quoted decimal like '7.5'", data)
+		}
+		return ParseAmount(s)
+	default:
+		// Maps and lists of objects set through environment variables arrive as JSON strings
+		s, ok := data.(string)
+		if ok && to.Kind() == reflect.Map {
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(s), &m); err != nil {
+				return nil, fmt.Errorf("invalid map %q, expected a JSON object: %w", s, err)
+			}
+			return m, nil
+		}
+		if ok && to.Kind() == reflect.Slice && to.Elem().Kind() == reflect.Struct {
+			var l []interface{}
+			if err := json.Unmarshal([]byte(s), &l); err != nil {
+				return nil, fmt.Errorf("invalid list %q, expected a JSON array: %w", s, err)
+			}
+			return l, nil
+		}
+		return data, nil
+	}
+}
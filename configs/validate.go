@@ -0,0 +1,388 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// removedKeys are keys earlier releases read, with what took their place
+var removedKeys = map[string]string{
+	"max_retries_tx_monitor": "transactions are followed for commitment_timeout instead",
+}
+
+// Validate checks the config for values that would make the bot misbehave, reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(key string, value string, allowed ...string) {
+		check(slices.Contains(allowed, value), "%s must be one of %v, got %q", key, allowed, value)
+	}
+
+	// Keys no field took - a typo would otherwise leave the setting it meant at its default without a word
+	for _, key := range c.unknownKeys {
+		if hint, ok := removedKeys[key]; ok {
+			check(false, "%s was removed - %s", key, hint)
+		} else {
+			check(false, "unknown key %q", key)
+		}
+	}
+
+	// Pair and order sizing
+	check(c.BaseCurrency != "" && c.QuoteCurrency != "", "base_currency and quote_currency are required")
+	check(c.BaseCurrency != c.QuoteCurrency, "base_currency and quote_currency must differ")
+	oneOf("pair_orientation", c.PairOrientation, "legacy", "conventional")
+	oneOf("pair_pricing", c.PairPricing, "usd", "quote")
+	check(c.BuyOrderSize > 0, "buy_order_size must be positive")
+	check(c.SellOrderSize > 0, "sell_order_size must be positive")
+
+	// Strategies
+	oneOf("ensemble_rule", c.EnsembleRule, "unanimous", "majority", "any_buy_vetoes_sell")
+	check(len(c.Strategies) > 0, "at least one strategy is required")
+	checkStrategies := func(field string, strategies []Strategy) {
+		names := make(map[string]bool)
+		for i, s := range strategies {
+			key := fmt.Sprintf("%s[%d]", field, i)
+			check(s.Name != "" && !names[s.Name], "%s needs a unique name", key)
+			names[s.Name] = true
+			check(s.RsiLength >= 2, "%s.rsi_length must be at least 2", key)
+			check(s.Grids >= 1, "%s.grids must be at least 1", key)
+			oneOf(key+".direction", s.Direction, "up", "neutral", "down")
+			oneOf(key+".no_trade_zone", s.NoTradeZone, "45-55", "40-60", "35-65", "30-70", "n/a")
+			oneOf(key+".aggression", s.Aggression, "low", "med", "high")
+			check(s.SellGrids >= 0, "%s.sell_grids must not be negative", key)
+			if s.SellNoTradeZone != "" {
+				oneOf(key+".sell_no_trade_zone", s.SellNoTradeZone, "45-55", "40-60", "35-65", "30-70", "n/a")
+			}
+			if s.SellAggression != "" {
+				oneOf(key+".sell_aggression", s.SellAggression, "low", "med", "high")
+			}
+			oneOf(key+".rsi_type", s.RsiType, "rsi", "rsx", "laguerre", "connors")
+			check(s.LaguerreGamma >= 0 && s.LaguerreGamma < 1, "%s.laguerre_gamma must be in [0, 1)", key)
+			if s.Source != "" {
+				oneOf(key+".source", s.Source, "close", "hl2", "hlc3", "ohlc4")
+			}
+			check(s.Smoothing >= 0, "%s.smoothing must not be negative", key)
+		}
+	}
+	checkStrategies("strategies", c.Strategies)
+	for i, w := range c.GridLevelSizeWeights {
+		check(w > 0, "grid_level_size_weights[%d] must be positive", i)
+	}
+
+	// A/B testing
+	checkStrategies("ab_strategies", c.AbStrategies)
+	if len(c.AbStrategies) > 0 {
+		check(c.AbMinBars >= 2, "ab_min_bars must be at least 2")
+		check(c.AbSignificance > 0 && c.AbSignificance < 1, "ab_significance must be between 0 and 1")
+	}
+
+	check(c.PlatformFeeBps >= 0 && c.PlatformFeeBps <= 10_000, "platform_fee_bps must be between 0 and 10000")
+	check(c.PlatformFeeBps == 0 || c.PlatformReferralAccount != "", "platform_fee_bps needs a platform_referral_account")
+	check(c.PriceImpactMaxBps >= 0, "price_impact_max_bps must not be negative")
+	check(c.SignalAuditMax >= 0, "signal_audit_max must not be negative")
+	check(c.PriceImpactMinSizePct >= 0 && c.PriceImpactMinSizePct <= 100,
+		"price_impact_min_size_pct must be between 0 and 100")
+
+	if c.SheetsSpreadsheetId != "" {
+		check(c.SheetsExportInterval > 0, "sheets_export_interval must be positive")
+		check(c.SheetsTradeRows >= 0, "sheets_trade_rows must not be negative")
+	}
+
+	check(len(c.ReportingCurrency) == 3, "reporting_currency must be an ISO 4217 code like EUR")
+	check(strings.EqualFold(c.ReportingCurrency, "USD") || c.FxRateTtl > 0, "fx_rate_ttl must be positive")
+
+	for job := range c.Schedules {
+		oneOf("schedules", job, "config_refresh", "dust_sweep", "maintenance", "reconciliation", "report",
+			"secret_key_refresh", "sheets_export")
+	}
+
+	// Capital allocation
+	check(c.BenchmarkCapital >= 0, "benchmark_capital must not be negative")
+	oneOf("capital_allocation", c.CapitalAllocation, "", "fixed", "sharpe")
+	if c.CapitalAllocation != "" {
+		check(c.CapitalBudgets[c.StrategyId] > 0, "capital_budgets needs a positive budget for strategy %q", c.StrategyId)
+		for id, b := range c.CapitalBudgets {
+			check(b >= 0, "capital_budgets.%s must not be negative", id)
+		}
+	}
+	if c.CapitalAllocation == "sharpe" {
+		check(c.CapitalMinShare >= 0 && c.CapitalMinShare*float64(len(c.CapitalBudgets)) <= 1,
+			"capital_min_share must not be negative, nor add up to more than the whole across capital_budgets")
+		for id := range c.CapitalBudgets {
+			_, ok := c.CapitalLedgers[id]
+			check(id == c.StrategyId || ok, "capital_ledgers needs the ledger of strategy %q", id)
+		}
+	}
+
+	check(c.InventorySkew >= 0, "inventory_skew must not be negative")
+	if c.InventorySkew > 0 {
+		check(c.InventoryTarget > 0, "inventory_skew needs a positive inventory_target")
+	}
+
+	check(c.DriftResetBars >= 0, "drift_reset_bars must not be negative")
+	check(c.DriftRsiExtreme > 0 && c.DriftRsiExtreme < 50, "drift_rsi_extreme must be between 0 and 50")
+
+	// Loop timing
+	check(c.Interval >= time.Second, "interval must be at least 1s")
+	check(c.IntervalMin <= c.IntervalMax, "interval_min must not exceed interval_max")
+	check(c.BarInterval >= 0, "bar_interval must not be negative")
+	oneOf("signal_evaluation", c.SignalEvaluation, "close", "intrabar")
+	if c.PriceTriggers {
+		check(c.PriceTriggerPoll > 0 && c.PriceTriggerPoll < c.Interval, "price_trigger_poll must be positive and "+
+			"shorter than interval")
+		check(c.PriceTriggerRangePct > 0 && c.PriceTriggerRangePct < 100, "price_trigger_range_pct must be between 0 "+
+			"and 100")
+	}
+	if c.SignalEvaluation == "intrabar" {
+		check(c.BarInterval > c.IntervalMax, "intrabar signal_evaluation needs a bar_interval longer than interval_max")
+		check(c.IntrabarConfirmTicks >= 1, "intrabar_confirm_ticks must be at least 1")
+	}
+	check(c.CandleBackfill >= 0, "candle_backfill must not be negative")
+	check(c.CandleWarmupBars >= 0, "candle_warmup_bars must not be negative")
+	if c.CandleBackfill > 0 || c.CandleWarmupBars > 0 {
+		check(c.CandleCacheDir != "", "candle_backfill and candle_warmup_bars need candle_cache_dir")
+	}
+	check(c.GridAutoTunePerWeek > 0, "grid_auto_tune_per_week must be positive")
+	if c.GridAutoTune {
+		check(c.CandleCacheDir != "", "grid_auto_tune needs candle_cache_dir for the bars it tunes on")
+	}
+	if c.CandleBackfill > 0 {
+		check(c.BirdeyeApiKey != "", "candle_backfill needs birdeye_api_key")
+		check(slices.Contains([]time.Duration{time.Minute, 3 * time.Minute, 5 * time.Minute, 15 * time.Minute,
+			30 * time.Minute, time.Hour, 2 * time.Hour, 4 * time.Hour, 6 * time.Hour, 8 * time.Hour, 12 * time.Hour,
+			24 * time.Hour}, c.BarInterval), "candle_backfill needs a bar_interval Birdeye serves bars of, from 1m to 1d")
+	}
+	check(c.VolatilityThreshold >= 0, "volatility_threshold must not be negative")
+	check(c.CommitmentTimeout > 0, "commitment_timeout must be positive")
+	check(c.TxStatusPoll > 0 && c.TxStatusPoll < c.CommitmentTimeout, "tx_status_poll must be positive and "+
+		"shorter than commitment_timeout")
+	check(c.LatencyWindow > 0, "latency_window must be positive")
+	check(c.HttpIdleTimeout > 0, "http_idle_timeout must be positive")
+	check(c.HttpKeepAlive >= 0, "http_keep_alive must not be negative")
+	check(c.HttpMaxConnsPerHost > 0, "http_max_conns_per_host must be positive")
+	check(c.HttpDnsRefresh >= 0, "http_dns_refresh must not be negative")
+	check(!c.HttpPrewarm || (c.HttpPrewarmInterval > 0 && c.HttpPrewarmInterval < c.HttpIdleTimeout),
+		"http_prewarm_interval must be positive and below http_idle_timeout")
+	check(c.MintCheckInterval >= 0, "mint_check_interval must not be negative")
+
+	// Health gate
+	check(c.HealthOutageFailures >= 0, "health_outage_failures must not be negative")
+	check(c.HealthOutageFailures == 0 || c.HealthRecoveryIntervals > 0, "health_recovery_intervals must be positive")
+
+	// Chaos mode
+	check(c.ChaosRate >= 0 && c.ChaosRate <= 1, "chaos_rate must be between 0 and 1")
+	check(c.ChaosRate == 0 || c.Environment != ProductionEnvironment, "chaos_rate must be 0 in production")
+	for i, fault := range c.ChaosFaults {
+		oneOf(fmt.Sprintf("chaos_faults[%d]", i), fault, "price_drop", "confirm_delay", "rpc_429", "blockhash_expiry")
+	}
+	check(c.ChaosConfirmDelay >= 0, "chaos_confirm_delay must not be negative")
+
+	// Profit lock
+	for i, m := range c.ProfitLockMilestones {
+		check(m > 0, "profit_lock_milestones[%d] must be positive", i)
+	}
+	if len(c.ProfitLockMilestones) > 0 {
+		check(c.ProfitLockGiveback > 0 && c.ProfitLockGiveback < 100, "profit_lock_giveback must be between 0 and 100")
+	}
+
+	// Execution
+	oneOf("execution_algo", c.ExecutionAlgo, "immediate", "twap", "quote_refresh")
+	for pair, algo := range c.ExecutionAlgoByPair {
+		oneOf("execution_algo_by_pair."+pair, algo, "immediate", "twap", "quote_refresh")
+	}
+	oneOf("residual_policy", c.ResidualPolicy, "chase", "rest", "cancel")
+	oneOf("stuck_tx_action", c.StuckTxAction, "resubmit", "fail")
+	check(c.StuckTxTimeout >= 0, "stuck_tx_timeout must not be negative")
+	check(c.StuckTxMaxResubmits >= 0, "stuck_tx_max_resubmits must not be negative")
+	check(c.OrderSlices >= 1, "order_slices must be at least 1")
+	// Only TWAP slices orders, so slices configured for another algorithm would silently go unused
+	twap := c.ExecutionAlgo == "twap"
+	for _, algo := range c.ExecutionAlgoByPair {
+		twap = twap || algo == "twap"
+	}
+	check(c.OrderSlices <= 1 || twap, "order_slices of %d needs execution_algo twap - only TWAP splits orders",
+		c.OrderSlices)
+	check(c.MaxQuoteDeviationBps >= 0, "max_quote_deviation_bps must not be negative")
+	oneOf("amount_rounding", c.AmountRounding, "floor", "ceil", "bankers")
+	for mint, size := range c.MinOrderSizes {
+		_, err := solana.PublicKeyFromBase58(mint)
+		check(err == nil, "min_order_sizes.%s must be keyed by a mint", mint)
+		check(size >= 0, "min_order_sizes.%s must not be negative", mint)
+	}
+	check(c.QuoteTtl > 0, "quote_ttl must be positive")
+	check(c.QuoteMaxRequotes >= 0, "quote_max_requotes must not be negative")
+	check(!c.QuotePrefetch || (c.QuotePrefetchMaxAge > 0 && c.QuotePrefetchMaxAge <= c.QuoteTtl),
+		"quote_prefetch_max_age must be positive and at most quote_ttl")
+	if c.ComputeUnitTuning {
+		check(c.ComputeUnitMargin >= 0, "compute_unit_margin must not be negative")
+		check(c.ComputeUnitPriceMax > 0, "compute_unit_price_max must be positive")
+		check(c.ComputeUnitPricePercentile > 0 && c.ComputeUnitPricePercentile <= 100,
+			"compute_unit_price_percentile must be between 0 and 100")
+	}
+	for _, action := range c.CongestionActions {
+		oneOf("congestion_actions", action, "raise_fees", "widen_slippage", "pause_entries")
+	}
+	if len(c.CongestionActions) > 0 {
+		check(c.CongestionCheckInterval > 0, "congestion_check_interval must be positive")
+		check(c.CongestionSlotTimeMs >= 0 && c.CongestionPriorityFee >= 0 && c.CongestionFailureRatePct >= 0,
+			"congestion thresholds must not be negative")
+		check(c.CongestionFailureWindow > 0, "congestion_failure_window must be positive")
+		check(c.CongestionFeeMultiplier >= 1, "congestion_fee_multiplier must be at least 1")
+		check(c.CongestionUnitPriceMax >= c.ComputeUnitPriceMax,
+			"congestion_unit_price_max must be at least compute_unit_price_max")
+		check(c.CongestionSlippageMaxBps >= 500 && c.CongestionSlippageMaxBps <= 10_000,
+			"congestion_slippage_max_bps must be between 500 and 10000")
+	}
+	for i, endpoint := range c.RpcBroadcastEndpoints {
+		u, err := url.Parse(endpoint)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"rpc_broadcast_endpoints[%d] must be an http(s) URL", i)
+	}
+	check(c.CircuitBreakerMaxVol >= 0, "circuit_breaker_max_vol must not be negative")
+	check(c.CircuitBreakerMaxMoveBps >= 0, "circuit_breaker_max_move_bps must not be negative")
+	check(c.CircuitBreakerMaxDrawdownPct >= 0, "circuit_breaker_max_drawdown_pct must not be negative")
+	check(c.EquityStatsWindow > 0, "equity_stats_window must be positive")
+	check(c.EquityRetention == 0 || c.EquityRetention >= c.EquityStatsWindow,
+		"equity_retention must be 0 or at least equity_stats_window")
+	if c.CircuitBreakerMaxDrawdownPct > 0 {
+		check(c.EquityFile != "", "circuit_breaker_max_drawdown_pct needs an equity_file")
+		check(c.BenchmarkCapital > 0,
+			"circuit_breaker_max_drawdown_pct needs a benchmark_capital, the equity its drawdown is a percentage of")
+		check(c.CircuitBreakerCooldown > 0, "circuit_breaker_cooldown must be positive")
+	}
+	if c.CircuitBreakerMaxVol > 0 || c.CircuitBreakerMaxMoveBps > 0 {
+		check(c.CircuitBreakerWindow > 0, "circuit_breaker_window must be positive")
+		check(c.CircuitBreakerCooldown > 0, "circuit_breaker_cooldown must be positive")
+	}
+
+	// Anomaly detection
+	check(c.AnomalyFillDeviationBps >= 0, "anomaly_fill_deviation_bps must not be negative")
+	check(c.AnomalyMaxFeeLamports >= 0, "anomaly_max_fee_lamports must not be negative")
+	check(c.AnomalyFeeMultiple >= 0, "anomaly_fee_multiple must not be negative")
+	check(c.AnomalySignalsWithoutFill >= 0, "anomaly_signals_without_fill must not be negative")
+	check(c.AnomalyBalanceInterval >= 0, "anomaly_balance_interval must not be negative")
+	check(c.AnomalyBalanceTolerancePct >= 0, "anomaly_balance_tolerance_pct must not be negative")
+	check(c.WalletActivityInterval >= 0, "wallet_activity_interval must not be negative")
+	check(c.DustSweepInterval >= 0, "dust_sweep_interval must not be negative")
+	check(c.ConfigRefreshInterval >= 0, "config_refresh_interval must not be negative")
+	check(c.DustConvertMinUsd >= 0, "dust_convert_min_usd must not be negative")
+	check(c.DustMaxPriceImpactPct > 0, "dust_max_price_impact_pct must be positive")
+
+	// Pricing
+	oneOf("price_aggregation", c.PriceAggregation, "median", "weighted")
+	for _, source := range c.PriceSources {
+		oneOf("price_sources", source, "jupiter", "birdeye")
+	}
+	check(c.PriceMinSources <= max(1, len(c.PriceSources)), "price_min_sources must not exceed the number of price_sources")
+	if slices.Contains(c.PriceSources, "birdeye") {
+		check(c.BirdeyeApiKey != "", "the birdeye price source needs birdeye_api_key")
+	}
+	check(c.PriceMaxStaleness >= 0, "price_max_staleness must not be negative")
+	check(c.PriceMaxStaleness == 0 || c.PriceMaxAge <= 0 || c.PriceMaxStaleness < c.PriceMaxAge,
+		"price_max_staleness must be under price_max_age, which drops older source prices before it could alert")
+
+	for i, p := range c.PricePrecision {
+		key := fmt.Sprintf("price_precision[%d]", i)
+		check(strings.Count(p.Pair, "/") == 1, "%s.pair must be BASE/QUOTE", key)
+		check(p.Decimals >= 0 && p.Decimals <= 18, "%s.decimals must be between 0 and 18", key)
+	}
+
+	// Filters
+	if c.VolumeMinUsd > 0 {
+		check(c.BirdeyeApiKey != "", "volume_min_usd needs birdeye_api_key")
+		oneOf("volume_window", c.VolumeWindow, "30m", "1h", "2h", "4h", "8h", "24h")
+	}
+
+	check(c.RegimeWindow >= 2, "regime_window must be at least 2")
+	check(c.RegimeCalmRsiStd <= c.RegimeVolatileRsiStd, "regime_calm_rsi_std must not exceed regime_volatile_rsi_std")
+	for _, regime := range c.RegimeBlock {
+		oneOf("regime_block", regime, "warmup", "calm", "normal", "volatile")
+	}
+
+	// Maintenance
+	for i, w := range c.MaintenanceWindows {
+		check(!w.Start.IsZero() && w.End.After(w.Start), "maintenance_windows[%d] must have a start before its end", i)
+	}
+
+	// Signal publishing
+	if c.SignalOnly {
+		check(c.SignalWebhookUrl != "" || c.SignalPubsubTopic != "", "signal_only needs signal_webhook_url or signal_pubsub_topic")
+	}
+	check(!c.SignalOnly || c.ObserveWallet == "", "signal_only and observe_wallet are exclusive")
+
+	// Delegated funding
+	check(c.FundingWallet == "" || c.NeedsSecretKey(), "funding_wallet needs a trading wallet, not signal_only or "+
+		"observe_wallet")
+	check(len(c.FundingAccounts) == 0 || c.FundingWallet != "", "funding_accounts needs funding_wallet")
+	check(c.DelegationCheckInterval >= 0, "delegation_check_interval must not be negative")
+
+	// Token metadata
+	check(c.TokenCacheTtl > 0, "token_cache_ttl must be positive")
+	check(!c.TokenOffline || c.TokenCacheFile != "", "token_offline needs token_cache_file")
+
+	// Multisig custody
+	check(c.SquadsMultisig == "" || c.NeedsSecretKey(), "squads_multisig needs a trading wallet, not signal_only or "+
+		"observe_wallet")
+	check(c.SquadsMultisig == "" || c.FundingWallet == "", "squads_multisig and funding_wallet are exclusive")
+	check(c.SquadsVaultIndex >= 0 && c.SquadsVaultIndex <= 255, "squads_vault_index must be between 0 and 255")
+	check(c.SquadsApprovalTimeout > 0, "squads_approval_timeout must be positive")
+
+	// Order lifecycle webhooks
+	for i, url := range c.OrderWebhookUrls {
+		check(strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://"),
+			"order_webhook_urls[%d] must be an http(s) URL", i)
+	}
+	for i, event := range c.OrderWebhookEvents {
+		oneOf(fmt.Sprintf("order_webhook_events[%d]", i), event, "order.submitted", "order.proposed",
+			"order.confirmed", "order.finalized", "order.failed")
+	}
+	check(c.OrderWebhookRetries >= 0, "order_webhook_retries must not be negative")
+	check(c.OrderWebhookRetryDelay >= 0, "order_webhook_retry_delay must not be negative")
+
+	// Paper confirmations
+	check(!c.PaperConfirmations || c.ObserveWallet != "", "paper_confirmations needs observe_wallet")
+	check(c.PaperConfirmDelay >= 0, "paper_confirm_delay must not be negative")
+	check(c.PaperFailureRate >= 0 && c.PaperFailureRate <= 1, "paper_failure_rate must be between 0 and 1")
+	if c.SignalPubsubTopic != "" && !strings.HasPrefix(c.SignalPubsubTopic, "projects/") {
+		check(c.GcpProjectId != "", "gcp_project_id is required to publish to signal_pubsub_topic by name")
+	}
+
+	// Secrets
+	if c.NeedsSecretKey() {
+		check(c.SecretKeyFile != "" || c.SmSecretKeyName != "", "either secret_key_file or sm_secret_key_name is required")
+	}
+	if c.UsesSecretManager() {
+		check(c.GcpProjectId != "", "gcp_project_id is required to read secrets from the Secret Manager")
+	}
+	if c.EncryptState {
+		check(c.EncryptionPassphraseEnv != "" || c.SmPassphraseName != "",
+			"encrypt_state needs encryption_passphrase_env or sm_passphrase_name")
+	}
+
+	// Reporting
+	if len(c.ReportEmailTo) > 0 {
+		oneOf("report_email_backend", c.ReportEmailBackend, "smtp", "sendgrid")
+		check(c.ReportEmailFrom != "", "report_email_from is required to send report emails")
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateFile loads the config at path like the bot would and validates it, for checking a config before deploying it
+func ValidateFile(path string) error {
+	cfg, err := NewConfig(path)
+	if err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
@@ -0,0 +1,198 @@
+package abtest
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/position"
+	"github.com/josephawallace/ninetyfive/internal/risk"
+)
+
+// Verdicts of an experiment
+const (
+	VerdictNotEnoughData = "not enough data"
+	VerdictNoDifference  = "no significant difference"
+	VerdictChallenger    = "challenger is better"
+	VerdictControl       = "control is better"
+)
+
+// ArmStats is how one configuration has done since the experiment started
+type ArmStats struct {
+	Name     string            `json:"name"`
+	Trades   int               `json:"trades"`
+	Pnl      float64           `json:"pnl"` // in the quote asset, marked at the latest bar
+	Position position.Position `json:"position"`
+}
+
+// Report compares the two configurations with a paired test on their per-bar PnL changes
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	Bars       int       `json:"bars"`
+	Control    ArmStats  `json:"control"`
+	Challenger ArmStats  `json:"challenger"`
+	MeanDiff   float64   `json:"mean_diff"` // the challenger's mean per-bar PnL change less the control's
+	TStat      float64   `json:"t_stat"`
+	PValue     float64   `json:"p_value"` // two-sided
+	Verdict    string    `json:"verdict"`
+}
+
+// String summarizes the report in one line
+func (r Report) String() string {
+	return fmt.Sprintf("%s - challenger PnL %f vs control %f over %d bars (p=%.3f)", r.Verdict, r.Challenger.Pnl,
+		r.Control.Pnl, r.Bars, r.PValue)
+}
+
+// arm is one configuration, paper-traded on every bar
+type arm struct {
+	name   string
+	pos    position.Position
+	trades int
+	pnl    float64
+}
+
+// Experiment runs a challenger strategy configuration beside the live one on the same bars - both are paper-traded
+// identically, at the bar's close with the configured order sizes and the same inventory rules, so only their signals
+// differ, and the per-bar PnL differences tell whether the challenger is better
+type Experiment struct {
+	cfg        *configs.Config
+	pair       common.Pair
+	challenger *ensemble.Ensemble
+	guard      *risk.Guard
+
+	mu        sync.Mutex
+	startedAt time.Time
+	control   arm
+	chall     arm
+	diffs     []float64
+}
+
+// NewExperiment creates the experiment from the config - nil when no challenger is configured
+func NewExperiment(cfg *configs.Config, pair common.Pair, log logger.Logger) (*Experiment, error) {
+	if len(cfg.AbStrategies) == 0 {
+		return nil, nil
+	}
+	c := *cfg
+	c.Strategies = cfg.AbStrategies
+	challenger, err := ensemble.NewEnsembleFromConfig(&c, logger.NopLogger{})
+	if err != nil {
+		return nil, fmt.Errorf("ab_strategies: %w", err)
+	}
+	log.Info().Msg("A/B testing %d challenger strategies against the live configuration", len(cfg.AbStrategies))
+	return &Experiment{
+		cfg:        cfg,
+		pair:       pair,
+		challenger: challenger,
+		guard:      risk.NewGuard(cfg),
+		startedAt:  time.Now(),
+		control:    arm{name: "control", pos: position.Position{Pair: pair}},
+		chall:      arm{name: "challenger", pos: position.Position{Pair: pair}},
+	}, nil
+}
+
+// WarmUp feeds a historical bar to the challenger without trading it, as the live strategies are warmed up
+func (x *Experiment) WarmUp(bar common.Bar) error {
+	if x == nil {
+		return nil
+	}
+	_, err := x.challenger.Process(bar)
+	return err
+}
+
+// Observe paper-trades the bar for both configurations - control is the live strategies' decision on it
+func (x *Experiment) Observe(bar common.Bar, control ensemble.Decision) error {
+	if x == nil {
+		return nil
+	}
+	challenger, err := x.challenger.Process(bar)
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	before := x.chall.pnl - x.control.pnl
+	x.trade(&x.control, control, bar)
+	x.trade(&x.chall, challenger, bar)
+	x.diffs = append(x.diffs, x.chall.pnl-x.control.pnl-before)
+	return nil
+}
+
+// trade applies the decision to the arm at the bar's close and marks it to market - callers must hold the lock
+func (x *Experiment) trade(a *arm, d ensemble.Decision, bar common.Bar) {
+	var size float64
+	var input, output string
+	switch d.Signal {
+	case common.BuySignal:
+		size, input, output = x.cfg.BuyOrderSize.Float()*d.Strength, x.pair.Quote, x.pair.Base
+	case common.SellSignal:
+		size, input, output = x.cfg.SellOrderSize.Float()*d.Strength, x.pair.Base, x.pair.Quote
+	}
+	if size > 0 && x.guard.CheckInventory(d.Signal, size, a.pos.Base) == nil {
+		a.pos.Apply(ledger.Trade{Side: d.Signal, InputMint: input, OutputMint: output, InputAmount: size,
+			Price: bar.Close, Simulated: true})
+		a.trades++
+	}
+	a.pnl = a.pos.Pnl(bar.Close)
+}
+
+// Report compares the configurations so far
+func (x *Experiment) Report() *Report {
+	if x == nil {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	r := &Report{
+		StartedAt:  x.startedAt,
+		Bars:       len(x.diffs),
+		Control:    ArmStats{Name: x.control.name, Trades: x.control.trades, Pnl: x.control.pnl, Position: x.control.pos},
+		Challenger: ArmStats{Name: x.chall.name, Trades: x.chall.trades, Pnl: x.chall.pnl, Position: x.chall.pos},
+		PValue:     1,
+		Verdict:    VerdictNotEnoughData,
+	}
+	if len(x.diffs) < max(x.cfg.AbMinBars, 2) {
+		return r
+	}
+
+	r.MeanDiff, r.TStat, r.PValue = pairedTest(x.diffs)
+	switch {
+	case r.PValue >= x.cfg.AbSignificance:
+		r.Verdict = VerdictNoDifference
+	case r.MeanDiff > 0:
+		r.Verdict = VerdictChallenger
+	default:
+		r.Verdict = VerdictControl
+	}
+	return r
+}
+
+// pairedTest tests whether the mean of the paired differences is zero, returning the mean, the t statistic, and the
+// two-sided p-value from the normal approximation, which the minimum sample size keeps reasonable
+func pairedTest(diffs []float64) (float64, float64, float64) {
+	n := float64(len(diffs))
+	var sum float64
+	for _, d := range diffs {
+		sum += d
+	}
+	mean := sum / n
+	var ss float64
+	for _, d := range diffs {
+		ss += (d - mean) * (d - mean)
+	}
+	se := math.Sqrt(ss/(n-1)) / math.Sqrt(n)
+	if se == 0 {
+		if mean == 0 {
+			return 0, 0, 1
+		}
+		return mean, math.Copysign(math.Inf(1), mean), 0
+	}
+	t := mean / se
+	return mean, t, math.Erfc(math.Abs(t) / math.Sqrt2)
+}
@@ -0,0 +1,148 @@
+package accuracy
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// HorizonStats is how the signals of a group did over one forward horizon - a hit is a BUY followed by a higher
+// close or a SELL followed by a lower one
+type HorizonStats struct {
+	Bars          int     `json:"bars"`
+	Count         int     `json:"count"`
+	Hits          int     `json:"hits"`
+	HitRate       float64 `json:"hit_rate"`
+	MeanReturnBps float64 `json:"mean_return_bps"` // in the signal's favor, negative when the price went against it
+}
+
+// Group is the accuracy of the signals sharing a grid level or a filter combination
+type Group struct {
+	Key      string         `json:"key"`
+	Signals  int            `json:"signals"`
+	Horizons []HorizonStats `json:"horizons"`
+}
+
+// Report is the accuracy of the audited signals overall, per grid level, and per filter combination
+type Report struct {
+	Signals   int     `json:"signals"`
+	Evaluated int     `json:"evaluated"` // signals with bars after them to evaluate
+	Overall   Group   `json:"overall"`
+	ByLevel   []Group `json:"by_level"`
+	ByFilters []Group `json:"by_filters"`
+}
+
+// Evaluate measures each signal's forward return over the horizons, in bars after the bar it was taken on - bars must
+// be oldest first, and a horizon reaching past the last bar leaves the signal out of that horizon
+func Evaluate(signals []ledger.SignalRecord, bars []common.Bar, horizons []int) Report {
+	r := Report{Signals: len(signals)}
+	overall := newAccumulator(horizons)
+	levels := make(map[string]*accumulator)
+	combos := make(map[string]*accumulator)
+
+	for _, s := range signals {
+		if s.Price <= 0 || (s.Side != common.BuySignal && s.Side != common.SellSignal) {
+			continue
+		}
+		// The signal's bar is the first closing at or after it
+		i := sort.Search(len(bars), func(i int) bool { return !bars[i].Time.Before(s.Time) })
+		if i+1 >= len(bars) {
+			continue
+		}
+		r.Evaluated++
+		level := fmt.Sprintf("%d", s.GridLevel)
+		combo := cmp.Or(s.Filters, "unknown")
+		if levels[level] == nil {
+			levels[level] = newAccumulator(horizons)
+		}
+		if combos[combo] == nil {
+			combos[combo] = newAccumulator(horizons)
+		}
+		for h, ret := range forward(bars, i, s, horizons) {
+			for _, a := range []*accumulator{overall, levels[level], combos[combo]} {
+				a.add(h, ret)
+			}
+		}
+		overall.signals++
+		levels[level].signals++
+		combos[combo].signals++
+	}
+
+	r.Overall = overall.group("all")
+	r.ByLevel = groups(levels)
+	r.ByFilters = groups(combos)
+	return r
+}
+
+// forward returns the signal's return in its favor at each horizon the bars reach, keyed by the horizon's index
+func forward(bars []common.Bar, i int, s ledger.SignalRecord, horizons []int) map[int]float64 {
+	returns := make(map[int]float64)
+	for h, n := range horizons {
+		if i+n >= len(bars) {
+			continue
+		}
+		ret := bars[i+n].Close/s.Price - 1
+		if s.Side == common.SellSignal {
+			ret = -ret
+		}
+		returns[h] = ret
+	}
+	return returns
+}
+
+// accumulator sums the forward returns of a group per horizon
+type accumulator struct {
+	horizons []int
+	signals  int
+	counts   []int
+	hits     []int
+	sums     []float64
+}
+
+func newAccumulator(horizons []int) *accumulator {
+	return &accumulator{
+		horizons: horizons,
+		counts:   make([]int, len(horizons)),
+		hits:     make([]int, len(horizons)),
+		sums:     make([]float64, len(horizons)),
+	}
+}
+
+// add counts a return at the horizon's index
+func (a *accumulator) add(h int, ret float64) {
+	a.counts[h]++
+	a.sums[h] += ret
+	if ret > 0 {
+		a.hits[h]++
+	}
+}
+
+// group summarizes the accumulated returns
+func (a *accumulator) group(key string) Group {
+	g := Group{Key: key, Signals: a.signals, Horizons: make([]HorizonStats, len(a.horizons))}
+	for h, n := range a.horizons {
+		s := HorizonStats{Bars: n, Count: a.counts[h], Hits: a.hits[h]}
+		if s.Count > 0 {
+			s.HitRate = float64(s.Hits) / float64(s.Count)
+			s.MeanReturnBps = a.sums[h] / float64(s.Count) * 10_000
+		}
+		g.Horizons[h] = s
+	}
+	return g
+}
+
+// groups summarizes the accumulators, most signals first
+func groups(m map[string]*accumulator) []Group {
+	out := make([]Group, 0, len(m))
+	for key, a := range m {
+		out = append(out, a.group(key))
+	}
+	slices.SortFunc(out, func(a, b Group) int {
+		return cmp.Or(b.Signals-a.Signals, cmp.Compare(a.Key, b.Key))
+	})
+	return out
+}
@@ -0,0 +1,223 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/buildinfo"
+	"github.com/josephawallace/ninetyfive/internal/equity"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// Server exposes the operator endpoints for observing and controlling a running bot
+type Server struct {
+	cfg  *configs.Config
+	t    *trader.Trader
+	auth *Authenticator
+	log  logger.Logger
+	srv  *http.Server
+}
+
+// OrderSizesRequest models the body accepted when changing order sizes at runtime
+type OrderSizesRequest struct {
+	BuySize  float64 `json:"buy_size"`
+	SellSize float64 `json:"sell_size"`
+}
+
+// SimulateRequest models the body accepted when simulating a hypothetical price
+type SimulateRequest struct {
+	Price float64 `json:"price"`
+}
+
+// EquityResponse models the equity curve served for charting
+type EquityResponse struct {
+	Snapshots []equity.Snapshot `json:"snapshots"`
+	Stats     *equity.Stats     `json:"stats"`
+}
+
+// NewServer creates the admin server - it fails when no credentials are configured so the control endpoints are
+// never exposed unauthenticated
+func NewServer(cfg *configs.Config, t *trader.Trader, log logger.Logger) (*Server, error) {
+	auth := NewAuthenticator(cfg)
+	if !auth.Enabled() {
+		return nil, fmt.Errorf("admin server requires API keys or an OIDC audience to be configured")
+	}
+
+	s := &Server{
+		cfg:  cfg,
+		t:    t,
+		auth: auth,
+		log:  log,
+	}
+
+	mux := http.NewServeMux()
+	// Health is left open for load balancers and Cloud Run probes - it exposes no trading state
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /api/status", auth.Require(RoleRead, s.handleStatus))
+	mux.HandleFunc("GET /metrics", auth.Require(RoleRead, s.handleMetrics))
+	mux.HandleFunc("GET /api/equity", auth.Require(RoleRead, s.handleEquity))
+	// The Grafana JSON datasource, pointed at /api/grafana, tests the connection with a GET of its root
+	mux.HandleFunc("GET /api/grafana", auth.Require(RoleRead, s.handleHealth))
+	mux.HandleFunc("POST /api/grafana/metrics", auth.Require(RoleRead, s.handleGrafanaMetrics))
+	mux.HandleFunc("POST /api/grafana/search", auth.Require(RoleRead, s.handleGrafanaMetrics))
+	mux.HandleFunc("POST /api/grafana/query", auth.Require(RoleRead, s.handleGrafanaQuery))
+	mux.HandleFunc("POST /api/grafana/annotations", auth.Require(RoleRead, s.handleGrafanaAnnotations))
+	mux.HandleFunc("GET /api/strategies", auth.Require(RoleRead, s.handleStrategies))
+	mux.HandleFunc("GET /api/trades", auth.Require(RoleRead, s.handleTrades))
+	mux.HandleFunc("GET /api/attribution", auth.Require(RoleRead, s.handleAttribution))
+	mux.HandleFunc("POST /api/simulate", auth.Require(RoleRead, s.handleSimulate))
+	mux.HandleFunc("POST /api/pause", auth.Require(RoleControl, s.handlePause))
+	mux.HandleFunc("POST /api/resume", auth.Require(RoleControl, s.handleResume))
+	mux.HandleFunc("POST /api/order-sizes", auth.Require(RoleControl, s.handleOrderSizes))
+
+	s.srv = &http.Server{
+		Addr:              cfg.AdminAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// ListenAndServe serves the admin endpoints until the context is cancelled
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Info().Msg("admin server listening on %s", s.cfg.AdminAddr)
+	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "build": buildinfo.Get()})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.t.Status())
+}
+
+func (s *Server) handleStrategies(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.t.Strategies())
+}
+
+// handleEquity serves the equity curve for charting, optionally bounded by RFC 3339 from and to query parameters
+func (s *Server) handleEquity(w http.ResponseWriter, r *http.Request) {
+	var bounds [2]time.Time
+	for i, key := range []string{"from", "to"} {
+		v := r.URL.Query().Get(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s: %s", key, err), http.StatusBadRequest)
+			return
+		}
+		bounds[i] = t
+	}
+	snapshots, stats, err := s.t.Equity(bounds[0], bounds[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, EquityResponse{Snapshots: snapshots, Stats: stats})
+}
+
+// handleTrades serves a page of the trade history, filtered by the optional pair (BASE/QUOTE mints), RFC 3339 from and
+// to, and order status query parameters and paged by offset and limit
+func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := trader.TradeQuery{Pair: query.Get("pair"), Status: strings.ToUpper(query.Get("status"))}
+	for key, bound := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		if v := query.Get(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s: %s", key, err), http.StatusBadRequest)
+				return
+			}
+			*bound = t
+		}
+	}
+	for key, n := range map[string]*int{"offset": &q.Offset, "limit": &q.Limit} {
+		if v := query.Get(key); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 0 {
+				http.Error(w, fmt.Sprintf("%s must be a non-negative integer", key), http.StatusBadRequest)
+				return
+			}
+			*n = i
+		}
+	}
+	writeJSON(w, http.StatusOK, s.t.TradeHistory(q))
+}
+
+// handleAttribution serves the realized PnL per grid level, of the round trips closed since the optional RFC 3339
+// from query parameter
+func (s *Server) handleAttribution(w http.ResponseWriter, r *http.Request) {
+	var from time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, fmt.Sprintf("from: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, s.t.Attribution(from))
+}
+
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	var req SimulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sim, err := s.t.Simulate(req.Price)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, sim)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, _ *http.Request) {
+	s.t.Pause()
+	writeJSON(w, http.StatusOK, s.t.Status())
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, _ *http.Request) {
+	s.t.Resume()
+	writeJSON(w, http.StatusOK, s.t.Status())
+}
+
+func (s *Server) handleOrderSizes(w http.ResponseWriter, r *http.Request) {
+	var req OrderSizesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.t.SetOrderSizes(req.BuySize, req.SellSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.t.Status())
+}
+
+// writeJSON encodes the value as the response body
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/idtoken"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Role is the access level granted to an authenticated caller
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleRead
+	RoleControl
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	iapJwtHeader = "X-Goog-IAP-JWT-Assertion"
+)
+
+// Authenticator resolves the role of an incoming request from an API key or a Google-signed identity token (either an
+// IAP assertion or an OIDC bearer token)
+type Authenticator struct {
+	readKeys     []string
+	controlKeys  []string
+	audience     string
+	readEmails   map[string]bool
+	controlEmail map[string]bool
+	validate     func(ctx context.Context, token string, audience string) (*idtoken.Payload, error)
+}
+
+// NewAuthenticator creates an authenticator from the admin settings in the config
+func NewAuthenticator(cfg *configs.Config) *Authenticator {
+	return &Authenticator{
+		readKeys:     cfg.AdminReadApiKeys,
+		controlKeys:  cfg.AdminControlApiKeys,
+		audience:     cfg.AdminOidcAudience,
+		readEmails:   toSet(cfg.AdminReadEmails),
+		controlEmail: toSet(cfg.AdminControlEmails),
+		validate:     idtoken.Validate,
+	}
+}
+
+// Enabled reports whether any credential has been configured - the admin server refuses to start without one
+func (a *Authenticator) Enabled() bool {
+	return len(a.readKeys) > 0 || len(a.controlKeys) > 0 || a.audience != ""
+}
+
+// Role returns the highest role the request's credentials grant
+func (a *Authenticator) Role(r *http.Request) Role {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return a.roleForKey(key)
+	}
+
+	// IAP places its own assertion on the request, otherwise fall back to an OIDC bearer token
+	token := r.Header.Get(iapJwtHeader)
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" || a.audience == "" {
+		return RoleNone
+	}
+	payload, err := a.validate(r.Context(), token, a.audience)
+	if err != nil {
+		return RoleNone
+	}
+	email, _ := payload.Claims["email"].(string)
+	email = strings.ToLower(email)
+	switch {
+	case a.controlEmail[email]:
+		return RoleControl
+	case a.readEmails[email]:
+		return RoleRead
+	default:
+		return RoleNone
+	}
+}
+
+// Require wraps a handler so it only runs for callers holding at least the given role
+func (a *Authenticator) Require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := a.Role(r)
+		if got == RoleNone {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if got < role {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// roleForKey compares the key against the configured keys in constant time
+func (a *Authenticator) roleForKey(key string) Role {
+	for _, k := range a.controlKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return RoleControl
+		}
+	}
+	for _, k := range a.readKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return RoleRead
+		}
+	}
+	return RoleNone
+}
+
+// toSet converts a list into a lookup map, normalizing emails to lower case
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
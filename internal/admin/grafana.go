@@ -0,0 +1,216 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/equity"
+)
+
+// The series and tables served to the Grafana JSON datasource, and the activity it can annotate charts with
+const (
+	grafanaEquity     = "equity"
+	grafanaPnl        = "pnl"
+	grafanaPrice      = "price"
+	grafanaTrades     = "trades"
+	grafanaSignals    = "signals"
+	grafanaSuppressed = "suppressed"
+)
+
+// GrafanaRange is the dashboard's time range
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaQueryRequest models the body the Grafana JSON datasource posts for a panel's data
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange `json:"range"`
+	MaxDataPoints int          `json:"maxDataPoints"`
+	Targets       []struct {
+		RefId  string `json:"refId"`
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// GrafanaAnnotationRequest models the body the Grafana JSON datasource posts for an annotation query - the query
+// names the activity to annotate with, trades when empty
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange `json:"range"`
+	Annotation struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+// GrafanaSeries is a time series, each datapoint a value and its Unix time in milliseconds
+type GrafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaColumn is a column of a table
+type GrafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// GrafanaTable is a table of rows, which the datasource tells apart from series by its type
+type GrafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []GrafanaColumn `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// GrafanaAnnotation marks a point of a chart with bot activity
+type GrafanaAnnotation struct {
+	Time  int64    `json:"time"` // Unix milliseconds
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Tags  []string `json:"tags"`
+}
+
+// GrafanaMetric is a target offered in the query editor
+type GrafanaMetric struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+var grafanaMetrics = []GrafanaMetric{
+	{Label: "Equity", Value: grafanaEquity},
+	{Label: "PnL", Value: grafanaPnl},
+	{Label: "Price", Value: grafanaPrice},
+	{Label: "Trades", Value: grafanaTrades},
+	{Label: "Signals", Value: grafanaSignals},
+	{Label: "Suppressed signals", Value: grafanaSuppressed},
+}
+
+// handleGrafanaMetrics lists the targets for the JSON datasource's query editor - the older SimpleJson datasource
+// searches the same list by name
+func (s *Server) handleGrafanaMetrics(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/search") {
+		names := make([]string, 0, len(grafanaMetrics))
+		for _, m := range grafanaMetrics {
+			names = append(names, m.Value)
+		}
+		writeJSON(w, http.StatusOK, names)
+		return
+	}
+	writeJSON(w, http.StatusOK, grafanaMetrics)
+}
+
+// handleGrafanaQuery serves the equity curve as series and the trades and signals as tables over the dashboard's
+// range, thinning series to the panel's maximum number of points
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var snapshots []equity.Snapshot
+	out := make([]interface{}, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		switch target.Target {
+		case grafanaEquity, grafanaPnl, grafanaPrice:
+			if snapshots == nil {
+				var err error
+				if snapshots, _, err = s.t.Equity(req.Range.From, req.Range.To); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+			out = append(out, series(target.Target, snapshots, req.MaxDataPoints))
+		case grafanaTrades:
+			table := GrafanaTable{Type: "table", Columns: []GrafanaColumn{
+				{"Time", "time"}, {"Side", "string"}, {"Input amount", "number"}, {"Output amount", "number"},
+				{"Price", "number"}, {"Order", "string"}, {"Transaction", "string"},
+			}, Rows: [][]interface{}{}}
+			for _, tr := range s.t.Trades(req.Range.From, req.Range.To) {
+				table.Rows = append(table.Rows, []interface{}{tr.Time.UnixMilli(), tr.Side, tr.InputAmount,
+					tr.OutputAmount, tr.Price, tr.OrderId, tr.TxId})
+			}
+			out = append(out, table)
+		case grafanaSignals, grafanaSuppressed:
+			table := GrafanaTable{Type: "table", Columns: []GrafanaColumn{
+				{"Time", "time"}, {"Side", "string"}, {"Price", "number"}, {"Grid level", "number"},
+				{"Filters", "string"}, {"Stage", "string"}, {"Filter", "string"}, {"Reason", "string"},
+			}, Rows: [][]interface{}{}}
+			for _, sig := range s.t.Signals(req.Range.From, req.Range.To) {
+				if sig.Suppressed == (target.Target == grafanaSuppressed) {
+					table.Rows = append(table.Rows, []interface{}{sig.Time.UnixMilli(), sig.Side, sig.Price,
+						sig.GridLevel, sig.Filters, sig.Stage, sig.Filter, sig.Reason})
+				}
+			}
+			out = append(out, table)
+		default:
+			http.Error(w, fmt.Sprintf("unknown target %q", target.Target), http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleGrafanaAnnotations marks the dashboard's range with the trades, signals, or suppressed signals
+func (s *Server) handleGrafanaAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req GrafanaAnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out := []GrafanaAnnotation{}
+	switch query := strings.TrimSpace(req.Annotation.Query); query {
+	case "", grafanaTrades:
+		for _, tr := range s.t.Trades(req.Range.From, req.Range.To) {
+			out = append(out, GrafanaAnnotation{
+				Time:  tr.Time.UnixMilli(),
+				Title: fmt.Sprintf("%s %f at %f", tr.Side, tr.InputAmount, tr.Price),
+				Text:  fmt.Sprintf("order %s, transaction %s", tr.OrderId, tr.TxId),
+				Tags:  []string{grafanaTrades, string(tr.Side)},
+			})
+		}
+	case grafanaSignals, grafanaSuppressed:
+		for _, sig := range s.t.Signals(req.Range.From, req.Range.To) {
+			if sig.Suppressed != (query == grafanaSuppressed) {
+				continue
+			}
+			a := GrafanaAnnotation{
+				Time:  sig.Time.UnixMilli(),
+				Title: fmt.Sprintf("%s signal at %f", sig.Side, sig.Price),
+				Text:  fmt.Sprintf("grid level %d, filters %s", sig.GridLevel, sig.Filters),
+				Tags:  []string{query, string(sig.Side)},
+			}
+			if sig.Suppressed {
+				a.Text = fmt.Sprintf("suppressed by %s %s: %s", sig.Stage, sig.Filter, sig.Reason)
+			}
+			out = append(out, a)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unknown annotation query %q", query), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// series picks a field of the equity snapshots as a time series, keeping every nth point to stay within limit
+func series(target string, snapshots []equity.Snapshot, limit int) GrafanaSeries {
+	step := 1
+	if limit > 0 && len(snapshots) > limit {
+		step = (len(snapshots) + limit - 1) / limit
+	}
+	s := GrafanaSeries{Target: target, Datapoints: make([][2]float64, 0, len(snapshots)/step+1)}
+	for i := 0; i < len(snapshots); i += step {
+		snap := snapshots[i]
+		v := snap.Equity
+		switch target {
+		case grafanaPnl:
+			v = snap.Pnl
+		case grafanaPrice:
+			v = snap.Price
+		}
+		s.Datapoints = append(s.Datapoints, [2]float64{v, float64(snap.Time.UnixMilli())})
+	}
+	return s
+}
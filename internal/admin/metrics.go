@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// handleMetrics serves the dependency statistics in the Prometheus text format, so scrapers can graph and alert on
+// a provider degrading
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	status := s.t.Status()
+	deps := status.Dependencies
+	labels := metricLabels(status.Labels)
+
+	writeHeader(w, "ninetyfive_dependency_latency_ms", "latency percentiles of each external dependency's recent calls", "gauge")
+	for _, d := range deps {
+		fmt.Fprintf(w, "ninetyfive_dependency_latency_ms{%sdependency=%q,quantile=\"0.5\"} %g\n", labels, d.Name, d.P50Ms)
+		fmt.Fprintf(w, "ninetyfive_dependency_latency_ms{%sdependency=%q,quantile=\"0.9\"} %g\n", labels, d.Name, d.P90Ms)
+		fmt.Fprintf(w, "ninetyfive_dependency_latency_ms{%sdependency=%q,quantile=\"0.99\"} %g\n", labels, d.Name, d.P99Ms)
+	}
+	writeHeader(w, "ninetyfive_dependency_error_rate", "share of each external dependency's recent calls that failed", "gauge")
+	for _, d := range deps {
+		fmt.Fprintf(w, "ninetyfive_dependency_error_rate{%sdependency=%q} %g\n", labels, d.Name, d.ErrorRate)
+	}
+	writeHeader(w, "ninetyfive_dependency_calls_total", "calls of each external dependency since start", "counter")
+	for _, d := range deps {
+		fmt.Fprintf(w, "ninetyfive_dependency_calls_total{%sdependency=%q} %d\n", labels, d.Name, d.Total)
+	}
+
+	if b := status.Benchmark; b != nil {
+		writeHeader(w, "ninetyfive_pnl", "the bot's PnL and buy-and-hold's since the bot started, in the quote asset", "gauge")
+		fmt.Fprintf(w, "ninetyfive_pnl{%sportfolio=\"bot\"} %g\n", labels, b.Pnl)
+		fmt.Fprintf(w, "ninetyfive_pnl{%sportfolio=\"buy_and_hold\"} %g\n", labels, b.HoldPnl)
+		writeHeader(w, "ninetyfive_excess_pnl_pct", "how much the bot beat buy-and-hold by, as a percentage of the capital", "gauge")
+		fmt.Fprintf(w, "ninetyfive_excess_pnl_pct{%s} %g\n", strings.TrimSuffix(labels, ","), b.ExcessPct)
+	}
+}
+
+// metricLabels formats the deployment's labels for the start of a series' label set, so series scraped from several
+// deployments stay apart
+func metricLabels(l common.Labels) string {
+	m := l.Map()
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		fmt.Fprintf(&b, "%s=%q,", k, m[k])
+	}
+	return b.String()
+}
+
+// writeHeader writes a metric's HELP and TYPE lines
+func writeHeader(w io.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
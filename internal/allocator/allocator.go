@@ -0,0 +1,170 @@
+package allocator
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/position"
+)
+
+// Allocation methods
+const (
+	MethodFixed  = "fixed"  // each strategy keeps its configured budget
+	MethodSharpe = "sharpe" // the budgets' total is redistributed monthly by the strategies' realized Sharpe ratios
+)
+
+// Allocator enforces the capital budget of this instance's strategy when several strategies share one wallet - BUYs
+// are capped so the quote capital the strategy has deployed never exceeds its budget
+type Allocator struct {
+	cfg      *configs.Config
+	strategy string
+	pair     common.Pair
+	l        *ledger.Ledger
+	log      logger.Logger
+
+	mu     sync.Mutex
+	budget float64
+	month  time.Time // the month the budget was allocated for
+}
+
+// NewAllocator creates the allocator for the configured strategy - nil when capital allocation is off
+func NewAllocator(cfg *configs.Config, pair common.Pair, l *ledger.Ledger, log logger.Logger) (*Allocator, error) {
+	if cfg.CapitalAllocation == "" {
+		return nil, nil
+	}
+	if _, ok := cfg.CapitalBudgets[cfg.StrategyId]; !ok {
+		return nil, fmt.Errorf("capital_budgets has no budget for strategy %q", cfg.StrategyId)
+	}
+	return &Allocator{cfg: cfg, strategy: cfg.StrategyId, pair: pair, l: l, log: log}, nil
+}
+
+// Size caps the order to what is left of the budget given the quote capital already deployed, including BUYs in
+// flight - only BUYs deploy capital, and a BUY is refused once the budget is spent
+func (a *Allocator) Size(side common.Signal, size float64, deployed float64, now time.Time) (float64, error) {
+	if a == nil || side != common.BuySignal {
+		return size, nil
+	}
+	budget := a.Budget(now)
+	left := budget - max(deployed, 0)
+	if left <= 0 {
+		return 0, fmt.Errorf("strategy %s has deployed its %f budget", a.strategy, budget)
+	}
+	if size > left {
+		a.log.Info().Msg("capping BUY of %f to the %f left of strategy %s's budget", size, left, a.strategy)
+		return left, nil
+	}
+	return size, nil
+}
+
+// Budget returns the strategy's budget in the quote asset, reallocating it when a new month starts
+func (a *Allocator) Budget(now time.Time) float64 {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if a.month.Equal(month) {
+		return a.budget
+	}
+	a.month = month
+	a.budget = a.cfg.CapitalBudgets[a.strategy]
+	if a.cfg.CapitalAllocation != MethodSharpe {
+		return a.budget
+	}
+
+	budget, err := a.sharpeBudget(month.AddDate(0, -1, 0), month)
+	if err != nil {
+		a.log.Error().Err(err).Msg("failed to reallocate capital - keeping the fixed budget of %f", a.budget)
+		return a.budget
+	}
+	a.log.Info().Msg("reallocated strategy %s's budget to %f for %s", a.strategy, budget, month.Format("2006-01"))
+	a.budget = budget
+	return a.budget
+}
+
+// sharpeBudget splits the total of the budgets between the strategies in proportion to their realized Sharpe ratios
+// over the period - a strategy without a positive Sharpe keeps only the minimum share, and when none has one the
+// fixed budgets stand
+func (a *Allocator) sharpeBudget(from time.Time, to time.Time) (float64, error) {
+	var total float64
+	for _, b := range a.cfg.CapitalBudgets {
+		total += b
+	}
+
+	sharpes := make(map[string]float64)
+	for strategy, budget := range a.cfg.CapitalBudgets {
+		l := a.l
+		if strategy != a.strategy {
+			path, ok := a.cfg.CapitalLedgers[strategy]
+			if !ok {
+				return 0, fmt.Errorf("capital_ledgers has no ledger for strategy %q", strategy)
+			}
+			var err error
+			if l, err = a.l.OpenPeer(path); err != nil {
+				return 0, fmt.Errorf("could not open the ledger of strategy %q: %w", strategy, err)
+			}
+		}
+		sharpes[strategy] = max(sharpe(a.pair, l.Trades(), budget, from, to), 0)
+	}
+
+	var sum float64
+	for _, s := range sharpes {
+		sum += s
+	}
+	if sum == 0 {
+		return a.cfg.CapitalBudgets[a.strategy], nil
+	}
+	floor := a.cfg.CapitalMinShare
+	n := float64(len(sharpes))
+	share := floor + (1-floor*n)*sharpes[a.strategy]/sum
+	return total * share, nil
+}
+
+// sharpe returns the annualized Sharpe ratio of the strategy's daily PnL over the period, relative to its budget -
+// each day's PnL is the position's value change marked at the last fill price, so days without fills add nothing
+func sharpe(pair common.Pair, trades []ledger.Trade, budget float64, from time.Time, to time.Time) float64 {
+	if budget <= 0 {
+		return 0
+	}
+	pos := position.Position{Pair: pair}
+	var price float64
+	var i int
+	for ; i < len(trades) && trades[i].Time.Before(from); i++ {
+		pos.Apply(trades[i])
+		price = trades[i].Price
+	}
+
+	var returns []float64
+	prev := pos.Pnl(price)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		for ; i < len(trades) && trades[i].Time.Before(end); i++ {
+			pos.Apply(trades[i])
+			price = trades[i].Price
+		}
+		pnl := pos.Pnl(price)
+		returns = append(returns, (pnl-prev)/budget)
+		prev = pnl
+	}
+
+	var mean, ss float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	for _, r := range returns {
+		ss += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(ss / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	return mean / std * math.Sqrt(365)
+}
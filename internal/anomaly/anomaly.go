@@ -0,0 +1,170 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// feeSamples is how many recent fees an unusually large fee is judged against, and minFeeSamples how many it takes
+// before the judgement is made
+const (
+	feeSamples    = 20
+	minFeeSamples = 5
+)
+
+// Balances are the wallet's holdings of the pair at one point in time
+type Balances struct {
+	Base  float64
+	Quote float64
+}
+
+// Detector watches fills, orders, and the wallet for what should not happen - a fill far from its quote, an outsized
+// fee, signal after signal that never fills, balances moving with no trade to explain them - and escalates each
+// through the notifier with the context to investigate, catching bugs and a compromised wallet alike
+type Detector struct {
+	cfg *configs.Config
+	n   *notifier.Aggregator
+	log logger.Logger
+
+	mu       sync.Mutex
+	fees     []uint64
+	unfilled []string // ids of the orders settled without a fill since the last fill
+	balances *Balances
+	trades   int // the ledger's trade count when the balances were taken
+}
+
+// NewDetector creates a detector alerting through the notifier with the configured thresholds
+func NewDetector(cfg *configs.Config, n *notifier.Aggregator, log logger.Logger) *Detector {
+	return &Detector{cfg: cfg, n: n, log: log}
+}
+
+// SeedFees primes the recent fees with ones paid before the detector was created, oldest first, so a fee is judged
+// against them from the first fill - only the last of them are kept, as with the fees seen by CheckFill
+func (d *Detector) SeedFees(fees []uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fees = append(d.fees, fees...)
+	if len(d.fees) > feeSamples {
+		d.fees = d.fees[len(d.fees)-feeSamples:]
+	}
+}
+
+// CheckFill flags a fill that deviates from its quote by more than the threshold, and a fee far above the usual or
+// above the cap
+func (d *Detector) CheckFill(ctx context.Context, txId string, fill common.Fill) {
+	if d == nil {
+		return
+	}
+	if limit := d.cfg.AnomalyFillDeviationBps; limit > 0 {
+		if dev := fill.DeviationBps(); dev > limit {
+			d.flag(ctx, notifier.KeyAnomalyFill, notifier.SeverityWarning, "fill far from its quote",
+				fmt.Sprintf("swap %s filled %.0f bps worse than quoted (threshold %.0f): %f %s for %f %s, quoted %f for %f",
+					txId, dev, limit, fill.OutAmount, fill.OutputMint, fill.InAmount, fill.InputMint,
+					fill.QuotedOut, fill.QuotedIn))
+		}
+	}
+
+	d.mu.Lock()
+	median, known := medianFee(d.fees)
+	d.fees = append(d.fees, fill.FeeLamports)
+	if len(d.fees) > feeSamples {
+		d.fees = d.fees[1:]
+	}
+	d.mu.Unlock()
+	switch {
+	case d.cfg.AnomalyMaxFeeLamports > 0 && fill.FeeLamports > uint64(d.cfg.AnomalyMaxFeeLamports):
+		d.flag(ctx, notifier.KeyAnomalyFee, notifier.SeverityWarning, "unusually large fee",
+			fmt.Sprintf("swap %s paid %d lamports in fees, above the %d lamport cap", txId, fill.FeeLamports,
+				d.cfg.AnomalyMaxFeeLamports))
+	case known && d.cfg.AnomalyFeeMultiple > 0 && float64(fill.FeeLamports) > d.cfg.AnomalyFeeMultiple*median:
+		d.flag(ctx, notifier.KeyAnomalyFee, notifier.SeverityWarning, "unusually large fee",
+			fmt.Sprintf("swap %s paid %d lamports in fees, %.1fx the recent median of %.0f", txId, fill.FeeLamports,
+				float64(fill.FeeLamports)/median, median))
+	}
+}
+
+// OrderSettled tracks orders settling without a fill, flagging once the configured number of them have in a row
+func (d *Detector) OrderSettled(ctx context.Context, orderId string, filled bool) {
+	if d == nil || d.cfg.AnomalySignalsWithoutFill <= 0 {
+		return
+	}
+	d.mu.Lock()
+	if filled {
+		d.unfilled = nil
+		d.mu.Unlock()
+		return
+	}
+	d.unfilled = append(d.unfilled, orderId)
+	unfilled := slices.Clone(d.unfilled)
+	d.mu.Unlock()
+
+	if len(unfilled) >= d.cfg.AnomalySignalsWithoutFill {
+		d.flag(ctx, notifier.KeyAnomalySignals, notifier.SeverityWarning, "signals are not filling",
+			fmt.Sprintf("the last %d orders settled without a fill: %v", len(unfilled), unfilled))
+	}
+}
+
+// CheckBalances compares the wallet's balances with the last ones taken while nothing was trading, flagging a change
+// beyond the tolerance that no trade explains - busy is whether orders are being worked, and trades the count of the
+// ledger's trades and external adjustments, so balances only count as unexplained when neither moved
+func (d *Detector) CheckBalances(ctx context.Context, b Balances, busy bool, trades int) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	prev, prevTrades := d.balances, d.trades
+	d.balances, d.trades = &b, trades
+	if busy {
+		// Balances taken while swaps are in flight are no baseline
+		d.balances = nil
+	}
+	d.mu.Unlock()
+	if prev == nil || busy || trades != prevTrades {
+		return
+	}
+
+	tolerance := d.cfg.AnomalyBalanceTolerancePct / 100
+	if changed(prev.Base, b.Base, tolerance) || changed(prev.Quote, b.Quote, tolerance) {
+		d.flag(ctx, notifier.KeyAnomalyBalance, notifier.SeverityCritical, "balances changed without a trade",
+			fmt.Sprintf("base %f -> %f, quote %f -> %f with no trade recorded or order worked - check the wallet "+
+				"has not been compromised", prev.Base, b.Base, prev.Quote, b.Quote))
+	}
+}
+
+// flag logs the anomaly and escalates it
+func (d *Detector) flag(ctx context.Context, key string, severity notifier.Severity, title string, body string) {
+	d.log.Warn().Msg("anomaly - %s: %s", title, body)
+	if err := d.n.Notify(ctx, notifier.Alert{Key: key, Severity: severity, Title: title, Body: body}); err != nil {
+		d.log.Error().Err(err).Msg("failed to send anomaly alert")
+	}
+}
+
+// changed reports whether the balance moved by more than the tolerance, relative to the previous balance
+func changed(prev float64, cur float64, tolerance float64) bool {
+	if prev == 0 {
+		return cur != 0
+	}
+	return math.Abs(cur-prev)/math.Abs(prev) > tolerance
+}
+
+// medianFee returns the median of the recent fees once there are enough of them
+func medianFee(fees []uint64) (float64, bool) {
+	if len(fees) < minFeeSamples {
+		return 0, false
+	}
+	sorted := slices.Clone(fees)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2, true
+	}
+	return float64(sorted[mid]), true
+}
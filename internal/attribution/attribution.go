@@ -0,0 +1,161 @@
+package attribution
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// Manual is the level of round trips opened by a trade with no strategy snapshot, such as a manual swap
+const Manual = -1
+
+// dust is the smallest quantity treated as an open lot rather than float noise
+const dust = 1e-9
+
+// Level is the realized PnL of the round trips opened at one grid level
+type Level struct {
+	Level       int     `json:"level"`       // the grid line index, Manual for trades without a strategy snapshot
+	RoundTrips  int     `json:"round_trips"` // a lot closed by several fills counts once per fill
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+	RealizedPnl float64 `json:"realized_pnl"` // in the quote asset
+	Volume      float64 `json:"volume"`       // base closed
+	OpenBase    float64 `json:"open_base"`    // base still open, negative for a short-like imbalance
+}
+
+// WinRate returns the share of the level's round trips that made money
+func (l Level) WinRate() float64 {
+	if l.RoundTrips == 0 {
+		return 0
+	}
+	return float64(l.Wins) / float64(l.RoundTrips)
+}
+
+// String formats the level's results on one line
+func (l Level) String() string {
+	name := fmt.Sprintf("grid level %d", l.Level)
+	if l.Level == Manual {
+		name = "manual"
+	}
+	return fmt.Sprintf("%s: %+f over %d round trips (%.0f%% won), %f base open", name, l.RealizedPnl, l.RoundTrips,
+		l.WinRate()*100, l.OpenBase)
+}
+
+// Report is the realized PnL of a pair attributed to the grid levels that opened each round trip
+type Report struct {
+	From        time.Time `json:"from"`
+	RealizedPnl float64   `json:"realized_pnl"`
+	Levels      []Level   `json:"levels"` // ordered by level
+}
+
+// lot is an open quantity of base waiting to be closed - negative for base sold without being held
+type lot struct {
+	level int
+	base  float64
+	price float64
+}
+
+// Attribute matches the pair's fills first in, first out into round trips and credits each round trip's PnL to the
+// grid level of the fill that opened it - every fill is matched, so round trips opened before from close correctly,
+// but only closes at or after from are counted, a zero from counting them all - trades must be oldest first
+func Attribute(pair common.Pair, trades []ledger.Trade, from time.Time) Report {
+	r := Report{From: from}
+	levels := make(map[int]*Level)
+	get := func(level int) *Level {
+		if levels[level] == nil {
+			levels[level] = &Level{Level: level}
+		}
+		return levels[level]
+	}
+
+	var lots []lot
+	for _, t := range trades {
+		side, base, ok := fill(pair, t)
+		if !ok {
+			continue
+		}
+		signed := base
+		if side == common.SellSignal {
+			signed = -base
+		}
+		counted := !t.Time.Before(from)
+
+		// Close lots on the other side first in, first out, then open a lot with what is left
+		for len(lots) > 0 && math.Abs(signed) > dust && (lots[0].base > 0) != (signed > 0) {
+			closed := math.Min(math.Abs(lots[0].base), math.Abs(signed))
+			pnl := closed * (t.Price - lots[0].price)
+			if lots[0].base < 0 {
+				pnl = -pnl
+			}
+			if counted {
+				l := get(lots[0].level)
+				l.RoundTrips++
+				l.RealizedPnl += pnl
+				l.Volume += closed
+				if pnl >= 0 {
+					l.Wins++
+				} else {
+					l.Losses++
+				}
+				r.RealizedPnl += pnl
+			}
+			step := math.Copysign(closed, lots[0].base)
+			lots[0].base -= step
+			signed += step
+			if math.Abs(lots[0].base) <= dust {
+				lots = lots[1:]
+			}
+		}
+		if math.Abs(signed) > dust {
+			lots = append(lots, lot{level: GridLevel(t), base: signed, price: t.Price})
+		}
+	}
+
+	for _, open := range lots {
+		get(open.level).OpenBase += open.base
+	}
+	for _, level := range slices.Sorted(maps.Keys(levels)) {
+		r.Levels = append(r.Levels, *levels[level])
+	}
+	return r
+}
+
+// GridLevel returns the grid level the trade was decided at - the signal line index of the first strategy in its
+// snapshot - or Manual without a strategy snapshot
+func GridLevel(t ledger.Trade) int {
+	if t.Snapshot == nil || len(t.Snapshot.Strategy) == 0 {
+		return Manual
+	}
+	var strategy struct {
+		Strategies []struct {
+			State struct {
+				LastSignalIndex int `json:"last_signal_index"`
+			} `json:"state"`
+		} `json:"strategies"`
+	}
+	if err := json.Unmarshal(t.Snapshot.Strategy, &strategy); err != nil || len(strategy.Strategies) == 0 {
+		return Manual
+	}
+	return strategy.Strategies[0].State.LastSignalIndex
+}
+
+// fill returns the side of the trade in the pair and the base it moved, false for trades in other pairs - a BUY's
+// base is what it received, or is derived from its reference price when the fill's output was not read
+func fill(pair common.Pair, t ledger.Trade) (common.Signal, float64, bool) {
+	switch {
+	case t.InputMint == pair.Quote && t.OutputMint == pair.Base && t.OutputAmount > 0:
+		return common.BuySignal, t.OutputAmount, true
+	case t.InputMint == pair.Quote && t.OutputMint == pair.Base && t.Price > 0:
+		return common.BuySignal, t.InputAmount / t.Price, true
+	case t.InputMint == pair.Base && t.OutputMint == pair.Quote:
+		return common.SellSignal, t.InputAmount, true
+	default:
+		return "", 0, false
+	}
+}
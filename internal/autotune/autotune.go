@@ -0,0 +1,177 @@
+package autotune
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// Grid counts the tuner chooses among
+const (
+	MinGrids = 2
+	MaxGrids = 20
+)
+
+// week is the period the crossings target is expressed over
+const week = 7 * 24 * time.Hour
+
+// noTradeZones are the zones the Grid Manager accepts, widest first so a tie goes to the wider one
+var noTradeZones = []struct {
+	name   string
+	lo, hi float64
+}{
+	{"30-70", 30, 70},
+	{"35-65", 35, 65},
+	{"40-60", 40, 60},
+	{"45-55", 45, 55},
+	{"n/a", 50, 50},
+}
+
+// ErrAsymmetric is returned for a strategy configuring its sell side apart from its buy side, which is left as
+// configured rather than having the sell side's grids and no-trade zone replaced by the buy side's
+var ErrAsymmetric = errors.New("asymmetric strategies are not tuned")
+
+// Distribution is where the strategy's RSI spent the analyzed bars
+type Distribution struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+}
+
+// Candidate is one grid count and no-trade zone replayed over the bars
+type Candidate struct {
+	Grids       int     `json:"grids"`
+	NoTradeZone string  `json:"no_trade_zone"`
+	Signals     int     `json:"signals"`
+	PerWeek     float64 `json:"per_week"`
+}
+
+// Report is the tuning of one strategy - Best is the candidate whose crossings per week come closest to the target
+type Report struct {
+	Strategy   string       `json:"strategy"`
+	Bars       int          `json:"bars"`
+	Weeks      float64      `json:"weeks"`
+	Target     float64      `json:"target"` // crossings per week
+	Rsi        Distribution `json:"rsi"`
+	Best       Candidate    `json:"best"`
+	Candidates []Candidate  `json:"candidates"`
+}
+
+// Tune replays the bars through the symmetric strategy with every grid count, without a no-trade zone and with each zone whose
+// bounds lie within the RSI's 10th to 90th percentiles - a zone RSI rarely leaves would keep the grid from trading at
+// all - and picks the one closest to target crossings per week, preferring the wider zone and then fewer grids on a tie
+func Tune(s configs.Strategy, bars []common.Bar, target float64) (Report, error) {
+	r := Report{Strategy: s.Name, Bars: len(bars), Target: target}
+	if s.Asymmetric() {
+		return r, ErrAsymmetric
+	}
+	if len(bars) < 2 {
+		return r, fmt.Errorf("tuning needs at least 2 bars, got %d", len(bars))
+	}
+	r.Weeks = float64(bars[len(bars)-1].Time.Sub(bars[0].Time)) / float64(week)
+	if r.Weeks <= 0 {
+		return r, fmt.Errorf("the bars span no time")
+	}
+
+	rsi, err := rsiSeries(s, bars)
+	if err != nil {
+		return r, err
+	}
+	if len(rsi) == 0 {
+		return r, fmt.Errorf("the bars are too few to warm the RSI up")
+	}
+	slices.Sort(rsi)
+	r.Rsi = Distribution{
+		P10: percentile(rsi, 10),
+		P25: percentile(rsi, 25),
+		P50: percentile(rsi, 50),
+		P75: percentile(rsi, 75),
+		P90: percentile(rsi, 90),
+	}
+
+	bestDiff := math.Inf(1)
+	for _, zone := range noTradeZones {
+		if zone.name != "n/a" && (zone.lo < r.Rsi.P10 || zone.hi > r.Rsi.P90) {
+			continue
+		}
+		for grids := MinGrids; grids <= MaxGrids; grids++ {
+			c := s
+			c.Grids, c.NoTradeZone = grids, zone.name
+			signals, err := countSignals(c, bars)
+			if err != nil {
+				return r, err
+			}
+			cand := Candidate{Grids: grids, NoTradeZone: zone.name, Signals: signals, PerWeek: float64(signals) / r.Weeks}
+			r.Candidates = append(r.Candidates, cand)
+			if diff := math.Abs(cand.PerWeek - target); diff < bestDiff {
+				bestDiff = diff
+				r.Best = cand
+			}
+		}
+	}
+	return r, nil
+}
+
+// Apply sets the strategy's grid count and no-trade zone to the best candidate's
+func (r Report) Apply(s *configs.Strategy) {
+	s.Grids, s.NoTradeZone = r.Best.Grids, r.Best.NoTradeZone
+}
+
+// String summarizes the recommendation on one line
+func (r Report) String() string {
+	return fmt.Sprintf("%s: %d grids, no-trade zone %s - %.1f crossings per week against %.1f targeted over %d bars "+
+		"(%.1f weeks, RSI p10 %.1f, p50 %.1f, p90 %.1f)", r.Strategy, r.Best.Grids, r.Best.NoTradeZone,
+		r.Best.PerWeek, r.Target, r.Bars, r.Weeks, r.Rsi.P10, r.Rsi.P50, r.Rsi.P90)
+}
+
+// rsiSeries is the strategy's RSI after each bar once it has warmed up
+func rsiSeries(s configs.Strategy, bars []common.Bar) ([]float64, error) {
+	gm, err := ensemble.NewStrategy(s, logger.NopLogger{})
+	if err != nil {
+		return nil, err
+	}
+	rsi := make([]float64, 0, len(bars))
+	for i, bar := range bars {
+		if _, err = gm.ProcessBar(bar); err != nil {
+			return nil, err
+		}
+		if i >= s.RsiLength {
+			rsi = append(rsi, gm.Rsi())
+		}
+	}
+	return rsi, nil
+}
+
+// countSignals replays the bars through the strategy and counts the BUY and SELL signals it fires
+func countSignals(s configs.Strategy, bars []common.Bar) (int, error) {
+	gm, err := ensemble.NewStrategy(s, logger.NopLogger{})
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, bar := range bars {
+		signal, err := gm.ProcessBar(bar)
+		if err != nil {
+			return 0, err
+		}
+		if signal != common.DoNothingSignal {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// percentile is the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(0, min(idx, len(sorted)-1))]
+}
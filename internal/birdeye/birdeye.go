@@ -0,0 +1,169 @@
+package birdeye
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+const (
+	overviewEndpoint = "https://public-api.birdeye.so/defi/token_overview"
+	priceEndpoint    = "https://public-api.birdeye.so/defi/price"
+	ohlcvEndpoint    = "https://public-api.birdeye.so/defi/ohlcv"
+
+	// ohlcvPageSize is the most bars Birdeye returns per OHLCV request
+	ohlcvPageSize = 1000
+)
+
+// ohlcvTypes are the bar intervals Birdeye serves OHLCV for, by their Birdeye names
+var ohlcvTypes = map[time.Duration]string{
+	time.Minute:      "1m",
+	3 * time.Minute:  "3m",
+	5 * time.Minute:  "5m",
+	15 * time.Minute: "15m",
+	30 * time.Minute: "30m",
+	time.Hour:        "1H",
+	2 * time.Hour:    "2H",
+	4 * time.Hour:    "4H",
+	6 * time.Hour:    "6H",
+	8 * time.Hour:    "8H",
+	12 * time.Hour:   "12H",
+	24 * time.Hour:   "1D",
+}
+
+// Windows accepted by Volume, as named by the Birdeye token overview
+var Windows = []string{"30m", "1h", "2h", "4h", "8h", "24h"}
+
+// Client reads market data from the Birdeye public API
+type Client struct {
+	apiKey string
+	hc     *http.Client
+}
+
+// NewClient creates a Birdeye client authenticating with the given API key
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey: apiKey,
+		hc:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Volume returns the USD volume swapped in the token over the trailing window, e.g. "1h"
+func (c *Client) Volume(ctx context.Context, mint string, window string) (float64, error) {
+	var data map[string]interface{}
+	if err := c.get(ctx, overviewEndpoint, mint, nil, &data); err != nil {
+		return 0, err
+	}
+	volume, ok := data["v"+window+"USD"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("no %s volume for %s", window, mint)
+	}
+	return volume, nil
+}
+
+// Price returns the USD price of the token and when Birdeye last updated it
+func (c *Client) Price(ctx context.Context, mint string) (float64, time.Time, error) {
+	var data struct {
+		Value          float64 `json:"value"`
+		UpdateUnixTime int64   `json:"updateUnixTime"`
+	}
+	if err := c.get(ctx, priceEndpoint, mint, nil, &data); err != nil {
+		return 0, time.Time{}, err
+	}
+	return data.Value, time.Unix(data.UpdateUnixTime, 0), nil
+}
+
+// Bars returns the token's USD OHLCV bars of the interval closing within [from, to], oldest first
+func (c *Client) Bars(ctx context.Context, mint string, interval time.Duration, from, to time.Time) ([]common.Bar, error) {
+	barType, ok := ohlcvTypes[interval]
+	if !ok {
+		return nil, fmt.Errorf("birdeye has no %s bars", interval)
+	}
+
+	// Birdeye stamps bars with their open, while a Bar's time is its close
+	var bars []common.Bar
+	for start := from.Add(-interval); start.Before(to); start = start.Add(ohlcvPageSize * interval) {
+		end := start.Add(ohlcvPageSize * interval)
+		if end.After(to) {
+			end = to
+		}
+		var data struct {
+			Items []struct {
+				Open     float64 `json:"o"`
+				High     float64 `json:"h"`
+				Low      float64 `json:"l"`
+				Close    float64 `json:"c"`
+				UnixTime int64   `json:"unixTime"`
+			} `json:"items"`
+		}
+		params := url.Values{}
+		params.Add("type", barType)
+		params.Add("time_from", strconv.FormatInt(start.Unix(), 10))
+		params.Add("time_to", strconv.FormatInt(end.Unix(), 10))
+		if err := c.get(ctx, ohlcvEndpoint, mint, params, &data); err != nil {
+			return nil, err
+		}
+		for _, item := range data.Items {
+			closeTime := time.Unix(item.UnixTime, 0).Add(interval)
+			if closeTime.Before(from) || closeTime.After(to) {
+				continue
+			}
+			bars = append(bars, common.Bar{Time: closeTime, Open: item.Open, High: item.High, Low: item.Low, Close: item.Close})
+		}
+	}
+	return bars, nil
+}
+
+// SupportsInterval reports whether Birdeye serves OHLCV bars of the interval
+func SupportsInterval(interval time.Duration) bool {
+	_, ok := ohlcvTypes[interval]
+	return ok
+}
+
+// get requests a token endpoint with any extra query parameters and decodes the data field of a successful response
+func (c *Client) get(ctx context.Context, endpoint string, mint string, params url.Values, data interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Add("address", mint)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-KEY", c.apiKey)
+	req.Header.Set("x-chain", "solana")
+
+	res, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("birdeye returned %d: %s", res.StatusCode, string(body))
+	}
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		return err
+	}
+	if !envelope.Success {
+		return fmt.Errorf("birdeye request for %s was not successful", mint)
+	}
+	return json.Unmarshal(envelope.Data, data)
+}
@@ -0,0 +1,69 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Event is a signal as published to external consumers - Size is the order the bot would place for it, in the asset
+// the side spends, and Strength the share of the bot's strategies that voted for it
+type Event struct {
+	Pair     string        `json:"pair"`
+	Base     string        `json:"base"`
+	Quote    string        `json:"quote"`
+	Side     common.Signal `json:"side"`
+	Price    float64       `json:"price"`
+	Rsi      float64       `json:"rsi"`
+	Size     float64       `json:"size"`
+	Strength float64       `json:"strength"`
+	Time     time.Time     `json:"time"`
+	Traded   bool          `json:"traded"` // false when the bot only publishes signals and will not act on it itself
+}
+
+// Publisher delivers signals to systems outside the bot
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Nop discards every signal and is used when no outlet is configured
+type Nop struct{}
+
+func (Nop) Publish(context.Context, Event) error {
+	return nil
+}
+
+// Multi fans a signal out to several outlets, attempting all of them even when one fails
+type Multi []Publisher
+
+func (m Multi) Publish(ctx context.Context, event Event) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewPublisher builds the publisher for every signal outlet in the config
+func NewPublisher(ctx context.Context, cfg *configs.Config) (Publisher, error) {
+	var outlets Multi
+	if cfg.SignalWebhookUrl != "" {
+		outlets = append(outlets, NewWebhook(cfg.SignalWebhookUrl, cfg.SignalWebhookSecret))
+	}
+	if cfg.SignalPubsubTopic != "" {
+		p, err := NewPubSub(ctx, cfg.GcpProjectId, cfg.SignalPubsubTopic)
+		if err != nil {
+			return nil, err
+		}
+		outlets = append(outlets, p)
+	}
+	if len(outlets) == 0 {
+		return Nop{}, nil
+	}
+	return outlets, nil
+}
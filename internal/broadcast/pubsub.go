@@ -0,0 +1,49 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/api/pubsub/v1"
+)
+
+// PubSub publishes signals as JSON messages to a Google Cloud Pub/Sub topic, with the pair and side as attributes
+// so subscriptions can filter on them
+type PubSub struct {
+	topic string
+	svc   *pubsub.Service
+}
+
+// NewPubSub creates a Pub/Sub publisher with the application default credentials - topic is either a full
+// projects/<project>/topics/<name> path or a name in the given project
+func NewPubSub(ctx context.Context, projectId string, topic string) (*PubSub, error) {
+	svc, err := pubsub.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(topic, "projects/") {
+		topic = "projects/" + projectId + "/topics/" + topic
+	}
+	return &PubSub{topic: topic, svc: svc}, nil
+}
+
+// Publish sends the signal as a single message
+func (p *PubSub) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req := &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{{
+			Data: base64.StdEncoding.EncodeToString(data),
+			Attributes: map[string]string{
+				"pair": event.Pair,
+				"side": string(event.Side),
+			},
+		}},
+	}
+	_, err = p.svc.Projects.Topics.Publish(p.topic, req).Context(ctx).Do()
+	return err
+}
@@ -0,0 +1,65 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when the webhook has a secret, so receivers can
+// reject signals that did not come from the bot
+const SignatureHeader = "X-Ninetyfive-Signature"
+
+// Webhook POSTs signals as JSON to a URL
+type Webhook struct {
+	url    string
+	secret []byte
+	hc     *http.Client
+}
+
+// NewWebhook creates a webhook publisher for the URL, signing bodies with the secret when it is not empty
+func NewWebhook(url string, secret string) *Webhook {
+	return &Webhook{
+		url:    url,
+		secret: []byte(secret),
+		hc:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish posts the signal, treating any non-2xx response as a failure
+func (w *Webhook) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	res, err := w.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("signal webhook returned %d: %s", res.StatusCode, string(msg))
+	}
+	return nil
+}
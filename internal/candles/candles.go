@@ -0,0 +1,260 @@
+package candles
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Feed serves historical bars for backfilling the cache
+type Feed interface {
+	Bars(ctx context.Context, mint string, interval time.Duration, from, to time.Time) ([]common.Bar, error)
+}
+
+// Cache persists the bars the bot trades on, one JSON lines file per pair and timeframe, so warm-up, backtests, and
+// live trading all read the same data
+type Cache struct {
+	dir string
+
+	mu   sync.Mutex
+	last map[string]time.Time // close of the newest bar in each file
+}
+
+// Open creates the cache directory when it does not exist yet
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir, last: make(map[string]time.Time)}, nil
+}
+
+// Timeframe names the bars of the interval, e.g. "15m" or "1h" - an interval of 0 means every tick is a bar
+func Timeframe(interval time.Duration) string {
+	switch {
+	case interval <= 0:
+		return "tick"
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%dh", interval/time.Hour)
+	case interval%time.Minute == 0:
+		return fmt.Sprintf("%dm", interval/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", interval/time.Second)
+	}
+}
+
+// TimeframeFromConfig names the configured bars - the timeframe of the bar interval, marked when the pair is priced
+// in its quote asset so those bars never share a file with USD-priced ones
+func TimeframeFromConfig(cfg *configs.Config) string {
+	if cfg.PairPricing == "quote" {
+		return Timeframe(cfg.BarInterval) + "-quote"
+	}
+	return Timeframe(cfg.BarInterval)
+}
+
+// Path returns the file holding the pair's bars of the timeframe
+func (c *Cache) Path(pair common.Pair, timeframe string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s_%s_%s.jsonl", pair.Base, pair.Quote, timeframe))
+}
+
+// Append adds a complete bar - bars closing no later than the newest cached one are ignored, so replays and restarts
+// never duplicate history
+func (c *Cache) Append(pair common.Pair, timeframe string, bar common.Bar) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.Path(pair, timeframe)
+	last, ok := c.last[path]
+	if !ok {
+		bars, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if len(bars) > 0 {
+			last = bars[len(bars)-1].Time
+		}
+	}
+	if !bar.Time.After(last) {
+		return nil
+	}
+
+	line, err := json.Marshal(bar)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	c.last[path] = bar.Time
+	return nil
+}
+
+// Load returns every cached bar of the pair and timeframe, oldest first
+func (c *Cache) Load(pair common.Pair, timeframe string) ([]common.Bar, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ReadFile(c.Path(pair, timeframe))
+}
+
+// Backfill fetches the bars missing from the cache of the timeframe between from and to from the feed, at the
+// interval, returning how many were added
+func (c *Cache) Backfill(ctx context.Context, feed Feed, pair common.Pair, timeframe string, interval time.Duration,
+	from, to time.Time) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.Path(pair, timeframe)
+	bars, err := ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var added []common.Bar
+	for _, g := range gaps(bars, interval, from, to) {
+		fetched, err := feed.Bars(ctx, pair.Base, interval, g.from, g.to)
+		if err != nil {
+			return 0, fmt.Errorf("backfilling %s to %s: %w", g.from.Format(time.RFC3339), g.to.Format(time.RFC3339), err)
+		}
+		added = append(added, fetched...)
+	}
+	if len(added) == 0 {
+		return 0, nil
+	}
+
+	merged := merge(append(bars, added...))
+	if err = writeFile(path, merged); err != nil {
+		return 0, err
+	}
+	c.last[path] = merged[len(merged)-1].Time
+	return len(merged) - len(bars), nil
+}
+
+// Import merges bars into the cache, keeping the cached bar where both have the same close, and returns how many were
+// added
+func (c *Cache) Import(pair common.Pair, timeframe string, bars []common.Bar) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.Path(pair, timeframe)
+	cached, err := ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	merged := merge(append(cached, bars...))
+	if len(merged) == len(cached) {
+		return 0, nil
+	}
+	if err = writeFile(path, merged); err != nil {
+		return 0, err
+	}
+	c.last[path] = merged[len(merged)-1].Time
+	return len(merged) - len(cached), nil
+}
+
+// span is a range of bar closes missing from the cache
+type span struct {
+	from, to time.Time
+}
+
+// gaps returns the ranges within [from, to] where more than an interval passes between cached bars
+func gaps(bars []common.Bar, interval time.Duration, from, to time.Time) []span {
+	var spans []span
+	prev := from.Add(-interval)
+	for _, b := range bars {
+		if b.Time.Before(from) {
+			continue
+		}
+		if b.Time.After(to) {
+			break
+		}
+		if b.Time.Sub(prev) > interval {
+			spans = append(spans, span{from: prev.Add(interval), to: b.Time.Add(-interval)})
+		}
+		prev = b.Time
+	}
+	if to.Sub(prev) >= interval {
+		spans = append(spans, span{from: prev.Add(interval), to: to})
+	}
+	return spans
+}
+
+// ReadFile reads a file of JSON lines bars, oldest first with duplicates removed - a missing file has no bars
+func ReadFile(path string) ([]common.Bar, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var bars []common.Bar
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var b common.Bar
+		if err = json.Unmarshal(scanner.Bytes(), &b); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		bars = append(bars, b)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return merge(bars), nil
+}
+
+// merge sorts the bars by close and keeps the first of any with the same close
+func merge(bars []common.Bar) []common.Bar {
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Time.Before(bars[j].Time) })
+	merged := bars[:0]
+	for _, b := range bars {
+		if len(merged) > 0 && b.Time.Equal(merged[len(merged)-1].Time) {
+			continue
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
+
+// writeFile rewrites the bars to a temporary file and renames it into place so a crash never leaves a torn file
+func writeFile(path string, bars []common.Bar) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, b := range bars {
+		if err = enc.Encode(b); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
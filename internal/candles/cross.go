@@ -0,0 +1,51 @@
+package candles
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// crossFeed serves a mint's bars priced in a quote asset, dividing the feed's USD bars of the mint by the quote's
+type crossFeed struct {
+	feed  Feed
+	quote string
+}
+
+// NewCross wraps a feed of USD bars so it serves bars priced in the quote mint - a bar is only served when the quote
+// has one at the same time, and its high and low are taken against the quote's close, the feed not telling when
+// within the bar either extreme was reached
+func NewCross(feed Feed, quote string) Feed {
+	return &crossFeed{feed: feed, quote: quote}
+}
+
+// Bars returns the mint's bars between the times, priced in the quote mint
+func (f *crossFeed) Bars(ctx context.Context, mint string, interval time.Duration, from, to time.Time) (
+	[]common.Bar, error) {
+	base, err := f.feed.Bars(ctx, mint, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := f.feed.Bars(ctx, f.quote, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	quoteAt := make(map[int64]common.Bar, len(quote))
+	for _, q := range quote {
+		quoteAt[q.Time.Unix()] = q
+	}
+
+	bars := make([]common.Bar, 0, len(base))
+	for _, b := range base {
+		q, ok := quoteAt[b.Time.Unix()]
+		if !ok || q.Open <= 0 || q.Close <= 0 {
+			continue
+		}
+		bar := common.Bar{Time: b.Time, Open: b.Open / q.Open, Close: b.Close / q.Close}
+		bar.High = max(b.High/q.Close, bar.Open, bar.Close)
+		bar.Low = min(b.Low/q.Close, bar.Open, bar.Close)
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
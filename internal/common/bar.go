@@ -0,0 +1,44 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// Price sources a bar can feed into an indicator, named as in TradingView
+const (
+	SourceClose = "close"
+	SourceHl2   = "hl2"   // (high + low) / 2
+	SourceHlc3  = "hlc3"  // (high + low + close) / 3, the typical price
+	SourceOhlc4 = "ohlc4" // (open + high + low + close) / 4
+)
+
+// Bar is one OHLC bar - Time is its close
+type Bar struct {
+	Time  time.Time `json:"time"`
+	Open  float64   `json:"open"`
+	High  float64   `json:"high"`
+	Low   float64   `json:"low"`
+	Close float64   `json:"close"`
+}
+
+// PriceBar is the bar of a single price sample, where every source is the price itself
+func PriceBar(t time.Time, price float64) Bar {
+	return Bar{Time: t, Open: price, High: price, Low: price, Close: price}
+}
+
+// Source returns the bar's value for the named price source - empty means close
+func (b Bar) Source(source string) (float64, error) {
+	switch source {
+	case "", SourceClose:
+		return b.Close, nil
+	case SourceHl2:
+		return (b.High + b.Low) / 2, nil
+	case SourceHlc3:
+		return (b.High + b.Low + b.Close) / 3, nil
+	case SourceOhlc4:
+		return (b.Open + b.High + b.Low + b.Close) / 4, nil
+	default:
+		return 0, fmt.Errorf("unknown price source %q", source)
+	}
+}
@@ -0,0 +1,14 @@
+package common
+
+// ComputeBudget is the compute a swap transaction requested and paid for - UnitsConsumed is only known when the
+// swap was simulated before it was sent
+type ComputeBudget struct {
+	UnitLimit     uint32 `json:"unit_limit"`
+	UnitPrice     uint64 `json:"unit_price"` // micro-lamports per compute unit
+	UnitsConsumed uint64 `json:"units_consumed,omitempty"`
+}
+
+// PriorityFeeLamports returns the most the transaction pays on top of the base fee for priority
+func (b ComputeBudget) PriorityFeeLamports() uint64 {
+	return uint64(b.UnitLimit) * b.UnitPrice / 1_000_000
+}
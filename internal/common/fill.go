@@ -0,0 +1,24 @@
+package common
+
+// Fill is what a confirmed swap actually exchanged, read back from the chain, next to what it was quoted at - amounts
+// are in each asset's own units
+type Fill struct {
+	InputMint   string  `json:"input_mint"`
+	OutputMint  string  `json:"output_mint"`
+	InAmount    float64 `json:"in_amount"`
+	OutAmount   float64 `json:"out_amount"`
+	QuotedIn    float64 `json:"quoted_in"`
+	QuotedOut   float64 `json:"quoted_out"`
+	FeeLamports uint64  `json:"fee_lamports"` // base and priority fee the transaction paid
+	PlatformFee float64 `json:"platform_fee"` // the platform fee collected out of the output
+}
+
+// DeviationBps returns how much worse than quoted the swap filled, in basis points of the quoted rate - negative when
+// it filled better
+func (f Fill) DeviationBps() float64 {
+	if f.InAmount <= 0 || f.QuotedIn <= 0 || f.QuotedOut <= 0 {
+		return 0
+	}
+	quoted := f.QuotedOut / f.QuotedIn
+	return (quoted - f.OutAmount/f.InAmount) / quoted * 10_000
+}
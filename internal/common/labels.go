@@ -0,0 +1,66 @@
+package common
+
+import (
+	"os"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Trading modes - paper trades are simulated at the quoted price and never sent
+const (
+	ModeLive  = "live"
+	ModePaper = "paper"
+)
+
+// Labels identify the deployment a record came from, so the ledgers, metrics, and logs of several deployments can be
+// told apart and paper trades never pass for live ones
+type Labels struct {
+	Environment string `json:"environment"`
+	Strategy    string `json:"strategy"`
+	Instance    string `json:"instance"`
+	Mode        string `json:"mode"`
+	Pricing     string `json:"pricing,omitempty"` // the pair pricing prices were recorded in, usd when empty
+}
+
+// NewLabels reads the labels from the config - the instance defaults to the host name, and observing a wallet is
+// paper trading
+func NewLabels(cfg *configs.Config) Labels {
+	instance := cfg.InstanceId
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	mode := ModeLive
+	if cfg.ObserveWallet != "" {
+		mode = ModePaper
+	}
+	return Labels{
+		Environment: cfg.Environment,
+		Strategy:    cfg.StrategyId,
+		Instance:    instance,
+		Mode:        mode,
+		Pricing:     cfg.PairPricing,
+	}
+}
+
+// Paper reports whether the labels are those of paper trading
+func (l Labels) Paper() bool {
+	return l.Mode == ModePaper
+}
+
+// PricingMode returns the pair pricing the labels record, usd for records from before pricing was labeled
+func (l Labels) PricingMode() string {
+	if l.Pricing == "" {
+		return "usd"
+	}
+	return l.Pricing
+}
+
+// Map returns the labels as key-value pairs, for loggers and metrics
+func (l Labels) Map() map[string]string {
+	return map[string]string{
+		"environment": l.Environment,
+		"strategy":    l.Strategy,
+		"instance":    l.Instance,
+		"mode":        l.Mode,
+	}
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Pair orientations accepted in the config
+const (
+	// OrientationLegacy treats base_currency as the asset spent on BUY, which is how the bot originally swapped
+	OrientationLegacy = "legacy"
+	// OrientationConventional treats base_currency as the traded asset priced in quote_currency, as exchanges do
+	OrientationConventional = "conventional"
+)
+
+// Pair is a market in conventional exchange terms - BUY acquires Base by spending Quote, SELL gives up Base for Quote
+type Pair struct {
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+}
+
+// NewPair validates both mints and returns the pair
+func NewPair(base string, quote string) (Pair, error) {
+	if _, err := solana.PublicKeyFromBase58(base); err != nil {
+		return Pair{}, fmt.Errorf("invalid base mint %q: %w", base, err)
+	}
+	if _, err := solana.PublicKeyFromBase58(quote); err != nil {
+		return Pair{}, fmt.Errorf("invalid quote mint %q: %w", quote, err)
+	}
+	if base == quote {
+		return Pair{}, fmt.Errorf("base and quote mints must differ")
+	}
+	return Pair{Base: base, Quote: quote}, nil
+}
+
+// NewPairFromConfig builds the traded pair from the configured currencies according to the configured orientation
+func NewPairFromConfig(cfg *configs.Config) (Pair, error) {
+	switch cfg.PairOrientation {
+	case "", OrientationLegacy:
+		return NewPair(cfg.QuoteCurrency, cfg.BaseCurrency)
+	case OrientationConventional:
+		return NewPair(cfg.BaseCurrency, cfg.QuoteCurrency)
+	default:
+		return Pair{}, fmt.Errorf("unknown pair orientation %q", cfg.PairOrientation)
+	}
+}
+
+// String formats the pair as BASE/QUOTE
+func (p Pair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// Mints returns the input and output mints of the swap that executes the given side
+func (p Pair) Mints(side Signal) (string, string, error) {
+	switch side {
+	case BuySignal:
+		return p.Quote, p.Base, nil
+	case SellSignal:
+		return p.Base, p.Quote, nil
+	default:
+		return "", "", fmt.Errorf("no swap executes a %s signal", side)
+	}
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+const (
+	usdc = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	ray  = "4k3Dyjzvzp8eMZWUXbBCjEvwSkkk59S5iCNLY3QrkX6R"
+)
+
+func TestNewPairFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation string
+		want        Pair
+		wantErr     bool
+	}{
+		{name: "unset is legacy", orientation: "", want: Pair{Base: ray, Quote: usdc}},
+		{name: "legacy spends base_currency on BUY", orientation: OrientationLegacy, want: Pair{Base: ray, Quote: usdc}},
+		{name: "conventional acquires base_currency on BUY", orientation: OrientationConventional,
+			want: Pair{Base: usdc, Quote: ray}},
+		{name: "unknown orientation", orientation: "sideways", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configs.Config{BaseCurrency: usdc, QuoteCurrency: ray, PairOrientation: tt.orientation}
+			got, err := NewPairFromConfig(cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPairRejectsInvalidMints(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		quote string
+	}{
+		{"invalid base", "not-a-mint", usdc},
+		{"invalid quote", usdc, "0OIl"},
+		{"empty base", "", usdc},
+		{"same mints", usdc, usdc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := NewPair(tt.base, tt.quote); err == nil {
+				t.Fatalf("got %+v, want an error", p)
+			}
+		})
+	}
+
+	cfg := &configs.Config{BaseCurrency: "not-a-mint", QuoteCurrency: usdc, PairOrientation: OrientationConventional}
+	if p, err := NewPairFromConfig(cfg); err == nil {
+		t.Fatalf("got %+v from an invalid base_currency, want an error", p)
+	}
+}
+
+func TestPairMints(t *testing.T) {
+	p, err := NewPair(solana.SolMint.String(), usdc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		side    Signal
+		input   string
+		output  string
+		wantErr bool
+	}{
+		{side: BuySignal, input: usdc, output: solana.SolMint.String()},
+		{side: SellSignal, input: solana.SolMint.String(), output: usdc},
+		{side: DoNothingSignal, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.side), func(t *testing.T) {
+			input, output, err := p.Mints(tt.side)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s -> %s, want an error", input, output)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if input != tt.input || output != tt.output {
+				t.Errorf("got %s -> %s, want %s -> %s", input, output, tt.input, tt.output)
+			}
+		})
+	}
+	if got, want := p.String(), solana.SolMint.String()+"/"+usdc; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+package common
+
+// TxStatus is what became of a transaction whose confirmation could not be followed to the end
+type TxStatus string
+
+const (
+	TxLanded  TxStatus = "landed"  // confirmed on chain without error
+	TxFailed  TxStatus = "failed"  // included on chain with an error
+	TxDropped TxStatus = "dropped" // never included, and its blockhash expired so it never will be
+	TxPending TxStatus = "pending" // not seen yet, but its blockhash is still valid so it may still land
+)
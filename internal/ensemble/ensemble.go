@@ -0,0 +1,223 @@
+package ensemble
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/gridmanager"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// Rules for combining the strategies' votes
+const (
+	RuleUnanimous        = "unanimous"           // every strategy must vote the same side
+	RuleMajority         = "majority"            // more than half of the strategies must vote the same side
+	RuleAnyBuyVetoesSell = "any_buy_vetoes_sell" // as majority, but a single BUY vote blocks a SELL
+)
+
+// Vote is one strategy's signal on a bar
+type Vote struct {
+	Strategy string        `json:"strategy"`
+	Signal   common.Signal `json:"signal"`
+}
+
+// Decision is the ensemble's signal on a bar - Strength is the share of strategies that voted for it, used to scale
+// the order size
+type Decision struct {
+	Signal   common.Signal `json:"signal"`
+	Strength float64       `json:"strength"`
+	Votes    []Vote        `json:"votes"`
+}
+
+// MemberState is a strategy's inputs and memory after the latest bar
+type MemberState struct {
+	Name       string                 `json:"name"`
+	Parameters gridmanager.Parameters `json:"parameters"`
+	State      gridmanager.State      `json:"state"`
+}
+
+// MemberSnapshot is a strategy's indicator, grid, and filter states after the latest bar
+type MemberSnapshot struct {
+	Name     string               `json:"name"`
+	Snapshot gridmanager.Snapshot `json:"snapshot"`
+}
+
+// member is a named Grid Manager of the ensemble
+type member struct {
+	name string
+	gm   *gridmanager.GridManager
+}
+
+// Ensemble runs several Grid Managers on the same prices and combines their signals into one - it is safe for
+// concurrent use, so simulations can run alongside the live loop
+type Ensemble struct {
+	mu      sync.Mutex
+	members []member
+	rule    string
+}
+
+// NewEnsemble builds a Grid Manager for each strategy, combining their votes with the rule
+func NewEnsemble(strategies []configs.Strategy, rule string, log logger.Logger) (*Ensemble, error) {
+	switch rule {
+	case RuleUnanimous, RuleMajority, RuleAnyBuyVetoesSell:
+	default:
+		return nil, fmt.Errorf("unknown ensemble rule %q", rule)
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("an ensemble needs at least one strategy")
+	}
+
+	e := &Ensemble{rule: rule}
+	for _, s := range strategies {
+		gm, err := NewStrategy(s, log)
+		if err != nil {
+			return nil, err
+		}
+		e.members = append(e.members, member{name: s.Name, gm: gm})
+	}
+	return e, nil
+}
+
+// NewStrategy builds the Grid Manager for a configured strategy
+func NewStrategy(s configs.Strategy, log logger.Logger) (*gridmanager.GridManager, error) {
+	gm := gridmanager.NewGridManager(s.RsiLength, s.Grids, s.Direction, s.NoTradeZone, s.Aggression, s.RsiType, log)
+	gm.LaguerreGamma = s.LaguerreGamma
+	if s.Asymmetric() {
+		grids, ntz, agg := s.Grids, s.NoTradeZone, s.Aggression
+		if s.SellGrids != 0 {
+			grids = s.SellGrids
+		}
+		if s.SellNoTradeZone != "" {
+			ntz = s.SellNoTradeZone
+		}
+		if s.SellAggression != "" {
+			agg = s.SellAggression
+		}
+		gm.SetSellSide(grids, ntz, agg)
+	}
+	if err := gm.SetSource(s.Source, s.Smoothing); err != nil {
+		return nil, fmt.Errorf("strategy %s: %w", s.Name, err)
+	}
+	return gm, nil
+}
+
+// NewEnsembleFromConfig builds the ensemble of the configured strategies, each resetting after prolonged drift as
+// configured
+func NewEnsembleFromConfig(cfg *configs.Config, log logger.Logger) (*Ensemble, error) {
+	e, err := NewEnsemble(cfg.Strategies, cfg.EnsembleRule, log)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range e.members {
+		m.gm.SetDriftReset(cfg.DriftResetBars, cfg.DriftRsiExtreme, cfg.DriftHold)
+	}
+	return e, nil
+}
+
+// Process feeds the bar to every strategy and combines their signals - every strategy sees every bar, even when an
+// earlier one fails, so their memories stay aligned
+func (e *Ensemble) Process(bar common.Bar) (Decision, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	d := Decision{Signal: common.DoNothingSignal, Votes: make([]Vote, 0, len(e.members))}
+	var firstErr error
+	counts := make(map[common.Signal]int)
+	for _, m := range e.members {
+		signal, err := m.gm.ProcessBar(bar)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("strategy %s: %w", m.name, err)
+			}
+			continue
+		}
+		d.Votes = append(d.Votes, Vote{Strategy: m.name, Signal: signal})
+		counts[signal]++
+	}
+	if firstErr != nil {
+		return d, firstErr
+	}
+
+	total := len(e.members)
+	for _, side := range []common.Signal{common.BuySignal, common.SellSignal} {
+		votes := counts[side]
+		var wins bool
+		switch e.rule {
+		case RuleUnanimous:
+			wins = votes == total
+		case RuleMajority:
+			wins = 2*votes > total
+		case RuleAnyBuyVetoesSell:
+			wins = 2*votes > total && (side != common.SellSignal || counts[common.BuySignal] == 0)
+		}
+		if wins {
+			d.Signal = side
+			d.Strength = float64(votes) / float64(total)
+			break
+		}
+	}
+	return d, nil
+}
+
+// SetSkew lowers every strategy's buy and sell thresholds by offset RSI points, skewing them against the inventory
+func (e *Ensemble) SetSkew(offset float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range e.members {
+		m.gm.SetSkew(offset)
+	}
+}
+
+// Simulate processes the bar on a copy of every strategy, returning the decision and the copies' states as they
+// would be after the bar - the live strategies are left untouched
+func (e *Ensemble) Simulate(bar common.Bar) (Decision, []MemberSnapshot, error) {
+	e.mu.Lock()
+	sim := &Ensemble{rule: e.rule, members: make([]member, 0, len(e.members))}
+	for _, m := range e.members {
+		sim.members = append(sim.members, member{name: m.name, gm: m.gm.Clone()})
+	}
+	e.mu.Unlock()
+
+	d, err := sim.Process(bar)
+	if err != nil {
+		return d, nil, err
+	}
+	return d, sim.Snapshot(), nil
+}
+
+// FullStrength reports whether every BUY or SELL decision has a strength of 1 - with a single strategy or unanimous
+// voting - so the order it sizes is known before the votes are in
+func (e *Ensemble) FullStrength() bool {
+	return len(e.members) == 1 || e.rule == RuleUnanimous
+}
+
+// Rsi returns the RSI/RSX value of the first strategy, which the filters and status report on
+func (e *Ensemble) Rsi() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.members[0].gm.Rsi()
+}
+
+// Snapshot returns every strategy's indicator, grid, and filter states
+func (e *Ensemble) Snapshot() []MemberSnapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	snapshots := make([]MemberSnapshot, 0, len(e.members))
+	for _, m := range e.members {
+		snapshots = append(snapshots, MemberSnapshot{Name: m.name, Snapshot: m.gm.Snapshot()})
+	}
+	return snapshots
+}
+
+// State returns every strategy's inputs and memory
+func (e *Ensemble) State() []MemberState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	states := make([]MemberState, 0, len(e.members))
+	for _, m := range e.members {
+		states = append(states, MemberState{Name: m.name, Parameters: m.gm.Parameters(), State: m.gm.State()})
+	}
+	return states
+}
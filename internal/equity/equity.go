@@ -0,0 +1,297 @@
+package equity
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Snapshot is the bot's equity at the close of a bar
+type Snapshot struct {
+	Time   time.Time `json:"time"`
+	Price  float64   `json:"price"`
+	Base   float64   `json:"base"`   // the position's net base acquired
+	Quote  float64   `json:"quote"`  // the position's net quote spent
+	Pnl    float64   `json:"pnl"`    // the position's PnL marked at the price
+	Equity float64   `json:"equity"` // the benchmark capital plus the PnL
+
+	QuoteUsd float64 `json:"quote_usd,omitempty"` // the quote asset's USD price, when the pair is priced in it
+}
+
+// Stats are risk-adjusted performance figures over a rolling window of snapshots - Sharpe and Sortino are annualized
+// from the per-bar equity changes, and drawdowns are measured from the window's running peak
+type Stats struct {
+	Window         string    `json:"window"`
+	Since          time.Time `json:"since"`
+	Samples        int       `json:"samples"`
+	Sharpe         float64   `json:"sharpe"`
+	Sortino        float64   `json:"sortino"`
+	MaxDrawdown    float64   `json:"max_drawdown"`     // in the quote asset
+	MaxDrawdownPct float64   `json:"max_drawdown_pct"` // of the peak equity, 0 when the equity has no capital behind it
+	Drawdown       float64   `json:"drawdown"`         // the current drawdown, in the quote asset
+	DrawdownPct    float64   `json:"drawdown_pct"`
+}
+
+// String summarizes the stats in one line
+func (s Stats) String() string {
+	return fmt.Sprintf("sharpe %.2f, sortino %.2f, max drawdown %f (%.2f%%), drawdown %f (%.2f%%) over %s",
+		s.Sharpe, s.Sortino, s.MaxDrawdown, s.MaxDrawdownPct, s.Drawdown, s.DrawdownPct, s.Window)
+}
+
+// Curve persists a snapshot per bar to an append-only file and keeps the window's worth in memory for the stats - the
+// file is rewritten without the snapshots older than the retention once they pass it by a tenth, so it stays bounded
+// without a rewrite per bar
+type Curve struct {
+	path      string
+	window    time.Duration
+	retention time.Duration // 0 keeps every snapshot
+
+	mu     sync.Mutex
+	recent []Snapshot
+	oldest time.Time // of the snapshots in the file
+}
+
+// NewCurve opens the curve at the path, reloading the snapshots within the window so the stats survive restarts
+func NewCurve(path string, window time.Duration, retention time.Duration) (*Curve, error) {
+	snapshots, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Curve{path: path, window: window, retention: retention, recent: snapshots}
+	if len(snapshots) > 0 {
+		c.oldest = snapshots[0].Time
+		latest := snapshots[len(snapshots)-1].Time
+		if retention > 0 && latest.Sub(c.oldest) > retention {
+			if err = c.rotate(snapshots, latest); err != nil {
+				return nil, err
+			}
+		}
+		c.trim(latest)
+	}
+	return c, nil
+}
+
+// Record appends a snapshot - snapshots no later than the newest one are ignored, so replays never duplicate history
+func (c *Curve) Record(s Snapshot) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n := len(c.recent); n > 0 && !s.Time.After(c.recent[n-1].Time) {
+		return nil
+	}
+
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	c.recent = append(c.recent, s)
+	c.trim(s.Time)
+	if c.oldest.IsZero() {
+		c.oldest = s.Time
+	}
+	if c.retention <= 0 || s.Time.Sub(c.oldest) <= c.retention+c.retention/10 {
+		return nil
+	}
+	snapshots, err := ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	return c.rotate(snapshots, s.Time)
+}
+
+// rotate rewrites the file with only the snapshots within the retention before now, through a temporary file renamed
+// into place so a crash never leaves a torn file - callers must hold the lock, or own the curve
+func (c *Curve) rotate(snapshots []Snapshot, now time.Time) error {
+	cutoff := now.Add(-c.retention)
+	i := 0
+	for i < len(snapshots) && snapshots[i].Time.Before(cutoff) {
+		i++
+	}
+	snapshots = snapshots[i:]
+	var data []byte
+	for _, s := range snapshots {
+		line, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := filepath.Join(filepath.Dir(c.path), "."+filepath.Base(c.path)+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("could not rotate %s: %w", c.path, err)
+	}
+	c.oldest = time.Time{}
+	if len(snapshots) > 0 {
+		c.oldest = snapshots[0].Time
+	}
+	return nil
+}
+
+// Load returns the persisted snapshots between from and to, oldest first - a zero bound is open, and a range starting
+// within the stats window is served from memory rather than the file
+func (c *Curve) Load(from time.Time, to time.Time) ([]Snapshot, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var snapshots []Snapshot
+	if n := len(c.recent); n > 0 && !from.IsZero() && !from.Before(c.recent[n-1].Time.Add(-c.window)) {
+		snapshots = slices.Clone(c.recent)
+	} else {
+		var err error
+		if snapshots, err = ReadFile(c.path); err != nil {
+			return nil, err
+		}
+	}
+	return slices.DeleteFunc(snapshots, func(s Snapshot) bool {
+		return (!from.IsZero() && s.Time.Before(from)) || (!to.IsZero() && s.Time.After(to))
+	}), nil
+}
+
+// Latest returns the most recent snapshot, or nil before the first one
+func (c *Curve) Latest() *Snapshot {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.recent) == 0 {
+		return nil
+	}
+	latest := c.recent[len(c.recent)-1]
+	return &latest
+}
+
+// Stats computes the rolling stats over the window, or nil before there are two snapshots
+func (c *Curve) Stats() *Stats {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.recent) < 2 {
+		return nil
+	}
+	s := Compute(c.recent)
+	s.Window = c.window.String()
+	return &s
+}
+
+// trim drops the snapshots older than the window before now - callers must hold the lock
+func (c *Curve) trim(now time.Time) {
+	cutoff := now.Add(-c.window)
+	i := 0
+	for i < len(c.recent) && c.recent[i].Time.Before(cutoff) {
+		i++
+	}
+	c.recent = slices.Delete(c.recent, 0, i)
+}
+
+// Compute returns the stats of the snapshots, which must be oldest first - the equity changes are annualized by the
+// median spacing between snapshots, so a curve of any bar interval compares with another
+func Compute(snapshots []Snapshot) Stats {
+	s := Stats{Samples: len(snapshots)}
+	if len(snapshots) == 0 {
+		return s
+	}
+	s.Since = snapshots[0].Time
+
+	peak := snapshots[0].Equity
+	for _, snap := range snapshots {
+		peak = max(peak, snap.Equity)
+		dd := peak - snap.Equity
+		if dd > s.MaxDrawdown {
+			s.MaxDrawdown = dd
+			s.MaxDrawdownPct = pct(dd, peak)
+		}
+		s.Drawdown, s.DrawdownPct = dd, pct(dd, peak)
+	}
+	if len(snapshots) < 3 {
+		return s
+	}
+
+	changes := make([]float64, len(snapshots)-1)
+	spacings := make([]float64, len(snapshots)-1)
+	var mean float64
+	for i := 1; i < len(snapshots); i++ {
+		changes[i-1] = snapshots[i].Equity - snapshots[i-1].Equity
+		spacings[i-1] = snapshots[i].Time.Sub(snapshots[i-1].Time).Seconds()
+		mean += changes[i-1]
+	}
+	mean /= float64(len(changes))
+	var ss, downside float64
+	for _, d := range changes {
+		ss += (d - mean) * (d - mean)
+		if d < 0 {
+			downside += d * d
+		}
+	}
+	slices.Sort(spacings)
+	spacing := spacings[len(spacings)/2]
+	if spacing <= 0 {
+		return s
+	}
+	annualize := math.Sqrt((365 * 24 * time.Hour).Seconds() / spacing)
+	if std := math.Sqrt(ss / float64(len(changes)-1)); std > 0 {
+		s.Sharpe = mean / std * annualize
+	}
+	if dev := math.Sqrt(downside / float64(len(changes))); dev > 0 {
+		s.Sortino = mean / dev * annualize
+	}
+	return s
+}
+
+// pct returns the drawdown as a percentage of the peak, or 0 when the peak is not positive
+func pct(dd float64, peak float64) float64 {
+	if peak <= 0 {
+		return 0
+	}
+	return dd / peak * 100
+}
+
+// ReadFile reads the snapshots of a curve file, oldest first - a missing file holds none
+func ReadFile(path string) ([]Snapshot, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var snapshots []Snapshot
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var s Snapshot
+		if err = json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, scanner.Err()
+}
@@ -0,0 +1,159 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Algorithm names accepted in the config
+const (
+	AlgoImmediate    = "immediate"
+	AlgoTwap         = "twap"
+	AlgoQuoteRefresh = "quote_refresh"
+)
+
+// Child is one piece of a parent order as scheduled by an algorithm
+type Child struct {
+	Size  float64
+	Delay time.Duration // wait before sending this child, relative to the previous one
+}
+
+// Quoter returns the output amount currently achievable for a swap, used by algorithms that wait for a better price
+type Quoter interface {
+	QuoteOutAmount(ctx context.Context, inputMint string, outputMint string, amount float64) (int64, error)
+}
+
+// Algo decides how a parent order is worked - how it is split and when each child may be sent
+type Algo interface {
+	Name() string
+	// Schedule splits the parent size into children
+	Schedule(size float64) []Child
+	// Trigger blocks until the child should be sent - an error means the child must not be sent
+	Trigger(ctx context.Context, inputMint string, outputMint string, size float64) error
+}
+
+// NewAlgo builds the algorithm configured for a pair - per-pair overrides take precedence over the default
+func NewAlgo(cfg *configs.Config, pair string, q Quoter) (Algo, error) {
+	// Viper lowercases map keys, so pairs are matched case-insensitively
+	name := cfg.ExecutionAlgo
+	for p, override := range cfg.ExecutionAlgoByPair {
+		if strings.EqualFold(p, pair) {
+			name = override
+		}
+	}
+
+	switch name {
+	case "", AlgoImmediate:
+		return Immediate{}, nil
+	case AlgoTwap:
+		return Twap{
+			Slices:   cfg.OrderSlices,
+			Interval: cfg.OrderSliceInterval,
+		}, nil
+	case AlgoQuoteRefresh:
+		return QuoteRefresh{
+			Quoter:         q,
+			Window:         cfg.QuoteRefreshWindow,
+			Poll:           cfg.QuoteRefreshPoll,
+			ImprovementBps: cfg.QuoteRefreshImprovementBps,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown execution algorithm %q", name)
+	}
+}
+
+// Immediate sends the whole order at once
+type Immediate struct{}
+
+func (Immediate) Name() string {
+	return AlgoImmediate
+}
+
+func (Immediate) Schedule(size float64) []Child {
+	return []Child{{Size: size}}
+}
+
+func (Immediate) Trigger(context.Context, string, string, float64) error {
+	return nil
+}
+
+// Twap splits the order into equal slices sent at a fixed interval, spreading price impact over Slices*Interval
+type Twap struct {
+	Slices   int
+	Interval time.Duration
+}
+
+func (t Twap) Name() string {
+	return AlgoTwap
+}
+
+func (t Twap) Schedule(size float64) []Child {
+	n := max(1, t.Slices)
+	children := make([]Child, n)
+	for i := range children {
+		children[i] = Child{Size: size / float64(n)}
+		if i > 0 {
+			children[i].Delay = t.Interval
+		}
+	}
+	return children
+}
+
+func (t Twap) Trigger(context.Context, string, string, float64) error {
+	return nil
+}
+
+// QuoteRefresh sends the whole order, but first re-quotes for up to Window and fires early as soon as the quoted
+// output improves on the initial quote by ImprovementBps - when the window closes it sends at the prevailing quote
+type QuoteRefresh struct {
+	Quoter         Quoter
+	Window         time.Duration
+	Poll           time.Duration
+	ImprovementBps float64
+}
+
+func (q QuoteRefresh) Name() string {
+	return AlgoQuoteRefresh
+}
+
+func (q QuoteRefresh) Schedule(size float64) []Child {
+	return []Child{{Size: size}}
+}
+
+func (q QuoteRefresh) Trigger(ctx context.Context, inputMint string, outputMint string, size float64) error {
+	initial, err := q.Quoter.QuoteOutAmount(ctx, inputMint, outputMint, size)
+	if err != nil {
+		return err
+	}
+	target := float64(initial) * (1 + q.ImprovementBps/10_000)
+
+	poll := q.Poll
+	if poll <= 0 {
+		poll = time.Second
+	}
+	deadline := time.After(q.Window)
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return nil
+		case <-ticker.C:
+		}
+
+		out, err := q.Quoter.QuoteOutAmount(ctx, inputMint, outputMint, size)
+		if err != nil {
+			continue
+		}
+		if float64(out) >= target {
+			return nil
+		}
+	}
+}
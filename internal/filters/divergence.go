@@ -0,0 +1,68 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Divergence requires RSI divergence to confirm a signal - a BUY needs bullish divergence (price makes a lower low
+// while RSI makes a higher low) and a SELL needs bearish divergence (price makes a higher high while RSI makes a
+// lower high), each measured against the extreme of the lookback window
+type Divergence struct {
+	lookback int
+	bars     []Bar
+}
+
+// NewDivergence creates a divergence filter comparing the latest bar against the previous lookback bars
+func NewDivergence(lookback int) *Divergence {
+	if lookback < 2 {
+		lookback = 2
+	}
+	return &Divergence{
+		lookback: lookback,
+		bars:     make([]Bar, 0, lookback+1),
+	}
+}
+
+func (d *Divergence) Name() string {
+	return "divergence"
+}
+
+func (d *Divergence) Observe(bar Bar) {
+	if len(d.bars) == d.lookback+1 {
+		copy(d.bars, d.bars[1:])
+		d.bars = d.bars[:d.lookback]
+	}
+	d.bars = append(d.bars, bar)
+}
+
+func (d *Divergence) Check(_ context.Context, signal common.Signal) error {
+	if signal != common.BuySignal && signal != common.SellSignal {
+		return nil
+	}
+	if len(d.bars) < d.lookback+1 {
+		return fmt.Errorf("need %d bars of history, have %d", d.lookback+1, len(d.bars))
+	}
+
+	last := d.bars[len(d.bars)-1]
+	window := d.bars[:len(d.bars)-1]
+	extreme := window[0]
+	for _, b := range window[1:] {
+		if (signal == common.BuySignal && b.Price < extreme.Price) ||
+			(signal == common.SellSignal && b.Price > extreme.Price) {
+			extreme = b
+		}
+	}
+
+	if signal == common.BuySignal && !(last.Price < extreme.Price && last.Rsi > extreme.Rsi) {
+		return fmt.Errorf("no bullish divergence - price %f vs prior low %f, RSI %.2f vs %.2f",
+			last.Price, extreme.Price, last.Rsi, extreme.Rsi)
+	}
+	if signal == common.SellSignal && !(last.Price > extreme.Price && last.Rsi < extreme.Rsi) {
+		return fmt.Errorf("no bearish divergence - price %f vs prior high %f, RSI %.2f vs %.2f",
+			last.Price, extreme.Price, last.Rsi, extreme.Rsi)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/birdeye"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/volatility"
+)
+
+// Bar is the data point each filter observes per interval
+type Bar struct {
+	Price   float64
+	Rsi     float64
+	Metrics volatility.Metrics
+}
+
+// Filter gates grid signals on a condition beyond the grid itself
+type Filter interface {
+	Name() string
+	// Observe records the latest bar - it is called on every bar, whatever the signal, so history stays contiguous
+	Observe(bar Bar)
+	// Check returns an error explaining why the signal must not be executed
+	Check(ctx context.Context, signal common.Signal) error
+}
+
+// Chain applies filters in order, rejecting a signal as soon as one of them does
+type Chain []Filter
+
+// NewChain builds the filters enabled in the config for the traded pair
+func NewChain(cfg *configs.Config, pair common.Pair) (Chain, error) {
+	var c Chain
+	if cfg.DivergenceFilter {
+		c = append(c, NewDivergence(cfg.DivergenceLookback))
+	}
+	if cfg.VolumeMinUsd > 0 {
+		if cfg.BirdeyeApiKey == "" {
+			return nil, fmt.Errorf("the volume filter needs a Birdeye API key")
+		}
+		if !slices.Contains(birdeye.Windows, cfg.VolumeWindow) {
+			return nil, fmt.Errorf("volume window must be one of %v", birdeye.Windows)
+		}
+		c = append(c, NewVolume(birdeye.NewClient(cfg.BirdeyeApiKey), pair.Base, cfg.VolumeWindow, cfg.VolumeMinUsd))
+	}
+	if len(cfg.RegimeBlock) > 0 {
+		c = append(c, NewRegime(cfg.RegimeBlock))
+	}
+	return c, nil
+}
+
+// Names returns the names of the filters in order
+func (c Chain) Names() []string {
+	names := make([]string, 0, len(c))
+	for _, f := range c {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+// Observe records the bar in every filter
+func (c Chain) Observe(bar Bar) {
+	for _, f := range c {
+		f.Observe(bar)
+	}
+}
+
+// Check returns the first filter's rejection of the signal, or nil when every filter allows it
+func (c Chain) Check(ctx context.Context, signal common.Signal) error {
+	for _, f := range c {
+		if err := f.Check(ctx, signal); err != nil {
+			return fmt.Errorf("%s filter: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
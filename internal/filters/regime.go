@@ -0,0 +1,39 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Regime suppresses signals while the market is in one of the blocked volatility regimes - grid mean reversion is
+// at its weakest when RSI is barely moving or swinging wildly
+type Regime struct {
+	blocked []string
+	regime  string
+}
+
+// NewRegime creates a regime filter rejecting signals in the blocked regimes
+func NewRegime(blocked []string) *Regime {
+	return &Regime{blocked: blocked}
+}
+
+func (r *Regime) Name() string {
+	return "regime"
+}
+
+func (r *Regime) Observe(bar Bar) {
+	r.regime = bar.Metrics.Regime
+}
+
+func (r *Regime) Check(_ context.Context, signal common.Signal) error {
+	if signal != common.BuySignal && signal != common.SellSignal {
+		return nil
+	}
+	if slices.Contains(r.blocked, r.regime) {
+		return fmt.Errorf("signals are blocked in the %s regime", r.regime)
+	}
+	return nil
+}
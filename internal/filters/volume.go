@@ -0,0 +1,53 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// VolumeSource reports the USD volume recently swapped in a token
+type VolumeSource interface {
+	Volume(ctx context.Context, mint string, window string) (float64, error)
+}
+
+// Volume suppresses signals while the traded token's recent volume is below a floor - RSI crosses in dead markets
+// are mostly noise and the thin liquidity makes fills bad
+type Volume struct {
+	source VolumeSource
+	mint   string
+	window string
+	minUsd float64
+}
+
+// NewVolume creates a volume filter requiring at least minUsd swapped in the mint over the trailing window
+func NewVolume(source VolumeSource, mint string, window string, minUsd float64) *Volume {
+	return &Volume{
+		source: source,
+		mint:   mint,
+		window: window,
+		minUsd: minUsd,
+	}
+}
+
+func (v *Volume) Name() string {
+	return "volume"
+}
+
+func (v *Volume) Observe(Bar) {}
+
+// Check fetches the volume only for actionable signals, and rejects the signal when the volume cannot be confirmed
+func (v *Volume) Check(ctx context.Context, signal common.Signal) error {
+	if signal != common.BuySignal && signal != common.SellSignal {
+		return nil
+	}
+	volume, err := v.source.Volume(ctx, v.mint, v.window)
+	if err != nil {
+		return fmt.Errorf("could not confirm volume: %w", err)
+	}
+	if volume < v.minUsd {
+		return fmt.Errorf("%s volume $%.0f is below the $%.0f minimum", v.window, volume, v.minUsd)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Rates converts the bot's USD figures - amounts in a dollar stablecoin quote, or in another quote such as SOL valued
+// at the quote's USD price - into the reporting currency, at a rate fetched from a Frankfurter-compatible FX API and
+// cached for a while
+type Rates struct {
+	currency string
+	endpoint string
+	ttl      time.Duration
+	hc       *http.Client
+
+	mu        sync.RWMutex
+	rate      float64
+	fetchedAt time.Time
+	checkedAt time.Time // of the last fetch, failed or not, so a failing API is retried once per TTL
+}
+
+// NewRates creates the converter to the configured reporting currency, or returns nil when reporting in USD
+func NewRates(cfg *configs.Config) *Rates {
+	currency := strings.ToUpper(cfg.ReportingCurrency)
+	if currency == "" || currency == "USD" {
+		return nil
+	}
+	return &Rates{
+		currency: currency,
+		endpoint: strings.TrimSuffix(cfg.FxRateUrl, "/"),
+		ttl:      cfg.FxRateTtl,
+		hc:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Currency returns the reporting currency's ISO 4217 code
+func (r *Rates) Currency() string {
+	if r == nil {
+		return "USD"
+	}
+	return r.currency
+}
+
+// Refresh fetches the rate once the last fetch is older than the TTL - a failed fetch keeps the previous rate
+func (r *Rates) Refresh(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	fresh := time.Since(r.checkedAt) < r.ttl
+	if !fresh {
+		r.checkedAt = time.Now()
+	}
+	r.mu.Unlock()
+	if fresh {
+		return nil
+	}
+
+	q := url.Values{"from": {"USD"}, "to": {r.currency}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint+"/latest?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not fetch the USD/%s rate: %w", r.currency, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("could not fetch the USD/%s rate: %s: %s", r.currency, resp.Status, body)
+	}
+	var out struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("could not decode the USD/%s rate: %w", r.currency, err)
+	}
+	rate := out.Rates[r.currency]
+	if rate <= 0 {
+		return fmt.Errorf("no USD/%s rate in the response", r.currency)
+	}
+
+	r.mu.Lock()
+	r.rate, r.fetchedAt = rate, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// Rate returns the cached rate, in units of the reporting currency per USD, and when it was fetched - false until a
+// rate has been fetched
+func (r *Rates) Rate() (float64, time.Time, bool) {
+	if r == nil {
+		return 1, time.Time{}, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.rate, r.fetchedAt, r.rate > 0
+}
+
+// Convert converts a USD amount at the cached rate
+func (r *Rates) Convert(usd float64) (float64, bool) {
+	rate, _, ok := r.Rate()
+	return usd * rate, ok
+}
+
+// Format formats a USD amount for notifications, followed by its value in the reporting currency when converting
+func (r *Rates) Format(usd float64) string {
+	if r == nil {
+		return fmt.Sprintf("%f", usd)
+	}
+	if v, ok := r.Convert(usd); ok {
+		return fmt.Sprintf("%f (%.2f %s)", usd, v, r.currency)
+	}
+	return fmt.Sprintf("%f", usd)
+}
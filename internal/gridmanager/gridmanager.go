@@ -3,8 +3,6 @@ package gridmanager
 import (
 	"math"
 
-	"github.com/rs/zerolog/log"
-
 	"github.com/josephawallace/ninetyfive/internal/common"
 	"github.com/josephawallace/ninetyfive/internal/logger"
 )
@@ -20,6 +18,8 @@ const (
 const (
 	RsiTypeClassic = iota
 	RsiTypeRSX
+	RsiTypeLaguerre
+	RsiTypeConnors
 )
 
 // GridManager holds parameters and per-bar “memory” to replicate Pine Script logic.
@@ -29,12 +29,14 @@ type GridManager struct {
 	NumberOfGrids   int
 	MarketDirection int // 1 = up, 0 = neutral, -1 = down
 	NoTradeZonePips int
-	AggressionLevel int // 0=low,1=med,2=high
-	CurrentRsiType  int // 0=RSI,1=RSX
+	AggressionLevel int     // 0=low,1=med,2=high
+	CurrentRsiType  int     // 0=RSI,1=RSX,2=Laguerre,3=Connors
+	LaguerreGamma   float64 // damping of the Laguerre RSI, DefaultLaguerreGamma when unset
 
 	// ----- Dynamic state for bar-to-bar logic -----
-	lastRsiValue float64 // RSI/RSX value from the previous bar
-	currentRsi   float64 // RSI/RSX value for the current bar
+	indicator    Indicator // built from the inputs on the first bar
+	lastRsiValue float64   // RSI/RSX value from the previous bar
+	currentRsi   float64   // RSI/RSX value for the current bar
 
 	lastSignal      float64 // 1=Buy, -1=Sell, 0=none
 	lastSignalIndex int
@@ -57,6 +59,37 @@ type GridManager struct {
 	buy  bool
 	sell bool
 
+	// The flags and line indexes of the current bar as each filter stage left them, kept for Snapshot
+	prevRsi       float64
+	buyLineIndex  int
+	sellLineIndex int
+	filters       FilterStates
+
+	// Price source fed into RSI/RSX, optionally pre-smoothed with an EMA of smoothing bars
+	source       string
+	smoothing    int
+	smoothed     float64
+	smoothedBars int
+
+	// Drift reset, which is not part of the script: after driftBars consecutive bars with RSI within driftExtreme of
+	// 0 or 100 the signal memory is reset, and with driftHold signals are held until RSI leaves the extreme
+	driftBars    int
+	driftExtreme float64
+	driftHold    bool
+	pinnedBars   int
+	holding      bool
+
+	// Asymmetry, which is not part of the script: the grid count, aggression, and no-trade zone the sell side runs
+	// with, the buy side running with the script's inputs - they match the buy side's unless SetSellSide changed them
+	SellNumberOfGrids   int
+	SellNoTradeZonePips int
+	SellAggressionLevel int
+	sellGridLines       []float64
+
+	// Inventory skew, which is not part of the script: RSI points the buy and sell thresholds are lowered by - positive
+	// when holding more than the target, making buys harder and sells easier, and negative when holding less
+	skew float64
+
 	log logger.Logger
 }
 
@@ -82,8 +115,12 @@ func NewGridManager(rsiLength, numberOfGrids int, direction string, ntZone strin
 	gm.lastSignalIndex = 0
 	gm.signalLine = 50.0 // Pine starts at mid-level
 
-	// 4) Create grid lines
-	gm.initGridLines()
+	// 4) Create grid lines, the same for both sides until SetSellSide says otherwise
+	gm.gridLines = newGridLines(gm.NumberOfGrids)
+	gm.SellNumberOfGrids = gm.NumberOfGrids
+	gm.SellNoTradeZonePips = gm.NoTradeZonePips
+	gm.SellAggressionLevel = gm.AggressionLevel
+	gm.sellGridLines = gm.gridLines
 
 	// 5) Add logger
 	gm.log = logger
@@ -134,36 +171,100 @@ func parseAggression(agg string) int {
 	}
 }
 
-// parseRsiType => “rsi” -> 0, “rsx” -> 1
+// parseRsiType => “rsi” -> 0, “rsx” -> 1, “laguerre” -> 2, “connors” -> 3
 func parseRsiType(t string) int {
-	if t == "rsx" {
+	switch t {
+	case "rsx":
 		return RsiTypeRSX
+	case "laguerre":
+		return RsiTypeLaguerre
+	case "connors":
+		return RsiTypeConnors
+	default:
+		return RsiTypeClassic
 	}
-	return RsiTypeClassic
 }
 
-// initGridLines constructs the array of grid values from 1..99
-func (gm *GridManager) initGridLines() {
-	gm.gridLines = make([]float64, gm.NumberOfGrids)
-	if gm.NumberOfGrids < 2 {
-		gm.gridLines[0] = 50
-		return
+// newGridLines constructs the array of grid values from 1..99
+func newGridLines(numberOfGrids int) []float64 {
+	lines := make([]float64, numberOfGrids)
+	if numberOfGrids < 2 {
+		lines[0] = 50
+		return lines
 	}
 
-	step := 100.0 / float64(gm.NumberOfGrids-1)
-	for i := 0; i < gm.NumberOfGrids; i++ {
-		gm.gridLines[i] = step * float64(i)
+	step := 100.0 / float64(numberOfGrids-1)
+	for i := 0; i < numberOfGrids; i++ {
+		lines[i] = step * float64(i)
 	}
-	gm.gridLines[0] = 1
-	gm.gridLines[gm.NumberOfGrids-1] = 99
+	lines[0] = 1
+	lines[numberOfGrids-1] = 99
+	return lines
 }
 
-// getGridValue safely fetches a grid line
+// SetSellSide gives the sell side its own grid count, no-trade zone, and aggression, in the script's textual form, for
+// accumulation- or distribution-biased operation - the buy side keeps the script's inputs
+func (gm *GridManager) SetSellSide(numberOfGrids int, ntZone string, aggLevel string) {
+	gm.SellNumberOfGrids = numberOfGrids + 1
+	gm.SellNoTradeZonePips = parseNoTradeZone(ntZone)
+	gm.SellAggressionLevel = parseAggression(aggLevel)
+	gm.sellGridLines = newGridLines(gm.SellNumberOfGrids)
+	gm.log.Info().Msg("[GridManager] Sell side set to Grids=%d, NTZ=%s, Agg=%s", numberOfGrids, ntZone, aggLevel)
+}
+
+// Asymmetric reports whether the sell side runs with inputs of its own
+func (gm *GridManager) Asymmetric() bool {
+	return gm.SellNumberOfGrids != gm.NumberOfGrids || gm.SellNoTradeZonePips != gm.NoTradeZonePips ||
+		gm.SellAggressionLevel != gm.AggressionLevel
+}
+
+// getGridValue safely fetches a buy grid line
 func (gm *GridManager) getGridValue(idx int) float64 {
-	if idx < 0 || idx >= len(gm.gridLines) {
+	return gridValue(gm.gridLines, idx)
+}
+
+// getSellGridValue safely fetches a sell grid line
+func (gm *GridManager) getSellGridValue(idx int) float64 {
+	return gridValue(gm.sellGridLines, idx)
+}
+
+// gridValue safely fetches a grid line
+func gridValue(lines []float64, idx int) float64 {
+	if idx < 0 || idx >= len(lines) {
 		return 0
 	}
-	return gm.gridLines[idx]
+	return lines[idx]
+}
+
+// SetSource selects the bar price fed into RSI/RSX (close, hl2, hlc3, or ohlc4) and pre-smooths it with an EMA over
+// smoothing bars - a smoothing of 0 or 1 feeds the source as is
+func (gm *GridManager) SetSource(source string, smoothing int) error {
+	if _, err := (common.Bar{}).Source(source); err != nil {
+		return err
+	}
+	gm.source = source
+	gm.smoothing = smoothing
+	return nil
+}
+
+// ProcessBar is Process for a full OHLC bar, feeding the configured price source
+func (gm *GridManager) ProcessBar(bar common.Bar) (common.Signal, error) {
+	price, err := bar.Source(gm.source)
+	if err != nil {
+		return common.DoNothingSignal, err
+	}
+	if gm.smoothing > 1 {
+		// Seed the EMA with the first value, as Pine's ta.ema does once it has history
+		if gm.smoothedBars == 0 {
+			gm.smoothed = price
+		} else {
+			alpha := 2 / float64(gm.smoothing+1)
+			gm.smoothed = alpha*price + (1-alpha)*gm.smoothed
+		}
+		gm.smoothedBars++
+		price = gm.smoothed
+	}
+	return gm.Process(price)
 }
 
 // Process is called once per bar with that bar’s close price. Returns the recommended signal.
@@ -171,11 +272,13 @@ func (gm *GridManager) Process(price float64) (common.Signal, error) {
 	gm.log.Debug().Msg("[GridManager] Processing new bar. Price=%.4f", price)
 
 	// 1) Compute RSI/RSX
-	if gm.CurrentRsiType == RsiTypeRSX {
-		gm.currentRsi = gm.computeRSX(price)
-	} else {
-		gm.currentRsi = gm.computeRSI(price)
+	if gm.indicator == nil {
+		gm.indicator = gm.newIndicator()
 	}
+	gm.currentRsi = gm.indicator.Next(price)
+	gm.prevRsi = gm.lastRsiValue
+	gm.buyLineIndex, gm.sellLineIndex = 0, 0
+	gm.filters = FilterStates{}
 
 	if gm.lastRsiValue == 0 {
 		// Warm-up bar => store RSI + do-nothing
@@ -187,6 +290,14 @@ func (gm *GridManager) Process(price float64) (common.Signal, error) {
 
 	gm.log.Debug().Msg("[GridManager] RSI/RSX=%.2f (prev=%.2f)", gm.currentRsi, gm.lastRsiValue)
 
+	// Reset the memory when RSI has been pinned in an extreme long enough that mean reversion is not coming
+	if gm.checkDrift() {
+		gm.buy = false
+		gm.sell = false
+		gm.lastRsiValue = gm.currentRsi
+		return common.DoNothingSignal, nil
+	}
+
 	// 2) Reset buy/sell for this bar
 	gm.buy = false
 	gm.sell = false
@@ -196,19 +307,24 @@ func (gm *GridManager) Process(price float64) (common.Signal, error) {
 	sellIdx := gm.getSellLineIndex()
 	gm.buy = (buyIdx > 0)
 	gm.sell = (sellIdx > 0)
-	log.Printf("[GridManager] BuyLineIndex=%d, SellLineIndex=%d => buy=%t, sell=%t", buyIdx, sellIdx, gm.buy, gm.sell)
+	gm.buyLineIndex, gm.sellLineIndex = buyIdx, sellIdx
+	gm.filters.Crossed = Sides{Buy: gm.buy, Sell: gm.sell}
+	gm.log.Debug().Msg("[GridManager] BuyLineIndex=%d, SellLineIndex=%d => buy=%t, sell=%t", buyIdx, sellIdx, gm.buy, gm.sell)
 
 	// 4) Apply aggression filter
 	gm.applyAggressionFilter()
-	log.Printf("[GridManager] After aggression => buy=%t, sell=%t", gm.buy, gm.sell)
+	gm.filters.Aggression = Sides{Buy: gm.buy, Sell: gm.sell}
+	gm.log.Debug().Msg("[GridManager] After aggression => buy=%t, sell=%t", gm.buy, gm.sell)
 
 	// 5) Apply no-trade zone filter
 	gm.applyNoTradeZoneFilter()
-	log.Printf("[GridManager] After no-trade zone => buy=%t, sell=%t", gm.buy, gm.sell)
+	gm.filters.NoTradeZone = Sides{Buy: gm.buy, Sell: gm.sell}
+	gm.log.Debug().Msg("[GridManager] After no-trade zone => buy=%t, sell=%t", gm.buy, gm.sell)
 
 	// 6) Direction filter
 	gm.applyDirectionFilter()
-	log.Printf("[GridManager] After direction filter => buy=%t, sell=%t", gm.buy, gm.sell)
+	gm.filters.Direction = Sides{Buy: gm.buy, Sell: gm.sell}
+	gm.log.Debug().Msg("[GridManager] After direction filter => buy=%t, sell=%t", gm.buy, gm.sell)
 
 	// 7) Determine final signal
 	var outSignal common.Signal
@@ -225,8 +341,12 @@ func (gm *GridManager) Process(price float64) (common.Signal, error) {
 		outSignal = common.DoNothingSignal
 	}
 
+	// The signal line sits on the grid of the side that last signalled
 	gm.signalLine = gm.getGridValue(gm.lastSignalIndex)
-	log.Printf("[GridManager] signalLine=%.2f, lastSignal=%.0f, lastSignalIndex=%d, finalSignal=%s",
+	if gm.lastSignal < 0 {
+		gm.signalLine = gm.getSellGridValue(gm.lastSignalIndex)
+	}
+	gm.log.Debug().Msg("[GridManager] signalLine=%.2f, lastSignal=%.0f, lastSignalIndex=%d, finalSignal=%s",
 		gm.signalLine, gm.lastSignal, gm.lastSignalIndex, outSignal)
 
 	// 8) Update memory for next iteration
@@ -235,6 +355,216 @@ func (gm *GridManager) Process(price float64) (common.Signal, error) {
 	return outSignal, nil
 }
 
+// SetDriftReset enables resetting the signal memory once RSI stays within extreme of 0 or 100 for bars consecutive
+// bars - a strong trend the grid would otherwise keep fading - and, with hold, holding signals until RSI leaves the
+// extreme again - zero bars disables it
+func (gm *GridManager) SetDriftReset(bars int, extreme float64, hold bool) {
+	gm.driftBars = bars
+	gm.driftExtreme = extreme
+	gm.driftHold = hold
+}
+
+// SetSkew lowers the buy and sell thresholds by offset RSI points - a positive offset makes buys harder and sells
+// easier, as when holding more inventory than the target, and a negative one the opposite
+func (gm *GridManager) SetSkew(offset float64) {
+	gm.skew = offset
+}
+
+// checkDrift tracks how long RSI has been pinned, resets the signal memory when it has been pinned for too long, and
+// reports whether signals are held on this bar
+func (gm *GridManager) checkDrift() bool {
+	if gm.driftBars <= 0 {
+		return false
+	}
+	if gm.currentRsi > gm.driftExtreme && gm.currentRsi < 100-gm.driftExtreme {
+		if gm.holding {
+			gm.log.Info().Msg("[GridManager] RSI=%.2f left the extreme - signals resumed", gm.currentRsi)
+		}
+		gm.pinnedBars = 0
+		gm.holding = false
+		return false
+	}
+
+	gm.pinnedBars++
+	if gm.pinnedBars == gm.driftBars {
+		// Forget the last signal and put the signal line back mid-grid, as on the first bar
+		gm.lastSignal = 0
+		gm.lastSignalIndex = (gm.NumberOfGrids - 1) / 2
+		gm.signalLine = gm.getGridValue(gm.lastSignalIndex)
+		gm.holding = gm.driftHold
+		gm.log.Warn().Msg("[GridManager] RSI=%.2f pinned for %d bars - signal memory reset", gm.currentRsi, gm.pinnedBars)
+	}
+	return gm.holding
+}
+
+// Clone returns an independent copy of the Grid Manager, memory included, so a hypothetical bar can be processed
+// without touching the original
+func (gm *GridManager) Clone() *GridManager {
+	c := *gm
+	c.gridLines = append([]float64(nil), gm.gridLines...)
+	c.sellGridLines = append([]float64(nil), gm.sellGridLines...)
+	c.indicator = c.cloneIndicator(gm.indicator)
+	return &c
+}
+
+// Rsi returns the RSI/RSX value computed for the most recent bar
+func (gm *GridManager) Rsi() float64 {
+	return gm.currentRsi
+}
+
+// Parameters are the script inputs the Grid Manager was built with, in their numeric form
+type Parameters struct {
+	RsiLength       int     `json:"rsi_length"`
+	NumberOfGrids   int     `json:"number_of_grids"`
+	MarketDirection int     `json:"market_direction"`
+	NoTradeZonePips int     `json:"no_trade_zone_pips"`
+	AggressionLevel int     `json:"aggression_level"`
+	RsiType         int     `json:"rsi_type"`
+	LaguerreGamma   float64 `json:"laguerre_gamma,omitempty"`
+	Source          string  `json:"source"`
+	Smoothing       int     `json:"smoothing"`
+	// The sell side's inputs, set only when they differ from the buy side's
+	SellNumberOfGrids   int `json:"sell_number_of_grids,omitempty"`
+	SellNoTradeZonePips int `json:"sell_no_trade_zone_pips,omitempty"`
+	SellAggressionLevel int `json:"sell_aggression_level,omitempty"`
+}
+
+// Parameters returns the inputs the Grid Manager runs with
+func (gm *GridManager) Parameters() Parameters {
+	p := Parameters{
+		RsiLength:       gm.RsiLength,
+		NumberOfGrids:   gm.NumberOfGrids,
+		MarketDirection: gm.MarketDirection,
+		NoTradeZonePips: gm.NoTradeZonePips,
+		AggressionLevel: gm.AggressionLevel,
+		RsiType:         gm.CurrentRsiType,
+		LaguerreGamma:   gm.LaguerreGamma,
+		Source:          gm.source,
+		Smoothing:       gm.smoothing,
+	}
+	if gm.Asymmetric() {
+		p.SellNumberOfGrids = gm.SellNumberOfGrids
+		p.SellNoTradeZonePips = gm.SellNoTradeZonePips
+		p.SellAggressionLevel = gm.SellAggressionLevel
+	}
+	return p
+}
+
+// State is the bar-to-bar memory of the Grid Manager after the most recent bar
+type State struct {
+	Rsi             float64 `json:"rsi"`
+	SignalLine      float64 `json:"signal_line"`
+	LastSignal      float64 `json:"last_signal"` // 1=Buy, -1=Sell, 0=none
+	LastSignalIndex int     `json:"last_signal_index"`
+	Buy             bool    `json:"buy"`
+	Sell            bool    `json:"sell"`
+	PinnedBars      int     `json:"pinned_bars"`
+	Holding         bool    `json:"holding"` // signals held after a drift reset until RSI leaves the extreme
+}
+
+// State returns a copy of the Grid Manager's memory, for diagnosing why a bar did or did not signal
+func (gm *GridManager) State() State {
+	return State{
+		Rsi:             gm.currentRsi,
+		SignalLine:      gm.signalLine,
+		LastSignal:      gm.lastSignal,
+		LastSignalIndex: gm.lastSignalIndex,
+		Buy:             gm.buy,
+		Sell:            gm.sell,
+		PinnedBars:      gm.pinnedBars,
+		Holding:         gm.holding,
+	}
+}
+
+// Sides are the buy and sell flags at one stage of a bar's evaluation
+type Sides struct {
+	Buy  bool `json:"buy"`
+	Sell bool `json:"sell"`
+}
+
+// FilterStates are the flags of the most recent bar after the grid line crossings and after each filter in turn - a
+// flag set at one stage and cleared at the next names the filter that blocked the signal
+type FilterStates struct {
+	Crossed     Sides `json:"crossed"`
+	Aggression  Sides `json:"aggression"`
+	NoTradeZone Sides `json:"no_trade_zone"`
+	Direction   Sides `json:"direction"`
+}
+
+// Blocked returns the side of the grid line crossing a filter cleared and the filter that cleared it, or DO_NOTHING
+// when no crossing was blocked
+func (f FilterStates) Blocked() (common.Signal, string) {
+	stages := []struct {
+		name  string
+		sides Sides
+	}{{"aggression", f.Aggression}, {"no_trade_zone", f.NoTradeZone}, {"direction", f.Direction}}
+	prev := f.Crossed
+	for _, s := range stages {
+		switch {
+		case prev.Buy && !s.sides.Buy:
+			return common.BuySignal, s.name
+		case prev.Sell && !s.sides.Sell:
+			return common.SellSignal, s.name
+		}
+		prev = s.sides
+	}
+	return common.DoNothingSignal, ""
+}
+
+// Snapshot is everything the Grid Manager derived from the most recent bar, for dashboards and the admin API
+type Snapshot struct {
+	Rsi             float64       `json:"rsi"`
+	PrevRsi         float64       `json:"prev_rsi"`
+	SignalLine      float64       `json:"signal_line"`
+	GridLevels      []float64     `json:"grid_levels"`
+	LastSignal      common.Signal `json:"last_signal"`
+	LastSignalIndex int           `json:"last_signal_index"`
+	BuyLineIndex    int           `json:"buy_line_index"`
+	SellLineIndex   int           `json:"sell_line_index"`
+	NoTradeZone     [2]float64    `json:"no_trade_zone"` // RSI bounds where signals are blocked
+	// The sell side's grid levels and no-trade zone, set only when they differ from the buy side's - the levels and
+	// zone above are then the buy side's
+	SellGridLevels  []float64    `json:"sell_grid_levels,omitempty"`
+	SellNoTradeZone *[2]float64  `json:"sell_no_trade_zone,omitempty"`
+	Filters         FilterStates `json:"filters"`
+	PinnedBars      int          `json:"pinned_bars"`
+	Holding         bool         `json:"holding"`
+	Skew            float64      `json:"skew"` // RSI points the thresholds are lowered by for inventory
+}
+
+// Snapshot returns a read-only copy of the Grid Manager's indicator, grid, and filter states
+func (gm *GridManager) Snapshot() Snapshot {
+	lastSignal := common.DoNothingSignal
+	switch {
+	case gm.lastSignal > 0:
+		lastSignal = common.BuySignal
+	case gm.lastSignal < 0:
+		lastSignal = common.SellSignal
+	}
+	ntz := float64(gm.NoTradeZonePips)
+	s := Snapshot{
+		Rsi:             gm.currentRsi,
+		PrevRsi:         gm.prevRsi,
+		SignalLine:      gm.signalLine,
+		GridLevels:      append([]float64(nil), gm.gridLines...),
+		LastSignal:      lastSignal,
+		LastSignalIndex: gm.lastSignalIndex,
+		BuyLineIndex:    gm.buyLineIndex,
+		SellLineIndex:   gm.sellLineIndex,
+		NoTradeZone:     [2]float64{50 - ntz, 50 + ntz},
+		Filters:         gm.filters,
+		PinnedBars:      gm.pinnedBars,
+		Holding:         gm.holding,
+		Skew:            gm.skew,
+	}
+	if gm.Asymmetric() {
+		sellNtz := float64(gm.SellNoTradeZonePips)
+		s.SellGridLevels = append([]float64(nil), gm.sellGridLines...)
+		s.SellNoTradeZone = &[2]float64{50 - sellNtz, 50 + sellNtz}
+	}
+	return s
+}
+
 // -------------------------------------------------------------------------------------
 //
 //	getBuyLineIndex / getSellLineIndex
@@ -259,8 +589,8 @@ func (gm *GridManager) getBuyLineIndex() int {
 
 func (gm *GridManager) getSellLineIndex() int {
 	idx := 0
-	for x := 0; x < gm.NumberOfGrids; x++ {
-		lineVal := gm.getGridValue(x)
+	for x := 0; x < gm.SellNumberOfGrids; x++ {
+		lineVal := gm.getSellGridValue(x)
 		// if RSI[1]>lineVal && RSI<=lineVal && RSI[1]>=SignalLine[1] => x
 		if gm.lastRsiValue > lineVal && gm.currentRsi <= lineVal && gm.lastRsiValue >= gm.signalLine {
 			idx = x
@@ -282,37 +612,44 @@ func (gm *GridManager) applyAggressionFilter() {
 	// Pine logic:
 	// if AGGR>0 => skip same-level trades
 	// else => simpler check
-	gi := 100.0 / float64(gm.NumberOfGrids-1)
-
+	// Each side is filtered with its own aggression and grid, which are the same unless the sell side has its own
 	if gm.AggressionLevel > 0 {
-		topIdx := (gm.NumberOfGrids - 1) - gm.AggressionLevel
 		botIdx := 1 + gm.AggressionLevel
-		topVal := gm.getGridValue(topIdx)
-		botVal := gm.getGridValue(botIdx)
-
+		botVal := gm.getGridValue(botIdx) - gm.skew
 		if gm.currentRsi > gm.signalLine && gm.lastRsiValue >= botVal {
 			gm.buy = false
 		}
-		if gm.currentRsi < gm.signalLine && gm.lastRsiValue <= topVal {
-			gm.sell = false
-		}
 	} else {
 		// Aggression=0 => simpler
-		if gm.lastRsiValue > gm.signalLine-gi {
+		gi := 100.0 / float64(gm.NumberOfGrids-1)
+		if gm.lastRsiValue > gm.signalLine-gi-gm.skew {
 			gm.buy = false
 		}
-		if gm.lastRsiValue < gm.signalLine+gi {
+	}
+
+	if gm.SellAggressionLevel > 0 {
+		topIdx := (gm.SellNumberOfGrids - 1) - gm.SellAggressionLevel
+		topVal := gm.getSellGridValue(topIdx) - gm.skew
+		if gm.currentRsi < gm.signalLine && gm.lastRsiValue <= topVal {
+			gm.sell = false
+		}
+	} else {
+		gi := 100.0 / float64(gm.SellNumberOfGrids-1)
+		if gm.lastRsiValue < gm.signalLine+gi-gm.skew {
 			gm.sell = false
 		}
 	}
 }
 
 func (gm *GridManager) applyNoTradeZoneFilter() {
-	// if RSI[1] > 50-NTZ && RSI[1] < 50+NTZ => buy=false, sell=false
-	lowerBound := 50.0 - float64(gm.NoTradeZonePips)
-	upperBound := 50.0 + float64(gm.NoTradeZonePips)
-	if gm.lastRsiValue > lowerBound && gm.lastRsiValue < upperBound {
+	// if RSI[1] > 50-NTZ && RSI[1] < 50+NTZ => buy=false, sell=false - each side with its own zone
+	inZone := func(pips int) bool {
+		return gm.lastRsiValue > 50.0-float64(pips) && gm.lastRsiValue < 50.0+float64(pips)
+	}
+	if inZone(gm.NoTradeZonePips) {
 		gm.buy = false
+	}
+	if inZone(gm.SellNoTradeZonePips) {
 		gm.sell = false
 	}
 }
@@ -321,10 +658,11 @@ func (gm *GridManager) applyDirectionFilter() {
 	// if RSI<100 or RSI>1 => skip signals if they go against the direction
 	if gm.currentRsi < 100 || gm.currentRsi > 1 {
 		gi := 100.0 / float64(gm.NumberOfGrids-1)
+		sellGi := 100.0 / float64(gm.SellNumberOfGrids-1)
 		if gm.MarketDirection == DirDown && gm.currentRsi >= gm.signalLine-(2*gi) {
 			gm.buy = false
 		}
-		if gm.MarketDirection == DirUp && gm.currentRsi <= gm.signalLine+(2*gi) {
+		if gm.MarketDirection == DirUp && gm.currentRsi <= gm.signalLine+(2*sellGi) {
 			gm.sell = false
 		}
 	}
@@ -0,0 +1,231 @@
+package gridmanager
+
+import "math"
+
+// Indicator turns a price series into an oscillator between 0 and 100, fed one bar at a time
+type Indicator interface {
+	Next(price float64) float64
+}
+
+// indicatorFunc adapts the Grid Manager's built-in RSI/RSX computations, whose memory lives on the Grid Manager itself
+type indicatorFunc func(price float64) float64
+
+func (f indicatorFunc) Next(price float64) float64 {
+	return f(price)
+}
+
+// newIndicator builds the indicator selected by CurrentRsiType
+func (gm *GridManager) newIndicator() Indicator {
+	switch gm.CurrentRsiType {
+	case RsiTypeRSX:
+		return indicatorFunc(gm.computeRSX)
+	case RsiTypeLaguerre:
+		return NewLaguerre(gm.LaguerreGamma)
+	case RsiTypeConnors:
+		return NewConnors(gm.RsiLength, connorsStreakLength, connorsRankLength)
+	default:
+		return indicatorFunc(gm.computeRSI)
+	}
+}
+
+// cloneIndicator deep-copies an indicator for a cloned Grid Manager - the built-in computations are rebound to the
+// clone, whose copied fields hold their memory
+func (gm *GridManager) cloneIndicator(ind Indicator) Indicator {
+	switch ind := ind.(type) {
+	case nil:
+		return nil
+	case *Laguerre:
+		c := *ind
+		return &c
+	case *Connors:
+		c := *ind
+		price, streak := *ind.price, *ind.streak
+		c.price, c.streak = &price, &streak
+		c.changes = append([]float64(nil), ind.changes...)
+		return &c
+	default:
+		// The built-in RSI/RSX, whose memory lives on the Grid Manager
+		return gm.newIndicator()
+	}
+}
+
+// DefaultLaguerreGamma is the damping factor Ehlers suggests for the Laguerre RSI
+const DefaultLaguerreGamma = 0.5
+
+// Laguerre is Ehlers' Laguerre RSI - an RSI over a four-element Laguerre filter, where gamma trades smoothness (near
+// 1) for responsiveness (near 0)
+type Laguerre struct {
+	gamma          float64
+	l0, l1, l2, l3 float64
+	last           float64
+	seeded         bool
+}
+
+// NewLaguerre creates a Laguerre RSI with the damping factor, DefaultLaguerreGamma when it is not in (0, 1)
+func NewLaguerre(gamma float64) *Laguerre {
+	if gamma <= 0 || gamma >= 1 {
+		gamma = DefaultLaguerreGamma
+	}
+	return &Laguerre{gamma: gamma, last: 50}
+}
+
+// Next feeds a price and returns the updated value - the filter is seeded with the first price, which reads 50
+func (l *Laguerre) Next(price float64) float64 {
+	if !l.seeded {
+		l.l0, l.l1, l.l2, l.l3 = price, price, price, price
+		l.seeded = true
+		return l.last
+	}
+
+	g := l.gamma
+	l0 := (1-g)*price + g*l.l0
+	l1 := -g*l0 + l.l0 + g*l.l1
+	l2 := -g*l1 + l.l1 + g*l.l2
+	l3 := -g*l2 + l.l2 + g*l.l3
+	l.l0, l.l1, l.l2, l.l3 = l0, l1, l2, l3
+
+	var up, down float64
+	for _, d := range []float64{l0 - l1, l1 - l2, l2 - l3} {
+		if d >= 0 {
+			up += d
+		} else {
+			down -= d
+		}
+	}
+	// A flat filter carries the previous reading, as the division is undefined
+	if up+down > 0 {
+		l.last = clamp(100*up/(up+down), 0, 100)
+	}
+	return l.last
+}
+
+// Connors RSI defaults from Connors' publication - the price RSI length comes from the strategy's rsi_length
+const (
+	connorsStreakLength = 2
+	connorsRankLength   = 100
+)
+
+// Connors is Connors RSI - the average of a short RSI of the price, an RSI of the up/down streak length, and the
+// percent rank of the latest one-bar change
+type Connors struct {
+	price  *wilderRsi
+	streak *wilderRsi
+
+	prevPrice  float64
+	run        float64
+	changes    []float64
+	rankLength int
+	seeded     bool
+}
+
+// NewConnors creates a Connors RSI with the given price RSI, streak RSI, and percent rank lengths
+func NewConnors(rsiLength int, streakLength int, rankLength int) *Connors {
+	return &Connors{
+		price:      newWilderRsi(rsiLength),
+		streak:     newWilderRsi(streakLength),
+		rankLength: rankLength,
+	}
+}
+
+// Next feeds a price and returns the updated value, reading 50 until every component has history
+func (c *Connors) Next(price float64) float64 {
+	if !c.seeded {
+		c.prevPrice = price
+		c.seeded = true
+		c.price.next(price)
+		c.streak.next(0)
+		return 50
+	}
+
+	// Count consecutive up (positive) or down (negative) closes, resetting on an unchanged one
+	switch {
+	case price > c.prevPrice:
+		c.run = math.Max(c.run, 0) + 1
+	case price < c.prevPrice:
+		c.run = math.Min(c.run, 0) - 1
+	default:
+		c.run = 0
+	}
+
+	// Percent of the previous changes at or below this one
+	var change float64
+	if c.prevPrice != 0 {
+		change = (price - c.prevPrice) / c.prevPrice
+	}
+	c.prevPrice = price
+	rank := 50.0
+	if len(c.changes) > 0 {
+		var below int
+		for _, prev := range c.changes {
+			if prev <= change {
+				below++
+			}
+		}
+		rank = 100 * float64(below) / float64(len(c.changes))
+	}
+	c.changes = append(c.changes, change)
+	if len(c.changes) > c.rankLength {
+		c.changes = c.changes[1:]
+	}
+
+	return clamp((c.price.next(price)+c.streak.next(c.run)+rank)/3, 0, 100)
+}
+
+// wilderRsi is the RSI TradingView's ta.rsi computes - seeded with the simple average of the first length changes,
+// then smoothed with Wilder's moving average - reading 50 until seeded
+type wilderRsi struct {
+	length           int
+	prev             float64
+	avgGain, avgLoss float64
+	n                int
+}
+
+// NewRsi creates the RSI TradingView's ta.rsi computes over length bars, as a standalone indicator
+func NewRsi(length int) Indicator {
+	return newWilderRsi(length)
+}
+
+// newWilderRsi creates an RSI over length bars
+func newWilderRsi(length int) *wilderRsi {
+	if length < 1 {
+		length = 1
+	}
+	return &wilderRsi{length: length}
+}
+
+// Next feeds a value and returns the updated RSI
+func (w *wilderRsi) Next(v float64) float64 {
+	return w.next(v)
+}
+
+// next feeds a value and returns the updated RSI
+func (w *wilderRsi) next(v float64) float64 {
+	w.n++
+	if w.n == 1 {
+		w.prev = v
+		return 50
+	}
+	delta := v - w.prev
+	w.prev = v
+	gain, loss := math.Max(delta, 0), math.Max(-delta, 0)
+
+	changes := w.n - 1
+	if changes <= w.length {
+		w.avgGain += gain / float64(w.length)
+		w.avgLoss += loss / float64(w.length)
+		if changes < w.length {
+			return 50
+		}
+	} else {
+		w.avgGain = (w.avgGain*float64(w.length-1) + gain) / float64(w.length)
+		w.avgLoss = (w.avgLoss*float64(w.length-1) + loss) / float64(w.length)
+	}
+
+	if w.avgLoss == 0 {
+		if w.avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+w.avgGain/w.avgLoss)
+}
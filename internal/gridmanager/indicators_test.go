@@ -0,0 +1,65 @@
+package gridmanager
+
+import (
+	"math"
+	"testing"
+)
+
+// goldenPrices is the series the golden values below were computed over, by an independent implementation of each
+// indicator's published formula
+var goldenPrices = []float64{100, 102, 101, 101, 104, 107, 105, 103, 103, 106, 110, 108}
+
+func TestIndicatorGoldenValues(t *testing.T) {
+	tests := []struct {
+		name string
+		ind  Indicator
+		want []float64
+	}{
+		{
+			name: "laguerre gamma 0.5",
+			ind:  NewLaguerre(0.5),
+			want: []float64{50, 71.42857142857143, 61.53846153846154, 87.5, 90.2439024390244, 99.02439024390245,
+				78.11023622047244, 96.57701711491443, 87.70642201834862, 93.92466585662211, 87.07539353769677,
+				81.12667091024825},
+		},
+		{
+			name: "laguerre gamma 0.8",
+			ind:  NewLaguerre(0.8),
+			want: []float64{50, 67.21311475409846, 70.2517162471399, 70.88607594936691, 73.2926363983212,
+				72.71967990055164, 76.49636432500942, 69.34733965505865, 68.02159329268665, 82.2247360359891, 100,
+				95.70670697155833},
+		},
+		{
+			name: "laguerre out of range gamma takes the default",
+			ind:  NewLaguerre(1.5),
+			want: []float64{50, 71.42857142857143, 61.53846153846154, 87.5, 90.2439024390244, 99.02439024390245,
+				78.11023622047244, 96.57701711491443, 87.70642201834862, 93.92466585662211, 87.07539353769677,
+				81.12667091024825},
+		},
+		{
+			name: "connors 3/2/5",
+			ind:  NewConnors(3, 2, 5),
+			want: []float64{50, 50, 27.777777777777775, 58.888888888888886, 88.14814814814815, 85.40591675266597,
+				28.72405372405373, 19.344732883423728, 55.37527889335118, 76.46518938502938, 91.05329331626216,
+				42.3930501126597},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, price := range goldenPrices {
+				if got := tt.ind.Next(price); math.Abs(got-tt.want[i]) > 1e-9 {
+					t.Errorf("bar %d: got %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLaguerreFlatSeries(t *testing.T) {
+	l := NewLaguerre(DefaultLaguerreGamma)
+	for i := 0; i < 10; i++ {
+		if got := l.Next(100); got != 50 {
+			t.Fatalf("bar %d: got %v on a flat series, want 50", i, got)
+		}
+	}
+}
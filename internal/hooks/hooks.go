@@ -0,0 +1,203 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// Lifecycle events of an order's swaps
+const (
+	OrderSubmitted = "order.submitted" // a slice's swap was sent
+	OrderProposed  = "order.proposed"  // a slice's swap was proposed to the multisig, awaiting its members' approval
+	OrderConfirmed = "order.confirmed" // the swap reached confirmed commitment
+	OrderFinalized = "order.finalized" // the swap is final and its fill recorded
+	OrderFailed    = "order.failed"    // the swap could not be sent or did not land
+)
+
+// Events lists every lifecycle event
+var Events = []string{OrderSubmitted, OrderProposed, OrderConfirmed, OrderFinalized, OrderFailed}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when the hooks have a secret, as the signal webhook
+// does
+const SignatureHeader = "X-Ninetyfive-Signature"
+
+// queueSize bounds the events waiting for delivery to each webhook - events beyond it are dropped and logged
+const queueSize = 256
+
+// Event is a step in the life of an order's swap as posted to the webhooks - Id is unique per event and stays the
+// same across retries, so receivers can deduplicate
+type Event struct {
+	Id        string        `json:"id"`
+	Type      string        `json:"type"`
+	OrderId   string        `json:"order_id"`
+	Slice     int           `json:"slice"`
+	TxId      string        `json:"tx_id,omitempty"`
+	Proposal  string        `json:"proposal,omitempty"` // the multisig proposal of the swap, when swaps are proposed
+	Pair      string        `json:"pair"`
+	Side      common.Signal `json:"side"`
+	Size      float64       `json:"size"`
+	OutAmount float64       `json:"out_amount,omitempty"` // what the swap received, once finalized and its fill read
+	Error     string        `json:"error,omitempty"`
+	Time      time.Time     `json:"time"`
+}
+
+// hook is a webhook with its own queue, so a slow receiver holds up no other and each sees events in order
+type hook struct {
+	url   string
+	queue chan []byte
+}
+
+// Dispatcher posts lifecycle events to the configured webhooks in the background, signing and retrying them - a nil
+// dispatcher posts nothing
+type Dispatcher struct {
+	hooks   []hook
+	events  []string
+	secret  []byte
+	retries int
+	backoff time.Duration
+	hc      *http.Client
+	log     logger.Logger
+
+	mu      sync.Mutex
+	seq     int
+	pending int
+}
+
+// NewDispatcher starts the delivery to the configured webhooks, or returns nil when there are none
+func NewDispatcher(cfg *configs.Config, log logger.Logger) *Dispatcher {
+	if len(cfg.OrderWebhookUrls) == 0 {
+		return nil
+	}
+	events := cfg.OrderWebhookEvents
+	if len(events) == 0 {
+		events = Events
+	}
+	d := &Dispatcher{
+		events:  events,
+		secret:  []byte(cfg.OrderWebhookSecret),
+		retries: cfg.OrderWebhookRetries,
+		backoff: cfg.OrderWebhookRetryDelay,
+		hc:      &http.Client{Timeout: 10 * time.Second},
+		log:     log,
+	}
+	for _, url := range cfg.OrderWebhookUrls {
+		h := hook{url: url, queue: make(chan []byte, queueSize)}
+		d.hooks = append(d.hooks, h)
+		go d.deliver(h)
+	}
+	return d
+}
+
+// Send queues the event for every webhook subscribed to its type, stamping its id and time
+func (d *Dispatcher) Send(e Event) {
+	if d == nil || !slices.Contains(d.events, e.Type) {
+		return
+	}
+	d.mu.Lock()
+	d.seq++
+	e.Id = fmt.Sprintf("%s-%d-%d", e.Type, time.Now().UnixNano(), d.seq)
+	d.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		d.log.Error().Err(err).Msg("failed to encode %s event of order %s", e.Type, e.OrderId)
+		return
+	}
+	for _, h := range d.hooks {
+		d.mu.Lock()
+		d.pending++
+		d.mu.Unlock()
+		select {
+		case h.queue <- body:
+		default:
+			d.done()
+			d.log.Warn().Msg("dropping %s event of order %s - the webhook queue is full", e.Type, e.OrderId)
+		}
+	}
+}
+
+// Flush waits until every queued event was delivered or given up on, or the context is done
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	for d != nil {
+		d.mu.Lock()
+		pending := d.pending
+		d.mu.Unlock()
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d webhook event(s) undelivered: %w", pending, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// deliver posts the webhook's events one at a time, retrying each with exponential backoff
+func (d *Dispatcher) deliver(h hook) {
+	for body := range h.queue {
+		delay := d.backoff
+		for attempt := 0; ; attempt++ {
+			err := d.post(h.url, body)
+			if err == nil {
+				break
+			}
+			if attempt >= d.retries {
+				d.log.Error().Err(err).Msg("giving up on a lifecycle webhook event after %d attempt(s)", attempt+1)
+				break
+			}
+			time.Sleep(delay)
+			delay *= 2
+		}
+		d.done()
+	}
+}
+
+// post sends the body once, treating any non-2xx response as a failure
+func (d *Dispatcher) post(url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(d.secret) > 0 {
+		mac := hmac.New(sha256.New, d.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	res, err := d.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("lifecycle webhook returned %d: %s", res.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// done counts an event as delivered or given up on
+func (d *Dispatcher) done() {
+	d.mu.Lock()
+	d.pending--
+	d.mu.Unlock()
+}
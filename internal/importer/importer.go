@@ -0,0 +1,165 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// FromHistory turns the wallet's transactions that swapped one asset of the pair for the other into trades - the
+// SOL fee the wallet paid is not part of the swap, and with jupiterOnly transactions that did not go through the
+// Jupiter aggregator are skipped
+func FromHistory(pair common.Pair, history []jupiter.Activity, jupiterOnly bool) []ledger.Trade {
+	var trades []ledger.Trade
+	for _, a := range history {
+		if jupiterOnly && !a.Jupiter {
+			continue
+		}
+		base, quote := a.Changes[pair.Base], a.Changes[pair.Quote]
+		if pair.Base == solana.SolMint.String() {
+			base += a.Fee
+		}
+		if pair.Quote == solana.SolMint.String() {
+			quote += a.Fee
+		}
+		// A swap of the pair moves its two assets in opposite directions
+		if base*quote >= 0 {
+			continue
+		}
+		side := common.BuySignal
+		if base < 0 {
+			side = common.SellSignal
+		}
+		t, err := trade(pair, side, math.Abs(base), math.Abs(quote), a.Time)
+		if err != nil {
+			continue
+		}
+		t.TxId = a.TxId
+		t.FeeLamports = uint64(math.Round(a.Fee * math.Pow10(9)))
+		trades = append(trades, t)
+	}
+	return trades
+}
+
+// LoadCsv reads trades from a CSV with a header naming "time", "side", "base", and "quote" columns - times as unix
+// seconds or RFC3339, side BUY or SELL, and base and quote the amounts of each asset exchanged - and an optional
+// "tx_id" column, the rows without one identified by their line so importing the file again adds nothing
+func LoadCsv(path string, pair common.Pair) ([]ledger.Trade, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not read the header: %w", path, err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	cols := make(map[string]int)
+	for _, name := range []string{"time", "side", "base", "quote", "tx_id"} {
+		if i := slices.Index(header, name); i >= 0 {
+			cols[name] = i
+		} else if name != "tx_id" {
+			return nil, fmt.Errorf("%s: missing the %q column", path, name)
+		}
+	}
+
+	var trades []ledger.Trade
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		t, err := csvTrade(row, cols, pair)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		if t.TxId == "" {
+			t.TxId = fmt.Sprintf("csv-%s-%d", t.Time.UTC().Format(time.RFC3339), line)
+		}
+		trades = append(trades, t)
+	}
+	return trades, nil
+}
+
+// csvTrade parses one CSV row into a trade
+func csvTrade(row []string, cols map[string]int, pair common.Pair) (ledger.Trade, error) {
+	at, err := parseTime(row[cols["time"]])
+	if err != nil {
+		return ledger.Trade{}, err
+	}
+	side := common.Signal(strings.ToUpper(strings.TrimSpace(row[cols["side"]])))
+	base, err := strconv.ParseFloat(strings.TrimSpace(row[cols["base"]]), 64)
+	if err != nil {
+		return ledger.Trade{}, fmt.Errorf("invalid base amount: %w", err)
+	}
+	quote, err := strconv.ParseFloat(strings.TrimSpace(row[cols["quote"]]), 64)
+	if err != nil {
+		return ledger.Trade{}, fmt.Errorf("invalid quote amount: %w", err)
+	}
+	t, err := trade(pair, side, base, quote, at)
+	if err != nil {
+		return ledger.Trade{}, err
+	}
+	if i, ok := cols["tx_id"]; ok {
+		t.TxId = strings.TrimSpace(row[i])
+	}
+	return t, nil
+}
+
+// trade records base exchanged for quote as the ledger records a fill - a BUY spends the quote, a SELL the base, and
+// the price is the quote paid per base
+func trade(pair common.Pair, side common.Signal, base float64, quote float64, at time.Time) (ledger.Trade, error) {
+	if base <= 0 || quote <= 0 {
+		return ledger.Trade{}, fmt.Errorf("base and quote amounts must be positive")
+	}
+	inputMint, outputMint, err := pair.Mints(side)
+	if err != nil {
+		return ledger.Trade{}, err
+	}
+	t := ledger.Trade{
+		Side:       side,
+		InputMint:  inputMint,
+		OutputMint: outputMint,
+		Price:      quote / base,
+		Time:       at,
+	}
+	if side == common.BuySignal {
+		t.InputAmount, t.OutputAmount = quote, base
+	} else {
+		t.InputAmount, t.OutputAmount = base, quote
+	}
+	return t, nil
+}
+
+// parseTime reads a time as unix seconds or RFC3339
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,143 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// The most signatures read per page of wallet history, and the most pages read per check - activity beyond them is
+// skipped, which only a wallet that went unwatched for long accumulates
+const (
+	activityPageSize = 100
+	activityMaxPages = 10
+)
+
+// Activity is a finalized transaction that touched the wallet and what it did to the wallet's holdings of the watched
+// mints
+type Activity struct {
+	TxId    string
+	Time    time.Time
+	Memo    string             // the transaction's memo, if it carried one
+	Changes map[string]float64 // by mint, positive when the wallet received
+	Fee     float64            // the SOL fee the wallet paid as the fee payer, counted in its SOL change
+	Jupiter bool               // whether the transaction invoked the Jupiter aggregator
+}
+
+// WalletActivity returns the wallet's successful transactions since the one with id until, oldest first, with the
+// id of the newest to continue from - without until nothing is returned, only the newest id, so watching starts from
+// the present rather than replaying the wallet's whole history
+func (j *Jupiter) WalletActivity(ctx context.Context, until string, mints []string) ([]Activity, string, error) {
+	var untilSig solana.Signature
+	if until != "" {
+		var err error
+		if untilSig, err = solana.SignatureFromBase58(until); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var sigs []*rpc.TransactionSignature
+	limit := activityPageSize
+	opts := &rpc.GetSignaturesForAddressOpts{Limit: &limit, Until: untilSig, Commitment: rpc.CommitmentFinalized}
+	for page := 0; page < activityMaxPages; page++ {
+		start := time.Now()
+		res, err := j.rc.GetSignaturesForAddressWithOpts(ctx, j.PublicKey(), opts)
+		j.lat.Observe(latency.Rpc, start, err)
+		if err != nil {
+			return nil, "", fmt.Errorf("could not get the wallet's signatures: %w", err)
+		}
+		sigs = append(sigs, res...)
+		if until == "" || len(res) < limit {
+			break
+		}
+		opts.Before = res[len(res)-1].Signature
+	}
+	if len(sigs) == 0 {
+		return nil, until, nil
+	}
+	newest := sigs[0].Signature.String()
+	if until == "" {
+		return nil, newest, nil
+	}
+
+	activity := make([]Activity, 0, len(sigs))
+	for _, sig := range slices.Backward(sigs) {
+		// A failed transaction only cost its fee payer the fee, and the wallet paid it only when it signed
+		if sig.Err != nil {
+			continue
+		}
+		a, err := j.activity(ctx, sig.Signature, j.PublicKey(), mints)
+		if err != nil {
+			return nil, "", err
+		}
+		if sig.Memo != nil {
+			a.Memo = *sig.Memo
+		}
+		activity = append(activity, a)
+	}
+	return activity, newest, nil
+}
+
+// activity reads what the transaction did to the wallet's holdings of the mints
+func (j *Jupiter) activity(ctx context.Context, sig solana.Signature, wallet solana.PublicKey, mints []string) (
+	Activity, error) {
+	version := uint64(0)
+	start := time.Now()
+	res, err := j.rc.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Commitment:                     rpc.CommitmentFinalized,
+		MaxSupportedTransactionVersion: &version,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return Activity{}, fmt.Errorf("could not get transaction %s: %w", sig, err)
+	}
+	if res.Meta == nil || res.Transaction == nil {
+		return Activity{}, fmt.Errorf("transaction %s has no status metadata", sig)
+	}
+	tx, err := res.Transaction.GetTransaction()
+	if err != nil {
+		return Activity{}, fmt.Errorf("could not decode transaction %s: %w", sig, err)
+	}
+
+	a := Activity{TxId: sig.String(), Changes: make(map[string]float64)}
+	if res.BlockTime != nil {
+		a.Time = res.BlockTime.Time()
+	}
+	// The balances are listed in the order of the static keys followed by the writable and read-only loaded ones
+	keys := slices.Concat(tx.Message.AccountKeys, res.Meta.LoadedAddresses.Writable, res.Meta.LoadedAddresses.ReadOnly)
+	if len(keys) > 0 && keys[0].Equals(wallet) {
+		a.Fee = float64(res.Meta.Fee) / math.Pow10(solDecimals)
+	}
+	for _, ix := range tx.Message.Instructions {
+		if int(ix.ProgramIDIndex) < len(keys) && keys[ix.ProgramIDIndex].Equals(jupiterProgram) {
+			a.Jupiter = true
+		}
+	}
+	for _, mint := range mints {
+		change, decimals := tokenChange(res.Meta, wallet, mint)
+		if mint == solana.SolMint.String() {
+			decimals = solDecimals
+			if i := slices.IndexFunc(keys, wallet.Equals); i >= 0 && i < len(res.Meta.PreBalances) &&
+				i < len(res.Meta.PostBalances) {
+				change += float64(res.Meta.PostBalances[i]) - float64(res.Meta.PreBalances[i])
+			}
+		}
+		if change != 0 {
+			a.Changes[mint] = change / math.Pow10(decimals)
+		}
+	}
+	return a, nil
+}
+
+// IsSwapMemo reports whether a memo is the one this bot tags its swaps with, whichever deployment sent them
+func IsSwapMemo(memo string) bool {
+	return strings.Contains(memo, swapMemoPrefix)
+}
@@ -0,0 +1,84 @@
+package jupiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// fanout is the RPC service the signing client sends through - reads go to the primary endpoint, while signed
+// transactions are broadcast to every endpoint at once so one congested node cannot keep a swap from landing
+type fanout struct {
+	*rpc.Client
+	extra []*rpc.Client
+}
+
+// newFanout pairs the primary endpoint with the extra broadcast endpoints, all sending through the shared connections
+func newFanout(tr *transport, primary string, extra []string) *fanout {
+	f := &fanout{Client: tr.rpc(primary)}
+	for _, endpoint := range extra {
+		f.extra = append(f.extra, tr.rpc(endpoint))
+	}
+	return f
+}
+
+// sendResult is one endpoint's answer to a broadcast
+type sendResult struct {
+	endpoint int
+	sig      solana.Signature
+	err      error
+}
+
+// SendTransactionWithOpts broadcasts the signed transaction to every endpoint and returns as soon as one accepts it -
+// the copies share one signature, so whichever lands first is the swap the monitor confirms, and the rest are
+// dropped by the cluster as duplicates
+func (f *fanout) SendTransactionWithOpts(ctx context.Context, tx *solana.Transaction,
+	opts rpc.TransactionOpts) (solana.Signature, error) {
+	if len(f.extra) == 0 {
+		return f.Client.SendTransactionWithOpts(ctx, tx, opts)
+	}
+	if len(tx.Signatures) == 0 {
+		return solana.Signature{}, fmt.Errorf("transaction is not signed")
+	}
+
+	// The minimum context slot came from the primary, which the other endpoints may lag behind
+	extraOpts := opts
+	extraOpts.MinContextSlot = nil
+
+	results := make(chan sendResult, len(f.extra)+1)
+	send := func(endpoint int, c *rpc.Client, opts rpc.TransactionOpts) {
+		sig, err := c.SendTransactionWithOpts(ctx, tx, opts)
+		results <- sendResult{endpoint: endpoint, sig: sig, err: err}
+	}
+	go send(0, f.Client, opts)
+	for i, c := range f.extra {
+		go send(i+1, c, extraOpts)
+	}
+
+	want := tx.Signatures[0]
+	var errs []error
+	for range len(f.extra) + 1 {
+		r := <-results
+		switch {
+		case r.err != nil:
+			errs = append(errs, fmt.Errorf("endpoint %d: %w", r.endpoint, r.err))
+		case !r.sig.Equals(want):
+			errs = append(errs, fmt.Errorf("endpoint %d: returned signature %s for transaction %s", r.endpoint, r.sig, want))
+		default:
+			return r.sig, nil
+		}
+	}
+	return solana.Signature{}, errors.Join(errs...)
+}
+
+// Close releases every endpoint's connections
+func (f *fanout) Close() error {
+	errs := []error{f.Client.Close()}
+	for _, c := range f.extra {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,70 @@
+package jupiter
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Faults chaos mode can inject
+const (
+	FaultPriceDrop       = "price_drop"       // price responses are lost
+	FaultConfirmDelay    = "confirm_delay"    // confirmations arrive late
+	FaultRpc429          = "rpc_429"          // the RPC rate limits requests
+	FaultBlockhashExpiry = "blockhash_expiry" // swaps expire before they land
+)
+
+// Faults lists every fault chaos mode can inject
+var Faults = []string{FaultPriceDrop, FaultConfirmDelay, FaultRpc429, FaultBlockhashExpiry}
+
+// chaos injects random failures into the wrapper so the retry, recovery, and alerting paths can be exercised end to
+// end - a nil chaos injects nothing
+type chaos struct {
+	rate   float64
+	faults []string
+	delay  time.Duration
+}
+
+// newChaos returns the fault injector the config asks for, or nil when chaos mode is off
+func newChaos(cfg *configs.Config) *chaos {
+	if cfg.ChaosRate <= 0 {
+		return nil
+	}
+	faults := cfg.ChaosFaults
+	if len(faults) == 0 {
+		faults = Faults
+	}
+	return &chaos{rate: cfg.ChaosRate, faults: faults, delay: cfg.ChaosConfirmDelay}
+}
+
+// inject reports whether the fault strikes this time
+func (c *chaos) inject(fault string) bool {
+	return c != nil && slices.Contains(c.faults, fault) && rand.Float64() < c.rate
+}
+
+// fail returns the error the fault surfaces as when it strikes, and nil otherwise
+func (c *chaos) fail(fault string) error {
+	if !c.inject(fault) {
+		return nil
+	}
+	switch fault {
+	case FaultPriceDrop:
+		return fmt.Errorf("chaos: price response dropped")
+	case FaultRpc429:
+		return fmt.Errorf("chaos: rpc returned 429 Too Many Requests")
+	case FaultBlockhashExpiry:
+		return fmt.Errorf("chaos: transaction expired, blockhash not found")
+	}
+	return fmt.Errorf("chaos: %s", fault)
+}
+
+// confirmDelay returns how long to hold back a confirmation, zero unless the fault strikes
+func (c *chaos) confirmDelay() time.Duration {
+	if !c.inject(FaultConfirmDelay) {
+		return 0
+	}
+	return c.delay
+}
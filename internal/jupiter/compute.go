@@ -0,0 +1,167 @@
+package jupiter
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	sl "github.com/ilkamo/jupiter-go/solana"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// Compute Budget program instructions, identified by their first data byte
+const (
+	setComputeUnitLimit = 2 // followed by a little-endian u32 limit
+	setComputeUnitPrice = 3 // followed by a little-endian u64 price in micro-lamports
+)
+
+// maxComputeUnits is the most compute a transaction may request
+const maxComputeUnits = 1_400_000
+
+// budgetCompute reads the compute budget of the swap transaction and, when tuning is enabled, right-sizes it before
+// it is signed - the limit from the units a simulation consumed plus a margin, and the price from the fees recently
+// paid to write the accounts the swap writes - returning the transaction to send and the budget it carries
+func (j *Jupiter) budgetCompute(ctx context.Context, txBase64 string) (string, common.ComputeBudget, error) {
+	tx, err := sl.NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", common.ComputeBudget{}, err
+	}
+	requested := readComputeBudget(tx)
+	if !j.cfg.ComputeUnitTuning {
+		return txBase64, requested, nil
+	}
+	budget := requested
+
+	if err = j.ch.fail(FaultRpc429); err != nil {
+		return "", common.ComputeBudget{}, err
+	}
+	start := time.Now()
+	sim, err := j.rc.SimulateTransactionWithOpts(ctx, &tx, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentProcessed,
+		ReplaceRecentBlockhash: true,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return "", common.ComputeBudget{}, fmt.Errorf("could not simulate swap: %w", err)
+	}
+	if sim.Value.Err != nil {
+		return "", common.ComputeBudget{}, fmt.Errorf("swap simulation failed: %v", sim.Value.Err)
+	}
+	if sim.Value.UnitsConsumed != nil && *sim.Value.UnitsConsumed > 0 {
+		budget.UnitsConsumed = *sim.Value.UnitsConsumed
+		limit := float64(budget.UnitsConsumed) * (1 + j.cfg.ComputeUnitMargin/100)
+		budget.UnitLimit = uint32(math.Min(math.Ceil(limit), maxComputeUnits))
+	}
+
+	start = time.Now()
+	fees, err := j.rc.GetRecentPrioritizationFees(ctx, writableAccounts(tx.Message))
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return "", common.ComputeBudget{}, fmt.Errorf("could not get recent priority fees: %w", err)
+	}
+	if price, ok := feePercentile(fees, j.cfg.ComputeUnitPricePercentile); ok {
+		budget.UnitPrice = min(price, uint64(j.cfg.ComputeUnitPriceMax))
+		// Congestion pays more to land, within a cap of its own
+		if j.congested().RaiseFees {
+			raised := math.Ceil(float64(price) * max(j.cfg.CongestionFeeMultiplier, 1))
+			budget.UnitPrice = uint64(math.Min(raised, float64(j.cfg.CongestionUnitPriceMax)))
+		}
+	}
+
+	if !writeComputeBudget(&tx, budget) {
+		// Without compute budget instructions to rewrite, Jupiter's budget stands
+		requested.UnitsConsumed = budget.UnitsConsumed
+		return txBase64, requested, nil
+	}
+	tuned, err := tx.ToBase64()
+	if err != nil {
+		return "", common.ComputeBudget{}, err
+	}
+	return tuned, budget, nil
+}
+
+// readComputeBudget returns the compute unit limit and price the transaction's compute budget instructions set
+func readComputeBudget(tx solana.Transaction) common.ComputeBudget {
+	var budget common.ComputeBudget
+	for _, ix := range computeBudgetInstructions(tx) {
+		data := tx.Message.Instructions[ix].Data
+		switch {
+		case data[0] == setComputeUnitLimit && len(data) >= 5:
+			budget.UnitLimit = binary.LittleEndian.Uint32(data[1:5])
+		case data[0] == setComputeUnitPrice && len(data) >= 9:
+			budget.UnitPrice = binary.LittleEndian.Uint64(data[1:9])
+		}
+	}
+	return budget
+}
+
+// writeComputeBudget rewrites the transaction's compute budget instructions to the budget, reporting whether it had
+// both instructions to rewrite
+func writeComputeBudget(tx *solana.Transaction, budget common.ComputeBudget) bool {
+	var limit, price bool
+	for _, ix := range computeBudgetInstructions(*tx) {
+		data := slices.Clone(tx.Message.Instructions[ix].Data)
+		switch {
+		case data[0] == setComputeUnitLimit && len(data) >= 5:
+			binary.LittleEndian.PutUint32(data[1:5], budget.UnitLimit)
+			limit = true
+		case data[0] == setComputeUnitPrice && len(data) >= 9:
+			binary.LittleEndian.PutUint64(data[1:9], budget.UnitPrice)
+			price = true
+		default:
+			continue
+		}
+		tx.Message.Instructions[ix].Data = data
+	}
+	return limit && price
+}
+
+// computeBudgetInstructions returns the indexes of the transaction's Compute Budget program instructions
+func computeBudgetInstructions(tx solana.Transaction) []int {
+	var indexes []int
+	for i, ix := range tx.Message.Instructions {
+		// Programs are always static keys, never loaded from lookup tables
+		if int(ix.ProgramIDIndex) >= len(tx.Message.AccountKeys) || len(ix.Data) == 0 {
+			continue
+		}
+		if tx.Message.AccountKeys[ix.ProgramIDIndex].Equals(solana.ComputeBudget) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// writableAccounts returns the static accounts the message writes, which priority fees are priced against
+func writableAccounts(m solana.Message) solana.PublicKeySlice {
+	var accounts solana.PublicKeySlice
+	signed := int(m.Header.NumRequiredSignatures)
+	for i, key := range m.AccountKeys {
+		writable := i < signed-int(m.Header.NumReadonlySignedAccounts) ||
+			(i >= signed && i < len(m.AccountKeys)-int(m.Header.NumReadonlyUnsignedAccounts))
+		if writable && len(accounts) < 128 {
+			accounts = append(accounts, key)
+		}
+	}
+	return accounts
+}
+
+// feePercentile returns the percentile of the recent priority fees, in micro-lamports per compute unit
+func feePercentile(fees []rpc.PriorizationFeeResult, percentile float64) (uint64, bool) {
+	if len(fees) == 0 {
+		return 0, false
+	}
+	prices := make([]uint64, 0, len(fees))
+	for _, f := range fees {
+		prices = append(prices, f.PrioritizationFee)
+	}
+	slices.Sort(prices)
+	i := int(math.Ceil(percentile/100*float64(len(prices)))) - 1
+	return prices[max(0, min(i, len(prices)-1))], true
+}
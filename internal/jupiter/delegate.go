@@ -0,0 +1,228 @@
+package jupiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	ata "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// Delegation is the spending authority the bot wallet holds over a funding token account - the bot can pull up to the
+// smaller of the allowance and the balance, and only while it is the account's delegate
+type Delegation struct {
+	Mint      string  `json:"mint"`
+	Account   string  `json:"account"`
+	Delegate  string  `json:"delegate,omitempty"`
+	Allowance float64 `json:"allowance"` // what the delegate may still transfer
+	Balance   float64 `json:"balance"`
+	Spendable float64 `json:"spendable"` // by this bot
+}
+
+// Delegated reports whether swaps are funded from the funding wallet's token accounts rather than the bot wallet
+func (j *Jupiter) Delegated() bool {
+	return j.cfg.FundingWallet != ""
+}
+
+// checkFunding makes sure the funding wallet and token accounts are valid addresses, keyed by valid mints
+func checkFunding(cfg *configs.Config) error {
+	if cfg.FundingWallet == "" {
+		return nil
+	}
+	if _, err := solana.PublicKeyFromBase58(cfg.FundingWallet); err != nil {
+		return fmt.Errorf("invalid funding_wallet: %w", err)
+	}
+	for mint, account := range cfg.FundingAccounts {
+		if _, err := solana.PublicKeyFromBase58(mint); err != nil {
+			return fmt.Errorf("invalid funding_accounts mint %s: %w", mint, err)
+		}
+		if _, err := solana.PublicKeyFromBase58(account); err != nil {
+			return fmt.Errorf("invalid funding_accounts entry of %s: %w", mint, err)
+		}
+	}
+	return nil
+}
+
+// fundingAccount returns the funding wallet's token account of the mint - the one configured for it, else the
+// wallet's associated token account
+func (j *Jupiter) fundingAccount(mint string) (solana.PublicKey, error) {
+	if account, ok := j.cfg.FundingAccounts[mint]; ok {
+		return solana.PublicKeyFromBase58(account)
+	}
+	owner, err := solana.PublicKeyFromBase58(j.cfg.FundingWallet)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("invalid funding wallet: %w", err)
+	}
+	mintPk, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	account, _, err := solana.FindAssociatedTokenAddress(owner, mintPk)
+	return account, err
+}
+
+// Delegation reads the funding token account of the mint - an account the bot is not the delegate of leaves it
+// nothing to spend
+func (j *Jupiter) Delegation(ctx context.Context, mint string) (Delegation, error) {
+	account, err := j.fundingAccount(mint)
+	if err != nil {
+		return Delegation{}, err
+	}
+	info, err := j.tokenAccount(ctx, account)
+	if err != nil {
+		return Delegation{}, err
+	}
+	if info == nil {
+		return Delegation{}, fmt.Errorf("funding account %s does not exist", account)
+	}
+	if info.Mint != mint {
+		return Delegation{}, fmt.Errorf("funding account %s holds %s, not %s", account, info.Mint, mint)
+	}
+	d := Delegation{
+		Mint:      mint,
+		Account:   account.String(),
+		Delegate:  info.Delegate,
+		Allowance: info.DelegatedAmount.value(),
+		Balance:   info.TokenAmount.value(),
+	}
+	if d.Delegate == j.PublicKey().String() {
+		d.Spendable = min(d.Allowance, d.Balance)
+	}
+	return d, nil
+}
+
+// tokenAccountInfo is a token account as the parsed account encoding reports it
+type tokenAccountInfo struct {
+	Mint            string   `json:"mint"`
+	Delegate        string   `json:"delegate"`
+	TokenAmount     uiAmount `json:"tokenAmount"`
+	DelegatedAmount uiAmount `json:"delegatedAmount"`
+}
+
+// uiAmount is a token amount as the parsed account encoding reports it
+type uiAmount struct {
+	Amount         string `json:"amount"` // in base units
+	UiAmountString string `json:"uiAmountString"`
+}
+
+// value returns the amount, 0 when it is missing
+func (a uiAmount) value() float64 {
+	v, _ := strconv.ParseFloat(a.UiAmountString, 64)
+	return v
+}
+
+// units returns the amount in base units, 0 when it is missing
+func (a uiAmount) units() uint64 {
+	v, _ := strconv.ParseUint(a.Amount, 10, 64)
+	return v
+}
+
+// tokenAccount reads the token account, nil when it does not exist
+func (j *Jupiter) tokenAccount(ctx context.Context, account solana.PublicKey) (*tokenAccountInfo, error) {
+	start := time.Now()
+	res, err := j.rc.GetAccountInfoWithOpts(ctx, account, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingJSONParsed,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err == rpc.ErrNotFound || (err == nil && (res == nil || res.Value == nil)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read token account %s: %w", account, err)
+	}
+	var data struct {
+		Parsed struct {
+			Type string           `json:"type"`
+			Info tokenAccountInfo `json:"info"`
+		} `json:"parsed"`
+	}
+	if res.Value.Data == nil || json.Unmarshal(res.Value.Data.GetRawJSON(), &data) != nil ||
+		data.Parsed.Type != "account" {
+		return nil, fmt.Errorf("account %s is not a token account", account)
+	}
+	return &data.Parsed.Info, nil
+}
+
+// pullDelegated transfers what the bot wallet lacks of the amount, in the mint's base units, from the funding token
+// account into the bot's own token account as the funding account's delegate, creating the bot's account first when
+// needed, and waits until the transfer is confirmed - only the swap's input ever sits in the bot wallet, and the
+// transfer carries the memo of the swap it funds, tag, so the wallet's history does not take it as external activity
+func (j *Jupiter) pullDelegated(ctx context.Context, mint string, amount uint64, tag string) error {
+	j.mu.RLock()
+	sc, pk := j.sc, *j.pk
+	j.mu.RUnlock()
+	if sc == nil {
+		return fmt.Errorf("no wallet key is loaded to pull delegated funds with")
+	}
+	mintPk, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return err
+	}
+	source, err := j.fundingAccount(mint)
+	if err != nil {
+		return err
+	}
+	destination, _, err := solana.FindAssociatedTokenAddress(pk, mintPk)
+	if err != nil {
+		return err
+	}
+
+	held, err := j.tokenAccount(ctx, destination)
+	if err != nil {
+		return err
+	}
+	var instructions []solana.Instruction
+	if held == nil {
+		held = &tokenAccountInfo{}
+		instructions = append(instructions, ata.NewCreateInstruction(pk, pk, mintPk).Build())
+	}
+	have := held.TokenAmount.units()
+	if have >= amount {
+		return nil
+	}
+	instructions = append(instructions, token.NewTransferInstruction(amount-have, source, destination, pk, nil).Build(),
+		solana.NewInstruction(memoProgram, solana.AccountMetaSlice{}, []byte(swapMemo(j.cfg.StrategyId, tag))))
+
+	txId, err := j.sendInstructions(ctx, sc, pk, instructions...)
+	if err != nil {
+		return fmt.Errorf("could not pull %d units of %s from funding account %s: %w", amount-have, mint, source,
+			err)
+	}
+	return j.awaitConfirmed(ctx, txId)
+}
+
+// awaitConfirmed waits on the batched status poll until the transaction is confirmed, failing when it failed on-chain
+// or did not confirm within the commitment timeout
+func (j *Jupiter) awaitConfirmed(ctx context.Context, txId string) error {
+	sig, err := solana.SignatureFromBase58(txId)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, j.cfg.CommitmentTimeout)
+	defer cancel()
+	updates, unwatch := j.st.watch(sig)
+	defer unwatch()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction %s not confirmed: %w", txId, ctx.Err())
+		case status := <-updates:
+			if status.Err != nil {
+				return fmt.Errorf("transaction %s failed: %v", txId, status.Err)
+			}
+			if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
+				status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+				return nil
+			}
+		}
+	}
+}
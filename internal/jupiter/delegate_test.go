@@ -0,0 +1,69 @@
+package jupiter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+const (
+	usdc    = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	ray     = "4k3Dyjzvzp8eMZWUXbBCjEvwSkkk59S5iCNLY3QrkX6R"
+	funding = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	account = "Gh9ZwEmdLJ8DscKNTkTqPbNwLNNBjuSzaG9Vp2KGtKJr"
+)
+
+// loadConfig writes the YAML to a config file of its own and loads it as the bot would
+func loadConfig(t *testing.T, yaml string) *configs.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := configs.NewConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestFundingAccountFromYaml(t *testing.T) {
+	cfg := loadConfig(t, "funding_wallet: "+funding+"\nfunding_accounts:\n  "+usdc+": "+account+"\n")
+	if err := checkFunding(cfg); err != nil {
+		t.Fatal(err)
+	}
+	j := &Jupiter{cfg: cfg}
+
+	got, err := j.fundingAccount(usdc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != account {
+		t.Errorf("funding account of %s = %s, want the configured %s", usdc, got, account)
+	}
+
+	// A mint without an entry falls back to the funding wallet's associated token account
+	got, err = j.fundingAccount(ray)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := solana.FindAssociatedTokenAddress(solana.MustPublicKeyFromBase58(funding),
+		solana.MustPublicKeyFromBase58(ray))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("funding account of %s = %s, want the associated token account %s", ray, got, want)
+	}
+}
+
+func TestCheckFundingRejectsInvalidMints(t *testing.T) {
+	cfg := loadConfig(t, "funding_wallet: "+funding+"\nfunding_accounts:\n  usdc: "+account+"\n")
+	if err := checkFunding(cfg); err == nil {
+		t.Error("funding_accounts keyed by usdc passed, want an invalid mint error")
+	}
+}
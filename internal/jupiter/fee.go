@@ -0,0 +1,26 @@
+package jupiter
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// referralProgram is Jupiter's referral program, which owns the token accounts platform fees are paid into
+var referralProgram = solana.MustPublicKeyFromBase58("REFER4ZgmyYx9c6He5XfaTMiGfdLwRnkV4RPp9t9iF3")
+
+// platformFeeAccount derives the referral account's token account for the mint the fee is taken in - the output mint
+// of an exact-in swap - which must have been created in the referral dashboard for the fee to be collected
+func platformFeeAccount(referral string, mint string) (solana.PublicKey, error) {
+	referralPk, err := solana.PublicKeyFromBase58(referral)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("invalid platform referral account: %w", err)
+	}
+	mintPk, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	account, _, err := solana.FindProgramAddress([][]byte{[]byte("referral_ata"), referralPk.Bytes(), mintPk.Bytes()},
+		referralProgram)
+	return account, err
+}
@@ -0,0 +1,137 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	jl "github.com/ilkamo/jupiter-go/jupiter"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// solDecimals is the precision of native SOL and wrapped SOL
+const solDecimals = 9
+
+// sentQuote is the quote a swap was sent on, kept until its fill is read
+type sentQuote struct {
+	quote     jl.QuoteResponse
+	sentAt    time.Time
+	blockhash solana.Hash // once it expires the swap can no longer land
+}
+
+// Fill reads what a confirmed swap sent by this client actually exchanged from the wallet's balance changes, next to
+// the quote it was sent on - the quote is kept until the fill is read, once
+func (j *Jupiter) Fill(ctx context.Context, txId string) (common.Fill, error) {
+	j.sentMu.Lock()
+	sent, ok := j.quotes[txId]
+	delete(j.quotes, txId)
+	j.sentMu.Unlock()
+	if !ok {
+		return common.Fill{}, fmt.Errorf("swap %s was not sent by this client", txId)
+	}
+	sig, err := solana.SignatureFromBase58(txId)
+	if err != nil {
+		return common.Fill{}, err
+	}
+
+	version := uint64(0)
+	start := time.Now()
+	res, err := j.rc.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Commitment:                     rpc.CommitmentConfirmed,
+		MaxSupportedTransactionVersion: &version,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return common.Fill{}, fmt.Errorf("could not get swap %s: %w", txId, err)
+	}
+	if res.Meta == nil {
+		return common.Fill{}, fmt.Errorf("swap %s has no status metadata", txId)
+	}
+	recipient := j.PublicKey()
+	if j.Delegated() {
+		if recipient, err = solana.PublicKeyFromBase58(j.cfg.FundingWallet); err != nil {
+			return common.Fill{}, err
+		}
+	}
+	return readFill(res.Meta, j.PublicKey(), recipient, sent.quote)
+}
+
+// readFill derives the fill from the transaction's balance changes for the wallet - the fee payer, whose native
+// balance also moves when SOL is wrapped or unwrapped - and for the recipient of the output, which is the wallet
+// itself unless the swap is funded by delegation
+func readFill(meta *rpc.TransactionMeta, wallet solana.PublicKey, recipient solana.PublicKey,
+	quote jl.QuoteResponse) (common.Fill, error) {
+	in, inDecimals := balanceChange(meta, wallet, quote.InputMint)
+	out, outDecimals := balanceChange(meta, wallet, quote.OutputMint)
+	if !recipient.Equals(wallet) {
+		out, outDecimals = tokenChange(meta, recipient, quote.OutputMint)
+	}
+	quotedIn, err := strconv.ParseFloat(quote.InAmount, 64)
+	if err != nil {
+		return common.Fill{}, err
+	}
+	quotedOut, err := strconv.ParseFloat(quote.OutAmount, 64)
+	if err != nil {
+		return common.Fill{}, err
+	}
+	var platformFee float64
+	if quote.PlatformFee != nil {
+		fee, err := strconv.ParseFloat(quote.PlatformFee.Amount, 64)
+		if err != nil {
+			return common.Fill{}, err
+		}
+		platformFee = fee / math.Pow10(outDecimals)
+	}
+	return common.Fill{
+		InputMint:   quote.InputMint,
+		OutputMint:  quote.OutputMint,
+		InAmount:    -in / math.Pow10(inDecimals),
+		OutAmount:   out / math.Pow10(outDecimals),
+		QuotedIn:    quotedIn / math.Pow10(inDecimals),
+		QuotedOut:   quotedOut / math.Pow10(outDecimals),
+		FeeLamports: meta.Fee,
+		PlatformFee: platformFee,
+	}, nil
+}
+
+// balanceChange returns how much of the mint the wallet gained in the transaction, in base units, and the mint's
+// decimals - for SOL the native balance counts too, less the fee it paid
+func balanceChange(meta *rpc.TransactionMeta, wallet solana.PublicKey, mint string) (float64, int) {
+	change, decimals := tokenChange(meta, wallet, mint)
+	if mint == solana.SolMint.String() {
+		decimals = solDecimals
+		if len(meta.PreBalances) > 0 && len(meta.PostBalances) > 0 {
+			change += float64(meta.PostBalances[0]) - float64(meta.PreBalances[0]) + float64(meta.Fee)
+		}
+	}
+	return change, decimals
+}
+
+// tokenChange returns how much of the mint the wallet's token accounts gained in the transaction, in base units, and
+// the mint's decimals, 0 when none of its accounts hold the mint
+func tokenChange(meta *rpc.TransactionMeta, wallet solana.PublicKey, mint string) (float64, int) {
+	decimals := -1
+	sum := func(balances []rpc.TokenBalance) float64 {
+		var total float64
+		for _, b := range balances {
+			if b.Owner == nil || !b.Owner.Equals(wallet) || b.Mint.String() != mint || b.UiTokenAmount == nil {
+				continue
+			}
+			amount, err := strconv.ParseFloat(b.UiTokenAmount.Amount, 64)
+			if err != nil {
+				continue
+			}
+			total += amount
+			decimals = int(b.UiTokenAmount.Decimals)
+		}
+		return total
+	}
+	change := sum(meta.PostTokenBalances) - sum(meta.PreTokenBalances)
+	return change, max(decimals, 0)
+}
@@ -0,0 +1,67 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// jupiterProgram is the Jupiter aggregator's swap program
+var jupiterProgram = solana.MustPublicKeyFromBase58("JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4")
+
+// History returns the successful transactions of any wallet, oldest first, with what each did to the wallet's
+// holdings of the mints - it reads back from the newest until limit signatures or the start of since, whichever
+// comes first, a zero since reading as far back as the limit allows
+func (j *Jupiter) History(ctx context.Context, address string, mints []string, since time.Time, limit int) (
+	[]Activity, error) {
+	wallet, err := solana.PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet address %q: %w", address, err)
+	}
+
+	var sigs []*rpc.TransactionSignature
+	pageSize := activityPageSize
+	opts := &rpc.GetSignaturesForAddressOpts{Limit: &pageSize, Commitment: rpc.CommitmentFinalized}
+	for len(sigs) < limit {
+		start := time.Now()
+		res, err := j.rc.GetSignaturesForAddressWithOpts(ctx, wallet, opts)
+		j.lat.Observe(latency.Rpc, start, err)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the wallet's signatures: %w", err)
+		}
+		for _, sig := range res {
+			if sig.BlockTime != nil && sig.BlockTime.Time().Before(since) {
+				res = nil
+				break
+			}
+			sigs = append(sigs, sig)
+		}
+		if len(res) < pageSize {
+			break
+		}
+		opts.Before = res[len(res)-1].Signature
+	}
+	sigs = sigs[:min(len(sigs), limit)]
+
+	history := make([]Activity, 0, len(sigs))
+	for _, sig := range slices.Backward(sigs) {
+		if sig.Err != nil {
+			continue
+		}
+		a, err := j.activity(ctx, sig.Signature, wallet, mints)
+		if err != nil {
+			return nil, err
+		}
+		if sig.Memo != nil {
+			a.Memo = *sig.Memo
+		}
+		history = append(history, a)
+	}
+	return history, nil
+}
@@ -3,29 +3,39 @@ package jupiter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	jl "github.com/ilkamo/jupiter-go/jupiter"
 	sl "github.com/ilkamo/jupiter-go/solana"
+	"github.com/mr-tron/base58"
 
 	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/latency"
 	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/secure"
 )
 
 const (
 	rpcEndpoint   = "https://api.mainnet-beta.solana.com"
-	wsEndpoint    = "wss://api.mainnet-beta.solana.com"
 	priceEndpoint = "https://api.jup.ag/price/v2"
 )
 
+// ErrQuoteExpired is returned when a swap's quote aged past its time to live before the swap could be sent
+var ErrQuoteExpired = errors.New("quote expired")
+
 // PriceData models the object returned from Jupiter for pricing on a particular asset
 type PriceData struct {
 	Id    string `json:"id"`
@@ -40,91 +50,329 @@ type GetPriceResponse struct {
 
 // Jupiter is a custom wrapper for interacting with various Jupiter and Solana services
 type Jupiter struct {
-	cfg *configs.Config
+	cfg    *configs.Config
+	rc     *rpc.Client
+	st     *statusPoller
+	jc     *jl.ClientWithResponses
+	tr     *transport
+	sq     *squads
+	lat    *latency.Tracker
+	tokens *tokenCache
+	ch     *chaos
+
+	mu  sync.RWMutex // guards the signer, which is swapped when the secret key rotates
 	sc  sl.Client
-	smn sl.Monitor
-	jc  *jl.ClientWithResponses
 	pk  *solana.PublicKey
+	key solana.PrivateKey // the key the signer signs with, zeroed once a rotated key replaces it
+
+	sentMu   sync.Mutex // guards what is kept about sent swaps until it is collected
+	budgets  map[string]common.ComputeBudget
+	quotes   map[string]sentQuote
+	untagged []UntaggedSwap
+
+	proposeMu sync.Mutex // serializes proposals, which each take the multisig's next transaction index
+
+	prefetchMu     sync.Mutex // guards the quotes fetched ahead of the next decision and the swaps that missed them
+	prefetched     map[prefetchKey]prefetched
+	prefetchMisses []PrefetchMiss
+
+	congestionMu sync.RWMutex
+	congestion   Congestion
 }
 
 // NewJupiter creates a new custom Jupiter object
 func NewJupiter(cfg *configs.Config) (*Jupiter, error) {
-	// Build a Solana wallet using the secret key in the config
-	sk, err := cfg.SecretKey()
+	// Build the Solana client responsible for signing and submitting transactions on-chain, using the secret key in
+	// the config - without one (signal-only and observer modes) the client can price and quote but not swap, and in
+	// observer mode the wallet is the watched public address
+	var (
+		tr  = newTransport(cfg)
+		sc  sl.Client
+		pk  solana.PublicKey
+		key solana.PrivateKey
+		err error
+	)
+	switch {
+	case cfg.NeedsSecretKey():
+		sc, key, err = newSigner(cfg, tr)
+		pk = key.PublicKey()
+	case cfg.ObserveWallet != "":
+		pk, err = solana.PublicKeyFromBase58(cfg.ObserveWallet)
+	}
 	if err != nil {
 		return nil, err
 	}
-	wallet, err := sl.NewWalletFromPrivateKeyBase58(sk)
+	if err = checkFunding(cfg); err != nil {
+		return nil, err
+	}
+	sq, err := newSquads(cfg)
 	if err != nil {
 		return nil, err
 	}
-	pk := wallet.PublicKey() // Save the public key for attaching to the Jupiter struct
 
-	// Initialize the Solana client responsible for submitting transactions on-chain
-	sc, err := sl.NewClient(wallet, rpcEndpoint)
+	// Initialize the Jupiter client responsible for creating swap transactions, on the shared connections
+	jc, err := jl.NewClientWithResponses(jl.DefaultAPIURL, jl.WithHTTPClient(tr.hc))
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize the Jupiter client responsible for creating swap transactions
-	jc, err := jl.NewClientWithResponses(jl.DefaultAPIURL)
+	// Return the Jupiter wrapper for interacting with Solana and Jupiter APIs, following the commitment of sent
+	// transactions with one batched status poll
+	rc, lat := tr.rpc(rpcEndpoint), latency.NewTracker(cfg.LatencyWindow)
+	return &Jupiter{
+		cfg:     cfg,
+		sc:      sc,
+		rc:      rc,
+		st:      newStatusPoller(rc, lat, cfg.TxStatusPoll),
+		jc:      jc,
+		tr:      tr,
+		sq:      sq,
+		tokens:  newTokenCache(cfg.TokenCacheFile),
+		lat:     lat,
+		ch:      newChaos(cfg),
+		pk:      &pk,
+		key:     key,
+		budgets: make(map[string]common.ComputeBudget),
+		quotes:  make(map[string]sentQuote),
+	}, nil
+}
+
+// ReloadSigner rebuilds the wallet and signing client from the secret key currently cached in the config, so a
+// rotated key takes effect without a restart, and zeroes the previous key so it does not linger in memory - swaps
+// already submitted are unaffected, and one being signed at that instant fails rather than keeping the old key alive
+func (j *Jupiter) ReloadSigner() (solana.PublicKey, error) {
+	sc, key, err := newSigner(j.cfg, j.tr)
 	if err != nil {
-		return nil, err
+		return solana.PublicKey{}, err
 	}
+	pk := key.PublicKey()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	previous := j.key
+	j.sc, j.pk, j.key = sc, &pk, key
+	secure.Zero(previous)
+	return pk, nil
+}
 
-	// Initialize the Solana Monitor client to watch transactions and track their statuses
-	smn, err := sl.NewMonitor(wsEndpoint)
+// PublicKey returns the address of the wallet that swaps - the one that signs them, the multisig vault they are
+// proposed from, or the watched wallet in observer mode
+func (j *Jupiter) PublicKey() solana.PublicKey {
+	if j.sq != nil {
+		return j.sq.vault
+	}
+	return j.Signer()
+}
+
+// Signer returns the address of the wallet the loaded secret key signs for
+func (j *Jupiter) Signer() solana.PublicKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return *j.pk
+}
+
+// newSigner builds a Solana wallet from the secret key cached in the config and a client that signs with it, sending
+// through the shared connections - the decoded key is returned for zeroing once the client is replaced
+func newSigner(cfg *configs.Config, tr *transport) (sl.Client, solana.PrivateKey, error) {
+	sk, err := cfg.SecretKey()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	key, err := base58.Decode(string(sk))
+	if err != nil {
+		return nil, nil, err
 	}
+	if len(key) != 64 {
+		secure.Zero(key)
+		return nil, nil, fmt.Errorf("invalid private key length %d", len(key))
+	}
+	// The signer now owns the decoded key, so wipe the encoded copy cached by the config
+	cfg.WipeSecretKey()
+	wallet := sl.Wallet{Wallet: &solana.Wallet{PrivateKey: key}}
 
-	// Return the Jupiter wrapper for interacting with Solana and Jupiter APIs
-	return &Jupiter{
-		cfg: cfg,
-		sc:  sc,
-		smn: smn,
-		jc:  jc,
-		pk:  &pk,
+	sc, err := sl.NewClient(wallet, rpcEndpoint, sl.WithClientRPC(newFanout(tr, rpcEndpoint, cfg.RpcBroadcastEndpoints)))
+	if err != nil {
+		secure.Zero(key)
+		return nil, nil, err
+	}
+	return sc, key, nil
+}
+
+// Execute interacts with Jupiter to "place an order" on the pair - BUY spends size of the quote asset for the base
+// asset, SELL spends size of the base asset for the quote asset - it strives for high order success - tag identifies
+// the order in the swap's memo when memos are enabled
+func (j *Jupiter) Execute(ctx context.Context, pair common.Pair, side common.Signal, size float64,
+	tag string) (string, error) {
+	return j.ExecuteWithSlippage(ctx, pair, side, size, 0, tag)
+}
+
+// ExecuteWithSlippage is Execute with a fixed slippage tolerance in basis points instead of Jupiter's automatic and
+// dynamic slippage - zero keeps the automatic behaviour
+func (j *Jupiter) ExecuteWithSlippage(ctx context.Context, pair common.Pair, side common.Signal, size float64,
+	slippageBps int, tag string) (string, error) {
+	inputMint, outputMint, err := pair.Mints(side)
+	if err != nil {
+		return "", err
+	}
+
+	for requotes := 0; ; requotes++ {
+		// 1) Get a quote from Jupiter that can be used to form a swap request, unless one was prefetched for the swap
+		var (
+			quote    jl.QuoteResponse
+			quotedAt time.Time
+			ok       bool
+		)
+		if slippageBps == 0 {
+			quote, quotedAt, ok = j.takePrefetched(inputMint, outputMint, size, true)
+		}
+		if !ok {
+			if quote, err = j.getQuote(ctx, inputMint, outputMint, size, slippageBps); err != nil {
+				return "", err
+			}
+			quotedAt = time.Now()
+		}
+		deadline := quotedAt.Add(j.cfg.QuoteTtl)
+
+		// 2) Get a swap transaction based on the quote and broadcast it, re-quoting when the quote went stale first
+		txId, err := j.swapQuote(ctx, quote, deadline, slippageBps == 0, tag)
+		if errors.Is(err, ErrQuoteExpired) && requotes < j.cfg.QuoteMaxRequotes {
+			continue
+		}
+		if errors.Is(err, ErrQuoteExpired) {
+			return "", fmt.Errorf("%w after %d re-quotes", err, requotes)
+		}
+		return txId, err
+	}
+}
+
+// QuoteOutAmount returns the output amount, in the output asset's base units, that Jupiter currently quotes for
+// swapping the given amount
+func (j *Jupiter) QuoteOutAmount(ctx context.Context, inputMint string, outputMint string, amount float64) (int64, error) {
+	quote, err := j.getQuote(ctx, inputMint, outputMint, amount, 0)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(quote.OutAmount, 10, 64)
+}
+
+// Estimate is the outcome Jupiter currently quotes for a swap, in human (not base unit) amounts
+type Estimate struct {
+	InAmount       float64
+	OutAmount      float64
+	PriceImpactPct float64
+}
+
+// EffectivePrice returns the price of the pair's base asset the estimate achieves for a swap executing the side -
+// BUY spends the quote asset for the base asset, SELL the other way around
+func (e Estimate) EffectivePrice(side common.Signal) (float64, error) {
+	if e.InAmount <= 0 || e.OutAmount <= 0 {
+		return 0, fmt.Errorf("quote returned empty amounts")
+	}
+	if side == common.SellSignal {
+		return e.OutAmount / e.InAmount, nil
+	}
+	return e.InAmount / e.OutAmount, nil
+}
+
+// EstimateSwap quotes a swap without executing it so callers can judge the achievable price before committing
+func (j *Jupiter) EstimateSwap(ctx context.Context, inputMint string, outputMint string, amount float64) (Estimate, error) {
+	quote, err := j.getQuote(ctx, inputMint, outputMint, amount, 0)
+	if err != nil {
+		return Estimate{}, err
+	}
+	decimals, err := j.getDecimals(ctx, []string{inputMint, outputMint})
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	in, err := strconv.ParseFloat(quote.InAmount, 64)
+	if err != nil {
+		return Estimate{}, err
+	}
+	out, err := strconv.ParseFloat(quote.OutAmount, 64)
+	if err != nil {
+		return Estimate{}, err
+	}
+	impact, _ := strconv.ParseFloat(quote.PriceImpactPct, 64)
+
+	return Estimate{
+		InAmount:       in / math.Pow(10, float64(decimals[inputMint])),
+		OutAmount:      out / math.Pow(10, float64(decimals[outputMint])),
+		PriceImpactPct: impact,
 	}, nil
 }
 
-// SubmitSwap interacts with Jupiter to "place an order" given the parameters - it strives for high order success
-func (j *Jupiter) SubmitSwap(ctx context.Context, baseCurrency string, quoteCurrency string, amount float64) (string, error) {
-	// 1) Get a quote from Jupiter that can be used to form a swap request
+// getQuote fetches a quote from Jupiter that can be used to form a swap request - a positive slippageBps fixes the
+// slippage tolerance, otherwise Jupiter picks it automatically
+func (j *Jupiter) getQuote(ctx context.Context, inputMint string, outputMint string, amount float64,
+	slippageBps int) (jl.QuoteResponse, error) {
 	// Convert the input amount to use the asset's most basic unit
-	unitAmount, err := j.convertToUnitAmount(baseCurrency, amount)
+	unitAmount, err := j.convertToUnitAmount(ctx, inputMint, amount)
 	if err != nil {
-		return "", err
+		return jl.QuoteResponse{}, err
 	}
+	return j.getUnitQuote(ctx, inputMint, outputMint, unitAmount, slippageBps)
+}
+
+// getUnitQuote is getQuote for an amount already in the input asset's base units
+func (j *Jupiter) getUnitQuote(ctx context.Context, inputMint string, outputMint string, unitAmount int64,
+	slippageBps int) (jl.QuoteResponse, error) {
 	// Configure options for the quote - most of which are to manage slippage to ensure swaps are accepted
 	autoSlippage := true
 	dynamicSlippageToggle := true
 	preferLiquidDexes := true
-	// Get the quote from Jupiter
-	getQuoteResponse, err := j.jc.GetQuoteWithResponse(ctx, &jl.GetQuoteParams{
-		InputMint:         baseCurrency,
-		OutputMint:        quoteCurrency,
+	params := &jl.GetQuoteParams{
+		InputMint:         inputMint,
+		OutputMint:        outputMint,
 		Amount:            unitAmount,
 		AutoSlippage:      &autoSlippage,
 		DynamicSlippage:   &dynamicSlippageToggle,
 		PreferLiquidDexes: &preferLiquidDexes,
-	})
-	if err != nil {
-		return "", err
 	}
-	if getQuoteResponse.JSON200 == nil {
-		return "", fmt.Errorf("could not get quote with error: %s", string(getQuoteResponse.Body))
+	if feeBps := j.cfg.PlatformFeeBps; feeBps > 0 {
+		params.PlatformFeeBps = &feeBps
+	}
+	if j.sq != nil {
+		maxAccounts := squadsMaxAccounts
+		params.MaxAccounts = &maxAccounts
+	}
+	if slippageBps > 0 {
+		autoSlippage = false
+		dynamicSlippageToggle = false
+		params.SlippageBps = &slippageBps
+	}
+	// Get the quote from Jupiter
+	start := time.Now()
+	getQuoteResponse, err := j.jc.GetQuoteWithResponse(ctx, params)
+	if err == nil && getQuoteResponse.JSON200 == nil {
+		err = fmt.Errorf("could not get quote with error: %s", string(getQuoteResponse.Body))
+	}
+	j.lat.Observe(latency.QuoteApi, start, err)
+	if err != nil {
+		return jl.QuoteResponse{}, err
 	}
-	quote := *getQuoteResponse.JSON200
+	return *getQuoteResponse.JSON200, nil
+}
 
-	// 2) Get a swap transaction based on the quote that can be signed and broadcast to the network
+// swapQuote gets a swap transaction based on the quote, then signs and broadcasts it to the network unless the
+// deadline has passed by then - dynamic lets Jupiter adjust the quote's slippage at swap time, and tag is the order
+// the swap's memo names
+func (j *Jupiter) swapQuote(ctx context.Context, quote jl.QuoteResponse, deadline time.Time, dynamic bool,
+	tag string) (string, error) {
 	// Configure options to follow recommendations for highest success probability
+	congestion := j.congested()
+	fee := `"auto"`
+	if congestion.RaiseFees {
+		fee = fmt.Sprintf(`{"autoMultiplier":%d}`, j.autoFeeMultiplier())
+	}
 	prioritizationFeeLamports := jl.SwapRequest_PrioritizationFeeLamports{}
-	if err = prioritizationFeeLamports.UnmarshalJSON([]byte(`"auto"`)); err != nil {
+	if err := prioritizationFeeLamports.UnmarshalJSON([]byte(fee)); err != nil {
 		return "", err
 	}
 	dynamicComputeUnitLimit := true
 	maxBps := 500
+	if congestion.WidenSlippage {
+		maxBps = j.cfg.CongestionSlippageMaxBps
+	}
 	minBps := 0
 	dynamicSlippage := struct {
 		MaxBps *int `json:"maxBps,omitempty"`
@@ -133,138 +381,370 @@ func (j *Jupiter) SubmitSwap(ctx context.Context, baseCurrency string, quoteCurr
 		MaxBps: &maxBps,
 		MinBps: &minBps,
 	}
-	// Get the swap transaction from Jupiter
-	postSwapResponse, err := j.jc.PostSwapWithResponse(ctx, jl.PostSwapJSONRequestBody{
-		UserPublicKey:             j.pk.String(),
+	j.mu.RLock()
+	sc, pk := j.sc, *j.pk
+	j.mu.RUnlock()
+	if sc == nil {
+		return "", fmt.Errorf("no wallet key is loaded to sign swaps with")
+	}
+
+	// Proposed, the swap is made for the vault, which the multisig signs for once it executes
+	if j.sq != nil {
+		pk = j.sq.vault
+	}
+	body := jl.PostSwapJSONRequestBody{
+		UserPublicKey:             pk.String(),
 		QuoteResponse:             quote,
 		DynamicComputeUnitLimit:   &dynamicComputeUnitLimit,
 		PrioritizationFeeLamports: &prioritizationFeeLamports,
-		DynamicSlippage:           &dynamicSlippage,
-	})
+	}
+	if dynamic {
+		body.DynamicSlippage = &dynamicSlippage
+	}
+	// Funded by delegation, the swap's input is pulled from the funding wallet first and its output lands there
+	if j.Delegated() {
+		amount, err := strconv.ParseUint(quote.InAmount, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		if err = j.pullDelegated(ctx, quote.InputMint, amount, tag); err != nil {
+			return "", err
+		}
+		destination, err := j.fundingAccount(quote.OutputMint)
+		if err != nil {
+			return "", err
+		}
+		d, wrap := destination.String(), false
+		body.DestinationTokenAccount = &d
+		body.WrapAndUnwrapSol = &wrap
+	}
+	if quote.PlatformFee != nil && j.cfg.PlatformFeeBps > 0 {
+		feeAccount, err := platformFeeAccount(j.cfg.PlatformReferralAccount, quote.OutputMint)
+		if err != nil {
+			return "", err
+		}
+		fa := feeAccount.String()
+		body.FeeAccount = &fa
+	}
+
+	// Get the swap transaction from Jupiter
+	start := time.Now()
+	postSwapResponse, err := j.jc.PostSwapWithResponse(ctx, body)
+	if err == nil && postSwapResponse.JSON200 == nil {
+		err = fmt.Errorf("could not get swap response with error: %s", string(postSwapResponse.Body))
+	}
+	j.lat.Observe(latency.SwapApi, start, err)
 	if err != nil {
 		return "", err
 	}
-	if postSwapResponse.JSON200 == nil {
-		return "", fmt.Errorf("could not get swap response with error: %s", string(postSwapResponse.Body))
-	}
 	swap := *postSwapResponse.JSON200
+	// The memo is added before the compute budget is simulated so its compute is accounted for - a swap the memo
+	// cannot be added to is still sent untagged rather than failing the trade, and kept to be reported
+	if j.cfg.SwapMemo && tag != "" {
+		if tagged, err := tagSwap(swap.SwapTransaction, swapMemo(j.cfg.StrategyId, tag)); err == nil {
+			swap.SwapTransaction = tagged
+		} else {
+			j.sentMu.Lock()
+			j.untagged = append(j.untagged, UntaggedSwap{Tag: tag, Err: err})
+			j.sentMu.Unlock()
+		}
+	}
+	if j.sq != nil {
+		if late := time.Since(deadline); late >= 0 {
+			return "", fmt.Errorf("%w: %s past its %s deadline", ErrQuoteExpired, late.Round(time.Millisecond),
+				j.cfg.QuoteTtl)
+		}
+		return j.propose(ctx, swap.SwapTransaction, quote)
+	}
+	txBase64, budget, err := j.budgetCompute(ctx, swap.SwapTransaction)
+	if err != nil {
+		return "", err
+	}
+
+	// A quote that aged past its deadline would most likely fail on slippage, so it is never sent
+	if late := time.Since(deadline); late >= 0 {
+		return "", fmt.Errorf("%w: %s past its %s deadline", ErrQuoteExpired, late.Round(time.Millisecond), j.cfg.QuoteTtl)
+	}
+
+	if err = j.ch.fail(FaultBlockhashExpiry); err != nil {
+		return "", err
+	}
+
+	// Keep the blockhash the swap expires with, to tell a dropped swap from a slow one
+	var blockhash solana.Hash
+	if tx, err := sl.NewTransactionFromBase64(txBase64); err == nil {
+		blockhash = tx.Message.RecentBlockhash
+	}
 
 	// Sign and send the transaction to the network
-	txId, err := j.sc.SendTransactionOnChain(ctx, swap.SwapTransaction)
+	start = time.Now()
+	txId, err := sc.SendTransactionOnChain(ctx, txBase64)
+	j.lat.Observe(latency.Rpc, start, err)
 	if err != nil {
 		return "", err
 	}
+	j.sentMu.Lock()
+	j.budgets[string(txId)] = budget
+	j.quotes[string(txId)] = sentQuote{quote: quote, sentAt: time.Now(), blockhash: blockhash}
+	for id, q := range j.quotes {
+		// Swaps that never confirmed are never collected
+		if time.Since(q.sentAt) > time.Hour {
+			delete(j.quotes, id)
+		}
+	}
+	j.sentMu.Unlock()
 
 	// Return the transaction ID for monitoring
 	return string(txId), nil
 }
 
+// ComputeBudget returns, once, the compute budget a swap sent by this client was submitted with
+func (j *Jupiter) ComputeBudget(txId string) (common.ComputeBudget, bool) {
+	j.sentMu.Lock()
+	defer j.sentMu.Unlock()
+	budget, ok := j.budgets[txId]
+	delete(j.budgets, txId)
+	return budget, ok
+}
+
+// Latency returns the rolling latency and error statistics of every external dependency the client calls
+func (j *Jupiter) Latency() []latency.Stats {
+	return j.lat.Stats()
+}
+
 // GetPrice returns the dollar (USDC) price of a given currency
-func (j *Jupiter) GetPrice(currency string) (float64, error) {
-	prices, err := j.getPrices([]string{currency})
+func (j *Jupiter) GetPrice(ctx context.Context, currency string) (float64, error) {
+	price, _, err := j.PriceSample(ctx, currency)
+	return price, err
+}
+
+// PriceSample is GetPrice with when the pricing endpoint produced the price - a response served from a cache in front
+// of the endpoint is dated by when the cache got it, not when it was served
+func (j *Jupiter) PriceSample(ctx context.Context, currency string) (float64, time.Time, error) {
+	prices, produced, err := j.getPrices(ctx, []string{currency})
 	if err != nil {
-		return 0, err
+		return 0, time.Time{}, err
 	}
 	priceData, ok := prices[currency]
 	if !ok {
-		return 0, fmt.Errorf("no prices for %s", currency)
+		return 0, time.Time{}, fmt.Errorf("no prices for %s", currency)
 	}
-	return strconv.ParseFloat(priceData.Price, 64)
+	price, err := strconv.ParseFloat(priceData.Price, 64)
+	return price, produced, err
 }
 
-// MonitorTx follows a submitted transaction through its commitment status for logging/tracking orders
-func (j *Jupiter) MonitorTx(ctx context.Context, txId string, log logger.Logger) {
-	var (
-		res    sl.MonitorResponse
-		err    error
-		stages = []sl.CommitmentStatus{
-			sl.CommitmentProcessed,
-			sl.CommitmentConfirmed,
-			sl.CommitmentFinalized,
-		}
-	)
+// CheckRpc asks the RPC node whether it is healthy, so setups can be verified before they trade
+func (j *Jupiter) CheckRpc(ctx context.Context) error {
+	start := time.Now()
+	health, err := j.rc.GetHealth(ctx)
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return err
+	}
+	if health != rpc.HealthOk {
+		return fmt.Errorf("rpc node reports %q", health)
+	}
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(j.cfg.CommitmentTimeoutSeconds))
-	defer cancel()
+// SolBalance returns the wallet's native SOL balance, which pays transaction fees and token account rent
+func (j *Jupiter) SolBalance(ctx context.Context) (float64, error) {
+	if err := j.ch.fail(FaultRpc429); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	res, err := j.rc.GetBalance(ctx, j.PublicKey(), rpc.CommitmentConfirmed)
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return 0, err
+	}
+	return float64(res.Value) / float64(solana.LAMPORTS_PER_SOL), nil
+}
 
-	count := 0
-	stageIndex := 0
-	for count < j.cfg.MaxRetriesTxMonitor {
-		// Give time between retries to allow for transaction propagation
-		time.Sleep(5 * time.Second)
-		// Count tries at the top of the loop to allow using `continue` for errors
-		count++
-
-		// Check if the transaction has reached the current stage evaluated
-		if res, err = j.smn.WaitForCommitmentStatus(ctx, sl.TxID(txId), stages[stageIndex]); err != nil {
-			continue
+// TokenBalance returns how much of the mint the wallet holds across its token accounts - for wrapped SOL the native
+// balance is included, since swaps wrap and unwrap it as needed
+func (j *Jupiter) TokenBalance(ctx context.Context, mint string) (float64, error) {
+	mintPk, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return 0, err
+	}
+	if err = j.ch.fail(FaultRpc429); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	accounts, err := j.rc.GetTokenAccountsByOwner(ctx, j.PublicKey(), &rpc.GetTokenAccountsConfig{Mint: &mintPk},
+		&rpc.GetTokenAccountsOpts{Commitment: rpc.CommitmentConfirmed})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, a := range accounts.Value {
+		start := time.Now()
+		res, err := j.rc.GetTokenAccountBalance(ctx, a.Pubkey, rpc.CommitmentConfirmed)
+		j.lat.Observe(latency.Rpc, start, err)
+		if err != nil {
+			return 0, err
 		}
-		if res.InstructionErr != nil {
+		if res.Value == nil {
 			continue
 		}
+		amount, err := strconv.ParseFloat(res.Value.UiAmountString, 64)
+		if err != nil {
+			return 0, err
+		}
+		total += amount
+	}
 
-		// Progress to the next stage on success - stop if all stages have been validated
-		stageIndex++
-		if stageIndex >= len(stages) {
-			break
+	if mintPk.Equals(solana.SolMint) {
+		native, err := j.SolBalance(ctx)
+		if err != nil {
+			return 0, err
 		}
+		total += native
 	}
+	return total, nil
+}
 
-	// Alert that the commitment status was not able to be confirmed as successful
-	if count >= j.cfg.MaxRetriesTxMonitor {
-		log.Error().Msg("could not get commitment status after %d retries for %s", j.cfg.MaxRetriesTxMonitor, txId)
-		return
+// MonitorTx follows a submitted transaction through its commitment status for logging/tracking orders - reached, when
+// not nil, is called with each stage the transaction gets to - its status is read by the batched poll shared by every
+// transaction in flight
+func (j *Jupiter) MonitorTx(ctx context.Context, txId string, log logger.Logger, reached func(stage string)) error {
+	sig, err := solana.SignatureFromBase58(txId)
+	if err != nil {
+		return err
+	}
+	stages := []rpc.ConfirmationStatusType{
+		rpc.ConfirmationStatusProcessed,
+		rpc.ConfirmationStatusConfirmed,
+		rpc.ConfirmationStatusFinalized,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, j.cfg.CommitmentTimeout)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(j.ch.confirmDelay()):
+	}
+	updates, unwatch := j.st.watch(sig)
+	defer unwatch()
+
+	stageIndex := 0
+	for {
+		select {
+		case <-ctx.Done():
+			// Alert that the commitment status was not able to be confirmed as successful
+			log.Error().Msg("could not get commitment status within %s for %s", j.cfg.CommitmentTimeout, txId)
+			return fmt.Errorf("could not get commitment status within %s for %s", j.cfg.CommitmentTimeout, txId)
+		case status := <-updates:
+			if status.Err != nil {
+				log.Error().Msg("transaction %s failed: %v", txId, status.Err)
+				return fmt.Errorf("transaction %s failed: %v", txId, status.Err)
+			}
+			// Progress through every stage the status got to - a poll may find the transaction several stages on
+			for stageIndex < len(stages) && slices.Index(stages, status.ConfirmationStatus) >= stageIndex {
+				if reached != nil {
+					reached(string(stages[stageIndex]))
+				}
+				stageIndex++
+			}
+			if stageIndex >= len(stages) {
+				// Alert that the commitment status was confirmed as successful and finalized
+				log.Info().Msg("commitment status is finalized for transaction %s", txId)
+				return nil
+			}
+		}
 	}
-	// Alert that the commitment status was confirmed as successful and finalized
-	log.Info().Msg("commitment status is finalized for transaction %s", txId)
 }
 
 // getPrices interacts with the Jupiter pricing endpoint to retrieve pricing data for selected assets
-func (j *Jupiter) getPrices(tokenAddresses []string) (map[string]PriceData, error) {
+func (j *Jupiter) getPrices(ctx context.Context, tokenAddresses []string) (map[string]PriceData, time.Time, error) {
+	start := time.Now()
+	prices, produced, err := j.fetchPrices(ctx, tokenAddresses)
+	if err == nil {
+		err = j.ch.fail(FaultPriceDrop)
+	}
+	j.lat.Observe(latency.PriceApi, start, err)
+	return prices, produced, err
+}
+
+// fetchPrices requests the pricing data for the assets from the pricing endpoint
+func (j *Jupiter) fetchPrices(ctx context.Context, tokenAddresses []string) (map[string]PriceData, time.Time, error) {
 	params := url.Values{}
 	params.Add("ids", strings.Join(tokenAddresses, ","))
 
 	u := priceEndpoint + "?" + params.Encode()
-	res, err := http.Get(u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
+	}
+	res, err := j.tr.hc.Do(req)
+	if err != nil {
+		return nil, time.Time{}, err
 	}
 	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
 	var getPriceResponse GetPriceResponse
 	err = json.Unmarshal(body, &getPriceResponse)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
-	return getPriceResponse.Data, nil
+	return getPriceResponse.Data, responseProduced(res), nil
 }
 
-// convertToUnitAmount converts a fractional token amount to its base unit representation
-func (j *Jupiter) convertToUnitAmount(currency string, amount float64) (int64, error) {
-	decimals, err := j.getDecimals([]string{currency})
+// convertToUnitAmount converts a fractional token amount to its base unit representation with the configured
+// rounding, refusing an amount too small to be an order
+func (j *Jupiter) convertToUnitAmount(ctx context.Context, currency string, amount float64) (int64, error) {
+	decimals, err := j.getDecimals(ctx, []string{currency})
 	if err != nil {
 		return 0, err
 	}
-	unitMultiplier := math.Pow(10, float64(decimals[currency]))
-	return int64(amount * unitMultiplier), nil
+	units := toUnits(amount, decimals[currency], j.cfg.AmountRounding)
+	return units, j.checkOrderSize(currency, amount, units)
+}
+
+// responseProduced returns when the server produced the response - its date, less the age a cache in front of the
+// server reports having held it for - or now when the response is undated
+func responseProduced(res *http.Response) time.Time {
+	produced, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return time.Now()
+	}
+	if age, err := strconv.Atoi(res.Header.Get("Age")); err == nil && age > 0 {
+		produced = produced.Add(-time.Duration(age) * time.Second)
+	}
+	return produced
 }
 
-// getDecimals returns the precision available for given assets
-func (j *Jupiter) getDecimals(tokenAddresses []string) (map[string]int, error) {
+// getDecimals returns the precision of the given assets, as the token list reports it through the token cache -
+// assets the token list cannot tell fall back to the precision of their price
+func (j *Jupiter) getDecimals(ctx context.Context, tokenAddresses []string) (map[string]int, error) {
+	decimals := make(map[string]int)
+	var unknown []string
+	for _, token := range tokenAddresses {
+		if info, err := j.TokenInfo(ctx, token); err == nil && info.Address != "" {
+			decimals[token] = info.Decimals
+			continue
+		}
+		unknown = append(unknown, token)
+	}
+	if len(unknown) == 0 {
+		return decimals, nil
+	}
+
 	// Confirmed through manual testing that the pricing endpoint returns the price with full precision, so it can be
 	// used to derive the precision value
-	prices, err := j.getPrices(tokenAddresses)
+	prices, _, err := j.getPrices(ctx, unknown)
 	if err != nil {
 		return nil, err
 	}
-
-	decimals := make(map[string]int)
 	for token, priceData := range prices {
 		priceParts := strings.Split(priceData.Price, ".")
 		if len(priceParts) != 2 {
@@ -0,0 +1,100 @@
+package jupiter
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	sl "github.com/ilkamo/jupiter-go/solana"
+)
+
+// memoProgram is the SPL Memo program (v2), which logs its instruction data and needs no accounts
+var memoProgram = solana.MustPublicKeyFromBase58("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+
+// memoComputeUnits is the compute the memo instruction is allowed on top of the limit Jupiter set for the swap
+const memoComputeUnits = 10_000
+
+// maxTransactionSize is the largest serialized transaction the network accepts
+const maxTransactionSize = 1232
+
+// swapMemoPrefix starts every swap memo
+const swapMemoPrefix = "ninetyfive strategy="
+
+// swapMemo is the memo tagging a swap with the strategy and the order it fills, matching the ledger's order ids
+func swapMemo(strategy string, tag string) string {
+	return fmt.Sprintf("%s%s order=%s", swapMemoPrefix, strategy, tag)
+}
+
+// UntaggedSwap is a swap sent without its memo because the memo could not be added to its transaction
+type UntaggedSwap struct {
+	Tag string // the order the swap fills
+	Err error  // why the memo could not be added
+}
+
+// TakeUntaggedSwaps returns the swaps sent untagged since the last call and forgets them
+func (j *Jupiter) TakeUntaggedSwaps() []UntaggedSwap {
+	j.sentMu.Lock()
+	defer j.sentMu.Unlock()
+	untagged := j.untagged
+	j.untagged = nil
+	return untagged
+}
+
+// tagSwap appends a memo instruction to the unsigned swap transaction, so its on-chain history can be reconciled
+// with the ledger - the Memo program becomes the last static account, which shifts the indexes of the accounts
+// loaded from lookup tables by one, and the compute unit limit grows to pay for the memo
+func tagSwap(txBase64 string, memo string) (string, error) {
+	tx, err := sl.NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", err
+	}
+	m := &tx.Message
+
+	program := -1
+	for i, key := range m.AccountKeys {
+		if key.Equals(memoProgram) {
+			program = i
+		}
+	}
+	if program < 0 {
+		program = len(m.AccountKeys)
+		for i := range m.Instructions {
+			ix := &m.Instructions[i]
+			if int(ix.ProgramIDIndex) >= program {
+				ix.ProgramIDIndex++
+			}
+			for j, account := range ix.Accounts {
+				if int(account) >= program {
+					ix.Accounts[j]++
+				}
+			}
+		}
+		m.AccountKeys = append(m.AccountKeys, memoProgram)
+		m.Header.NumReadonlyUnsignedAccounts++
+	}
+	m.Instructions = append(m.Instructions, solana.CompiledInstruction{
+		ProgramIDIndex: uint16(program),
+		Accounts:       []uint16{},
+		Data:           []byte(memo),
+	})
+
+	for _, ix := range computeBudgetInstructions(tx) {
+		data := m.Instructions[ix].Data
+		if data[0] == setComputeUnitLimit && len(data) >= 5 {
+			limit := binary.LittleEndian.Uint32(data[1:5]) + memoComputeUnits
+			data = append([]byte{}, data...)
+			binary.LittleEndian.PutUint32(data[1:5], min(limit, maxComputeUnits))
+			m.Instructions[ix].Data = data
+		}
+	}
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	if len(raw) > maxTransactionSize {
+		return "", fmt.Errorf("the memo would grow the swap transaction to %d bytes, over the %d byte limit", len(raw),
+			maxTransactionSize)
+	}
+	return tx.ToBase64()
+}
@@ -0,0 +1,62 @@
+package jupiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// MintInfo is what the chain and Jupiter's token list say about a mint - a mint whose account was closed no longer
+// exists, and the token list may not know a mint at all
+type MintInfo struct {
+	Mint     string `json:"mint"`
+	Exists   bool   `json:"exists"`
+	Decimals int    `json:"decimals"`
+	Symbol   string `json:"symbol,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// MintInfo reads the mint account and looks the mint up in the token list - only a failed read of the account is an
+// error, as the token list is best effort
+func (j *Jupiter) MintInfo(ctx context.Context, mint string) (MintInfo, error) {
+	pk, err := solana.PublicKeyFromBase58(mint)
+	if err != nil {
+		return MintInfo{}, err
+	}
+	start := time.Now()
+	res, err := j.rc.GetAccountInfoWithOpts(ctx, pk, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingJSONParsed,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	info := MintInfo{Mint: mint}
+	if err == rpc.ErrNotFound || (err == nil && (res == nil || res.Value == nil)) {
+		return info, nil
+	}
+	if err != nil {
+		return MintInfo{}, fmt.Errorf("could not read mint %s: %w", mint, err)
+	}
+	var data struct {
+		Parsed struct {
+			Type string `json:"type"`
+			Info struct {
+				Decimals int `json:"decimals"`
+			} `json:"info"`
+		} `json:"parsed"`
+	}
+	if res.Value.Data == nil || json.Unmarshal(res.Value.Data.GetRawJSON(), &data) != nil || data.Parsed.Type != "mint" {
+		return MintInfo{}, fmt.Errorf("account %s is not a token mint", mint)
+	}
+	info.Exists, info.Decimals = true, data.Parsed.Info.Decimals
+
+	if token, err := j.TokenInfo(ctx, mint); err == nil {
+		info.Symbol, info.Name = token.Symbol, token.Name
+	}
+	return info, nil
+}
@@ -0,0 +1,75 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// performanceSamples is how many of the cluster's per-minute performance samples slot times are averaged over
+const performanceSamples = 5
+
+// NetworkStats is what the cluster's recent performance and fee market say about congestion
+type NetworkStats struct {
+	SlotTime    time.Duration `json:"slot_time"`    // average over the last few minutes, about 400ms when healthy
+	PriorityFee uint64        `json:"priority_fee"` // median recently paid, in micro-lamports per compute unit
+}
+
+// Congestion is how swaps adapt while the network is congested
+type Congestion struct {
+	RaiseFees     bool
+	WidenSlippage bool
+}
+
+// NetworkStats samples the cluster's recent slot times and priority fees
+func (j *Jupiter) NetworkStats(ctx context.Context) (NetworkStats, error) {
+	var stats NetworkStats
+	limit := uint(performanceSamples)
+	start := time.Now()
+	samples, err := j.rc.GetRecentPerformanceSamples(ctx, &limit)
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return stats, fmt.Errorf("could not get recent performance samples: %w", err)
+	}
+	var slots uint64
+	var seconds uint64
+	for _, s := range samples {
+		slots += s.NumSlots
+		seconds += uint64(s.SamplePeriodSecs)
+	}
+	if slots > 0 {
+		stats.SlotTime = time.Duration(float64(seconds) / float64(slots) * float64(time.Second))
+	}
+
+	start = time.Now()
+	fees, err := j.rc.GetRecentPrioritizationFees(ctx, nil)
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return stats, fmt.Errorf("could not get recent priority fees: %w", err)
+	}
+	stats.PriorityFee, _ = feePercentile(fees, 50)
+	return stats, nil
+}
+
+// SetCongestion changes how swaps adapt to congestion - raising fees multiplies the priority fee within its congestion
+// cap, and widening slippage lets Jupiter's dynamic slippage go up to its congestion cap
+func (j *Jupiter) SetCongestion(c Congestion) {
+	j.congestionMu.Lock()
+	defer j.congestionMu.Unlock()
+	j.congestion = c
+}
+
+// congested returns how swaps currently adapt to congestion
+func (j *Jupiter) congested() Congestion {
+	j.congestionMu.RLock()
+	defer j.congestionMu.RUnlock()
+	return j.congestion
+}
+
+// autoFeeMultiplier is the multiplier of Jupiter's automatic priority fee while fees are raised
+func (j *Jupiter) autoFeeMultiplier() int {
+	return max(1, int(math.Ceil(j.cfg.CongestionFeeMultiplier)))
+}
@@ -0,0 +1,144 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// paperStages are the commitment stages a paper swap goes through, as a real one does
+var paperStages = []rpc.CommitmentType{rpc.CommitmentProcessed, rpc.CommitmentConfirmed, rpc.CommitmentFinalized}
+
+// paperSwap is a swap the paper swapper pretended to send, with the quote it fills at and how it ends
+type paperSwap struct {
+	quote   *Quote
+	outcome common.TxStatus
+}
+
+// Paper stands in for the wrapper's swaps in paper mode - swaps are quoted but never sent, and go through synthetic
+// commitment stages that may fail or be dropped, so the order pipeline's notifications, ledger updates, and recovery
+// run end to end without touching mainnet
+type Paper struct {
+	j           *Jupiter
+	delay       time.Duration
+	failureRate float64
+
+	mu    sync.Mutex
+	seq   int
+	swaps map[string]paperSwap
+}
+
+// NewPaper creates the paper swapper on top of the wrapper, which it only quotes with
+func NewPaper(j *Jupiter, cfg *configs.Config) *Paper {
+	return &Paper{
+		j:           j,
+		delay:       cfg.PaperConfirmDelay,
+		failureRate: cfg.PaperFailureRate,
+		swaps:       make(map[string]paperSwap),
+	}
+}
+
+// Simulated reports that the swapper's fills are simulated
+func (p *Paper) Simulated() bool {
+	return true
+}
+
+// Execute quotes the swap and returns a synthetic transaction id for it - whether it lands, fails, or is dropped is
+// drawn now, half of the failures each way
+func (p *Paper) Execute(ctx context.Context, pair common.Pair, side common.Signal, size float64,
+	tag string) (string, error) {
+	q, err := p.j.Quote(ctx, pair, side, size)
+	if err != nil {
+		return "", err
+	}
+	outcome := common.TxLanded
+	if rand.Float64() < p.failureRate {
+		outcome = common.TxFailed
+		if rand.IntN(2) == 0 {
+			outcome = common.TxDropped
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	txId := fmt.Sprintf("paper-%d-%d", time.Now().UnixNano(), p.seq)
+	p.swaps[txId] = paperSwap{quote: q, outcome: outcome}
+	return txId, nil
+}
+
+// MonitorTx walks a paper swap through the commitment stages, one delay apart, calling reached with each - a dropped
+// swap never reaches the first stage and a failed one stops after it, as a swap with an instruction error does
+func (p *Paper) MonitorTx(ctx context.Context, txId string, log logger.Logger, reached func(stage string)) error {
+	p.mu.Lock()
+	swap, ok := p.swaps[txId]
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("paper swap %s is unknown", txId)
+	}
+
+	for i, stage := range paperStages {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.delay):
+		}
+		if swap.outcome == common.TxDropped || (swap.outcome == common.TxFailed && i > 0) {
+			log.Error().Msg("could not get commitment status of paper swap %s past %s", txId, stage)
+			return fmt.Errorf("could not get commitment status of paper swap %s past %s", txId, stage)
+		}
+		log.Info().Msg("paper swap %s reached %s commitment", txId, stage)
+		if reached != nil {
+			reached(string(stage))
+		}
+	}
+	log.Info().Msg("commitment status is finalized for transaction %s", txId)
+	return nil
+}
+
+// Resolve returns how the paper swap was drawn to end - one that did not land is forgotten, as it has no fill to read
+func (p *Paper) Resolve(ctx context.Context, txId string) (common.TxStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	swap, ok := p.swaps[txId]
+	if !ok {
+		return "", fmt.Errorf("paper swap %s is unknown", txId)
+	}
+	if swap.outcome != common.TxLanded {
+		delete(p.swaps, txId)
+	}
+	return swap.outcome, nil
+}
+
+// Fill returns the paper swap's fill, exactly as quoted - it is read once
+func (p *Paper) Fill(ctx context.Context, txId string) (common.Fill, error) {
+	p.mu.Lock()
+	swap, ok := p.swaps[txId]
+	delete(p.swaps, txId)
+	p.mu.Unlock()
+	if !ok {
+		return common.Fill{}, fmt.Errorf("paper swap %s is unknown", txId)
+	}
+	q := swap.quote
+	return common.Fill{
+		InputMint:  q.InputMint,
+		OutputMint: q.OutputMint,
+		InAmount:   q.InAmount,
+		OutAmount:  q.OutAmount,
+		QuotedIn:   q.InAmount,
+		QuotedOut:  q.OutAmount,
+	}, nil
+}
+
+// ComputeBudget implements the swapper - paper swaps request no compute
+func (p *Paper) ComputeBudget(txId string) (common.ComputeBudget, bool) {
+	return common.ComputeBudget{}, false
+}
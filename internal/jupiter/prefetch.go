@@ -0,0 +1,109 @@
+package jupiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	jl "github.com/ilkamo/jupiter-go/jupiter"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// prefetchKey identifies a prefetched quote by the swap it prices
+type prefetchKey struct {
+	inputMint  string
+	outputMint string
+	amount     float64
+}
+
+// prefetched is a quote fetched ahead of a decision
+type prefetched struct {
+	resp     jl.QuoteResponse
+	quotedAt time.Time
+}
+
+// PrefetchMiss is a swap that found a quote prefetched for its mints, but for another amount, and was quoted again
+type PrefetchMiss struct {
+	InputMint  string
+	OutputMint string
+	Prefetched float64 // the amount the quote was prefetched for
+	Amount     float64 // the amount swapped
+}
+
+// TakePrefetchMisses returns the misses since the last call and forgets them
+func (j *Jupiter) TakePrefetchMisses() []PrefetchMiss {
+	j.prefetchMu.Lock()
+	defer j.prefetchMu.Unlock()
+	misses := j.prefetchMisses
+	j.prefetchMisses = nil
+	return misses
+}
+
+// Prefetch quotes each side of the pair for its amount in parallel, bounded by the prefetch max age, and keeps the
+// quotes so a signal of exactly that amount is priced and swapped without another quote round trip - sides with no
+// amount are skipped, and the quotes of a previous prefetch are replaced
+func (j *Jupiter) Prefetch(ctx context.Context, pair common.Pair, amounts map[common.Signal]float64) error {
+	ctx, cancel := context.WithTimeout(ctx, j.cfg.QuotePrefetchMaxAge)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		fetched = make(map[prefetchKey]prefetched)
+	)
+	for side, amount := range amounts {
+		if amount <= 0 {
+			continue
+		}
+		inputMint, outputMint, err := pair.Mints(side)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := j.getQuote(ctx, inputMint, outputMint, amount, 0)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			fetched[prefetchKey{inputMint, outputMint, amount}] = prefetched{resp: resp, quotedAt: time.Now()}
+		}()
+	}
+	wg.Wait()
+
+	j.prefetchMu.Lock()
+	j.prefetched = fetched
+	j.prefetchMu.Unlock()
+	return errors.Join(errs...)
+}
+
+// takePrefetched returns the prefetched quote of the swap while it is younger than the prefetch max age, forgetting
+// it when consume is set so a swap never reuses the quote another swap was sent with - a swap finding a quote for its
+// mints but another amount is recorded as a miss
+func (j *Jupiter) takePrefetched(inputMint string, outputMint string, amount float64,
+	consume bool) (jl.QuoteResponse, time.Time, bool) {
+	key := prefetchKey{inputMint, outputMint, amount}
+	j.prefetchMu.Lock()
+	defer j.prefetchMu.Unlock()
+	p, ok := j.prefetched[key]
+	if !ok && consume {
+		for k := range j.prefetched {
+			if k.inputMint == inputMint && k.outputMint == outputMint {
+				j.prefetchMisses = append(j.prefetchMisses, PrefetchMiss{inputMint, outputMint, k.amount, amount})
+			}
+		}
+	}
+	if !ok || time.Since(p.quotedAt) >= j.cfg.QuotePrefetchMaxAge {
+		return jl.QuoteResponse{}, time.Time{}, false
+	}
+	if consume {
+		delete(j.prefetched, key)
+	}
+	return p.resp, p.quotedAt, true
+}
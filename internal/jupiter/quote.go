@@ -0,0 +1,118 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// RouteHop is one leg of the route Jupiter found for a swap
+type RouteHop struct {
+	Label      string `json:"label"`
+	AmmKey     string `json:"ammKey"`
+	Percent    int    `json:"percent"` // share of the input routed through this leg
+	InputMint  string `json:"inputMint"`
+	OutputMint string `json:"outputMint"`
+}
+
+// Quote is what Jupiter currently offers for swapping an amount on a pair, in human (not base unit) amounts
+type Quote struct {
+	Pair           common.Pair   `json:"pair"`
+	Side           common.Signal `json:"side"`
+	InputMint      string        `json:"inputMint"`
+	OutputMint     string        `json:"outputMint"`
+	InAmount       float64       `json:"inAmount"`
+	OutAmount      float64       `json:"outAmount"`
+	Price          float64       `json:"price"` // effective price of the base asset in the quote asset
+	PriceImpactPct float64       `json:"priceImpactPct"`
+	SlippageBps    int           `json:"slippageBps"`
+	Route          []RouteHop    `json:"route"`
+	ContextSlot    uint64        `json:"contextSlot,omitempty"`
+	QuotedAt       time.Time     `json:"quotedAt"`
+	ExpiresAt      time.Time     `json:"expiresAt"`
+}
+
+// Expired reports whether the quote is past its expiration at now
+func (q *Quote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// RouteSummary describes the route as its venues in order, e.g. "Orca (100%) -> Raydium (100%)"
+func (q *Quote) RouteSummary() string {
+	if len(q.Route) == 0 {
+		return "no route"
+	}
+	hops := make([]string, 0, len(q.Route))
+	for _, h := range q.Route {
+		hops = append(hops, fmt.Sprintf("%s (%d%%)", h.Label, h.Percent))
+	}
+	return strings.Join(hops, " -> ")
+}
+
+// Quote asks Jupiter what swapping amount on the pair would achieve right now without executing it - BUY spends
+// amount of the quote asset for the base asset, SELL spends amount of the base asset for the quote asset
+func (j *Jupiter) Quote(ctx context.Context, pair common.Pair, side common.Signal, amount float64) (*Quote, error) {
+	inputMint, outputMint, err := pair.Mints(side)
+	if err != nil {
+		return nil, err
+	}
+	// A prefetched quote is left for the swap to send with, which is what makes prefetching save the round trip
+	resp, quotedAt, ok := j.takePrefetched(inputMint, outputMint, amount, false)
+	if !ok {
+		if resp, err = j.getQuote(ctx, inputMint, outputMint, amount, 0); err != nil {
+			return nil, err
+		}
+		quotedAt = time.Now()
+	}
+	decimals, err := j.getDecimals(ctx, []string{inputMint, outputMint})
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := strconv.ParseFloat(resp.InAmount, 64)
+	if err != nil {
+		return nil, err
+	}
+	out, err := strconv.ParseFloat(resp.OutAmount, 64)
+	if err != nil {
+		return nil, err
+	}
+	impact, _ := strconv.ParseFloat(resp.PriceImpactPct, 64)
+
+	q := &Quote{
+		Pair:           pair,
+		Side:           side,
+		InputMint:      inputMint,
+		OutputMint:     outputMint,
+		InAmount:       in / math.Pow(10, float64(decimals[inputMint])),
+		OutAmount:      out / math.Pow(10, float64(decimals[outputMint])),
+		PriceImpactPct: impact,
+		SlippageBps:    int(resp.SlippageBps),
+		Route:          make([]RouteHop, 0, len(resp.RoutePlan)),
+		QuotedAt:       quotedAt,
+		ExpiresAt:      quotedAt.Add(j.cfg.QuoteTtl),
+	}
+	if resp.ContextSlot != nil {
+		q.ContextSlot = uint64(*resp.ContextSlot)
+	}
+	for _, step := range resp.RoutePlan {
+		q.Route = append(q.Route, RouteHop{
+			Label:      step.SwapInfo.Label,
+			AmmKey:     step.SwapInfo.AmmKey,
+			Percent:    int(step.Percent),
+			InputMint:  step.SwapInfo.InputMint,
+			OutputMint: step.SwapInfo.OutputMint,
+		})
+	}
+
+	q.Price, err = Estimate{InAmount: q.InAmount, OutAmount: q.OutAmount}.EffectivePrice(side)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
@@ -0,0 +1,61 @@
+package jupiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// Resolve settles what became of a swap whose monitoring gave up - its status is looked up through the ledger
+// history, and a swap the cluster never saw is only taken as dropped once its blockhash expired, since until then it
+// may still land
+func (j *Jupiter) Resolve(ctx context.Context, txId string) (common.TxStatus, error) {
+	sig, err := solana.SignatureFromBase58(txId)
+	if err != nil {
+		return "", err
+	}
+
+	// Whether the blockhash expired is asked first, so a swap landing meanwhile shows up in its status
+	j.sentMu.Lock()
+	blockhash := j.quotes[txId].blockhash
+	j.sentMu.Unlock()
+	expired := false
+	if !blockhash.IsZero() {
+		start := time.Now()
+		valid, err := j.rc.IsBlockhashValid(ctx, blockhash, rpc.CommitmentProcessed)
+		j.lat.Observe(latency.Rpc, start, err)
+		if err != nil {
+			return "", fmt.Errorf("could not check the blockhash of %s: %w", txId, err)
+		}
+		expired = !valid.Value
+	}
+
+	start := time.Now()
+	statuses, err := j.rc.GetSignatureStatuses(ctx, true, sig)
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return "", fmt.Errorf("could not get the status of %s: %w", txId, err)
+	}
+	if len(statuses.Value) > 0 && statuses.Value[0] != nil {
+		status := statuses.Value[0]
+		switch {
+		case status.Err != nil:
+			return common.TxFailed, nil
+		case status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed ||
+			status.ConfirmationStatus == rpc.ConfirmationStatusFinalized:
+			return common.TxLanded, nil
+		default:
+			return common.TxPending, nil
+		}
+	}
+	if expired {
+		return common.TxDropped, nil
+	}
+	return common.TxPending, nil
+}
@@ -0,0 +1,312 @@
+package jupiter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	jl "github.com/ilkamo/jupiter-go/jupiter"
+	sl "github.com/ilkamo/jupiter-go/solana"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// squadsProgram is the Squads v4 multisig program
+var squadsProgram = solana.MustPublicKeyFromBase58("SQDS4ep65T869zMMBKyuUq6aD6EgTu8psMjkvj52pCf")
+
+// squadsMaxAccounts caps the accounts of a proposed swap's route, so its message fits in the transaction that stores
+// it in the multisig
+const squadsMaxAccounts = 32
+
+// squadsPollInterval is how often a proposed swap's proposal is read while it awaits approval
+const squadsPollInterval = 5 * time.Second
+
+// squadsTransactionIndex is the offset of the transaction index in a multisig account - after the discriminator, the
+// create key, the config authority, the threshold, and the time lock
+const squadsTransactionIndex = 8 + 32 + 32 + 2 + 4
+
+// squadsProposalStatus is the offset of the status in a proposal account - after the discriminator, the multisig, and
+// the transaction index
+const squadsProposalStatus = 8 + 32 + 8
+
+// proposalStatuses names the variants of a proposal's status, in the order the program declares them
+var proposalStatuses = []string{"draft", "active", "rejected", "approved", "executing", "executed", "cancelled"}
+
+// squads is the Squads multisig swaps are proposed to, and the vault of it that holds the funds
+type squads struct {
+	multisig solana.PublicKey
+	vault    solana.PublicKey
+	index    uint8
+}
+
+// newSquads reads the multisig from the config, nil when swaps are sent rather than proposed
+func newSquads(cfg *configs.Config) (*squads, error) {
+	if cfg.SquadsMultisig == "" {
+		return nil, nil
+	}
+	multisig, err := solana.PublicKeyFromBase58(cfg.SquadsMultisig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid squads_multisig: %w", err)
+	}
+	s := &squads{multisig: multisig, index: uint8(cfg.SquadsVaultIndex)}
+	if s.vault, _, err = s.pda([]byte("vault"), []byte{s.index}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// pda derives an account of the multisig from the seeds that follow its own
+func (s *squads) pda(seeds ...[]byte) (solana.PublicKey, uint8, error) {
+	return solana.FindProgramAddress(append([][]byte{[]byte("multisig"), s.multisig[:]}, seeds...), squadsProgram)
+}
+
+// accounts returns the vault transaction and the proposal of the multisig's transaction at the index
+func (s *squads) accounts(index uint64) (solana.PublicKey, solana.PublicKey, error) {
+	le := binary.LittleEndian.AppendUint64(nil, index)
+	transaction, _, err := s.pda([]byte("transaction"), le)
+	if err != nil {
+		return solana.PublicKey{}, solana.PublicKey{}, err
+	}
+	proposal, _, err := s.pda([]byte("transaction"), le, []byte("proposal"))
+	return transaction, proposal, err
+}
+
+// squadsInstruction builds a Squads instruction, its data prefixed with the discriminator of the named instruction
+func squadsInstruction(name string, accounts solana.AccountMetaSlice, args []byte) solana.Instruction {
+	discriminator := sha256.Sum256([]byte("global:" + name))
+	return solana.NewInstruction(squadsProgram, accounts, append(discriminator[:8:8], args...))
+}
+
+// Proposing reports whether swaps are proposed to a Squads multisig rather than sent
+func (j *Jupiter) Proposing() bool {
+	return j.sq != nil
+}
+
+// propose stores the swap transaction in the multisig as a vault transaction, opens a proposal for it, and approves
+// it as the bot's member, returning the proposal - the swap runs once enough other members approve and one of them
+// executes it
+func (j *Jupiter) propose(ctx context.Context, txBase64 string, quote jl.QuoteResponse) (string, error) {
+	j.mu.RLock()
+	sc, pk := j.sc, *j.pk
+	j.mu.RUnlock()
+	if sc == nil {
+		return "", fmt.Errorf("no wallet key is loaded to propose swaps with")
+	}
+	tx, err := sl.NewTransactionFromBase64(txBase64)
+	if err != nil {
+		return "", err
+	}
+	message := vaultMessage(tx)
+
+	// Proposals take the multisig's next transaction index, so two of them are never opened at once
+	j.proposeMu.Lock()
+	defer j.proposeMu.Unlock()
+	index, err := j.nextTransactionIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+	transaction, proposal, err := j.sq.accounts(index)
+	if err != nil {
+		return "", err
+	}
+
+	// The vault transaction fills its own transaction, as the swap's message leaves no room for the proposal
+	args := []byte{j.sq.index, 0} // no ephemeral signers
+	args = binary.LittleEndian.AppendUint32(args, uint32(len(message)))
+	args = append(append(args, message...), 0) // no memo
+	create := squadsInstruction("vault_transaction_create", solana.AccountMetaSlice{
+		solana.Meta(j.sq.multisig).WRITE(),
+		solana.Meta(transaction).WRITE(),
+		solana.Meta(pk).SIGNER(),
+		solana.Meta(pk).WRITE().SIGNER(),
+		solana.Meta(solana.SystemProgramID),
+	}, args)
+	txId, err := j.sendInstructions(ctx, sc, pk, create)
+	if err == nil {
+		err = j.awaitConfirmed(ctx, txId)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not create vault transaction %d of multisig %s: %w", index, j.sq.multisig, err)
+	}
+
+	open := squadsInstruction("proposal_create", solana.AccountMetaSlice{
+		solana.Meta(j.sq.multisig),
+		solana.Meta(proposal).WRITE(),
+		solana.Meta(pk).SIGNER(),
+		solana.Meta(pk).WRITE().SIGNER(),
+		solana.Meta(solana.SystemProgramID),
+	}, append(binary.LittleEndian.AppendUint64(nil, index), 0)) // not a draft
+	approve := squadsInstruction("proposal_approve", solana.AccountMetaSlice{
+		solana.Meta(j.sq.multisig),
+		solana.Meta(pk).WRITE().SIGNER(),
+		solana.Meta(proposal).WRITE(),
+	}, []byte{0}) // no memo
+	txId, err = j.sendInstructions(ctx, sc, pk, open, approve)
+	if err == nil {
+		err = j.awaitConfirmed(ctx, txId)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not open proposal %d of multisig %s: %w", index, j.sq.multisig, err)
+	}
+
+	j.sentMu.Lock()
+	j.quotes[proposal.String()] = sentQuote{quote: quote, sentAt: time.Now()}
+	j.sentMu.Unlock()
+	return proposal.String(), nil
+}
+
+// vaultMessage encodes the swap's message the way the Squads program stores a vault transaction - account counts,
+// keys, instructions, and lookups, with one-byte lengths except for instruction data - leaving out the compute budget
+// instructions, which the program cannot invoke on the swap's behalf
+func vaultMessage(tx solana.Transaction) []byte {
+	m := tx.Message
+	signers := m.Header.NumRequiredSignatures
+	unsigned := uint8(len(m.AccountKeys)) - signers
+	out := []byte{signers, signers - m.Header.NumReadonlySignedAccounts, unsigned - m.Header.NumReadonlyUnsignedAccounts}
+
+	out = append(out, uint8(len(m.AccountKeys)))
+	for _, key := range m.AccountKeys {
+		out = append(out, key[:]...)
+	}
+	skip := make(map[int]bool)
+	for _, ix := range computeBudgetInstructions(tx) {
+		skip[ix] = true
+	}
+	out = append(out, uint8(len(m.Instructions)-len(skip)))
+	for i, ix := range m.Instructions {
+		if skip[i] {
+			continue
+		}
+		out = append(out, uint8(ix.ProgramIDIndex), uint8(len(ix.Accounts)))
+		for _, account := range ix.Accounts {
+			out = append(out, uint8(account))
+		}
+		out = binary.LittleEndian.AppendUint16(out, uint16(len(ix.Data)))
+		out = append(out, ix.Data...)
+	}
+	out = append(out, uint8(len(m.AddressTableLookups)))
+	for _, lookup := range m.AddressTableLookups {
+		out = append(out, lookup.AccountKey[:]...)
+		out = append(append(out, uint8(len(lookup.WritableIndexes))), lookup.WritableIndexes...)
+		out = append(append(out, uint8(len(lookup.ReadonlyIndexes))), lookup.ReadonlyIndexes...)
+	}
+	return out
+}
+
+// nextTransactionIndex returns the index the multisig's next transaction takes
+func (j *Jupiter) nextTransactionIndex(ctx context.Context) (uint64, error) {
+	data, err := j.squadsAccount(ctx, j.sq.multisig)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < squadsTransactionIndex+8 {
+		return 0, fmt.Errorf("account %s is not a Squads multisig", j.sq.multisig)
+	}
+	return binary.LittleEndian.Uint64(data[squadsTransactionIndex:]) + 1, nil
+}
+
+// squadsAccount reads the data of an account owned by the Squads program
+func (j *Jupiter) squadsAccount(ctx context.Context, account solana.PublicKey) ([]byte, error) {
+	start := time.Now()
+	res, err := j.rc.GetAccountInfoWithOpts(ctx, account, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return nil, fmt.Errorf("could not read account %s: %w", account, err)
+	}
+	if res == nil || res.Value == nil || res.Value.Data == nil || !res.Value.Owner.Equals(squadsProgram) {
+		return nil, fmt.Errorf("account %s is not owned by the Squads program", account)
+	}
+	return res.Value.Data.GetBinary(), nil
+}
+
+// AwaitExecution waits until the proposed swap was approved and executed, returning the transaction that executed it
+// - a proposal that was rejected or cancelled, or is still open after the approval timeout, fails
+func (j *Jupiter) AwaitExecution(ctx context.Context, proposal string) (string, error) {
+	account, err := solana.PublicKeyFromBase58(proposal)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, j.cfg.SquadsApprovalTimeout)
+	defer cancel()
+	status := "unknown"
+	for {
+		if data, err := j.squadsAccount(ctx, account); err == nil && len(data) > squadsProposalStatus &&
+			int(data[squadsProposalStatus]) < len(proposalStatuses) {
+			status = proposalStatuses[data[squadsProposalStatus]]
+		}
+		switch status {
+		case "executed":
+			if txId, err := j.executedBy(ctx, account); err == nil {
+				j.sentMu.Lock()
+				if sent, ok := j.quotes[proposal]; ok {
+					j.quotes[txId] = sent
+					delete(j.quotes, proposal)
+				}
+				j.sentMu.Unlock()
+				return txId, nil
+			}
+		case "rejected", "cancelled":
+			return "", fmt.Errorf("proposal %s was %s", proposal, status)
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("proposal %s is still %s after %s: %w", proposal, status,
+				j.cfg.SquadsApprovalTimeout, ctx.Err())
+		case <-time.After(squadsPollInterval):
+		}
+	}
+}
+
+// executedBy returns the transaction that executed the proposal - the last one to succeed on it, as nothing touches
+// a proposal once it is executed
+func (j *Jupiter) executedBy(ctx context.Context, proposal solana.PublicKey) (string, error) {
+	limit := 10
+	start := time.Now()
+	res, err := j.rc.GetSignaturesForAddressWithOpts(ctx, proposal, &rpc.GetSignaturesForAddressOpts{
+		Limit:      &limit,
+		Commitment: rpc.CommitmentConfirmed,
+	})
+	j.lat.Observe(latency.Rpc, start, err)
+	if err != nil {
+		return "", fmt.Errorf("could not get the signatures of proposal %s: %w", proposal, err)
+	}
+	for _, sig := range res {
+		if sig.Err == nil {
+			return sig.Signature.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no transaction executed proposal %s", proposal)
+}
+
+// sendInstructions signs the instructions into a transaction paid by the bot wallet and sends it, returning its id
+func (j *Jupiter) sendInstructions(ctx context.Context, sc sl.Client, payer solana.PublicKey,
+	instructions ...solana.Instruction) (string, error) {
+	// The signing client replaces the blockhash when it signs
+	tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(payer))
+	if err != nil {
+		return "", err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	if len(raw) > maxTransactionSize {
+		return "", fmt.Errorf("transaction of %d bytes is over the %d byte limit", len(raw), maxTransactionSize)
+	}
+	txBase64, err := tx.ToBase64()
+	if err != nil {
+		return "", err
+	}
+	start := time.Now()
+	txId, err := sc.SendTransactionOnChain(ctx, txBase64)
+	j.lat.Observe(latency.Rpc, start, err)
+	return string(txId), err
+}
@@ -0,0 +1,114 @@
+package jupiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+// maxStatusBatch is the most signatures one getSignatureStatuses call accepts
+const maxStatusBatch = 256
+
+// statusPoller follows the commitment of every watched transaction with a single getSignatureStatuses call per poll,
+// however many are in flight - it polls only while something is watched
+type statusPoller struct {
+	rc    *rpc.Client
+	lat   *latency.Tracker
+	every time.Duration
+
+	mu      sync.Mutex
+	watched map[solana.Signature][]chan *rpc.SignatureStatusesResult
+	polling bool
+}
+
+// newStatusPoller creates a poller calling the RPC at the interval
+func newStatusPoller(rc *rpc.Client, lat *latency.Tracker, every time.Duration) *statusPoller {
+	return &statusPoller{
+		rc:      rc,
+		lat:     lat,
+		every:   every,
+		watched: make(map[solana.Signature][]chan *rpc.SignatureStatusesResult),
+	}
+}
+
+// watch returns a channel receiving the transaction's latest status whenever a poll finds one, and the function to
+// stop watching it with - the channel only holds the latest status, so a slow reader skips to it
+func (p *statusPoller) watch(sig solana.Signature) (<-chan *rpc.SignatureStatusesResult, func()) {
+	updates := make(chan *rpc.SignatureStatusesResult, 1)
+	p.mu.Lock()
+	p.watched[sig] = append(p.watched[sig], updates)
+	if !p.polling {
+		p.polling = true
+		go p.poll()
+	}
+	p.mu.Unlock()
+
+	return updates, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		chans := p.watched[sig]
+		for i, c := range chans {
+			if c == updates {
+				chans = append(chans[:i], chans[i+1:]...)
+				break
+			}
+		}
+		if len(chans) == 0 {
+			delete(p.watched, sig)
+		} else {
+			p.watched[sig] = chans
+		}
+	}
+}
+
+// poll reads the statuses of the watched transactions every interval, in batches the RPC accepts, until none are
+// watched - a failed call is retried on the next poll
+func (p *statusPoller) poll() {
+	tick := time.NewTicker(p.every)
+	defer tick.Stop()
+	for range tick.C {
+		p.mu.Lock()
+		if len(p.watched) == 0 {
+			p.polling = false
+			p.mu.Unlock()
+			return
+		}
+		sigs := make([]solana.Signature, 0, len(p.watched))
+		for sig := range p.watched {
+			sigs = append(sigs, sig)
+		}
+		p.mu.Unlock()
+
+		for from := 0; from < len(sigs); from += maxStatusBatch {
+			batch := sigs[from:min(from+maxStatusBatch, len(sigs))]
+			ctx, cancel := context.WithTimeout(context.Background(), p.every)
+			start := time.Now()
+			res, err := p.rc.GetSignatureStatuses(ctx, false, batch...)
+			p.lat.Observe(latency.Rpc, start, err)
+			cancel()
+			if err != nil || len(res.Value) != len(batch) {
+				continue
+			}
+			p.mu.Lock()
+			for i, status := range res.Value {
+				if status == nil {
+					continue
+				}
+				for _, c := range p.watched[batch[i]] {
+					// Replace a status the watcher has not read yet
+					select {
+					case <-c:
+					default:
+					}
+					c <- status
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package jupiter
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cachedToken is a token list entry as the cache keeps it, with when the token list returned it
+type cachedToken struct {
+	Info      TokenInfo `json:"info"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// tokenCache keeps what the token list said about each mint, persisted to a file when one is configured so a restart
+// during a token list outage still knows the tokens it traded
+type tokenCache struct {
+	path string
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+// newTokenCache opens the token cache at the path, empty when the path is empty or holds no readable cache - it is
+// only a cache, so a torn or missing file costs a token list request rather than the startup
+func newTokenCache(path string) *tokenCache {
+	c := &tokenCache{path: path, tokens: make(map[string]cachedToken)}
+	if path == "" {
+		return c
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		var tokens map[string]cachedToken
+		if json.Unmarshal(data, &tokens) == nil && tokens != nil {
+			c.tokens = tokens
+		}
+	}
+	return c
+}
+
+// get returns the cached entry of the mint, however old it is
+func (c *tokenCache) get(mint string) (cachedToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tokens[mint]
+	return t, ok
+}
+
+// put caches what the token list returned for a mint and writes the cache to its file, through a temporary file so a
+// crash never leaves a torn one
+func (c *tokenCache) put(info TokenInfo) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[info.Address] = cachedToken{Info: info, FetchedAt: time.Now()}
+	if c.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// errTokenOffline is returned for a token that is not cached while the token list is not asked
+var errTokenOffline = errors.New("token is not cached and token_offline is set")
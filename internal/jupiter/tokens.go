@@ -0,0 +1,158 @@
+package jupiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+
+	"github.com/josephawallace/ninetyfive/internal/latency"
+)
+
+const tokenEndpoint = "https://api.jup.ag/tokens/v1/token/"
+
+// Holding is the wallet's balance of a token, summed over its token accounts
+type Holding struct {
+	Mint     string  `json:"mint"`
+	Amount   float64 `json:"amount"`
+	Units    int64   `json:"units"` // the amount in the token's base units
+	Decimals int     `json:"decimals"`
+}
+
+// TokenInfo is what Jupiter's token list knows about a mint
+type TokenInfo struct {
+	Address         string   `json:"address"`
+	Name            string   `json:"name"`
+	Symbol          string   `json:"symbol"`
+	Decimals        int      `json:"decimals"`
+	Tags            []string `json:"tags"`
+	FreezeAuthority *string  `json:"freeze_authority"`
+	MintAuthority   *string  `json:"mint_authority"`
+}
+
+// Verified reports whether Jupiter's token list vouches for the token
+func (t TokenInfo) Verified() bool {
+	return slices.Contains(t.Tags, "verified") || slices.Contains(t.Tags, "strict")
+}
+
+// Holdings returns every non-zero token balance of the wallet, across both the token and the token-2022 programs -
+// native SOL is not a token account and is left out
+func (j *Jupiter) Holdings(ctx context.Context) ([]Holding, error) {
+	var holdings []Holding
+	index := make(map[string]int)
+	for _, program := range []solana.PublicKey{solana.TokenProgramID, solana.Token2022ProgramID} {
+		start := time.Now()
+		accounts, err := j.rc.GetTokenAccountsByOwner(ctx, j.PublicKey(), &rpc.GetTokenAccountsConfig{ProgramId: &program},
+			&rpc.GetTokenAccountsOpts{Commitment: rpc.CommitmentConfirmed, Encoding: solana.EncodingJSONParsed})
+		j.lat.Observe(latency.Rpc, start, err)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accounts.Value {
+			if a.Account.Data == nil {
+				continue
+			}
+			var data struct {
+				Parsed struct {
+					Info struct {
+						Mint        string `json:"mint"`
+						TokenAmount struct {
+							Amount         string `json:"amount"`
+							Decimals       int    `json:"decimals"`
+							UiAmountString string `json:"uiAmountString"`
+						} `json:"tokenAmount"`
+					} `json:"info"`
+				} `json:"parsed"`
+			}
+			if err = json.Unmarshal(a.Account.Data.GetRawJSON(), &data); err != nil {
+				return nil, fmt.Errorf("could not decode token account %s: %w", a.Pubkey, err)
+			}
+			info := data.Parsed.Info
+			units, err := strconv.ParseInt(info.TokenAmount.Amount, 10, 64)
+			if err != nil || units == 0 {
+				continue
+			}
+			amount, err := strconv.ParseFloat(info.TokenAmount.UiAmountString, 64)
+			if err != nil {
+				continue
+			}
+			if i, ok := index[info.Mint]; ok {
+				holdings[i].Amount += amount
+				holdings[i].Units += units
+				continue
+			}
+			index[info.Mint] = len(holdings)
+			holdings = append(holdings, Holding{Mint: info.Mint, Amount: amount, Units: units,
+				Decimals: info.TokenAmount.Decimals})
+		}
+	}
+	return holdings, nil
+}
+
+// TokenInfo looks the mint up in Jupiter's token list, read through the token cache - a cached entry is served until
+// it is older than the cache's time to live, and past it whenever the token list cannot be reached
+func (j *Jupiter) TokenInfo(ctx context.Context, mint string) (TokenInfo, error) {
+	cached, ok := j.tokens.get(mint)
+	if ok && (j.cfg.TokenOffline || time.Since(cached.FetchedAt) < j.cfg.TokenCacheTtl) {
+		return cached.Info, nil
+	}
+	if j.cfg.TokenOffline {
+		return TokenInfo{}, fmt.Errorf("could not look up token %s: %w", mint, errTokenOffline)
+	}
+	start := time.Now()
+	info, err := j.fetchTokenInfo(ctx, mint)
+	j.lat.Observe(latency.TokenApi, start, err)
+	if err != nil {
+		if ok {
+			return cached.Info, nil
+		}
+		return TokenInfo{}, err
+	}
+	// A cache that cannot be written still serves from memory
+	_ = j.tokens.put(info)
+	return info, nil
+}
+
+// fetchTokenInfo asks Jupiter's token list about the mint
+func (j *Jupiter) fetchTokenInfo(ctx context.Context, mint string) (TokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenEndpoint+mint, nil)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	res, err := j.tr.hc.Do(req)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return TokenInfo{}, fmt.Errorf("could not look up token %s: %s: %s", mint, res.Status, body)
+	}
+	var info TokenInfo
+	if err = json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return TokenInfo{}, fmt.Errorf("could not decode token %s: %w", mint, err)
+	}
+	return info, nil
+}
+
+// Sweep swaps the whole holding for the output mint, refusing when the quote's price impact exceeds maxImpactPct -
+// tag identifies the swap in its memo when memos are enabled
+func (j *Jupiter) Sweep(ctx context.Context, h Holding, outputMint string, maxImpactPct float64,
+	tag string) (string, error) {
+	quote, err := j.getUnitQuote(ctx, h.Mint, outputMint, h.Units, 0)
+	if err != nil {
+		return "", err
+	}
+	impact, _ := strconv.ParseFloat(quote.PriceImpactPct, 64)
+	if impact*100 > maxImpactPct {
+		return "", fmt.Errorf("price impact %.2f%% exceeds the %.2f%% limit", impact*100, maxImpactPct)
+	}
+	return j.swapQuote(ctx, quote, time.Now().Add(j.cfg.QuoteTtl), true, tag)
+}
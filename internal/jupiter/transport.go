@@ -0,0 +1,231 @@
+package jupiter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	jl "github.com/ilkamo/jupiter-go/jupiter"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// transport is the HTTP stack every call to Jupiter and the RPC shares - one pool of keep-alive connections, HTTP/2
+// where the server offers it, and hosts resolved ahead of time - so the quote, swap, and broadcast a signal triggers
+// reuse warm connections instead of each paying for a DNS lookup and a TLS handshake
+type transport struct {
+	hc      *http.Client
+	dialer  *net.Dialer
+	refresh time.Duration
+	origins []string
+
+	mu         sync.RWMutex // guards the resolved addresses, refreshed while requests dial
+	resolved   map[string][]string
+	resolvedAt time.Time
+}
+
+// newTransport builds the shared HTTP stack, keeping warm the origins of the price, quote, swap, token, and RPC
+// endpoints
+func newTransport(cfg *configs.Config) *transport {
+	t := &transport{
+		dialer:   &net.Dialer{Timeout: 10 * time.Second, KeepAlive: cfg.HttpKeepAlive},
+		refresh:  cfg.HttpDnsRefresh,
+		resolved: make(map[string][]string),
+	}
+	endpoints := []string{rpcEndpoint, jl.DefaultAPIURL, priceEndpoint, tokenEndpoint}
+	for _, endpoint := range append(endpoints, cfg.RpcBroadcastEndpoints...) {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if origin := u.Scheme + "://" + u.Host; !slices.Contains(t.origins, origin) {
+			t.origins = append(t.origins, origin)
+		}
+	}
+	t.hc = &http.Client{Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           t.dial,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConnsPerHost:   cfg.HttpMaxConnsPerHost,
+		MaxConnsPerHost:       cfg.HttpMaxConnsPerHost,
+		IdleConnTimeout:       cfg.HttpIdleTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}}
+	return t
+}
+
+// rpc returns a Solana RPC client of the endpoint that sends through the shared connections
+func (t *transport) rpc(endpoint string) *rpc.Client {
+	return rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(endpoint, &jsonrpc.RPCClientOpts{HTTPClient: t.hc}))
+}
+
+// dialStagger is how long a dial to one pre-resolved address gets the head start over a dial to the next
+const dialStagger = 250 * time.Millisecond
+
+// dial connects to the host's pre-resolved addresses, falling back to resolving it when none were resolved or none
+// answer - TLS still verifies the host name, as the transport takes it from the request
+func (t *transport) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return t.dialer.DialContext(ctx, network, addr)
+	}
+	t.mu.RLock()
+	ips := t.resolved[host]
+	t.mu.RUnlock()
+	if len(ips) > 0 {
+		if conn, err := t.dialAny(ctx, network, port, ips); err == nil {
+			return conn, nil
+		}
+	}
+	return t.dialer.DialContext(ctx, network, addr)
+}
+
+// dialAny races dials to the addresses, happy-eyeballs style - each starts once the one before it has had its head
+// start or failed, the first to connect wins and the others are abandoned, so a stale or blackholed address costs a
+// stagger rather than a dial timeout
+func (t *transport) dialAny(ctx context.Context, network, port string, ips []string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan result, len(ips))
+	// abandon closes the connections of the dials still racing as they land
+	abandon := func(pending int) {
+		go func() {
+			for ; pending > 0; pending-- {
+				if r := <-results; r.err == nil {
+					r.conn.Close()
+				}
+			}
+		}()
+	}
+
+	var errs []error
+	started, pending := 0, 0
+	for started < len(ips) || pending > 0 {
+		var stagger <-chan time.Time
+		if started < len(ips) {
+			ip := ips[started]
+			go func() {
+				conn, err := t.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+				results <- result{conn, err}
+			}()
+			started++
+			pending++
+			stagger = time.After(dialStagger)
+		}
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				abandon(pending)
+				return r.conn, nil
+			}
+			errs = append(errs, r.err)
+		case <-stagger:
+		case <-ctx.Done():
+			abandon(pending)
+			return nil, ctx.Err()
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// resolve looks the origins' hosts up again once the refresh interval has passed - a host that fails to resolve
+// keeps its previous addresses
+func (t *transport) resolve(ctx context.Context) error {
+	t.mu.RLock()
+	due := t.refresh > 0 && time.Since(t.resolvedAt) >= t.refresh
+	t.mu.RUnlock()
+	if !due {
+		return nil
+	}
+	var errs []error
+	resolved := make(map[string][]string)
+	for _, origin := range t.origins {
+		u, _ := url.Parse(origin)
+		ips, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resolved[u.Hostname()] = ips
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for host, ips := range resolved {
+		t.resolved[host] = ips
+	}
+	t.resolvedAt = time.Now()
+	return errors.Join(errs...)
+}
+
+// touch sends a HEAD request to every origin at once, opening a connection to each or keeping the open one from
+// going idle - any response will do, as only the connection matters
+func (t *transport) touch(ctx context.Context) error {
+	errs := make([]error, len(t.origins))
+	var wg sync.WaitGroup
+	for i, origin := range t.origins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, origin, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			res, err := t.hc.Do(req)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Warm resolves the endpoints' hosts when due and, when prewarming is on, opens or refreshes a connection to each
+// of them, returning how long it took - a failure only costs the next request the handshake it would have paid
+// anyway
+func (j *Jupiter) Warm(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	err := j.tr.resolve(ctx)
+	if j.cfg.HttpPrewarm {
+		err = errors.Join(err, j.tr.touch(ctx))
+	}
+	return time.Since(start), err
+}
+
+// KeepWarm warms the connections every prewarm interval, or only refreshes the resolved hosts when prewarming is
+// off, until the context is done
+func (j *Jupiter) KeepWarm(ctx context.Context) {
+	every := j.cfg.HttpPrewarmInterval
+	if !j.cfg.HttpPrewarm {
+		every = j.cfg.HttpDnsRefresh
+	}
+	if every <= 0 {
+		return
+	}
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			_, _ = j.Warm(ctx)
+		}
+	}
+}
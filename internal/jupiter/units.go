@@ -0,0 +1,47 @@
+package jupiter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Rounding modes for converting order amounts to base units
+const (
+	RoundFloor   = "floor"
+	RoundCeil    = "ceil"
+	RoundBankers = "bankers"
+)
+
+// ErrDustOrder is returned for an order too small to submit - below its mint's configured minimum, or rounding to no
+// base units at all
+var ErrDustOrder = errors.New("order below the minimum size")
+
+// toUnits converts an amount of a token with the decimals to its base units with the rounding mode - a product within
+// float noise of a whole or half unit is taken as that, so 0.3 of a token is not floored to 299999 micro-units and a
+// tie is still a tie for bankers' rounding
+func toUnits(amount float64, decimals int, mode string) int64 {
+	units := amount * math.Pow10(decimals)
+	if nearest := math.Round(units*2) / 2; math.Abs(units-nearest) < 1e-9*math.Max(1, math.Abs(units)) {
+		units = nearest
+	}
+	switch mode {
+	case RoundCeil:
+		return int64(math.Ceil(units))
+	case RoundBankers:
+		return int64(math.RoundToEven(units))
+	default:
+		return int64(math.Floor(units))
+	}
+}
+
+// checkOrderSize refuses an order spending amount of the mint, units once rounded, when it is dust
+func (j *Jupiter) checkOrderSize(mint string, amount float64, units int64) error {
+	if minimum := j.cfg.MinOrderSizes[mint]; amount < minimum {
+		return fmt.Errorf("%w: %f of %s is under the %f minimum", ErrDustOrder, amount, mint, minimum)
+	}
+	if units < 1 {
+		return fmt.Errorf("%w: %f of %s rounds to %d base units", ErrDustOrder, amount, mint, units)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package jupiter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToUnits(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		decimals int
+		mode     string
+		want     int64
+	}{
+		{amount: 1.2345678, decimals: 6, mode: RoundFloor, want: 1234567},
+		{amount: 1.2345678, decimals: 6, mode: RoundCeil, want: 1234568},
+		{amount: 1.2345678, decimals: 6, mode: RoundBankers, want: 1234568},
+		{amount: 1.2345674, decimals: 6, mode: RoundBankers, want: 1234567},
+		// Float noise is not floored or ceiled a whole unit away
+		{amount: 0.3, decimals: 6, mode: RoundFloor, want: 300000},
+		{amount: 0.3, decimals: 6, mode: RoundCeil, want: 300000},
+		{amount: 0.3, decimals: 6, mode: RoundBankers, want: 300000},
+		// Ties go to the even unit under bankers' rounding only
+		{amount: 0.0000025, decimals: 6, mode: RoundFloor, want: 2},
+		{amount: 0.0000025, decimals: 6, mode: RoundCeil, want: 3},
+		{amount: 0.0000025, decimals: 6, mode: RoundBankers, want: 2},
+		{amount: 0.0000035, decimals: 6, mode: RoundBankers, want: 4},
+		// An unknown mode floors
+		{amount: 1.9, decimals: 0, mode: "", want: 1},
+	}
+	for _, tt := range tests {
+		if got := toUnits(tt.amount, tt.decimals, tt.mode); got != tt.want {
+			t.Errorf("toUnits(%v, %d, %q) = %d, want %d", tt.amount, tt.decimals, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestCheckOrderSize(t *testing.T) {
+	j := &Jupiter{cfg: loadConfig(t, "min_order_sizes:\n  "+usdc+": 5\n")}
+	tests := []struct {
+		name    string
+		mint    string
+		amount  float64
+		units   int64
+		wantErr bool
+	}{
+		{name: "at the minimum", mint: usdc, amount: 5, units: 5000000},
+		{name: "under the minimum", mint: usdc, amount: 4.99, units: 4990000, wantErr: true},
+		{name: "no minimum configured", mint: ray, amount: 0.01, units: 10000},
+		{name: "rounds to no units", mint: ray, amount: 0.0000001, units: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := j.checkOrderSize(tt.mint, tt.amount, tt.units)
+			if tt.wantErr != errors.Is(err, ErrDustOrder) {
+				t.Errorf("checkOrderSize(%s, %v, %d) = %v, want dust %v", tt.mint, tt.amount, tt.units, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package latency
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// External dependencies whose calls are tracked
+const (
+	PriceApi = "price_api" // Jupiter's price endpoint
+	QuoteApi = "quote_api" // Jupiter's quote endpoint
+	SwapApi  = "swap_api"  // Jupiter's swap transaction endpoint
+	TokenApi = "token_api" // Jupiter's token list
+	Rpc      = "rpc"       // Solana JSON RPC
+)
+
+// Stats is how a dependency has behaved over its most recent calls
+type Stats struct {
+	Name      string    `json:"name"`
+	Calls     int       `json:"calls"`  // within the window
+	Errors    int       `json:"errors"` // within the window
+	ErrorRate float64   `json:"error_rate"`
+	P50Ms     float64   `json:"p50_ms"`
+	P90Ms     float64   `json:"p90_ms"`
+	P99Ms     float64   `json:"p99_ms"`
+	Total     int       `json:"total"` // calls since start
+	LastError string    `json:"last_error,omitempty"`
+	LastCall  time.Time `json:"last_call"`
+}
+
+// call is one observed call of a dependency
+type call struct {
+	took   time.Duration
+	failed bool
+}
+
+// series is a ring of a dependency's most recent calls
+type series struct {
+	calls     []call
+	next      int
+	total     int
+	lastError string
+	lastCall  time.Time
+}
+
+// Tracker keeps rolling latency and error statistics of every external dependency, so degradation of one provider
+// shows before it costs trades - it is safe for concurrent use
+type Tracker struct {
+	window int
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// NewTracker creates a tracker keeping each dependency's last window calls
+func NewTracker(window int) *Tracker {
+	return &Tracker{window: max(window, 1), series: make(map[string]*series)}
+}
+
+// Observe records a call of the dependency that started at start and ended now with err
+func (t *Tracker) Observe(dep string, start time.Time, err error) {
+	if t == nil {
+		return
+	}
+	took := time.Since(start)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.series[dep]
+	if !ok {
+		s = &series{calls: make([]call, 0, t.window)}
+		t.series[dep] = s
+	}
+	c := call{took: took, failed: err != nil}
+	if len(s.calls) < t.window {
+		s.calls = append(s.calls, c)
+	} else {
+		s.calls[s.next] = c
+	}
+	s.next = (s.next + 1) % t.window
+	s.total++
+	s.lastCall = time.Now()
+	if err != nil {
+		s.lastError = err.Error()
+	}
+}
+
+// Stats returns every dependency's statistics, by name
+func (t *Tracker) Stats() []Stats {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]Stats, 0, len(t.series))
+	for name, s := range t.series {
+		st := Stats{Name: name, Calls: len(s.calls), Total: s.total, LastError: s.lastError, LastCall: s.lastCall}
+		took := make([]time.Duration, 0, len(s.calls))
+		for _, c := range s.calls {
+			took = append(took, c.took)
+			if c.failed {
+				st.Errors++
+			}
+		}
+		slices.Sort(took)
+		st.ErrorRate = float64(st.Errors) / float64(st.Calls)
+		st.P50Ms = percentile(took, 50)
+		st.P90Ms = percentile(took, 90)
+		st.P99Ms = percentile(took, 99)
+		stats = append(stats, st)
+	}
+	slices.SortFunc(stats, func(a, b Stats) int { return strings.Compare(a.Name, b.Name) })
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return float64(sorted[max(0, min(i, len(sorted)-1))]) / float64(time.Millisecond)
+}
@@ -0,0 +1,557 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/secure"
+)
+
+// Trade is a single filled swap
+type Trade struct {
+	TxId         string                `json:"tx_id"`
+	OrderId      string                `json:"order_id"`
+	Side         common.Signal         `json:"side"`
+	InputMint    string                `json:"input_mint"`
+	OutputMint   string                `json:"output_mint"`
+	InputAmount  float64               `json:"input_amount"`
+	OutputAmount float64               `json:"output_amount,omitempty"`
+	Price        float64               `json:"price"`
+	Time         time.Time             `json:"time"`
+	Simulated    bool                  `json:"simulated,omitempty"`    // recorded in observer mode at the quoted price, never sent
+	Compute      *common.ComputeBudget `json:"compute,omitempty"`      // the compute the swap requested and paid for
+	FeeLamports  uint64                `json:"fee_lamports,omitempty"` // the fee the confirmed swap paid
+	PlatformFee  float64               `json:"platform_fee,omitempty"` // the platform fee collected, in the output mint
+	Imported     bool                  `json:"imported,omitempty"`     // brought over from history the bot did not trade
+	Snapshot     *Snapshot             `json:"snapshot,omitempty"`
+	Labels       *common.Labels        `json:"labels,omitempty"` // stamped by the ledger
+}
+
+// Snapshot is what the bot knew when it decided to trade - the strategy's state and the config in force - kept with
+// each fill so post-mortems can reconstruct the decision after parameters have changed
+type Snapshot struct {
+	SignalTime time.Time              `json:"signal_time"`
+	Price      float64                `json:"price"`
+	QuotePrice float64                `json:"quote_price,omitempty"`
+	QuoteUsd   float64                `json:"quote_usd,omitempty"` // the quote asset's USD price, when the pair is priced in it
+	Strategy   json.RawMessage        `json:"strategy,omitempty"`  // absent for manual trades
+	Config     map[string]interface{} `json:"config"`
+}
+
+// OrderRecord is the settled outcome of a logical order - FilledSize may be less than Size when only some of its
+// slices filled
+type OrderRecord struct {
+	OrderId    string         `json:"order_id"`
+	Side       common.Signal  `json:"side"`
+	Effect     string         `json:"effect,omitempty"`
+	Size       float64        `json:"size"`
+	FilledSize float64        `json:"filled_size"`
+	Residual   float64        `json:"residual"`
+	Status     string         `json:"status"`
+	CreatedAt  time.Time      `json:"created_at"`
+	SettledAt  time.Time      `json:"settled_at"`
+	Labels     *common.Labels `json:"labels,omitempty"` // stamped by the ledger
+}
+
+// SignalRecord is an entry of the signal audit - a signal the strategies emitted, or one a strategy filter or a risk
+// limit stopped from trading
+type SignalRecord struct {
+	Time       time.Time      `json:"time"`
+	Pair       string         `json:"pair"`
+	Strategy   string         `json:"strategy,omitempty"` // the ensemble member, for signals stopped by its own filters
+	Side       common.Signal  `json:"side"`
+	Price      float64        `json:"price"`
+	GridLevel  int            `json:"grid_level"`        // the index of the grid line the signal line sat on
+	Filters    string         `json:"filters,omitempty"` // the filter combination in force
+	Suppressed bool           `json:"suppressed"`
+	Stage      string         `json:"stage,omitempty"`  // strategy, entry_filter, or risk
+	Filter     string         `json:"filter,omitempty"` // what stopped it within the stage
+	Reason     string         `json:"reason,omitempty"`
+	Labels     *common.Labels `json:"labels,omitempty"` // stamped by the ledger
+}
+
+// ProfitLock is the floor ratcheted under the bot's gains - the PnL must not fall back below Floor once Milestone has
+// been reached
+type ProfitLock struct {
+	Milestone float64   `json:"milestone"`
+	Floor     float64   `json:"floor"`
+	RaisedAt  time.Time `json:"raised_at"`
+}
+
+// Benchmark is the buy-and-hold position the bot is measured against in a pair - the capital converted to the base
+// asset at the price when the bot started trading the pair
+type Benchmark struct {
+	StartedAt time.Time `json:"started_at"`
+	Price     float64   `json:"price"`
+	Capital   float64   `json:"capital"` // in the quote asset
+	// Base is the part of the capital held in the base asset at the start, the rest being held in the quote asset
+	Base float64 `json:"base,omitempty"`
+	// PositionBase and PositionQuote are the bot's position at the start, so only its trades since count
+	PositionBase  float64 `json:"position_base,omitempty"`
+	PositionQuote float64 `json:"position_quote,omitempty"`
+}
+
+// Adjustment is a change to the wallet's holdings that the bot did not make - a manual transfer, an airdrop, or a
+// compromised key draining it - recorded so accounting can tell the wallet's balances apart from its trading
+type Adjustment struct {
+	TxId   string         `json:"tx_id"`
+	Mint   string         `json:"mint"`
+	Amount float64        `json:"amount"` // positive when the wallet received
+	Time   time.Time      `json:"time"`
+	Memo   string         `json:"memo,omitempty"`
+	Labels *common.Labels `json:"labels,omitempty"` // stamped by the ledger
+}
+
+// MintRecord is a traded mint's metadata as first seen
+type MintRecord struct {
+	Decimals int       `json:"decimals"`
+	Symbol   string    `json:"symbol,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	SeenAt   time.Time `json:"seen_at"`
+}
+
+// document is the on-disk layout of the ledger
+type document struct {
+	Labels     *common.Labels       `json:"labels,omitempty"` // the deployment that last wrote the ledger
+	Trades     []Trade              `json:"trades"`
+	Orders     []OrderRecord        `json:"orders"`
+	Signals    []SignalRecord       `json:"signals,omitempty"` // the signal audit
+	ProfitLock *ProfitLock          `json:"profit_lock,omitempty"`
+	Benchmarks map[string]Benchmark `json:"benchmarks,omitempty"` // keyed by BASE/QUOTE pair
+	// Adjustments are the wallet's external activity, read up to and including the transaction WalletCursor
+	Adjustments  []Adjustment `json:"adjustments,omitempty"`
+	WalletCursor string       `json:"wallet_cursor,omitempty"`
+	// Mints are the traded mints' metadata as first seen, keyed by mint, to tell when a mint changed or migrated
+	Mints map[string]MintRecord `json:"mints,omitempty"`
+}
+
+// Ledger persists trades and order outcomes to a local file, sealed with a passphrase when one is given - a ledger
+// belongs to one environment and one trading mode, so paper trades never mingle with live ones
+type Ledger struct {
+	path       string
+	passphrase []byte
+	labels     common.Labels
+	maxSignals int // signal audit entries kept, 0 for every one
+
+	mu  sync.RWMutex
+	doc document
+}
+
+// Open loads the ledger at the path, starting empty when the file does not exist yet - a ledger written in another
+// environment or trading mode, or holding trades of another pair pricing, is refused
+func Open(path string, passphrase []byte, labels common.Labels) (*Ledger, error) {
+	l := &Ledger{
+		path:       path,
+		passphrase: passphrase,
+		labels:     labels,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if secure.IsSealed(data) {
+		if data, err = secure.Open(data, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	if err = json.Unmarshal(data, &l.doc); err != nil {
+		return nil, err
+	}
+	if o := l.doc.Labels; o != nil && (o.Environment != labels.Environment || o.Mode != labels.Mode) {
+		return nil, fmt.Errorf("ledger %s holds %s trades of the %s environment, not %s trades of the %s environment - "+
+			"give each its own ledger_path", path, o.Mode, o.Environment, labels.Mode, labels.Environment)
+	}
+	// Prices of trades recorded under the other pair pricing are in other units, which would corrupt the position
+	if o := l.doc.Labels; o != nil && len(l.doc.Trades) > 0 && o.PricingMode() != labels.PricingMode() {
+		return nil, fmt.Errorf("ledger %s holds trades priced in %s, not %s - keep pair_pricing %s or give the new "+
+			"pricing its own ledger_path", path, o.PricingMode(), labels.PricingMode(), o.PricingMode())
+	}
+	return l, nil
+}
+
+// OpenPeer opens another deployment's ledger with this ledger's passphrase and labels, to read the trades of a
+// strategy sharing the wallet
+func (l *Ledger) OpenPeer(path string) (*Ledger, error) {
+	return Open(path, l.passphrase, l.labels)
+}
+
+// RecordTrade appends a filled swap, refusing a simulated fill in a live ledger and a real one in a paper ledger
+func (l *Ledger) RecordTrade(t Trade) error {
+	if t.Simulated != l.labels.Paper() {
+		return fmt.Errorf("refusing to record trade %s in a %s ledger (simulated: %t)", t.TxId, l.labels.Mode, t.Simulated)
+	}
+	labels := l.labels
+	t.Labels = &labels
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc.Trades = append(l.doc.Trades, t)
+	return l.save()
+}
+
+// ImportTrades merges trades from history the bot did not make into the ledger in time order, skipping the ones whose
+// transaction is already recorded, and returns how many were added
+func (l *Ledger) ImportTrades(trades []Trade) (int, error) {
+	labels := l.labels
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seen := make(map[string]bool, len(l.doc.Trades))
+	for _, t := range l.doc.Trades {
+		seen[t.TxId] = true
+	}
+	var added int
+	for _, t := range trades {
+		if t.Simulated != labels.Paper() {
+			return 0, fmt.Errorf("refusing to import trade %s in a %s ledger (simulated: %t)", t.TxId, labels.Mode,
+				t.Simulated)
+		}
+		if seen[t.TxId] {
+			continue
+		}
+		seen[t.TxId] = true
+		t.Imported = true
+		t.Labels = &labels
+		l.doc.Trades = append(l.doc.Trades, t)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	slices.SortStableFunc(l.doc.Trades, func(a, b Trade) int { return a.Time.Compare(b.Time) })
+	return added, l.save()
+}
+
+// RecordOrder appends the outcome of a settled order
+func (l *Ledger) RecordOrder(o OrderRecord) error {
+	labels := l.labels
+	o.Labels = &labels
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc.Orders = append(l.doc.Orders, o)
+	return l.save()
+}
+
+// LimitSignals caps the signal audit at its newest n entries, applied as the next signal is recorded - 0 keeps every
+// entry
+func (l *Ledger) LimitSignals(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxSignals = n
+}
+
+// RecordSignal appends an entry to the signal audit, dropping the oldest entries beyond the limit
+func (l *Ledger) RecordSignal(s SignalRecord) error {
+	labels := l.labels
+	s.Labels = &labels
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc.Signals = append(l.doc.Signals, s)
+	if n := len(l.doc.Signals); l.maxSignals > 0 && n > l.maxSignals {
+		l.doc.Signals = slices.Clone(l.doc.Signals[n-l.maxSignals:])
+	}
+	return l.save()
+}
+
+// Signals returns a copy of the signal audit, oldest first
+func (l *Ledger) Signals() []SignalRecord {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]SignalRecord(nil), l.doc.Signals...)
+}
+
+// SetProfitLock persists the ratcheted profit floor
+func (l *Ledger) SetProfitLock(p ProfitLock) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc.ProfitLock = &p
+	return l.save()
+}
+
+// ProfitLock returns the ratcheted profit floor, or nil when no milestone has been reached
+func (l *Ledger) ProfitLock() *ProfitLock {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.doc.ProfitLock == nil {
+		return nil
+	}
+	p := *l.doc.ProfitLock
+	return &p
+}
+
+// SetBenchmark persists the pair's buy-and-hold benchmark
+func (l *Ledger) SetBenchmark(pair common.Pair, b Benchmark) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.doc.Benchmarks == nil {
+		l.doc.Benchmarks = make(map[string]Benchmark)
+	}
+	l.doc.Benchmarks[pair.String()] = b
+	return l.save()
+}
+
+// Benchmark returns the pair's buy-and-hold benchmark, or nil before the bot has started one
+func (l *Ledger) Benchmark(pair common.Pair) *Benchmark {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	b, ok := l.doc.Benchmarks[pair.String()]
+	if !ok {
+		return nil
+	}
+	return &b
+}
+
+// SetMintRecord records a mint's metadata as the baseline later reads are compared to
+func (l *Ledger) SetMintRecord(mint string, r MintRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.doc.Mints == nil {
+		l.doc.Mints = make(map[string]MintRecord)
+	}
+	l.doc.Mints[mint] = r
+	return l.save()
+}
+
+// MintRecord returns the mint's recorded metadata - false when none was recorded yet
+func (l *Ledger) MintRecord(mint string) (MintRecord, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	r, ok := l.doc.Mints[mint]
+	return r, ok
+}
+
+// ForgetMint drops the mint's recorded metadata, so its current metadata becomes the baseline once read again
+func (l *Ledger) ForgetMint(mint string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.doc.Mints, mint)
+	return l.save()
+}
+
+// RemapMint carries the ledger over from a mint to the one it migrated to - every trade and adjustment in the old
+// mint is rewritten to the new one with its amounts multiplied by ratio, the new mint's units per old unit, and the
+// price of trades whose base asset migrated divided by it, so positions carry over - returns the records rewritten
+func (l *Ledger) RemapMint(from string, to string, ratio float64) (int, error) {
+	if ratio <= 0 {
+		return 0, fmt.Errorf("the ratio must be positive")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for i := range l.doc.Trades {
+		t := &l.doc.Trades[i]
+		base := t.OutputMint
+		if t.Side == common.SellSignal {
+			base = t.InputMint
+		}
+		switch {
+		case t.InputMint == from:
+			t.InputMint = to
+			t.InputAmount *= ratio
+		case t.OutputMint == from:
+			t.OutputMint = to
+			t.OutputAmount *= ratio
+			t.PlatformFee *= ratio
+		default:
+			continue
+		}
+		// Prices are in quote units per base unit, so they scale down with the base and up with the quote
+		if base == from {
+			t.Price /= ratio
+		} else {
+			t.Price *= ratio
+		}
+		n++
+	}
+	for i := range l.doc.Adjustments {
+		if a := &l.doc.Adjustments[i]; a.Mint == from {
+			a.Mint = to
+			a.Amount *= ratio
+			n++
+		}
+	}
+	for key, b := range l.doc.Benchmarks {
+		base, quote, _ := strings.Cut(key, "/")
+		if base != from && quote != from {
+			continue
+		}
+		delete(l.doc.Benchmarks, key)
+		if base == from {
+			base, b.Price = to, b.Price/ratio
+			b.Base, b.PositionBase = b.Base*ratio, b.PositionBase*ratio
+		} else {
+			quote, b.Price, b.Capital = to, b.Price*ratio, b.Capital*ratio
+			b.PositionQuote *= ratio
+		}
+		l.doc.Benchmarks[base+"/"+quote] = b
+	}
+	delete(l.doc.Mints, from)
+	return n, l.save()
+}
+
+// RecordAdjustments appends the wallet's external activity read since the last call, moving the cursor to the
+// newest transaction read in the same write so no activity is recorded twice
+func (l *Ledger) RecordAdjustments(cursor string, adjustments []Adjustment) error {
+	labels := l.labels
+	for i := range adjustments {
+		adjustments[i].Labels = &labels
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc.Adjustments = append(l.doc.Adjustments, adjustments...)
+	l.doc.WalletCursor = cursor
+	return l.save()
+}
+
+// RecordAdjustment appends an external adjustment the bot made itself, such as converting an airdrop
+func (l *Ledger) RecordAdjustment(a Adjustment) error {
+	labels := l.labels
+	a.Labels = &labels
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc.Adjustments = append(l.doc.Adjustments, a)
+	return l.save()
+}
+
+// Adjustments returns a copy of the wallet's recorded external activity, oldest first
+func (l *Ledger) Adjustments() []Adjustment {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Adjustment(nil), l.doc.Adjustments...)
+}
+
+// WalletCursor returns the id of the newest wallet transaction read for external activity, empty before the first
+// read
+func (l *Ledger) WalletCursor() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.doc.WalletCursor
+}
+
+// Trades returns a copy of every recorded trade of the ledger's trading mode, oldest first - ledgers from before
+// labels may hold both
+func (l *Ledger) Trades() []Trade {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	trades := make([]Trade, 0, len(l.doc.Trades))
+	for _, t := range l.doc.Trades {
+		if t.Simulated == l.labels.Paper() {
+			trades = append(trades, t)
+		}
+	}
+	return trades
+}
+
+// PlatformFees returns the platform fees collected through the recorded swaps, keyed by the mint they were paid in
+func (l *Ledger) PlatformFees() map[string]float64 {
+	fees := make(map[string]float64)
+	for _, t := range l.Trades() {
+		if t.PlatformFee > 0 {
+			fees[t.OutputMint] += t.PlatformFee
+		}
+	}
+	return fees
+}
+
+// ExternalFlows returns the net external adjustments to the wallet, keyed by mint
+func (l *Ledger) ExternalFlows() map[string]float64 {
+	flows := make(map[string]float64)
+	for _, a := range l.Adjustments() {
+		flows[a.Mint] += a.Amount
+	}
+	return flows
+}
+
+// Orders returns a copy of every recorded order outcome, oldest first
+func (l *Ledger) Orders() []OrderRecord {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]OrderRecord(nil), l.doc.Orders...)
+}
+
+// Export returns the whole ledger as unsealed JSON, labels included, to move it to another deployment
+func (l *Ledger) Export() (json.RawMessage, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	doc := l.doc
+	doc.Labels = &l.labels
+	return json.Marshal(doc)
+}
+
+// CheckImport reports whether Import would accept the exported ledger, without changing anything
+func (l *Ledger) CheckImport(data json.RawMessage) error {
+	_, err := l.decodeImport(data)
+	return err
+}
+
+// Import replaces the ledger with an exported one and saves it - a ledger exported from another environment or
+// trading mode, or holding trades of another pair pricing, is refused, as Open refuses one
+func (l *Ledger) Import(data json.RawMessage) error {
+	doc, err := l.decodeImport(data)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.doc = doc
+	return l.save()
+}
+
+// decodeImport decodes an exported ledger, refusing one this ledger's deployment cannot take over
+func (l *Ledger) decodeImport(data json.RawMessage) (document, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return document{}, fmt.Errorf("invalid ledger: %w", err)
+	}
+	o := doc.Labels
+	if o != nil && (o.Environment != l.labels.Environment || o.Mode != l.labels.Mode) {
+		return document{}, fmt.Errorf("the ledger holds %s trades of the %s environment, not %s trades of the %s "+
+			"environment", o.Mode, o.Environment, l.labels.Mode, l.labels.Environment)
+	}
+	if o != nil && len(doc.Trades) > 0 && o.PricingMode() != l.labels.PricingMode() {
+		return document{}, fmt.Errorf("the ledger holds trades priced in %s, not %s", o.PricingMode(),
+			l.labels.PricingMode())
+	}
+	return doc, nil
+}
+
+// Empty reports whether the ledger has recorded nothing yet
+func (l *Ledger) Empty() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.doc.Trades) == 0 && len(l.doc.Orders) == 0 && len(l.doc.Signals) == 0 && len(l.doc.Adjustments) == 0
+}
+
+// save writes the whole ledger to a temporary file and renames it into place so a crash never leaves a torn file -
+// callers must hold the lock
+func (l *Ledger) save() error {
+	l.doc.Labels = &l.labels
+	data, err := json.Marshal(l.doc)
+	if err != nil {
+		return err
+	}
+	if len(l.passphrase) > 0 {
+		if data, err = secure.Seal(data, l.passphrase); err != nil {
+			return err
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
@@ -21,9 +21,9 @@ func NewCloudEvent(logger *CloudLogger, severity logging.Severity, err error) *C
 }
 
 func (ce *CloudEvent) Msg(format string, args ...interface{}) {
-	ce.logger.client.Logger(name).StandardLogger(ce.severity).Println(fmt.Sprintf(format, args...))
+	ce.logger.logger.StandardLogger(ce.severity).Println(fmt.Sprintf(format, args...))
 	if ce.err != nil {
-		ce.logger.client.Logger(name).StandardLogger(ce.severity).Println(ce.err.Error())
+		ce.logger.logger.StandardLogger(ce.severity).Println(ce.err.Error())
 	}
 }
 
@@ -33,7 +33,7 @@ func (ce *CloudEvent) Err(err error) Event {
 }
 
 type CloudLogger struct {
-	client *logging.Client
+	logger *logging.Logger
 }
 
 func (l CloudLogger) Info() Event {
@@ -1,16 +1,20 @@
 package logger
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 type LocalEvent struct {
 	*zerolog.Event
+	logger *zerolog.Logger
 }
 
-func NewLocalEvent(event *zerolog.Event) *LocalEvent {
-	return &LocalEvent{event}
+func NewLocalEvent(logger *zerolog.Logger, event *zerolog.Event) *LocalEvent {
+	return &LocalEvent{Event: event, logger: logger}
 }
 
 func (l *LocalEvent) Msg(format string, args ...interface{}) {
@@ -18,24 +22,34 @@ func (l *LocalEvent) Msg(format string, args ...interface{}) {
 }
 
 func (l *LocalEvent) Err(err error) Event {
-	return NewLocalEvent(log.Err(err))
+	return NewLocalEvent(l.logger, l.logger.Err(err))
 }
 
 type LocalLogger struct {
+	logger zerolog.Logger
+}
+
+// NewLocalLogger logs through zerolog's global logger, attaching the labels to every entry
+func NewLocalLogger(labels map[string]string) LocalLogger {
+	ctx := log.Logger.With()
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		ctx = ctx.Str(k, labels[k])
+	}
+	return LocalLogger{logger: ctx.Logger()}
 }
 
 func (l LocalLogger) Info() Event {
-	return NewLocalEvent(log.Info())
+	return NewLocalEvent(&l.logger, l.logger.Info())
 }
 
 func (l LocalLogger) Debug() Event {
-	return NewLocalEvent(log.Debug())
+	return NewLocalEvent(&l.logger, l.logger.Debug())
 }
 
 func (l LocalLogger) Warn() Event {
-	return NewLocalEvent(log.Warn())
+	return NewLocalEvent(&l.logger, l.logger.Warn())
 }
 
 func (l LocalLogger) Error() Event {
-	return NewLocalEvent(log.Error())
+	return NewLocalEvent(&l.logger, l.logger.Error())
 }
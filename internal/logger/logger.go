@@ -20,9 +20,11 @@ type Logger interface {
 	Error() Event
 }
 
-func NewLogger(client *logging.Client) Logger {
+// NewLogger logs to Google Cloud Logging through the client, or locally when it is nil, attaching the labels to every
+// entry
+func NewLogger(client *logging.Client, labels map[string]string) Logger {
 	if client == nil {
-		return LocalLogger{}
+		return NewLocalLogger(labels)
 	}
-	return CloudLogger{client}
+	return CloudLogger{client.Logger(name, logging.CommonLabels(labels))}
 }
@@ -0,0 +1,29 @@
+package logger
+
+// NopEvent discards the event
+type NopEvent struct{}
+
+func (NopEvent) Msg(string, ...interface{}) {}
+
+func (e NopEvent) Err(error) Event {
+	return e
+}
+
+// NopLogger discards everything, for embedding the engine where its narration is unwanted
+type NopLogger struct{}
+
+func (NopLogger) Debug() Event {
+	return NopEvent{}
+}
+
+func (NopLogger) Info() Event {
+	return NopEvent{}
+}
+
+func (NopLogger) Warn() Event {
+	return NopEvent{}
+}
+
+func (NopLogger) Error() Event {
+	return NopEvent{}
+}
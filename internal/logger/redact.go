@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// redacted replaces a masked value
+const redacted = "REDACTED"
+
+var (
+	// address matches a base58 public key - a transaction signature is longer, so it is left alone
+	address = regexp.MustCompile(`\b[1-9A-HJ-NP-Za-km-z]{32,44}\b`)
+	// credential matches a key or token passed as a URL query parameter, as RPC providers often take it
+	credential = regexp.MustCompile(`(?i)\b((?:api[-_]?key|apikey|access[-_]?token|token|key|secret)=)[^&\s"']+`)
+	// secretPath matches a Secret Manager resource name, whose last segment names the secret
+	secretPath = regexp.MustCompile(`\bsecrets/[^/\s"']+`)
+)
+
+// Redaction describes what to mask in log entries
+type Redaction struct {
+	Secrets []string // values masked in full wherever they appear, like API keys and tokens
+	Names   []string // secret names masked in full wherever they appear
+	Keep    []string // addresses logged as they are, like the pair's mints
+}
+
+// Redactor masks wallet addresses partially, and secret values, secret names, and credentials in URLs in full
+type Redactor struct {
+	secrets []string
+	keep    []string
+}
+
+// NewRedactor creates a redactor for the given values - the longest are masked first so a secret containing another
+// is masked whole
+func NewRedactor(r Redaction) *Redactor {
+	var secrets []string
+	for _, s := range slices.Concat(r.Secrets, r.Names) {
+		if s = strings.TrimSpace(s); s != "" && !slices.Contains(secrets, s) {
+			secrets = append(secrets, s)
+		}
+	}
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+	return &Redactor{secrets: secrets, keep: r.Keep}
+}
+
+// Redact masks a log line
+func (r *Redactor) Redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	s = credential.ReplaceAllString(s, "${1}"+redacted)
+	s = secretPath.ReplaceAllString(s, "secrets/"+redacted)
+	return address.ReplaceAllStringFunc(s, func(a string) string {
+		if slices.Contains(r.keep, a) {
+			return a
+		}
+		return a[:4] + "..." + a[len(a)-4:]
+	})
+}
+
+// RedactedEvent masks the message and error of an event before handing them on
+type RedactedEvent struct {
+	next Event
+	r    *Redactor
+}
+
+func (e RedactedEvent) Msg(format string, args ...interface{}) {
+	e.next.Msg("%s", e.r.Redact(fmt.Sprintf(format, args...)))
+}
+
+func (e RedactedEvent) Err(err error) Event {
+	if err != nil {
+		err = errors.New(e.r.Redact(err.Error()))
+	}
+	return RedactedEvent{next: e.next.Err(err), r: e.r}
+}
+
+// RedactedLogger masks every entry before it reaches the sink of the logger it wraps
+type RedactedLogger struct {
+	next Logger
+	r    *Redactor
+}
+
+// NewRedactedLogger wraps a logger so everything it logs goes through the redactor
+func NewRedactedLogger(next Logger, r *Redactor) RedactedLogger {
+	return RedactedLogger{next: next, r: r}
+}
+
+func (l RedactedLogger) Debug() Event {
+	return RedactedEvent{next: l.next.Debug(), r: l.r}
+}
+
+func (l RedactedLogger) Info() Event {
+	return RedactedEvent{next: l.next.Info(), r: l.r}
+}
+
+func (l RedactedLogger) Warn() Event {
+	return RedactedEvent{next: l.next.Warn(), r: l.r}
+}
+
+func (l RedactedLogger) Error() Event {
+	return RedactedEvent{next: l.next.Error(), r: l.r}
+}
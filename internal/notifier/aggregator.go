@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// condition tracks an alert key that is currently firing
+type condition struct {
+	first      Alert
+	firstSeen  time.Time
+	lastSent   time.Time
+	count      int // total occurrences since the condition started
+	suppressed int // occurrences since the last delivered alert
+	severity   Severity
+}
+
+// Aggregator deduplicates bursts of identical alerts - the first occurrence of a key is delivered immediately, repeats
+// within the key's cooldown are counted instead of sent, each elapsed cooldown delivers one escalated reminder, and
+// clearing the key delivers a summary
+type Aggregator struct {
+	next            Notifier
+	defaultCooldown time.Duration
+	cooldowns       map[string]time.Duration
+	now             func() time.Time
+
+	mu     sync.Mutex
+	active map[string]*condition
+}
+
+// NewAggregator wraps a notifier with deduplication - cooldowns overrides the default per event key
+func NewAggregator(next Notifier, defaultCooldown time.Duration, cooldowns map[string]time.Duration) *Aggregator {
+	if cooldowns == nil {
+		cooldowns = make(map[string]time.Duration)
+	}
+	return &Aggregator{
+		next:            next,
+		defaultCooldown: defaultCooldown,
+		cooldowns:       cooldowns,
+		now:             time.Now,
+		active:          make(map[string]*condition),
+	}
+}
+
+// Notify delivers or suppresses the alert depending on whether its key is already firing
+func (a *Aggregator) Notify(ctx context.Context, alert Alert) error {
+	// Alerts without a key are one-off messages (reports, confirmations) and are never aggregated
+	if alert.Key == "" {
+		return a.next.Notify(ctx, alert)
+	}
+
+	a.mu.Lock()
+	now := a.now()
+	c, ok := a.active[alert.Key]
+	if !ok {
+		a.active[alert.Key] = &condition{
+			first:     alert,
+			firstSeen: now,
+			lastSent:  now,
+			count:     1,
+			severity:  alert.Severity,
+		}
+		a.mu.Unlock()
+		return a.next.Notify(ctx, alert)
+	}
+
+	c.count++
+	c.suppressed++
+	if now.Sub(c.lastSent) < a.cooldown(alert.Key) {
+		a.mu.Unlock()
+		return nil
+	}
+
+	// The cooldown elapsed with the condition still firing - escalate one level and send a single reminder
+	if c.severity < SeverityCritical {
+		c.severity++
+	}
+	reminder := Alert{
+		Key:      alert.Key,
+		Severity: c.severity,
+		Title:    fmt.Sprintf("%s (ongoing for %s)", alert.Title, now.Sub(c.firstSeen).Round(time.Second)),
+		Body: fmt.Sprintf("%d occurrences since the last alert, %d in total\n%s",
+			c.suppressed, c.count, alert.Body),
+	}
+	c.lastSent = now
+	c.suppressed = 0
+	a.mu.Unlock()
+	return a.next.Notify(ctx, reminder)
+}
+
+// Clear marks the condition behind a key as resolved and delivers a summary if it had fired
+func (a *Aggregator) Clear(ctx context.Context, key string) error {
+	a.mu.Lock()
+	c, ok := a.active[key]
+	if !ok {
+		a.mu.Unlock()
+		return nil
+	}
+	delete(a.active, key)
+	now := a.now()
+	a.mu.Unlock()
+
+	return a.next.Notify(ctx, Alert{
+		Key:      key,
+		Severity: SeverityInfo,
+		Title:    fmt.Sprintf("resolved: %s", c.first.Title),
+		Body: fmt.Sprintf("condition lasted %s with %d occurrences",
+			now.Sub(c.firstSeen).Round(time.Second), c.count),
+	})
+}
+
+// cooldown returns the reminder interval for the key
+func (a *Aggregator) cooldown(key string) time.Duration {
+	if d, ok := a.cooldowns[key]; ok {
+		return d
+	}
+	return a.defaultCooldown
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Event keys used for deduplicating alerts raised by the trading loop
+const (
+	KeyPriceFetch     = "price_fetch"
+	KeyProcess        = "process"
+	KeyLowSol         = "low_sol"
+	KeyCrashLoop      = "crash_loop"
+	KeyFlatten        = "flatten"
+	KeySwapSubmit     = "swap_submit"
+	KeyTxMonitor      = "tx_monitor"
+	KeySignalPublish  = "signal_publish"
+	KeyCircuitBreaker = "circuit_breaker"
+	KeyProfitLock     = "profit_lock"
+	KeyAnomalyFill    = "anomaly_fill"
+	KeyAnomalyFee     = "anomaly_fee"
+	KeyAnomalySignals = "anomaly_signals"
+	KeyAnomalyBalance = "anomaly_balance"
+	KeyCongestion     = "congestion"
+	KeyWalletActivity = "wallet_activity"
+	KeyPriceOutage    = "price_outage"
+	KeyPriceStale     = "price_stale"
+	KeyMintChange     = "mint_change"
+	KeyDelegation     = "delegation"
+	KeyApproval       = "approval"
+)
+
+// NewNotifier builds the real-time notifier from the config - every configured backend is wrapped in a single
+// aggregator so bursts of identical alerts are deduplicated once, regardless of how many backends receive them
+func NewNotifier(cfg *configs.Config) *Aggregator {
+	var backends Multi
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatId != "" {
+		backends = append(backends, NewTelegram(cfg.TelegramBotToken, cfg.TelegramChatId))
+	}
+
+	var next Notifier = Nop{}
+	if len(backends) > 0 {
+		next = backends
+	}
+
+	return NewAggregator(next, cfg.NotifyCooldown, cfg.NotifyEventCooldown)
+}
+
+// NewReportNotifier builds the notifier used for low-urgency reports such as daily summaries - it is configured
+// separately from the real-time notifier so reports don't flood chat channels
+func NewReportNotifier(cfg *configs.Config) Notifier {
+	if len(cfg.ReportEmailTo) == 0 {
+		return Nop{}
+	}
+	switch cfg.ReportEmailBackend {
+	case "smtp":
+		return NewSMTP(cfg.SmtpHost, cfg.SmtpPort, cfg.SmtpUsername, cfg.SmtpPassword, cfg.ReportEmailFrom, cfg.ReportEmailTo)
+	case "sendgrid":
+		return NewSendGrid(cfg.SendGridApiKey, cfg.ReportEmailFrom, cfg.ReportEmailTo)
+	default:
+		return Nop{}
+	}
+}
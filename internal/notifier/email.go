@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SMTP delivers alerts as plain-text email through an SMTP relay
+type SMTP struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewSMTP creates an email notifier that authenticates with PLAIN auth when a username is given
+func NewSMTP(host string, port int, username string, password string, from string, to []string) *SMTP {
+	return &SMTP{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+	}
+}
+
+// Notify sends the alert as an email - net/smtp does not accept a context, so the deadline is only checked up front
+func (s *SMTP) Notify(ctx context.Context, alert Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + s.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(s.to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + subject(alert) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(alert.Body + "\r\n")
+
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	return smtp.SendMail(addr, auth, s.from, s.to, []byte(msg.String()))
+}
+
+// SendGrid delivers alerts as email through the SendGrid v3 API
+type SendGrid struct {
+	apiKey string
+	from   string
+	to     []string
+	hc     *http.Client
+}
+
+// NewSendGrid creates an email notifier backed by SendGrid
+func NewSendGrid(apiKey string, from string, to []string) *SendGrid {
+	return &SendGrid{
+		apiKey: apiKey,
+		from:   from,
+		to:     to,
+		hc:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends the alert as an email through the SendGrid API
+func (s *SendGrid) Notify(ctx context.Context, alert Alert) error {
+	type address struct {
+		Email string `json:"email"`
+	}
+	to := make([]address, 0, len(s.to))
+	for _, addr := range s.to {
+		to = append(to, address{addr})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": to}},
+		"from":             address{s.from},
+		"subject":          subject(alert),
+		"content":          []map[string]string{{"type": "text/plain", "value": alert.Body}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("sendgrid returned %d: %s", res.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// subject builds the email subject line for an alert
+func subject(alert Alert) string {
+	return fmt.Sprintf("[ninetyfive] [%s] %s", alert.Severity, alert.Title)
+}
@@ -0,0 +1,69 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Severity ranks alerts so escalations and routing can be expressed in one place
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// String returns the label shown in notifications
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return "INFO"
+	}
+}
+
+// Alert is a single notification - Key identifies the event type and is what deduplication is based on
+type Alert struct {
+	Key      string
+	Severity Severity
+	Title    string
+	Body     string
+}
+
+// Text renders the alert as a plain message for chat-style backends
+func (a Alert) Text() string {
+	if a.Body == "" {
+		return fmt.Sprintf("[%s] %s", a.Severity, a.Title)
+	}
+	return fmt.Sprintf("[%s] %s\n%s", a.Severity, a.Title, a.Body)
+}
+
+// Notifier delivers alerts to operators
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Nop discards every alert and is used when no backend is configured
+type Nop struct{}
+
+func (Nop) Notify(context.Context, Alert) error {
+	return nil
+}
+
+// Multi fans an alert out to several backends, attempting all of them even when one fails
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const telegramEndpoint = "https://api.telegram.org/bot%s/sendMessage"
+
+// Telegram sends alerts to a chat through the Telegram Bot API
+type Telegram struct {
+	token  string
+	chatId string
+	hc     *http.Client
+}
+
+// NewTelegram creates a Telegram notifier for the given bot token and chat
+func NewTelegram(token string, chatId string) *Telegram {
+	return &Telegram{
+		token:  token,
+		chatId: chatId,
+		hc:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify posts the alert text to the configured chat
+func (t *Telegram) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": t.chatId,
+		"text":    alert.Text(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(telegramEndpoint, t.token), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := t.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("telegram returned %d: %s", res.StatusCode, string(msg))
+	}
+	return nil
+}
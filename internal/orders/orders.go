@@ -0,0 +1,652 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/anomaly"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/execution"
+	"github.com/josephawallace/ninetyfive/internal/hooks"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/position"
+)
+
+// Status is the lifecycle state of an order or one of its slices
+type Status string
+
+const (
+	StatusWorking   Status = "WORKING"
+	StatusSubmitted Status = "SUBMITTED"
+	StatusApproval  Status = "PENDING_APPROVAL" // proposed to the multisig, waiting for its members to execute it
+	StatusFilled    Status = "FILLED"
+	StatusPartial   Status = "PARTIALLY_FILLED"
+	StatusFailed    Status = "FAILED"
+)
+
+// Residual policies decide what happens to the unfilled remainder of an order
+const (
+	ResidualChase  = "chase"  // resubmit the remainder immediately
+	ResidualRest   = "rest"   // resubmit the remainder after a pause, letting the algorithm re-trigger
+	ResidualCancel = "cancel" // settle the order with whatever filled
+)
+
+// retention is how long settled orders stay queryable in memory
+const retention = 24 * time.Hour
+
+// dust is the smallest residual worth acting on - anything below is float noise from slicing
+const dust = 1e-9
+
+// Slice is one swap executed as part of a logical order
+type Slice struct {
+	Index     int       `json:"index"`
+	Size      float64   `json:"size"`
+	TxId      string    `json:"tx_id,omitempty"`
+	Proposal  string    `json:"proposal,omitempty"` // the multisig proposal of the slice's swap, when swaps are proposed
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	SentAt    time.Time `json:"sent_at,omitempty"`
+	Resubmits int       `json:"resubmits,omitempty"` // times the slice was sent again after its swap was dropped
+}
+
+// Order is a logical order created from a single signal - it may be executed as several slices
+type Order struct {
+	Id         string          `json:"id"`
+	Side       common.Signal   `json:"side"`
+	Pair       common.Pair     `json:"pair"`
+	InputMint  string          `json:"input_mint"`
+	OutputMint string          `json:"output_mint"`
+	Size       float64         `json:"size"`
+	Price      float64         `json:"price"`
+	Effect     position.Effect `json:"effect"`
+	Algo       string          `json:"algo"`
+	Status     Status          `json:"status"`
+	Filled     float64         `json:"filled"`
+	Residual   float64         `json:"residual"`
+	Retries    int             `json:"retries"`
+	SignalTime time.Time       `json:"signal_time"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Deadline   time.Time       `json:"deadline"`
+	Slices     []Slice         `json:"slices"`
+
+	ctx      context.Context
+	algo     execution.Algo
+	snapshot *ledger.Snapshot
+}
+
+// Request describes the order to create from a signal
+type Request struct {
+	Side       common.Signal
+	Pair       common.Pair
+	Size       float64 // in the quote asset for BUY and the base asset for SELL
+	Effect     position.Effect
+	Price      float64          // reference price when the signal fired, recorded with each fill
+	SignalTime time.Time        // close of the bar that produced the signal, used to enforce the latency budget
+	Snapshot   *ledger.Snapshot // what the bot knew when it decided, journaled with each fill
+}
+
+// Policy bounds how long an order may be worked and what happens to its unfilled remainder
+type Policy struct {
+	LatencyBudget time.Duration
+	Timeout       time.Duration
+	Residual      string
+	MaxRetries    int
+	RestDelay     time.Duration
+	StuckTimeout  time.Duration // how long a swap whose confirmation was lost is resolved for
+	StuckAction   string        // resubmit or fail a swap found dropped
+	MaxResubmits  int
+}
+
+// NewPolicy reads the order policy from the config
+func NewPolicy(cfg *configs.Config) Policy {
+	return Policy{
+		LatencyBudget: cfg.MaxSignalLatency,
+		Timeout:       cfg.OrderTimeout,
+		Residual:      cfg.ResidualPolicy,
+		MaxRetries:    cfg.ResidualMaxRetries,
+		RestDelay:     cfg.ResidualRest,
+		StuckTimeout:  cfg.StuckTxTimeout,
+		StuckAction:   cfg.StuckTxAction,
+		MaxResubmits:  cfg.StuckTxMaxResubmits,
+	}
+}
+
+// Swapper is the subset of the Jupiter wrapper needed to execute and follow swaps
+type Swapper interface {
+	Execute(ctx context.Context, pair common.Pair, side common.Signal, size float64, tag string) (string, error)
+	MonitorTx(ctx context.Context, txId string, log logger.Logger, reached func(stage string)) error
+	ComputeBudget(txId string) (common.ComputeBudget, bool)
+	Fill(ctx context.Context, txId string) (common.Fill, error)
+	Resolve(ctx context.Context, txId string) (common.TxStatus, error)
+}
+
+// simulator is implemented by swappers that only pretend to swap, whose fills are recorded as simulated
+type simulator interface {
+	Simulated() bool
+}
+
+// approver is implemented by swappers that propose swaps to a multisig instead of sending them - Execute returns the
+// proposal, and the swap only exists once the members approved and executed it
+type approver interface {
+	Proposing() bool
+	AwaitExecution(ctx context.Context, proposal string) (string, error)
+}
+
+// Manager executes logical orders and keeps track of their slices until they are settled
+type Manager struct {
+	s      Swapper
+	l      *ledger.Ledger
+	n      *notifier.Aggregator
+	log    logger.Logger
+	policy Policy
+	watch  *anomaly.Detector
+	hooks  *hooks.Dispatcher
+
+	mu       sync.RWMutex
+	orders   map[string]*Order
+	outcomes []outcome
+}
+
+// outcome is whether a submitted swap landed, kept for the recent failure rate
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// maxOutcomes bounds the swap outcomes kept for the failure rate
+const maxOutcomes = 1000
+
+// NewManager creates an order manager on top of the given swapper, recording fills in the ledger, showing them to the
+// anomaly detector, and posting the lifecycle of its swaps to the webhooks - a nil detector watches nothing and a nil
+// dispatcher posts nothing
+func NewManager(s Swapper, l *ledger.Ledger, n *notifier.Aggregator, log logger.Logger, policy Policy,
+	watch *anomaly.Detector, hooks *hooks.Dispatcher) *Manager {
+	return &Manager{
+		s:      s,
+		l:      l,
+		n:      n,
+		log:    log,
+		policy: policy,
+		watch:  watch,
+		hooks:  hooks,
+		orders: make(map[string]*Order),
+	}
+}
+
+// Execute creates a logical order and works it in the background according to the execution algorithm - only an
+// invalid request is returned as an error, execution failures are reported through notifications and the order's
+// status
+func (m *Manager) Execute(ctx context.Context, req Request, algo execution.Algo) (*Order, error) {
+	inputMint, outputMint, err := req.Pair.Mints(req.Side)
+	if err != nil {
+		return nil, err
+	}
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("order size must be positive")
+	}
+	children := algo.Schedule(req.Size)
+
+	now := time.Now()
+	o := &Order{
+		Id:         fmt.Sprintf("%s-%d", req.Side, now.UnixNano()),
+		Side:       req.Side,
+		Pair:       req.Pair,
+		InputMint:  inputMint,
+		OutputMint: outputMint,
+		Size:       req.Size,
+		Price:      req.Price,
+		SignalTime: req.SignalTime,
+		Effect:     req.Effect,
+		Algo:       algo.Name(),
+		Status:     StatusWorking,
+		Residual:   req.Size,
+		CreatedAt:  now,
+		Slices:     make([]Slice, len(children)),
+		ctx:        ctx,
+		algo:       algo,
+		snapshot:   req.Snapshot,
+	}
+	if m.policy.Timeout > 0 {
+		o.Deadline = now.Add(m.policy.Timeout)
+	}
+	delays := make([]time.Duration, len(children))
+	for i, c := range children {
+		o.Slices[i] = Slice{Index: i, Size: c.Size, Status: StatusWorking}
+		delays[i] = c.Delay
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.orders[o.Id] = o
+	m.mu.Unlock()
+
+	go m.work(o, 0, delays)
+	return m.Order(o.Id), nil
+}
+
+// Order returns a copy of the order with the given id, or nil if it is unknown
+func (m *Manager) Order(id string) *Order {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	o, ok := m.orders[id]
+	if !ok {
+		return nil
+	}
+	c := *o
+	c.Slices = append([]Slice(nil), o.Slices...)
+	return &c
+}
+
+// Pending returns how much of the open orders of the given side has not filled yet
+func (m *Manager) Pending(side common.Signal) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pending := 0.0
+	for _, o := range m.orders {
+		if o.Status != StatusWorking || o.Side != side {
+			continue
+		}
+		for _, s := range o.Slices {
+			if s.Status == StatusWorking || s.Status == StatusSubmitted || s.Status == StatusApproval {
+				pending += s.Size
+			}
+		}
+	}
+	return pending
+}
+
+// Open returns copies of all orders that still have unsettled slices
+func (m *Manager) Open() []Order {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	open := make([]Order, 0)
+	for _, o := range m.orders {
+		if o.Status == StatusWorking {
+			c := *o
+			c.Slices = append([]Slice(nil), o.Slices...)
+			open = append(open, c)
+		}
+	}
+	return open
+}
+
+// InFlight returns the transaction ids of submitted slices that have not settled yet
+func (m *Manager) InFlight() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var txIds []string
+	for _, o := range m.orders {
+		for _, s := range o.Slices {
+			if s.Status == StatusSubmitted {
+				txIds = append(txIds, s.TxId)
+			}
+		}
+	}
+	return txIds
+}
+
+// Sent reports whether the transaction is a swap of an order the manager still holds, settled or not
+func (m *Manager) Sent(txId string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, o := range m.orders {
+		for _, s := range o.Slices {
+			if s.TxId == txId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// work submits the slices starting at the given index, waiting the matching delay before each one
+func (m *Manager) work(o *Order, from int, delays []time.Duration) {
+	for i := from; i < from+len(delays); i++ {
+		select {
+		case <-o.ctx.Done():
+			m.abandon(o, i, "not submitted before shutdown")
+			return
+		case <-time.After(delays[i-from]):
+		}
+
+		if !o.Deadline.IsZero() && time.Now().After(o.Deadline) {
+			m.abandon(o, i, "not submitted before the order timed out")
+			return
+		}
+		m.mu.RLock()
+		size := o.Slices[i].Size
+		m.mu.RUnlock()
+		if err := o.algo.Trigger(o.ctx, o.InputMint, o.OutputMint, size); err != nil {
+			m.abandon(o, i, fmt.Sprintf("execution algorithm did not trigger: %s", err))
+			return
+		}
+		// Only the first slice is held to the latency budget - later slices are deliberately delayed by the algorithm
+		if i == 0 && m.policy.LatencyBudget > 0 && !o.SignalTime.IsZero() {
+			if elapsed := time.Since(o.SignalTime); elapsed > m.policy.LatencyBudget {
+				m.log.Warn().Msg("skipping order %s - %s elapsed since the signal, over the %s latency budget",
+					o.Id, elapsed.Round(time.Millisecond), m.policy.LatencyBudget)
+				m.abandon(o, i, "latency budget exceeded")
+				return
+			}
+		}
+		if err := m.submitSlice(o, i, size); err != nil {
+			m.abandon(o, i+1, "not submitted because an earlier slice failed")
+			return
+		}
+	}
+}
+
+// submitSlice sends one slice and starts monitoring it
+func (m *Manager) submitSlice(o *Order, i int, size float64) error {
+	ctx := o.ctx
+	txId, err := m.s.Execute(ctx, o.Pair, o.Side, size, fmt.Sprintf("%s/%d", o.Id, i+1))
+	if err != nil {
+		m.observe(true)
+		m.updateSlice(o, i, func(s *Slice) {
+			s.Status = StatusFailed
+			s.Error = err.Error()
+		})
+		m.emit(o, i, hooks.Event{Type: hooks.OrderFailed, Size: size, Error: err.Error()})
+		m.alert(ctx, notifier.KeySwapSubmit, notifier.SeverityCritical, "failed to submit swap", err)
+		return err
+	}
+	m.clear(ctx, notifier.KeySwapSubmit)
+	if a, ok := m.s.(approver); ok && a.Proposing() {
+		m.log.Info().Msg("proposed slice %d of order %s to the multisig as %s - awaiting approval", i+1, o.Id, txId)
+		m.updateSlice(o, i, func(s *Slice) {
+			s.Status = StatusApproval
+			s.Proposal = txId
+			s.SentAt = time.Now()
+		})
+		m.emit(o, i, hooks.Event{Type: hooks.OrderProposed, Proposal: txId, Size: size})
+		go m.approve(o, i, size, a, txId)
+		return nil
+	}
+	m.emit(o, i, hooks.Event{Type: hooks.OrderSubmitted, TxId: txId, Size: size})
+
+	m.log.Info().Msg("submitted slice %d of order %s as swap %s", i+1, o.Id, txId)
+	var compute *common.ComputeBudget
+	if budget, ok := m.s.ComputeBudget(txId); ok {
+		compute = &budget
+	}
+	m.updateSlice(o, i, func(s *Slice) {
+		s.Status = StatusSubmitted
+		s.TxId = txId
+		s.SentAt = time.Now()
+	})
+	go m.follow(o, i, size, txId, compute)
+	return nil
+}
+
+// approve waits for the multisig's members to execute the slice's proposed swap, then follows the swap as one that
+// was sent - a proposal that is not executed fails the slice
+func (m *Manager) approve(o *Order, i int, size float64, a approver, proposal string) {
+	ctx := o.ctx
+	txId, err := a.AwaitExecution(ctx, proposal)
+	if err != nil {
+		m.observe(true)
+		m.updateSlice(o, i, func(s *Slice) {
+			s.Status = StatusFailed
+			s.Error = err.Error()
+		})
+		m.emit(o, i, hooks.Event{Type: hooks.OrderFailed, Proposal: proposal, Size: size, Error: err.Error()})
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w - reject it in the multisig unless it should still execute", err)
+		}
+		m.alert(ctx, notifier.KeyApproval, notifier.SeverityCritical, "swap proposal not executed", err)
+		return
+	}
+	m.clear(ctx, notifier.KeyApproval)
+	m.log.Info().Msg("proposal %s of slice %d of order %s executed as swap %s", proposal, i+1, o.Id, txId)
+	m.emit(o, i, hooks.Event{Type: hooks.OrderSubmitted, TxId: txId, Proposal: proposal, Size: size})
+	m.updateSlice(o, i, func(s *Slice) {
+		s.Status = StatusSubmitted
+		s.TxId = txId
+	})
+	m.follow(o, i, size, txId, nil)
+}
+
+// follow monitors the slice's swap until it confirms, recording its fill, or fails - a swap whose confirmation was
+// lost is resolved and may be sent again when it was dropped
+func (m *Manager) follow(o *Order, i int, size float64, txId string, compute *common.ComputeBudget) {
+	ctx := o.ctx
+	reached := func(stage string) {
+		if stage == "confirmed" {
+			m.emit(o, i, hooks.Event{Type: hooks.OrderConfirmed, TxId: txId, Size: size})
+		}
+	}
+	if err := m.s.MonitorTx(ctx, txId, m.log, reached); err != nil {
+		// A swap whose confirmation was lost is resolved before the slice is given up on, as it may have landed regardless
+		status := m.resolve(ctx, txId)
+		if status != common.TxLanded {
+			m.observe(true)
+			if status == common.TxDropped && m.resubmit(o, i, size, txId) {
+				return
+			}
+			m.updateSlice(o, i, func(s *Slice) {
+				s.Status = StatusFailed
+				s.Error = fmt.Sprintf("%s - swap %s", err, status)
+			})
+			severity := notifier.SeverityWarning
+			if status == common.TxPending {
+				severity = notifier.SeverityCritical
+				err = fmt.Errorf("%w - swap %s is unresolved and may still land, check the wallet", err, txId)
+			}
+			m.emit(o, i, hooks.Event{Type: hooks.OrderFailed, TxId: txId, Size: size,
+				Error: fmt.Sprintf("%s - swap %s", err, status)})
+			m.alert(ctx, notifier.KeyTxMonitor, severity, "transaction not confirmed", err)
+			return
+		}
+		m.log.Warn().Msg("swap %s landed although its confirmation could not be followed", txId)
+	}
+	m.observe(false)
+	// What the swap actually exchanged is only known once it confirmed
+	var fill *common.Fill
+	if f, err := m.s.Fill(ctx, txId); err != nil {
+		m.log.Warn().Err(err).Msg("failed to read the fill of %s", txId)
+	} else {
+		fill = &f
+		m.watch.CheckFill(ctx, txId, f)
+	}
+	m.recordFill(o, txId, size, compute, fill)
+	m.updateSlice(o, i, func(s *Slice) { s.Status = StatusFilled })
+	finalized := hooks.Event{Type: hooks.OrderFinalized, TxId: txId, Size: size}
+	if fill != nil {
+		finalized.OutAmount = fill.OutAmount
+	}
+	m.emit(o, i, finalized)
+	m.clear(ctx, notifier.KeyTxMonitor)
+}
+
+// emit posts a lifecycle event of the order's slice to the webhooks
+func (m *Manager) emit(o *Order, i int, e hooks.Event) {
+	e.OrderId, e.Slice, e.Pair, e.Side = o.Id, i+1, o.Pair.String(), o.Side
+	m.hooks.Send(e)
+}
+
+// recordFill writes a filled slice to the ledger, with what it received and paid when the fill could be read
+func (m *Manager) recordFill(o *Order, txId string, size float64, compute *common.ComputeBudget, fill *common.Fill) {
+	trade := ledger.Trade{
+		TxId:        txId,
+		OrderId:     o.Id,
+		Side:        o.Side,
+		InputMint:   o.InputMint,
+		OutputMint:  o.OutputMint,
+		InputAmount: size,
+		Price:       o.Price,
+		Time:        time.Now(),
+		Compute:     compute,
+		Snapshot:    o.snapshot,
+	}
+	if sim, ok := m.s.(simulator); ok {
+		trade.Simulated = sim.Simulated()
+	}
+	if fill != nil {
+		trade.OutputAmount = fill.OutAmount
+		trade.FeeLamports = fill.FeeLamports
+		trade.PlatformFee = fill.PlatformFee
+	}
+	if err := m.l.RecordTrade(trade); err != nil {
+		m.log.Error().Err(err).Msg("failed to record fill of %s in the ledger", txId)
+	}
+}
+
+// observe keeps whether a swap landed or failed, to be submitted or to confirm
+func (m *Manager) observe(failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes = append(m.outcomes, outcome{at: time.Now(), failed: failed})
+	if len(m.outcomes) > maxOutcomes {
+		m.outcomes = m.outcomes[len(m.outcomes)-maxOutcomes:]
+	}
+}
+
+// FailureRate returns the share of the swaps submitted over the trailing window that failed, and how many there were
+func (m *Manager) FailureRate(window time.Duration) (float64, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var failed, total int
+	since := time.Now().Add(-window)
+	for _, o := range m.outcomes {
+		if o.at.After(since) {
+			total++
+			if o.failed {
+				failed++
+			}
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failed) / float64(total), total
+}
+
+// updateSlice applies a change to a slice and settles the order once every slice is final
+func (m *Manager) updateSlice(o *Order, i int, apply func(s *Slice)) {
+	m.mu.Lock()
+	apply(&o.Slices[i])
+	m.mu.Unlock()
+	m.finish(o)
+}
+
+// abandon fails every unsubmitted slice from the given index onwards
+func (m *Manager) abandon(o *Order, from int, reason string) {
+	m.mu.Lock()
+	for i := from; i < len(o.Slices); i++ {
+		if o.Slices[i].Status == StatusWorking {
+			o.Slices[i].Status = StatusFailed
+			o.Slices[i].Error = reason
+		}
+	}
+	m.mu.Unlock()
+	m.finish(o)
+}
+
+// prune drops settled orders older than the retention period so the manager does not grow without bound - callers
+// must hold the lock
+func (m *Manager) prune() {
+	for id, o := range m.orders {
+		if o.Status != StatusWorking && time.Since(o.CreatedAt) > retention {
+			delete(m.orders, id)
+		}
+	}
+}
+
+// finish runs once none of the order's slices are pending - an unfilled residual is handled per the residual policy,
+// otherwise the order is settled and its outcome recorded in the ledger
+func (m *Manager) finish(o *Order) {
+	m.mu.Lock()
+	if o.Status != StatusWorking {
+		m.mu.Unlock()
+		return
+	}
+	filled := 0.0
+	for _, s := range o.Slices {
+		switch s.Status {
+		case StatusWorking, StatusSubmitted, StatusApproval:
+			m.mu.Unlock()
+			return
+		case StatusFilled:
+			filled += s.Size
+		}
+	}
+	o.Filled = filled
+	o.Residual = max(0, o.Size-filled)
+
+	// Retry the remainder as a new slice while the policy, the retry budget, and the deadline allow it
+	if delay, ok := m.retryDelay(o); ok {
+		o.Retries++
+		o.Slices = append(o.Slices, Slice{Index: len(o.Slices), Size: o.Residual, Status: StatusWorking})
+		next := len(o.Slices) - 1
+		m.log.Warn().Msg("order %s filled %f of %f - retrying the residual %f (%s, attempt %d)",
+			o.Id, o.Filled, o.Size, o.Residual, m.policy.Residual, o.Retries)
+		m.mu.Unlock()
+		go m.work(o, next, []time.Duration{delay})
+		return
+	}
+
+	switch {
+	case o.Residual <= dust:
+		o.Status = StatusFilled
+	case o.Filled > dust:
+		o.Status = StatusPartial
+	default:
+		o.Status = StatusFailed
+	}
+	record := ledger.OrderRecord{
+		OrderId:    o.Id,
+		Side:       o.Side,
+		Effect:     string(o.Effect),
+		Size:       o.Size,
+		FilledSize: o.Filled,
+		Residual:   o.Residual,
+		Status:     string(o.Status),
+		CreatedAt:  o.CreatedAt,
+		SettledAt:  time.Now(),
+	}
+	m.mu.Unlock()
+
+	m.log.Info().Msg("order %s settled as %s with %f of %f filled", o.Id, record.Status, record.FilledSize, record.Size)
+	m.watch.OrderSettled(o.ctx, o.Id, record.FilledSize > dust)
+	if err := m.l.RecordOrder(record); err != nil {
+		m.log.Error().Err(err).Msg("failed to record order %s in the ledger", o.Id)
+	}
+}
+
+// retryDelay decides whether the order's residual should be retried and after how long - callers must hold the lock
+func (m *Manager) retryDelay(o *Order) (time.Duration, bool) {
+	if o.Residual <= dust || o.Retries >= m.policy.MaxRetries || o.ctx.Err() != nil {
+		return 0, false
+	}
+	if !o.Deadline.IsZero() && time.Now().After(o.Deadline) {
+		return 0, false
+	}
+	switch m.policy.Residual {
+	case ResidualChase:
+		return 0, true
+	case ResidualRest:
+		return m.policy.RestDelay, true
+	default:
+		return 0, false
+	}
+}
+
+// alert sends a deduplicated notification, logging rather than failing when delivery does not work
+func (m *Manager) alert(ctx context.Context, key string, severity notifier.Severity, title string, err error) {
+	if nErr := m.n.Notify(ctx, notifier.Alert{Key: key, Severity: severity, Title: title, Body: err.Error()}); nErr != nil {
+		m.log.Error().Err(nErr).Msg("failed to send notification")
+	}
+}
+
+// clear resolves a previously alerted condition
+func (m *Manager) clear(ctx context.Context, key string) {
+	if err := m.n.Clear(ctx, key); err != nil {
+		m.log.Error().Err(err).Msg("failed to send notification")
+	}
+}
@@ -0,0 +1,66 @@
+package orders
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Stuck swap actions
+const (
+	StuckResubmit = "resubmit"
+	StuckFail     = "fail"
+)
+
+// stuckPoll is the time between status checks of a swap being resolved
+const stuckPoll = 5 * time.Second
+
+// resolve follows a swap whose confirmation was lost until it is known to have landed, failed, or been dropped - one
+// still pending when the policy's timeout runs out is reported pending, so the order is never blocked on it forever
+func (m *Manager) resolve(ctx context.Context, txId string) common.TxStatus {
+	if m.policy.StuckTimeout <= 0 {
+		return common.TxPending
+	}
+	deadline := time.Now().Add(m.policy.StuckTimeout)
+	for {
+		status, err := m.s.Resolve(ctx, txId)
+		switch {
+		case err != nil:
+			m.log.Warn().Err(err).Msg("failed to resolve swap %s", txId)
+		case status != common.TxPending:
+			m.log.Info().Msg("resolved swap %s as %s", txId, status)
+			return status
+		}
+		if time.Now().Add(stuckPoll).After(deadline) {
+			m.log.Error().Msg("swap %s is still unresolved after %s", txId, m.policy.StuckTimeout)
+			return common.TxPending
+		}
+		select {
+		case <-ctx.Done():
+			return common.TxPending
+		case <-time.After(stuckPoll):
+		}
+	}
+}
+
+// resubmit sends a slice whose swap was dropped again when the policy allows it, reporting whether it did - a dropped
+// swap's blockhash has expired, so it can never land alongside the new one
+func (m *Manager) resubmit(o *Order, i int, size float64, txId string) bool {
+	m.mu.RLock()
+	resubmits := o.Slices[i].Resubmits
+	m.mu.RUnlock()
+	if m.policy.StuckAction != StuckResubmit || resubmits >= m.policy.MaxResubmits || o.ctx.Err() != nil ||
+		(!o.Deadline.IsZero() && time.Now().After(o.Deadline)) {
+		return false
+	}
+	m.log.Warn().Msg("swap %s of order %s was dropped - resubmitting slice %d", txId, o.Id, i+1)
+	m.updateSlice(o, i, func(s *Slice) {
+		s.Status = StatusWorking
+		s.TxId = ""
+		s.Resubmits++
+	})
+	// A failed resubmission marks the slice failed itself
+	_ = m.submitSlice(o, i, size)
+	return true
+}
@@ -0,0 +1,121 @@
+package parity
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// rsiTolerance absorbs float noise when comparing indicator values across refactors
+const rsiTolerance = 1e-9
+
+// Fixture is a recorded run of a strategy over a series of bars - replaying it after the Pine port's math has been
+// refactored proves the refactor did not change a single signal or indicator value
+type Fixture struct {
+	Strategy configs.Strategy `json:"strategy"`
+	Bars     []common.Bar     `json:"bars"`
+	Expected []Expectation    `json:"expected"` // one per bar
+}
+
+// Expectation is what the strategy produced on a bar when the fixture was recorded
+type Expectation struct {
+	Signal common.Signal `json:"signal"`
+	Rsi    float64       `json:"rsi"`
+}
+
+// Generate runs the strategy over the bars and records its output on every bar
+func Generate(s configs.Strategy, bars []common.Bar, log logger.Logger) (Fixture, error) {
+	gm, err := ensemble.NewStrategy(s, log)
+	if err != nil {
+		return Fixture{}, err
+	}
+	f := Fixture{Strategy: s, Bars: bars, Expected: make([]Expectation, 0, len(bars))}
+	for i, b := range bars {
+		signal, err := gm.ProcessBar(b)
+		if err != nil {
+			return Fixture{}, fmt.Errorf("bar %d: %w", i, err)
+		}
+		f.Expected = append(f.Expected, Expectation{Signal: signal, Rsi: gm.Rsi()})
+	}
+	return f, nil
+}
+
+// Replay runs the fixture's strategy over its bars again and reports every bar whose signal or indicator value
+// differs from the recording
+func Replay(f Fixture, log logger.Logger) ([]Mismatch, error) {
+	if len(f.Expected) != len(f.Bars) {
+		return nil, fmt.Errorf("fixture has %d bars but %d expectations", len(f.Bars), len(f.Expected))
+	}
+	gm, err := ensemble.NewStrategy(f.Strategy, log)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []Mismatch
+	for i, b := range f.Bars {
+		prevRsi := gm.Rsi()
+		got, err := gm.ProcessBar(b)
+		if err != nil {
+			return nil, fmt.Errorf("bar %d: %w", i, err)
+		}
+		want := f.Expected[i]
+		if got == want.Signal && math.Abs(gm.Rsi()-want.Rsi) <= rsiTolerance {
+			continue
+		}
+		mismatches = append(mismatches, Mismatch{
+			Index:       i,
+			Time:        b.Time,
+			Close:       b.Close,
+			Expected:    want.Signal,
+			Got:         got,
+			ExpectedRsi: want.Rsi,
+			PrevRsi:     prevRsi,
+			State:       gm.State(),
+		})
+	}
+	return mismatches, nil
+}
+
+// WriteFixture saves the fixture as indented JSON, named after its strategy, in dir
+func WriteFixture(dir string, f Fixture) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, f.Strategy.Name+".json")
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadFixtures reads every fixture in dir, in file name order
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err = json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,130 @@
+package parity
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// fixtureDir holds the fixtures gen-fixtures recorded over 240 synthetic hourly bars, one per rsi_type and direction
+const fixtureDir = "testdata/fixtures"
+
+func TestReplayFixtures(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		signals int
+	}{
+		{"connors-asymmetric", 50},
+		{"laguerre-down", 9},
+		{"rsi-up", 9},
+		{"rsx-neutral", 29},
+	}
+	if len(fixtures) != len(tests) {
+		t.Fatalf("got %d fixtures in %s, want %d", len(fixtures), fixtureDir, len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fixtures[i]
+			if f.Strategy.Name != tt.name {
+				t.Fatalf("fixture %d is strategy %s, want %s", i, f.Strategy.Name, tt.name)
+			}
+			var signals int
+			for _, e := range f.Expected {
+				if e.Signal != common.DoNothingSignal {
+					signals++
+				}
+			}
+			if signals != tt.signals {
+				t.Errorf("fixture records %d signals, want %d", signals, tt.signals)
+			}
+			mismatches, err := Replay(f, logger.NopLogger{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range mismatches {
+				t.Errorf("bar %d (%s): got %s, want %s (rsi want %v)", m.Index, m.Time, m.Got, m.Expected, m.ExpectedRsi)
+			}
+		})
+	}
+}
+
+func TestReplayDetectsChanges(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := fixtures[0]
+	tests := []struct {
+		name   string
+		tamper func(e *Expectation)
+	}{
+		{"signal", func(e *Expectation) {
+			if e.Signal == common.BuySignal {
+				e.Signal = common.SellSignal
+			} else {
+				e.Signal = common.BuySignal
+			}
+		}},
+		{"rsi", func(e *Expectation) { e.Rsi += 1e-6 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := f
+			tampered.Expected = append([]Expectation(nil), f.Expected...)
+			const bar = 100
+			tt.tamper(&tampered.Expected[bar])
+			mismatches, err := Replay(tampered, logger.NopLogger{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(mismatches) != 1 || mismatches[0].Index != bar {
+				t.Fatalf("got mismatches %+v, want one at bar %d", mismatches, bar)
+			}
+		})
+	}
+}
+
+func TestReplayRejectsMisalignedFixture(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := fixtures[0]
+	f.Expected = f.Expected[:len(f.Expected)-1]
+	if _, err = Replay(f, logger.NopLogger{}); err == nil || !strings.Contains(err.Error(), "expectations") {
+		t.Fatalf("got error %v, want one about the expectations", err)
+	}
+}
+
+func TestWriteFixtureRoundTrips(t *testing.T) {
+	fixtures, err := LoadFixtures(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path, err := WriteFixture(dir, fixtures[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, fixtures[0].Strategy.Name+".json"); path != want {
+		t.Errorf("wrote %s, want %s", path, want)
+	}
+	loaded, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || len(loaded[0].Bars) != len(fixtures[0].Bars) {
+		t.Fatalf("reloaded %d fixtures, want the one written with %d bars", len(loaded), len(fixtures[0].Bars))
+	}
+	mismatches, err := Replay(loaded[0], logger.NopLogger{})
+	if err != nil || len(mismatches) > 0 {
+		t.Fatalf("replaying the rewritten fixture: %d mismatches, err %v", len(mismatches), err)
+	}
+}
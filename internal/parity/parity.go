@@ -0,0 +1,192 @@
+package parity
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/gridmanager"
+)
+
+// Mismatch is a bar where the Grid Manager and the Pine Script disagree, with the RSI of the bar before - the grid
+// crossings are judged against it - and the Grid Manager's state after the bar
+type Mismatch struct {
+	Index       int               `json:"index"`
+	Time        time.Time         `json:"time"`
+	Close       float64           `json:"close"`
+	Expected    common.Signal     `json:"expected"`
+	Got         common.Signal     `json:"got"`
+	ExpectedRsi float64           `json:"expected_rsi,omitempty"` // only known when replaying fixtures
+	PrevRsi     float64           `json:"prev_rsi"`
+	State       gridmanager.State `json:"state"`
+}
+
+// Report summarizes a replay of the Grid Manager over a chart
+type Report struct {
+	Bars       int        `json:"bars"`
+	Signals    int        `json:"signals"`
+	Matched    int        `json:"matched"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// Ok reports whether the Grid Manager reproduced every signal and no others
+func (r Report) Ok() bool {
+	return len(r.Mismatches) == 0
+}
+
+// Run feeds the bars through the Grid Manager in order and compares its signal on each bar with the signal the Pine
+// Script produced on it - bars missing from signals are expected to do nothing
+func Run(gm *gridmanager.GridManager, bars []common.Bar, signals map[time.Time]common.Signal) (Report, error) {
+	r := Report{Bars: len(bars), Signals: len(signals)}
+	for i, b := range bars {
+		prevRsi := gm.Rsi()
+		got, err := gm.ProcessBar(b)
+		if err != nil {
+			return r, fmt.Errorf("bar %d (%s): %w", i, b.Time.Format(time.RFC3339), err)
+		}
+		want, ok := signals[b.Time]
+		if !ok {
+			want = common.DoNothingSignal
+		}
+		if got == want {
+			if want != common.DoNothingSignal {
+				r.Matched++
+			}
+			continue
+		}
+		r.Mismatches = append(r.Mismatches, Mismatch{
+			Index:    i,
+			Time:     b.Time,
+			Close:    b.Close,
+			Expected: want,
+			Got:      got,
+			PrevRsi:  prevRsi,
+			State:    gm.State(),
+		})
+	}
+	return r, nil
+}
+
+// LoadBars reads a TradingView chart export - a CSV with a header naming at least "time" and "close" columns, times
+// as unix seconds or RFC3339 - and returns its bars in file order - open, high, and low are read when the export has
+// them and default to the close otherwise - a candle cache file (.jsonl) is read as is, so backtests can run on the
+// bars the bot cached live
+func LoadBars(path string) ([]common.Bar, error) {
+	if strings.HasSuffix(path, ".jsonl") {
+		return candles.ReadFile(path)
+	}
+	rows, cols, err := readCsv(path, "time", "close")
+	if err != nil {
+		return nil, err
+	}
+	_, ohl, _ := readCsv(path, "open", "high", "low")
+
+	bars := make([]common.Bar, 0, len(rows))
+	for i, row := range rows {
+		t, err := parseTime(row[cols[0]])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, i+2, err)
+		}
+		c, err := strconv.ParseFloat(strings.TrimSpace(row[cols[1]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid close: %w", path, i+2, err)
+		}
+		b := common.PriceBar(t, c)
+		if ohl != nil {
+			for j, field := range []*float64{&b.Open, &b.High, &b.Low} {
+				if *field, err = strconv.ParseFloat(strings.TrimSpace(row[ohl[j]]), 64); err != nil {
+					return nil, fmt.Errorf("%s line %d: invalid open/high/low: %w", path, i+2, err)
+				}
+			}
+		}
+		bars = append(bars, b)
+	}
+	return bars, nil
+}
+
+// LoadSignals reads the signals the Pine Script fired - a CSV with a header naming "time" and "signal" columns, where
+// signal is BUY or SELL - keyed by the time of the bar they fired on
+func LoadSignals(path string) (map[time.Time]common.Signal, error) {
+	rows, cols, err := readCsv(path, "time", "signal")
+	if err != nil {
+		return nil, err
+	}
+	signals := make(map[time.Time]common.Signal, len(rows))
+	for i, row := range rows {
+		t, err := parseTime(row[cols[0]])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, i+2, err)
+		}
+		s := common.Signal(strings.ToUpper(strings.TrimSpace(row[cols[1]])))
+		if s != common.BuySignal && s != common.SellSignal {
+			return nil, fmt.Errorf("%s line %d: unknown signal %q", path, i+2, s)
+		}
+		signals[t] = s
+	}
+	return signals, nil
+}
+
+// readCsv reads a CSV file and locates the named columns in its header, matched case-insensitively
+func readCsv(path string, names ...string) ([][]string, []int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := make([]int, len(names))
+	for i, name := range names {
+		cols[i] = -1
+		for j, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				cols[i] = j
+				break
+			}
+		}
+		if cols[i] < 0 {
+			return nil, nil, fmt.Errorf("%s has no %q column", path, name)
+		}
+	}
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, row := range rows {
+		for _, c := range cols {
+			if c >= len(row) {
+				return nil, nil, fmt.Errorf("%s line %d: too few fields", path, i+2)
+			}
+		}
+	}
+	return rows, cols, nil
+}
+
+// parseTime accepts the time formats TradingView exports: unix seconds or RFC3339
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected unix seconds or RFC3339", s)
+	}
+	return t.UTC(), nil
+}
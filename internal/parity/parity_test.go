@@ -0,0 +1,150 @@
+package parity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// barsCsv is a synthetic chart of 240 hourly bars in TradingView's export format, and testdata/signals holds the
+// signals each strategy below fired on it when recorded, in the time,signal format the parity command reads
+const barsCsv = "testdata/bars.csv"
+
+func TestRunReplaysCsvFixtures(t *testing.T) {
+	bars, err := LoadBars(barsCsv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 240 {
+		t.Fatalf("got %d bars, want 240", len(bars))
+	}
+	tests := []struct {
+		strategy configs.Strategy
+		signals  int
+	}{
+		{configs.Strategy{Name: "connors-asymmetric", RsiLength: 3, Grids: 8, Direction: "neutral", NoTradeZone: "45-55",
+			Aggression: "low", RsiType: "connors", Source: "close", SellGrids: 5, SellNoTradeZone: "n/a"}, 50},
+		{configs.Strategy{Name: "laguerre-down", RsiLength: 7, Grids: 8, Direction: "down", NoTradeZone: "40-60",
+			Aggression: "low", RsiType: "laguerre", LaguerreGamma: 0.6, Source: "close"}, 9},
+		{configs.Strategy{Name: "rsi-up", RsiLength: 14, Grids: 6, Direction: "up", NoTradeZone: "n/a",
+			Aggression: "high", RsiType: "rsi", Source: "close"}, 9},
+		{configs.Strategy{Name: "rsx-neutral", RsiLength: 7, Grids: 10, Direction: "neutral", NoTradeZone: "35-65",
+			Aggression: "low", RsiType: "rsx", Source: "close"}, 29},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy.Name, func(t *testing.T) {
+			signals, err := LoadSignals(filepath.Join("testdata", "signals", tt.strategy.Name+".csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			gm, err := ensemble.NewStrategy(tt.strategy, logger.NopLogger{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := Run(gm, bars, signals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Bars != len(bars) || r.Signals != tt.signals || r.Matched != tt.signals {
+				t.Errorf("got %d bars, %d signals, %d matched, want %d bars and %d signals all matched", r.Bars,
+					r.Signals, r.Matched, len(bars), tt.signals)
+			}
+			for _, m := range r.Mismatches {
+				t.Errorf("bar %d (%s): got %s, want %s", m.Index, m.Time.Format(time.RFC3339), m.Got, m.Expected)
+			}
+			if !r.Ok() {
+				t.Errorf("report is not ok")
+			}
+		})
+	}
+}
+
+func TestRunReportsMismatches(t *testing.T) {
+	bars, err := LoadBars(barsCsv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gm, err := ensemble.NewStrategy(configs.Strategy{Name: "rsi-up", RsiLength: 14, Grids: 6, Direction: "up",
+		NoTradeZone: "n/a", Aggression: "high", RsiType: "rsi", Source: "close"}, logger.NopLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A signal the Pine Script never fired, on the first bar, where the RSI has not warmed up yet
+	r, err := Run(gm, bars, map[time.Time]common.Signal{bars[0].Time: common.BuySignal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Ok() || r.Mismatches[0].Index != 0 || r.Mismatches[0].Expected != common.BuySignal {
+		t.Fatalf("got mismatches %+v, want the first to be the BUY expected on bar 0", r.Mismatches)
+	}
+	// Every signal the Grid Manager fired is unexpected too
+	if len(r.Mismatches) != 1+9 {
+		t.Errorf("got %d mismatches, want 10", len(r.Mismatches))
+	}
+}
+
+func TestLoadBars(t *testing.T) {
+	bars, err := LoadBars(barsCsv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := common.Bar{Time: time.Unix(1704067200, 0).UTC(), Open: 100, High: 100.4, Low: 99.59, Close: 99.99}
+	if bars[0] != want {
+		t.Errorf("got first bar %+v, want %+v", bars[0], want)
+	}
+
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		csv     string
+		want    []common.Bar
+		wantErr bool
+	}{
+		{
+			name: "close only defaults open, high, and low to the close",
+			csv:  "Time,Close\n2024-01-01T00:00:00Z,5\n",
+			want: []common.Bar{common.PriceBar(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 5)},
+		},
+		{name: "missing close column", csv: "time,open\n1704067200,5\n", wantErr: true},
+		{name: "invalid time", csv: "time,close\nyesterday,5\n", wantErr: true},
+		{name: "invalid close", csv: "time,close\n1704067200,five\n", wantErr: true},
+		{name: "empty", csv: "", wantErr: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+i))+".csv")
+			if err := os.WriteFile(path, []byte(tt.csv), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadBars(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) || got[0] != tt.want[0] {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSignalsRejectsUnknownSignals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signals.csv")
+	if err := os.WriteFile(path, []byte("time,signal\n1704067200,HOLD\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSignals(path); err == nil {
+		t.Fatal("got no error for a HOLD signal")
+	}
+}
@@ -0,0 +1,127 @@
+package position
+
+import (
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// Effect describes what an order does to the position
+type Effect string
+
+const (
+	EffectOpen  Effect = "OPEN"  // grows the position in the direction it already leans, or opens it from flat
+	EffectClose Effect = "CLOSE" // reduces the position without crossing flat
+	EffectFlip  Effect = "FLIP"  // closes the position and opens one on the other side, e.g. selling more than is held
+)
+
+// dust is the smallest quantity treated as a position rather than float noise
+const dust = 1e-9
+
+// Position is the bot's own inventory in a pair, derived from its fills - it does not include holdings the wallet had
+// before the bot traded
+type Position struct {
+	Pair common.Pair `json:"pair"`
+	// Base is the net amount of the base asset acquired - negative means more was sold than bought, a short-like
+	// imbalance that spot trading can only sustain out of pre-existing holdings
+	Base float64 `json:"base"`
+	// Quote is the net amount of the quote asset spent - negative means more was received than spent
+	Quote float64 `json:"quote"`
+	// QuoteCostUsd is the part of Quote whose trades recorded the quote's USD price, valued in USD at that price, and
+	// QuoteUnpriced the part whose trades did not - only pairs priced in their quote asset record it
+	QuoteCostUsd  float64 `json:"quote_cost_usd,omitempty"`
+	QuoteUnpriced float64 `json:"quote_unpriced,omitempty"`
+}
+
+// FromTrades rebuilds the position in the pair from the ledger's fills, ignoring fills in other pairs
+func FromTrades(pair common.Pair, trades []ledger.Trade) Position {
+	p := Position{Pair: pair}
+	for _, t := range trades {
+		p.Apply(t)
+	}
+	return p
+}
+
+// Apply adds a fill to the position - what it received is the recorded output amount, net of slippage and fees, or
+// is derived from the reference price when the fill's output was not read
+func (p *Position) Apply(t ledger.Trade) {
+	switch {
+	case t.InputMint == p.Pair.Quote && t.OutputMint == p.Pair.Base:
+		p.Quote += t.InputAmount
+		if t.OutputAmount > 0 {
+			p.Base += t.OutputAmount
+		} else {
+			p.Base += baseAmount(common.BuySignal, t.InputAmount, t.Price)
+		}
+		p.costUsd(t, t.InputAmount)
+	case t.InputMint == p.Pair.Base && t.OutputMint == p.Pair.Quote:
+		received := t.OutputAmount
+		if received <= 0 {
+			received = t.InputAmount * t.Price
+		}
+		p.Base -= t.InputAmount
+		p.Quote -= received
+		p.costUsd(t, -received)
+	}
+}
+
+// costUsd adds quote spent by the trade, negative when received, to the USD cost at the quote's price when it filled
+func (p *Position) costUsd(t ledger.Trade, quote float64) {
+	if t.Snapshot != nil && t.Snapshot.QuoteUsd > 0 {
+		p.QuoteCostUsd += quote * t.Snapshot.QuoteUsd
+		return
+	}
+	p.QuoteUnpriced += quote
+}
+
+// Pnl returns the realized and unrealized profit of the position marked at the price, in the quote asset
+func (p Position) Pnl(price float64) float64 {
+	return p.Base*price - p.Quote
+}
+
+// PnlUsd returns the profit of a position priced in its quote asset in USD, marked at the price and the quote's
+// current USD price - each trade's quote is valued at the USD price it filled at, so moves of the quote itself count,
+// and the quote of trades that recorded none is valued at the current one
+func (p Position) PnlUsd(price float64, quoteUsd float64) float64 {
+	return p.Base*price*quoteUsd - p.QuoteCostUsd - p.QuoteUnpriced*quoteUsd
+}
+
+// Classify tells whether an order of the given side and size, priced at price, opens, closes, or flips the position
+func (p Position) Classify(side common.Signal, size float64, price float64) Effect {
+	delta := baseAmount(side, size, price)
+	if side == common.SellSignal {
+		delta = -delta
+	}
+	switch {
+	case p.Base > dust && delta < 0, p.Base < -dust && delta > 0:
+		if (p.Base+delta)*p.Base < 0 {
+			return EffectFlip
+		}
+		return EffectClose
+	default:
+		return EffectOpen
+	}
+}
+
+// Flatten returns the side and base amount of the trade that brings the position back to flat, or DO_NOTHING when
+// it already is
+func (p Position) Flatten() (common.Signal, float64) {
+	switch {
+	case p.Base > dust:
+		return common.SellSignal, p.Base
+	case p.Base < -dust:
+		return common.BuySignal, -p.Base
+	default:
+		return common.DoNothingSignal, 0
+	}
+}
+
+// baseAmount converts an order size to the base asset - BUY sizes are in the quote asset, SELL sizes already in base
+func baseAmount(side common.Signal, size float64, price float64) float64 {
+	if side != common.BuySignal {
+		return size
+	}
+	if price <= 0 {
+		return 0
+	}
+	return size / price
+}
@@ -0,0 +1,61 @@
+package pricing
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/josephawallace/ninetyfive/configs"
+)
+
+// Precision conditions the prices fed to the strategies of tightly pegged pairs - prices are rounded in decimal to
+// a fixed number of places, so float noise cannot pass for a deviation, and moves smaller than the minimum change
+// hold the previous price, so jitter around the peg does not move RSI
+type Precision struct {
+	decimals  int32
+	minChange decimal.Decimal
+	last      decimal.Decimal
+	seeded    bool
+}
+
+// NewPrecision creates a conditioner rounding to decimals places and ignoring moves below minChange - a negative
+// decimals leaves prices unrounded
+func NewPrecision(decimals int, minChange float64) *Precision {
+	return &Precision{
+		decimals:  int32(decimals),
+		minChange: decimal.NewFromFloat(minChange),
+	}
+}
+
+// NewPrecisionFromConfig creates the conditioner configured for the pair, or nil when prices are used as is
+func NewPrecisionFromConfig(cfg *configs.Config, pair string) *Precision {
+	for _, p := range cfg.PricePrecision {
+		if strings.EqualFold(p.Pair, pair) {
+			return NewPrecision(p.Decimals, p.MinChange.Float())
+		}
+	}
+	return nil
+}
+
+// Round rounds the price to the decimals without the min-change hysteresis - it reads no state Apply writes, so it
+// may run alongside the loop
+func (p *Precision) Round(price float64) float64 {
+	if p.decimals < 0 {
+		return price
+	}
+	return decimal.NewFromFloat(price).Round(p.decimals).InexactFloat64()
+}
+
+// Apply returns the conditioned price
+func (p *Precision) Apply(price float64) float64 {
+	d := decimal.NewFromFloat(price)
+	if p.decimals >= 0 {
+		d = d.Round(p.decimals)
+	}
+	if p.seeded && d.Sub(p.last).Abs().LessThan(p.minChange) {
+		d = p.last
+	}
+	p.last = d
+	p.seeded = true
+	return d.InexactFloat64()
+}
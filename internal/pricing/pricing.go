@@ -0,0 +1,314 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/birdeye"
+)
+
+// Aggregation methods accepted in the config
+const (
+	AggregateMedian   = "median"
+	AggregateWeighted = "weighted"
+)
+
+// Source names accepted in the config
+const (
+	SourceJupiter = "jupiter"
+	SourceBirdeye = "birdeye"
+)
+
+// Pair pricing modes accepted in the config
+const (
+	PricingUsd   = "usd"   // the pair is priced as the base's USD price, the quote taken as USD
+	PricingQuote = "quote" // the pair is priced as the base in units of the quote asset
+)
+
+// Quote is one source's price for a token and when the source last updated it
+type Quote struct {
+	Price float64
+	Time  time.Time
+}
+
+// Source provides the USD price of a token
+type Source interface {
+	Quote(ctx context.Context, mint string) (Quote, error)
+}
+
+// Live adapts a price function without its own timestamp into a source - prices are considered current when fetched
+type Live func(ctx context.Context, mint string) (float64, error)
+
+func (f Live) Quote(ctx context.Context, mint string) (Quote, error) {
+	price, err := f(ctx, mint)
+	return Quote{Price: price, Time: time.Now()}, err
+}
+
+// Timed adapts a price function that reports when the price was last updated into a source
+type Timed func(ctx context.Context, mint string) (float64, time.Time, error)
+
+func (f Timed) Quote(ctx context.Context, mint string) (Quote, error) {
+	price, updated, err := f(ctx, mint)
+	return Quote{Price: price, Time: updated}, err
+}
+
+// SourceStats tracks how a source has been behaving, for operators
+type SourceStats struct {
+	Name         string    `json:"name"`
+	Weight       float64   `json:"weight"`
+	LastPrice    float64   `json:"last_price"`
+	LastUpdate   time.Time `json:"last_update"`
+	DeviationBps float64   `json:"deviation_bps"` // of the last price from the composite
+	LastError    string    `json:"last_error,omitempty"`
+	Errors       int       `json:"errors"`
+	Stale        int       `json:"stale"`    // quotes dropped for being older than the max age
+	Outliers     int       `json:"outliers"` // quotes dropped for deviating from the median
+	Accepted     int       `json:"accepted"` // quotes that fed the composite
+}
+
+// weighted is a configured source
+type weighted struct {
+	name   string
+	weight float64
+	source Source
+}
+
+// Composite combines several sources into one price so that a single manipulated or broken feed cannot move the
+// strategy on its own
+type Composite struct {
+	sources         []weighted
+	method          string
+	maxAge          time.Duration
+	maxDeviationBps float64
+	minSources      int
+	inQuote         bool // prices pairs in the quote asset rather than in USD
+
+	mu    sync.RWMutex
+	stats map[string]*SourceStats
+}
+
+// NewComposite combines the sources - quotes older than maxAge (when positive) or further than maxDeviationBps (when
+// positive) from the median are dropped, and at least minSources quotes must remain
+func NewComposite(method string, maxAge time.Duration, maxDeviationBps float64, minSources int) *Composite {
+	return &Composite{
+		method:          method,
+		maxAge:          maxAge,
+		maxDeviationBps: maxDeviationBps,
+		minSources:      max(1, minSources),
+		stats:           make(map[string]*SourceStats),
+	}
+}
+
+// NewCompositeFromConfig builds the composite from the configured source names, using the Jupiter source given
+func NewCompositeFromConfig(cfg *configs.Config, jupiter Source) (*Composite, error) {
+	if cfg.PriceAggregation != AggregateMedian && cfg.PriceAggregation != AggregateWeighted {
+		return nil, fmt.Errorf("unknown price aggregation %q", cfg.PriceAggregation)
+	}
+	c := NewComposite(cfg.PriceAggregation, cfg.PriceMaxAge, cfg.PriceMaxDeviationBps, cfg.PriceMinSources)
+	c.inQuote = cfg.PairPricing == PricingQuote
+
+	names := cfg.PriceSources
+	if len(names) == 0 {
+		names = []string{SourceJupiter}
+	}
+	for _, name := range names {
+		weight, ok := cfg.PriceSourceWeights[name]
+		if !ok {
+			weight = 1
+		}
+		switch name {
+		case SourceJupiter:
+			c.Add(name, weight, jupiter)
+		case SourceBirdeye:
+			if cfg.BirdeyeApiKey == "" {
+				return nil, fmt.Errorf("the birdeye price source needs a Birdeye API key")
+			}
+			c.Add(name, weight, Timed(birdeye.NewClient(cfg.BirdeyeApiKey).Price))
+		default:
+			return nil, fmt.Errorf("unknown price source %q", name)
+		}
+	}
+	if c.minSources > len(c.sources) {
+		return nil, fmt.Errorf("price_min_sources is %d but only %d sources are configured", c.minSources, len(c.sources))
+	}
+	return c, nil
+}
+
+// Add registers a source with its weight, used by the weighted aggregation
+func (c *Composite) Add(name string, weight float64, source Source) {
+	c.sources = append(c.sources, weighted{name: name, weight: weight, source: source})
+	c.stats[name] = &SourceStats{Name: name, Weight: weight}
+}
+
+// Price fetches every source concurrently and returns their combined price
+func (c *Composite) Price(ctx context.Context, mint string) (float64, error) {
+	q, err := c.Sample(ctx, mint)
+	return q.Price, err
+}
+
+// Sample is Price with the time of the combined price - that of the oldest quote it combines, so a single stale
+// source among fresh ones still shows
+func (c *Composite) Sample(ctx context.Context, mint string) (Quote, error) {
+	quotes := make([]Quote, len(c.sources))
+	errs := make([]error, len(c.sources))
+	var wg sync.WaitGroup
+	for i, s := range c.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quotes[i], errs[i] = s.source.Quote(ctx, mint)
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop failed and stale quotes
+	var fresh []int
+	for i, s := range c.sources {
+		st := c.stats[s.name]
+		switch {
+		case errs[i] != nil:
+			st.Errors++
+			st.LastError = errs[i].Error()
+		case quotes[i].Price <= 0:
+			st.Errors++
+			st.LastError = "non-positive price"
+		default:
+			st.LastPrice = quotes[i].Price
+			st.LastUpdate = quotes[i].Time
+			st.LastError = ""
+			if c.maxAge > 0 && time.Since(quotes[i].Time) > c.maxAge {
+				st.Stale++
+				continue
+			}
+			fresh = append(fresh, i)
+		}
+	}
+	if len(fresh) == 0 {
+		return Quote{}, fmt.Errorf("no price source returned a fresh price for %s", mint)
+	}
+
+	// Drop outliers relative to the median of the fresh quotes
+	prices := make([]float64, 0, len(fresh))
+	for _, i := range fresh {
+		prices = append(prices, quotes[i].Price)
+	}
+	mid := median(prices)
+	accepted := slices.DeleteFunc(slices.Clone(fresh), func(i int) bool {
+		if c.maxDeviationBps > 0 && math.Abs(quotes[i].Price-mid)/mid*10_000 > c.maxDeviationBps {
+			c.stats[c.sources[i].name].Outliers++
+			return true
+		}
+		return false
+	})
+	if len(accepted) < c.minSources {
+		return Quote{}, fmt.Errorf("only %d of the required %d price sources agree on %s", len(accepted), c.minSources, mint)
+	}
+
+	// Combine what is left
+	var price float64
+	switch c.method {
+	case AggregateWeighted:
+		var sum, weights float64
+		for _, i := range accepted {
+			sum += quotes[i].Price * c.sources[i].weight
+			weights += c.sources[i].weight
+		}
+		if weights <= 0 {
+			return Quote{}, fmt.Errorf("price source weights sum to zero")
+		}
+		price = sum / weights
+	default:
+		prices = prices[:0]
+		for _, i := range accepted {
+			prices = append(prices, quotes[i].Price)
+		}
+		price = median(prices)
+	}
+
+	sample := Quote{Price: price}
+	for _, i := range accepted {
+		st := c.stats[c.sources[i].name]
+		st.Accepted++
+		if sample.Time.IsZero() || quotes[i].Time.Before(sample.Time) {
+			sample.Time = quotes[i].Time
+		}
+	}
+	for _, i := range fresh {
+		c.stats[c.sources[i].name].DeviationBps = (quotes[i].Price - price) / price * 10_000
+	}
+	return sample, nil
+}
+
+// PairQuote is the price of a pair - the base in units of the quote - with the USD price of the quote asset, which
+// converts figures in the quote asset to USD
+type PairQuote struct {
+	Price    float64
+	QuoteUsd float64
+	Time     time.Time // of the oldest price combined
+}
+
+// PairPrice prices the pair - in quote pricing as the ratio of the base's and the quote's USD prices, fetched
+// concurrently, otherwise as the base's USD price with the quote taken as USD
+func (c *Composite) PairPrice(ctx context.Context, base string, quote string) (PairQuote, error) {
+	if !c.inQuote {
+		q, err := c.Sample(ctx, base)
+		return PairQuote{Price: q.Price, QuoteUsd: 1, Time: q.Time}, err
+	}
+	var (
+		wg                sync.WaitGroup
+		baseQ, quoteQ     Quote
+		baseErr, quoteErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		baseQ, baseErr = c.Sample(ctx, base)
+	}()
+	go func() {
+		defer wg.Done()
+		quoteQ, quoteErr = c.Sample(ctx, quote)
+	}()
+	wg.Wait()
+	if baseErr != nil {
+		return PairQuote{}, baseErr
+	}
+	if quoteErr != nil {
+		return PairQuote{}, quoteErr
+	}
+	pq := PairQuote{Price: baseQ.Price / quoteQ.Price, QuoteUsd: quoteQ.Price, Time: baseQ.Time}
+	if quoteQ.Time.Before(pq.Time) {
+		pq.Time = quoteQ.Time
+	}
+	return pq, nil
+}
+
+// Stats returns a copy of every source's stats, in configuration order
+func (c *Composite) Stats() []SourceStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stats := make([]SourceStats, 0, len(c.sources))
+	for _, s := range c.sources {
+		stats = append(stats, *c.stats[s.name])
+	}
+	return stats
+}
+
+// median returns the median of the values, averaging the middle two when there is an even number of them
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,97 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// sample is a price observed by the circuit breaker
+type sample struct {
+	time  time.Time
+	price float64
+}
+
+// CircuitBreaker suspends execution for a cooldown once the prices sampled over a short window move too far or too
+// erratically - AMM slippage and failed transactions spike exactly then - while the indicators keep being computed
+type CircuitBreaker struct {
+	window     time.Duration
+	maxVol     float64
+	maxMoveBps float64
+	cooldown   time.Duration
+
+	samples []sample
+	until   time.Time
+}
+
+// NewCircuitBreaker creates a breaker tripping when the realized volatility of the per-sample log returns within the
+// window exceeds maxVol, or the price moves more than maxMoveBps across it - a zero threshold disables its check
+func NewCircuitBreaker(window time.Duration, maxVol float64, maxMoveBps float64, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		window:     window,
+		maxVol:     maxVol,
+		maxMoveBps: maxMoveBps,
+		cooldown:   cooldown,
+	}
+}
+
+// Hold keeps execution suspended until at least until, e.g. for a cooldown carried over a restart
+func (b *CircuitBreaker) Hold(until time.Time) {
+	if until.After(b.until) {
+		b.until = until
+	}
+}
+
+// Observe records a price and returns why the breaker tripped on it, extending the cooldown - nil when it did not
+func (b *CircuitBreaker) Observe(t time.Time, price float64) error {
+	if price <= 0 || (b.maxVol <= 0 && b.maxMoveBps <= 0) {
+		return nil
+	}
+	b.samples = append(b.samples, sample{time: t, price: price})
+	cutoff := t.Add(-b.window)
+	for len(b.samples) > 0 && b.samples[0].time.Before(cutoff) {
+		b.samples = b.samples[1:]
+	}
+
+	var reason error
+	if moveBps := math.Abs(price/b.samples[0].price-1) * 10_000; b.maxMoveBps > 0 && moveBps > b.maxMoveBps {
+		reason = fmt.Errorf("price moved %.1f bps within %s (max %.1f bps)", moveBps, b.window, b.maxMoveBps)
+	} else if vol := b.realizedVol(); b.maxVol > 0 && vol > b.maxVol {
+		reason = fmt.Errorf("realized volatility %.4f within %s (max %.4f)", vol, b.window, b.maxVol)
+	}
+	if reason != nil {
+		b.until = t.Add(b.cooldown)
+	}
+	return reason
+}
+
+// Open reports whether execution is suspended at the time
+func (b *CircuitBreaker) Open(t time.Time) bool {
+	return t.Before(b.until)
+}
+
+// Until returns when the latest cooldown ends
+func (b *CircuitBreaker) Until() time.Time {
+	return b.until
+}
+
+// realizedVol returns the standard deviation of the log returns between the samples in the window
+func (b *CircuitBreaker) realizedVol() float64 {
+	n := len(b.samples) - 1
+	if n < 2 {
+		return 0
+	}
+	returns := make([]float64, n)
+	mean := 0.0
+	for i := 1; i < len(b.samples); i++ {
+		returns[i-1] = math.Log(b.samples[i].price / b.samples[i-1].price)
+		mean += returns[i-1]
+	}
+	mean /= float64(n)
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance / float64(n-1))
+}
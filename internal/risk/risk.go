@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Guard holds the pre-trade checks that can veto a signal before it is executed
+type Guard struct {
+	cfg *configs.Config
+}
+
+// NewGuard creates the pre-trade checks from the config
+func NewGuard(cfg *configs.Config) *Guard {
+	return &Guard{cfg: cfg}
+}
+
+// CheckQuote vetoes a signal when the price achievable right now has moved against the trade by more than the
+// configured tolerance since the price that generated the signal - buying higher or selling lower than the grid
+// level intended means the signal is stale
+func (g *Guard) CheckQuote(side common.Signal, signalPrice float64, quotePrice float64) error {
+	if g.cfg.MaxQuoteDeviationBps <= 0 || signalPrice <= 0 {
+		return nil
+	}
+
+	deviationBps := (quotePrice - signalPrice) / signalPrice * 10_000
+	if side == common.SellSignal {
+		deviationBps = -deviationBps
+	}
+	if deviationBps > g.cfg.MaxQuoteDeviationBps {
+		return fmt.Errorf("quoted price %f is %.1f bps worse than the signal price %f (max %.1f bps)",
+			quotePrice, deviationBps, signalPrice, g.cfg.MaxQuoteDeviationBps)
+	}
+	return nil
+}
+
+// CheckInventory vetoes a SELL larger than the base asset available to sell when spot-only mode is enabled, so the bot
+// never sells inventory it did not buy
+func (g *Guard) CheckInventory(side common.Signal, size float64, available float64) error {
+	if !g.cfg.SpotOnly || side != common.SellSignal {
+		return nil
+	}
+	if size > available {
+		return fmt.Errorf("selling %f exceeds the %f held (spot-only mode)", size, max(0, available))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// Job is a recurring task of the bot
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Immediate runs the job once when the scheduler starts as well, for jobs whose state must be right from the
+	// start, like maintenance windows
+	Immediate bool
+	Run       func(ctx context.Context) error
+}
+
+// Scheduler runs the bot's recurring jobs on their schedules, each in its own goroutine - a job still running when
+// it comes due again skips that run rather than piling up
+type Scheduler struct {
+	log  logger.Logger
+	jobs []Job
+}
+
+// NewScheduler creates an empty scheduler
+func NewScheduler(log logger.Logger) *Scheduler {
+	return &Scheduler{log: log}
+}
+
+// Add registers a job to run on the schedule described by spec, matched in UTC - an empty spec leaves the job
+// disabled
+func (s *Scheduler) Add(name string, spec string, immediate bool, run func(ctx context.Context) error) error {
+	if spec == "" {
+		return nil
+	}
+	schedule, err := Parse(spec, time.UTC)
+	if err != nil {
+		return fmt.Errorf("schedule of %s: %w", name, err)
+	}
+	s.jobs = append(s.jobs, Job{Name: name, Schedule: schedule, Immediate: immediate, Run: run})
+	return nil
+}
+
+// Run runs the jobs until the context is cancelled, then waits for the ones still running
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop runs one job each time it comes due
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	var running sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	run := func() {
+		if !running.TryLock() {
+			s.log.Warn().Msg("skipping scheduled %s - the previous run has not finished", job.Name)
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer running.Unlock()
+			if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+				s.log.Error().Err(err).Msg("scheduled %s failed", job.Name)
+			}
+		}()
+	}
+
+	if job.Immediate {
+		run()
+	}
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.log.Warn().Msg("scheduled %s will never run again", job.Name)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+		run()
+	}
+}
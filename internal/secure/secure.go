@@ -0,0 +1,108 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltSize  = 16
+	nonceSize = 24
+	keySize   = 32
+
+	// scrypt cost parameters - these are the recommended interactive values and keep decryption under a second
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// magic prefixes sealed payloads so that plaintext files can still be read without a passphrase
+var magic = []byte("NF95SEAL1")
+
+// Seal encrypts the plaintext with a key derived from the passphrase using NaCl secretbox
+func Seal(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase is required to seal data")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(key[:])
+
+	// Layout: magic || salt || nonce || box
+	out := make([]byte, 0, len(magic)+saltSize+nonceSize+len(plaintext)+secretbox.Overhead)
+	out = append(out, magic...)
+	out = append(out, salt...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, plaintext, &nonce, key), nil
+}
+
+// Open decrypts data previously produced by Seal
+func Open(sealed, passphrase []byte) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, fmt.Errorf("data is not sealed")
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase is required to open sealed data")
+	}
+
+	body := sealed[len(magic):]
+	if len(body) < saltSize+nonceSize+secretbox.Overhead {
+		return nil, fmt.Errorf("sealed data is truncated")
+	}
+	salt := body[:saltSize]
+	var nonce [nonceSize]byte
+	copy(nonce[:], body[saltSize:saltSize+nonceSize])
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(key[:])
+
+	plaintext, ok := secretbox.Open(nil, body[saltSize+nonceSize:], &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("could not open sealed data - wrong passphrase or corrupted data")
+	}
+	return plaintext, nil
+}
+
+// IsSealed reports whether the data carries the sealed payload header
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Zero overwrites the slice in place so sensitive material does not linger in memory
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// deriveKey stretches the passphrase into a secretbox key with scrypt
+func deriveKey(passphrase, salt []byte) (*[keySize]byte, error) {
+	derived, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	var key [keySize]byte
+	copy(key[:], derived)
+	Zero(derived)
+	return &key, nil
+}
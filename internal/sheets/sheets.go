@@ -0,0 +1,90 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// Tab is one sheet of the spreadsheet, rewritten in full on every export - the first row is the header
+type Tab struct {
+	Title string
+	Rows  [][]interface{}
+}
+
+// Spreadsheet writes tabs to a Google Sheet with the application default credentials, which need edit access to it
+type Spreadsheet struct {
+	id  string
+	svc *sheets.Service
+}
+
+// NewSpreadsheet creates a writer for the spreadsheet with the given id, the part of its URL after /d/
+func NewSpreadsheet(ctx context.Context, id string) (*Spreadsheet, error) {
+	svc, err := sheets.NewService(ctx, option.WithScopes(sheets.SpreadsheetsScope))
+	if err != nil {
+		return nil, err
+	}
+	return &Spreadsheet{id: id, svc: svc}, nil
+}
+
+// Write replaces the contents of the tabs, adding the ones the spreadsheet does not have yet - tabs not written are
+// left alone, so stakeholders can keep their own charts and notes next to the exported ones
+func (s *Spreadsheet) Write(ctx context.Context, tabs []Tab) error {
+	if err := s.addMissing(ctx, tabs); err != nil {
+		return err
+	}
+	ranges := make([]string, 0, len(tabs))
+	data := make([]*sheets.ValueRange, 0, len(tabs))
+	for _, tab := range tabs {
+		ranges = append(ranges, quote(tab.Title))
+		data = append(data, &sheets.ValueRange{Range: quote(tab.Title), Values: tab.Rows})
+	}
+	if _, err := s.svc.Spreadsheets.Values.BatchClear(s.id, &sheets.BatchClearValuesRequest{Ranges: ranges}).
+		Context(ctx).Do(); err != nil {
+		return fmt.Errorf("could not clear the spreadsheet: %w", err)
+	}
+	if _, err := s.svc.Spreadsheets.Values.BatchUpdate(s.id, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             data,
+	}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("could not update the spreadsheet: %w", err)
+	}
+	return nil
+}
+
+// addMissing adds the tabs the spreadsheet does not have
+func (s *Spreadsheet) addMissing(ctx context.Context, tabs []Tab) error {
+	ss, err := s.svc.Spreadsheets.Get(s.id).Fields("sheets.properties.title").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("could not read the spreadsheet: %w", err)
+	}
+	var titles []string
+	for _, sh := range ss.Sheets {
+		titles = append(titles, sh.Properties.Title)
+	}
+	var add []*sheets.Request
+	for _, tab := range tabs {
+		if !slices.Contains(titles, tab.Title) {
+			add = append(add, &sheets.Request{AddSheet: &sheets.AddSheetRequest{
+				Properties: &sheets.SheetProperties{Title: tab.Title},
+			}})
+		}
+	}
+	if len(add) == 0 {
+		return nil
+	}
+	if _, err = s.svc.Spreadsheets.BatchUpdate(s.id, &sheets.BatchUpdateSpreadsheetRequest{Requests: add}).
+		Context(ctx).Do(); err != nil {
+		return fmt.Errorf("could not add tabs to the spreadsheet: %w", err)
+	}
+	return nil
+}
+
+// quote makes the tab title an A1 range covering the whole tab
+func quote(title string) string {
+	return "'" + strings.ReplaceAll(title, "'", "''") + "'"
+}
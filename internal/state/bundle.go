@@ -0,0 +1,54 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// SchemaVersion is the version of the bundle layout - bump it when a change would make older bundles import wrong
+const SchemaVersion = 1
+
+// Bundle is everything a deployment remembers about a pair - the runtime state, the ledger, and the cached bars the
+// strategies warm up on - to carry it over to another deployment, e.g. from a VM to Cloud Run
+type Bundle struct {
+	SchemaVersion int             `json:"schema_version"`
+	ExportedAt    time.Time       `json:"exported_at"`
+	Build         string          `json:"build"`
+	Labels        common.Labels   `json:"labels"`
+	Pair          common.Pair     `json:"pair"`
+	Handoff       *trader.Handoff `json:"handoff,omitempty"`
+	Ledger        json.RawMessage `json:"ledger"`
+	Timeframe     string          `json:"timeframe,omitempty"`
+	Bars          []common.Bar    `json:"bars,omitempty"`
+}
+
+// WriteBundle writes the bundle to the path, readable by the owner only since the ledger is no longer sealed
+func WriteBundle(path string, b Bundle) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ReadBundle reads the bundle at the path, refusing one of another schema version
+func ReadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if err = json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid state bundle %s: %w", path, err)
+	}
+	if b.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("state bundle %s has schema version %d, this build reads version %d", path,
+			b.SchemaVersion, SchemaVersion)
+	}
+	return &b, nil
+}
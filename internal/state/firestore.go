@@ -0,0 +1,96 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/api/firestore/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/trader"
+)
+
+// Firestore keeps each pair's runtime state in a document of a Firestore collection, so instances that scale to zero
+// hand their state to whichever instance handles the pair next
+type Firestore struct {
+	collection string
+	svc        *firestore.Service
+}
+
+// NewFirestore creates a state store in the collection of the project's default database with the application
+// default credentials
+func NewFirestore(ctx context.Context, projectId string, collection string) (*Firestore, error) {
+	svc, err := firestore.NewService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Firestore{
+		collection: "projects/" + projectId + "/databases/(default)/documents/" + collection,
+		svc:        svc,
+	}, nil
+}
+
+// MaxBars is the most bars a record keeps, the newest - Firestore limits a document to 1 MiB
+const MaxBars = 5000
+
+// Record is what the push mode keeps of a pair between messages - the runtime state, and the newest bars of the
+// timeframe the strategies warm up on, since the candle cache of an instance that scaled to zero is gone with it
+type Record struct {
+	Handoff   trader.Handoff
+	Timeframe string
+	Bars      []common.Bar
+}
+
+// Load returns the pair's record - nil when none was saved yet, and without bars when it was saved without them
+func (f *Firestore) Load(ctx context.Context, pair common.Pair) (*Record, error) {
+	doc, err := f.svc.Projects.Databases.Documents.Get(f.document(pair)).Context(ctx).Do()
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) && gerr.Code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err = json.Unmarshal([]byte(doc.Fields["handoff"].StringValue), &r.Handoff); err != nil {
+		return nil, err
+	}
+	if bars := doc.Fields["bars"].StringValue; bars != "" {
+		if err = json.Unmarshal([]byte(bars), &r.Bars); err != nil {
+			return nil, err
+		}
+		r.Timeframe = doc.Fields["timeframe"].StringValue
+	}
+	return &r, nil
+}
+
+// Save writes the pair's record, replacing the previous one, with at most the newest MaxBars bars
+func (f *Firestore) Save(ctx context.Context, pair common.Pair, r Record) error {
+	handoff, err := json.Marshal(r.Handoff)
+	if err != nil {
+		return err
+	}
+	bars, err := json.Marshal(r.Bars[max(len(r.Bars)-MaxBars, 0):])
+	if err != nil {
+		return err
+	}
+	doc := &firestore.Document{Fields: map[string]firestore.Value{
+		"pair":      {StringValue: pair.String()},
+		"handoff":   {StringValue: string(handoff)},
+		"timeframe": {StringValue: r.Timeframe},
+		"bars":      {StringValue: string(bars)},
+		"saved_at":  {TimestampValue: r.Handoff.SavedAt.UTC().Format(time.RFC3339Nano)},
+	}}
+	_, err = f.svc.Projects.Databases.Documents.Patch(f.document(pair), doc).Context(ctx).Do()
+	return err
+}
+
+// document is the path of the pair's document - mints never contain the separator, which ids must not
+func (f *Firestore) document(pair common.Pair) string {
+	return f.collection + "/" + strings.ReplaceAll(pair.String(), "/", "-")
+}
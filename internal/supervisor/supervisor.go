@@ -0,0 +1,83 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = 5 * time.Minute
+	// stableAfter is how long a loop must run before its backoff resets
+	stableAfter = 10 * time.Minute
+	// crashLoop is the number of consecutive quick crashes that raises a critical alert
+	crashLoop = 3
+)
+
+// Supervisor runs long-lived loops, recovering panics and restarting the loop that crashed so one pair's bug does not
+// take down the others
+type Supervisor struct {
+	n   notifier.Notifier
+	log logger.Logger
+}
+
+// NewSupervisor creates a supervisor that alerts through the notifier when a loop keeps crashing
+func NewSupervisor(n notifier.Notifier, log logger.Logger) *Supervisor {
+	return &Supervisor{n: n, log: log}
+}
+
+// Run calls loop until the context is cancelled, restarting it with exponential backoff whenever it panics - a loop
+// that returns without panicking is considered finished
+func (s *Supervisor) Run(ctx context.Context, name string, loop func(ctx context.Context)) {
+	backoff := minBackoff
+	crashes := 0
+	for {
+		started := time.Now()
+		recovered, stack := s.call(ctx, loop)
+		if recovered == nil {
+			return
+		}
+
+		// Crashes after a long healthy run start over rather than escalating
+		if time.Since(started) > stableAfter {
+			backoff = minBackoff
+			crashes = 0
+		}
+		crashes++
+		s.log.Error().Msg("%s loop panicked (crash %d), restarting in %s: %v\n%s", name, crashes, backoff, recovered, stack)
+		if crashes >= crashLoop {
+			alert := notifier.Alert{
+				Key:      notifier.KeyCrashLoop + ":" + name,
+				Severity: notifier.SeverityCritical,
+				Title:    fmt.Sprintf("%s loop is crash-looping", name),
+				Body:     fmt.Sprintf("%d consecutive crashes, latest: %v", crashes, recovered),
+			}
+			if err := s.n.Notify(ctx, alert); err != nil {
+				s.log.Error().Err(err).Msg("failed to send notification")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(maxBackoff, backoff*2)
+	}
+}
+
+// call runs the loop once, returning the recovered panic value and its stack if it panicked
+func (s *Supervisor) call(ctx context.Context, loop func(ctx context.Context)) (recovered interface{}, stack []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered, stack = r, debug.Stack()
+		}
+	}()
+	loop(ctx)
+	return nil, nil
+}
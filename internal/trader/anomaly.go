@@ -0,0 +1,35 @@
+package trader
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/anomaly"
+)
+
+// checkBalances shows the wallet's balances to the anomaly detector every configured interval - only a wallet the bot
+// trades itself is checked, since a watched wallet's owner trades it too, and a wallet funded by delegation holds
+// nothing between swaps
+func (t *Trader) checkBalances(ctx context.Context, now time.Time) {
+	interval := t.cfg.AnomalyBalanceInterval
+	if interval <= 0 || !t.cfg.NeedsSecretKey() || t.j.Delegated() || now.Sub(t.balancesChecked) < interval {
+		return
+	}
+	t.balancesChecked = now
+
+	// Count the trades and the recorded external activity first, so a fill recorded while the balances are read is
+	// taken as a trade
+	trades := len(t.l.Trades()) + len(t.l.Adjustments())
+	base, err := t.j.TokenBalance(ctx, t.pair.Base)
+	if err != nil {
+		t.log.Error().Err(err).Msg("failed to get the base balance for anomaly detection")
+		return
+	}
+	quote, err := t.j.TokenBalance(ctx, t.pair.Quote)
+	if err != nil {
+		t.log.Error().Err(err).Msg("failed to get the quote balance for anomaly detection")
+		return
+	}
+	busy := len(t.om.Open()) > 0
+	t.watch.CheckBalances(ctx, anomaly.Balances{Base: base, Quote: quote}, busy, trades)
+}
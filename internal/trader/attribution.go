@@ -0,0 +1,13 @@
+package trader
+
+import (
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/attribution"
+)
+
+// Attribution credits the realized PnL of the pair's round trips closed since from to the grid levels that opened
+// them - a zero from covers the whole ledger
+func (t *Trader) Attribution(from time.Time) attribution.Report {
+	return attribution.Attribute(t.pair, t.Trades(time.Time{}, time.Time{}), from)
+}
@@ -0,0 +1,118 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// Stages of the signal audit where a signal can be stopped
+const (
+	stageStrategy    = "strategy"     // a Grid Manager's own filters cleared the grid line crossing
+	stageEntryFilter = "entry_filter" // the optional entry filters did not confirm the signal
+	stageRisk        = "risk"         // a risk limit vetoed the order
+)
+
+// auditSignal records the signal the strategies emitted on the bar, with the grid line it was taken at - forward
+// returns of these entries tell how accurate each grid level and filter combination is
+func (t *Trader) auditSignal(bar common.Bar, decision ensemble.Decision) {
+	if decision.Signal != common.BuySignal && decision.Signal != common.SellSignal {
+		return
+	}
+	if err := t.l.RecordSignal(ledger.SignalRecord{
+		Time:      bar.Time,
+		Pair:      t.pair.String(),
+		Side:      decision.Signal,
+		Price:     bar.Close,
+		GridLevel: t.gridLevel(),
+		Filters:   t.filterCombination(),
+	}); err != nil {
+		t.log.Error().Err(err).Msg("failed to audit the %s signal", decision.Signal)
+	}
+}
+
+// gridLevel returns the index of the grid line the first strategy's signal line sits on
+func (t *Trader) gridLevel() int {
+	if members := t.e.Snapshot(); len(members) > 0 {
+		return members[0].Snapshot.LastSignalIndex
+	}
+	return 0
+}
+
+// filterCombination describes the filters in force - each strategy's direction, no trade zone, and aggression, then
+// the entry filters - e.g. "neutral/35-65/low + divergence,regime"
+func (t *Trader) filterCombination() string {
+	strategies := make([]string, 0, len(t.cfg.Strategies))
+	for _, s := range t.cfg.Strategies {
+		strategies = append(strategies, fmt.Sprintf("%s/%s/%s", s.Direction, s.NoTradeZone, s.Aggression))
+	}
+	entry := "none"
+	if names := t.fc.Names(); len(names) > 0 {
+		entry = strings.Join(names, ",")
+	}
+	return strings.Join(strategies, ",") + " + " + entry
+}
+
+// auditStrategies records the grid line crossings the strategies' own filters blocked on the bar
+func (t *Trader) auditStrategies(ctx context.Context, bar common.Bar) {
+	for _, m := range t.e.Snapshot() {
+		side, filter := m.Snapshot.Filters.Blocked()
+		if side == common.DoNothingSignal {
+			continue
+		}
+		t.suppress(ctx, ledger.SignalRecord{
+			Time:      bar.Time,
+			Strategy:  m.Name,
+			Side:      side,
+			Price:     bar.Close,
+			GridLevel: m.Snapshot.LastSignalIndex,
+			Stage:     stageStrategy,
+			Filter:    filter,
+		}, fmt.Errorf("%s blocked the grid line crossing", filter))
+	}
+}
+
+// veto logs and audits a signal a filter or risk limit stopped in the given stage
+func (t *Trader) veto(ctx context.Context, stage string, filter string, side common.Signal, price float64,
+	signalTime time.Time, err error) {
+	if stage == stageEntryFilter {
+		t.log.Info().Msg("%s signal filtered: %s", side, err)
+	} else {
+		t.log.Warn().Msg("%s signal vetoed: %s", side, err)
+	}
+	t.suppress(ctx, ledger.SignalRecord{
+		Time:      signalTime,
+		Side:      side,
+		Price:     price,
+		GridLevel: t.gridLevel(),
+		Stage:     stage,
+		Filter:    filter,
+	}, err)
+}
+
+// suppress records a signal the bot wanted to take but did not in the signal audit, and tells the operators when
+// asked to, so filters can be tuned on the trades they stop
+func (t *Trader) suppress(ctx context.Context, s ledger.SignalRecord, reason error) {
+	s.Pair = t.pair.String()
+	s.Filters = t.filterCombination()
+	s.Suppressed = true
+	s.Reason = reason.Error()
+	if err := t.l.RecordSignal(s); err != nil {
+		t.log.Error().Err(err).Msg("failed to audit the suppressed %s signal", s.Side)
+	}
+	if !t.cfg.NotifySuppressedSignals {
+		return
+	}
+	who := s.Stage
+	if s.Strategy != "" {
+		who = "strategy " + s.Strategy
+	}
+	t.notify(ctx, fmt.Sprintf("%s signal suppressed", s.Side),
+		fmt.Sprintf("%s stopped a %s signal on %s at %f (grid level %d): %s", who, s.Side, s.Pair, s.Price,
+			s.GridLevel, s.Reason))
+}
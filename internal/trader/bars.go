@@ -0,0 +1,36 @@
+package trader
+
+import (
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// barBuilder aggregates ticks into OHLC bars aligned to the bar interval
+type barBuilder struct {
+	interval time.Duration
+	bar      common.Bar // the forming bar, whose Time is when it will close
+	forming  bool
+}
+
+// add folds the tick into the forming bar - when the tick belongs to a later bar, the forming bar is returned complete
+// and the tick opens the next one
+func (b *barBuilder) add(t time.Time, price float64) (common.Bar, bool) {
+	closeTime := t.Truncate(b.interval).Add(b.interval)
+	if b.forming && closeTime.Equal(b.bar.Time) {
+		b.bar.High = max(b.bar.High, price)
+		b.bar.Low = min(b.bar.Low, price)
+		b.bar.Close = price
+		return common.Bar{}, false
+	}
+
+	completed, ok := b.bar, b.forming
+	b.bar = common.PriceBar(closeTime, price)
+	b.forming = true
+	return completed, ok
+}
+
+// closes reports whether a tick at t would complete the forming bar
+func (b *barBuilder) closes(t time.Time) bool {
+	return b.forming && !t.Truncate(b.interval).Add(b.interval).Equal(b.bar.Time)
+}
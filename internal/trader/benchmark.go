@@ -0,0 +1,87 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// Benchmark compares how the capital grew under the bot with having bought the base asset with it when the bot
+// started and held it since - both as the change in the capital's value in the quote asset
+type Benchmark struct {
+	StartedAt  time.Time `json:"started_at"`
+	StartPrice float64   `json:"start_price"`
+	Capital    float64   `json:"capital"`
+	Pnl        float64   `json:"pnl"`        // the change in the capital's value under the bot at the latest price
+	HoldPnl    float64   `json:"hold_pnl"`   // buy-and-hold's change at the latest price
+	ExcessPnl  float64   `json:"excess_pnl"` // how much the bot beat buy-and-hold by, negative when it trailed
+	ExcessPct  float64   `json:"excess_pct"` // the excess as a percentage of the capital
+}
+
+// String summarizes the comparison in one line
+func (b Benchmark) String() string {
+	return fmt.Sprintf("equity change %f vs buy-and-hold %f since %s - %+.2f%% of %f capital", b.Pnl, b.HoldPnl,
+		b.StartedAt.Format(time.RFC3339), b.ExcessPct, b.Capital)
+}
+
+// startBenchmark records the buy-and-hold benchmark on the first tick, valued at the price - the capital is the
+// configured one or else the strategy's capital budget, both held in the quote asset, else what the wallet held of
+// the pair - along with the bot's position, so the trades before it are left out
+func (t *Trader) startBenchmark(ctx context.Context, price float64) {
+	if t.l.Benchmark(t.pair) != nil || price <= 0 {
+		return
+	}
+	capital, held := t.cfg.BenchmarkCapital, 0.0
+	if capital <= 0 {
+		capital = t.alloc.Budget(time.Now())
+	}
+	if capital <= 0 && (t.cfg.NeedsSecretKey() || t.observing()) {
+		base, err := t.j.TokenBalance(ctx, t.pair.Base)
+		if err != nil {
+			t.log.Error().Err(err).Msg("failed to get the base balance for the benchmark")
+			return
+		}
+		quote, err := t.j.TokenBalance(ctx, t.pair.Quote)
+		if err != nil {
+			t.log.Error().Err(err).Msg("failed to get the quote balance for the benchmark")
+			return
+		}
+		capital, held = base*price+quote, base
+	}
+	if capital <= 0 {
+		return
+	}
+
+	pos := t.Position()
+	b := ledger.Benchmark{StartedAt: time.Now(), Price: price, Capital: capital, Base: held, PositionBase: pos.Base,
+		PositionQuote: pos.Quote}
+	if err := t.l.SetBenchmark(t.pair, b); err != nil {
+		t.log.Error().Err(err).Msg("failed to persist the benchmark")
+		return
+	}
+	t.log.Info().Msg("benchmarking against buying and holding %f of capital at %f", b.Capital, b.Price)
+}
+
+// benchmark compares the change in the capital's value under the bot with buy-and-hold at the price, or nil before
+// the benchmark has started
+func (t *Trader) benchmark(price float64) *Benchmark {
+	b := t.l.Benchmark(t.pair)
+	if b == nil || price <= 0 {
+		return nil
+	}
+	// The capital's base grows with the price, and the bot's trades since the start add their own PnL to it
+	pos := t.Position()
+	pnl := b.Base*(price-b.Price) + pos.Pnl(price) - (b.PositionBase*price - b.PositionQuote)
+	hold := b.Capital * (price/b.Price - 1)
+	return &Benchmark{
+		StartedAt:  b.StartedAt,
+		StartPrice: b.Price,
+		Capital:    b.Capital,
+		Pnl:        pnl,
+		HoldPnl:    hold,
+		ExcessPnl:  pnl - hold,
+		ExcessPct:  (pnl - hold) / b.Capital * 100,
+	}
+}
@@ -0,0 +1,76 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// The congestion policy's actions
+const (
+	congestionRaiseFees     = "raise_fees"
+	congestionWidenSlippage = "widen_slippage"
+	congestionPauseEntries  = "pause_entries"
+)
+
+// checkCongestion looks for congestion every configured interval - slow slots, an expensive fee market, or the bot's
+// own swaps failing - and applies the congestion policy while any of them lasts, lifting it once they all clear
+func (t *Trader) checkCongestion(ctx context.Context, now time.Time) {
+	actions := t.cfg.CongestionActions
+	if len(actions) == 0 || t.observing() || now.Sub(t.congestionChecked) < t.cfg.CongestionCheckInterval {
+		return
+	}
+	t.congestionChecked = now
+
+	stats, err := t.j.NetworkStats(ctx)
+	if err != nil {
+		t.log.Warn().Err(err).Msg("failed to sample the network for congestion")
+		return
+	}
+	var reasons []string
+	if limit := t.cfg.CongestionSlotTimeMs; limit > 0 && stats.SlotTime > time.Duration(limit*float64(time.Millisecond)) {
+		reasons = append(reasons, fmt.Sprintf("slots take %s", stats.SlotTime.Round(time.Millisecond)))
+	}
+	if limit := t.cfg.CongestionPriorityFee; limit > 0 && stats.PriorityFee > uint64(limit) {
+		reasons = append(reasons, fmt.Sprintf("median priority fee %d micro-lamports", stats.PriorityFee))
+	}
+	rate, swaps := t.om.FailureRate(t.cfg.CongestionFailureWindow)
+	if limit := t.cfg.CongestionFailureRatePct; limit > 0 && swaps >= t.cfg.CongestionFailureMinSwaps &&
+		rate*100 >= limit {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% of the last %d swaps failed", rate*100, swaps))
+	}
+
+	t.mu.Lock()
+	was := len(t.congestion) > 0
+	t.congestion = reasons
+	t.mu.Unlock()
+	congested := len(reasons) > 0
+	t.j.SetCongestion(jupiter.Congestion{
+		RaiseFees:     congested && slices.Contains(actions, congestionRaiseFees),
+		WidenSlippage: congested && slices.Contains(actions, congestionWidenSlippage),
+	})
+
+	switch {
+	case congested:
+		err = fmt.Errorf("%s - %s in effect", strings.Join(reasons, ", "), strings.Join(actions, ", "))
+		if !was {
+			t.log.Warn().Err(err).Msg("network congested")
+		}
+		t.alert(ctx, notifier.KeyCongestion, notifier.SeverityWarning, "network congested", err)
+	case was:
+		t.log.Info().Msg("network congestion cleared - %s lifted", strings.Join(actions, ", "))
+		t.clear(ctx, notifier.KeyCongestion)
+	}
+}
+
+// pausingEntries reports whether the congestion policy currently holds back orders that open positions
+func (t *Trader) pausingEntries() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.congestion) > 0 && slices.Contains(t.cfg.CongestionActions, congestionPauseEntries)
+}
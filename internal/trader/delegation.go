@@ -0,0 +1,74 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// checkDelegations reads what the funding wallet delegated to the bot every configured interval, alerting while the
+// bot is not the delegate of a funding account or the allowance left cannot cover an order of the configured size
+func (t *Trader) checkDelegations(ctx context.Context, now time.Time) {
+	interval := t.cfg.DelegationCheckInterval
+	if !t.j.Delegated() || interval <= 0 || now.Sub(t.delegationsChecked) < interval {
+		return
+	}
+	t.delegationsChecked = now
+
+	t.mu.RLock()
+	sizes := map[string]float64{t.pair.Quote: t.buySize, t.pair.Base: t.sellSize}
+	t.mu.RUnlock()
+	var (
+		delegations []jupiter.Delegation
+		problems    []string
+	)
+	for _, mint := range []string{t.pair.Base, t.pair.Quote} {
+		d, err := t.j.Delegation(ctx, mint)
+		if err != nil {
+			t.log.Error().Err(err).Msg("failed to read the delegation of mint %s", mint)
+			continue
+		}
+		delegations = append(delegations, d)
+		switch {
+		case d.Delegate != t.j.PublicKey().String():
+			problems = append(problems, fmt.Sprintf("the bot is not the delegate of funding account %s", d.Account))
+		case d.Spendable < sizes[mint]:
+			problems = append(problems, fmt.Sprintf("%f of %s is left to spend from funding account %s, less than "+
+				"an order of %f", d.Spendable, mint, d.Account, sizes[mint]))
+		}
+	}
+
+	t.mu.Lock()
+	t.delegations = delegations
+	t.mu.Unlock()
+	if len(problems) > 0 {
+		t.alert(ctx, notifier.KeyDelegation, notifier.SeverityWarning,
+			"delegated funds running out - approve a new allowance from the funding wallet",
+			errors.New(strings.Join(problems, "; ")))
+		return
+	}
+	t.clear(ctx, notifier.KeyDelegation)
+}
+
+// delegatedSpendable returns what the bot may still pull from the funding wallet to spend on the side, as of the last
+// check, and whether it was checked
+func (t *Trader) delegatedSpendable(side common.Signal) (float64, bool) {
+	input, _, err := t.pair.Mints(side)
+	if err != nil {
+		return 0, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, d := range t.delegations {
+		if d.Mint == input {
+			return d.Spendable, true
+		}
+	}
+	return 0, false
+}
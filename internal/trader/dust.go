@@ -0,0 +1,110 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// Dust is a balance of a token outside the pair, typically an airdrop - it is kept out of the bot's accounting
+type Dust struct {
+	jupiter.Holding
+	ValueUsd float64 `json:"value_usd,omitempty"` // zero when the token has no price
+	Skipped  string  `json:"skipped,omitempty"`   // why it was not converted
+}
+
+// SweepDust finds the wallet's balances of tokens outside the pair and, when conversion is enabled, swaps each worth
+// at least the configured minimum to the quote asset once it passes the scam-token checks - the quote received is
+// recorded in the ledger as an external adjustment, as the bot did not earn it trading - it is run by the scheduler
+func (t *Trader) SweepDust(ctx context.Context) error {
+	holdings, err := t.j.Holdings(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list the wallet's tokens: %w", err)
+	}
+
+	var dust []Dust
+	for _, h := range holdings {
+		if h.Mint == t.pair.Base || h.Mint == t.pair.Quote || h.Mint == solana.SolMint.String() ||
+			slices.Contains(t.cfg.DustIgnoreMints, h.Mint) {
+			continue
+		}
+		d := Dust{Holding: h}
+		if price, err := t.j.GetPrice(ctx, h.Mint); err == nil {
+			d.ValueUsd = h.Amount * price
+		}
+		switch {
+		case !t.cfg.DustConvert || !t.cfg.NeedsSecretKey():
+			d.Skipped = "conversion disabled"
+		case d.ValueUsd < t.cfg.DustConvertMinUsd:
+			d.Skipped = fmt.Sprintf("worth less than $%.2f", t.cfg.DustConvertMinUsd)
+		default:
+			if err = t.convertDust(ctx, d); err != nil {
+				d.Skipped = err.Error()
+				t.log.Warn().Msg("not converting %f of token %s: %s", h.Amount, h.Mint, err)
+			}
+		}
+		dust = append(dust, d)
+	}
+
+	t.mu.Lock()
+	t.dust = dust
+	t.mu.Unlock()
+	return nil
+}
+
+// convertDust screens the token for the signs of a scam - not vouched for by Jupiter's token list, or freezable so
+// the swap may be blocked - then swaps it to the quote asset and records what it brought in
+func (t *Trader) convertDust(ctx context.Context, d Dust) error {
+	info, err := t.j.TokenInfo(ctx, d.Mint)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.cfg.DustRequireVerified && !info.Verified():
+		return errors.New("not verified by Jupiter's token list")
+	case info.FreezeAuthority != nil && *info.FreezeAuthority != "":
+		return fmt.Errorf("the token can be frozen by %s", *info.FreezeAuthority)
+	}
+
+	txId, err := t.j.Sweep(ctx, d.Holding, t.pair.Quote, t.cfg.DustMaxPriceImpactPct, "dust-"+d.Mint)
+	if err != nil {
+		return err
+	}
+	if t.j.Proposing() {
+		proposal := txId
+		if txId, err = t.j.AwaitExecution(ctx, proposal); err != nil {
+			return fmt.Errorf("proposal %s not executed - reject it in the multisig unless it should still execute: %w",
+				proposal, err)
+		}
+	}
+	t.mu.Lock()
+	t.sweeps[txId] = true
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.sweeps, txId)
+		t.mu.Unlock()
+	}()
+	if err = t.j.MonitorTx(ctx, txId, t.log, nil); err != nil {
+		return fmt.Errorf("swap %s not confirmed: %w", txId, err)
+	}
+	fill, err := t.j.Fill(ctx, txId)
+	if err != nil {
+		return fmt.Errorf("could not read the fill of swap %s: %w", txId, err)
+	}
+
+	memo := fmt.Sprintf("converted %f %s (%s) to the quote asset", fill.InAmount, info.Symbol, d.Mint)
+	if err = t.l.RecordAdjustment(ledger.Adjustment{TxId: txId, Mint: t.pair.Quote, Amount: fill.OutAmount,
+		Time: time.Now(), Memo: memo}); err != nil {
+		return fmt.Errorf("could not record the conversion: %w", err)
+	}
+	t.notify(ctx, "converted dust", fmt.Sprintf("%s for %f of the quote asset in swap %s", memo, fill.OutAmount, txId))
+	return nil
+}
@@ -0,0 +1,72 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/equity"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+)
+
+// recordEquity snapshots the equity at the bar's close, then suspends execution when the drawdown crosses the
+// circuit breaker's limit - only crossing it trips the breaker, so a drawdown that outlasts the cooldown does not
+// keep execution suspended for good
+func (t *Trader) recordEquity(ctx context.Context, bar common.Bar) {
+	if t.curve == nil {
+		return
+	}
+	pos := t.Position()
+	pnl := pos.Pnl(bar.Close)
+	var capital float64
+	if b := t.l.Benchmark(t.pair); b != nil {
+		capital = b.Capital
+	}
+	snap := equity.Snapshot{
+		Time:   bar.Time,
+		Price:  bar.Close,
+		Base:   pos.Base,
+		Quote:  pos.Quote,
+		Pnl:    pnl,
+		Equity: capital + pnl,
+	}
+	if t.cfg.PairPricing == pricing.PricingQuote {
+		t.mu.RLock()
+		snap.QuoteUsd = t.quoteUsd
+		t.mu.RUnlock()
+	}
+	if err := t.curve.Record(snap); err != nil {
+		t.log.Error().Err(err).Msg("failed to record equity")
+	}
+
+	limit := t.cfg.CircuitBreakerMaxDrawdownPct
+	stats := t.curve.Stats()
+	if limit <= 0 || stats == nil {
+		return
+	}
+	tripped := t.drawdownTripped
+	t.drawdownTripped = stats.DrawdownPct > limit
+	if tripped || !t.drawdownTripped {
+		return
+	}
+	until := time.Now().Add(t.cfg.CircuitBreakerCooldown)
+	t.breaker.Hold(until)
+	t.mu.Lock()
+	t.breakerUntil = &until
+	t.mu.Unlock()
+	err := fmt.Errorf("equity drawdown %.2f%% exceeds %.2f%% (%s)", stats.DrawdownPct, limit, stats)
+	t.log.Warn().Msg("circuit breaker tripped, suspending execution until %s: %s", until.Format(time.RFC3339), err)
+	t.alert(ctx, notifier.KeyCircuitBreaker, notifier.SeverityWarning, "execution suspended by the circuit breaker", err)
+}
+
+// Equity returns the persisted equity snapshots between from and to, where a zero bound is open, with the rolling
+// stats - nil snapshots when no equity file is configured
+func (t *Trader) Equity(from time.Time, to time.Time) ([]equity.Snapshot, *equity.Stats, error) {
+	snapshots, err := t.curve.Load(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshots, t.curve.Stats(), nil
+}
@@ -0,0 +1,120 @@
+package trader
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Handoff is the runtime state one process hands to the next across a restart - everything the ledger and the candle
+// cache do not already persist
+type Handoff struct {
+	SavedAt         time.Time     `json:"saved_at"`
+	Paused          bool          `json:"paused"`
+	BuySize         float64       `json:"buy_size"`
+	SellSize        float64       `json:"sell_size"`
+	Interval        time.Duration `json:"interval"`
+	LastTick        time.Time     `json:"last_tick"`
+	LastPrice       float64       `json:"last_price"`
+	LastSignal      common.Signal `json:"last_signal"`
+	BreakerUntil    *time.Time    `json:"breaker_until,omitempty"`
+	ProfitLockArmed bool          `json:"profit_lock_armed"`
+	FormingBar      *common.Bar   `json:"forming_bar,omitempty"`
+	IntrabarTraded  common.Signal `json:"intrabar_traded,omitempty"`
+	InFlight        []string      `json:"in_flight,omitempty"` // swaps that had not settled when the process stopped
+}
+
+// Handoff captures the loop's runtime state - it must not run concurrently with the loop
+func (t *Trader) Handoff() Handoff {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	h := Handoff{
+		SavedAt:         time.Now(),
+		Paused:          t.paused,
+		BuySize:         t.buySize,
+		SellSize:        t.sellSize,
+		Interval:        t.interval,
+		LastTick:        t.lastTick,
+		LastPrice:       t.lastPrice,
+		LastSignal:      t.lastSignal,
+		BreakerUntil:    t.breakerUntil,
+		ProfitLockArmed: t.profitLockArmed,
+		IntrabarTraded:  t.intrabar.traded,
+		InFlight:        t.om.InFlight(),
+	}
+	if t.bars.forming {
+		bar := t.bars.bar
+		h.FormingBar = &bar
+	}
+	return h
+}
+
+// Restore carries a previous process's runtime state over - call it after warming up and before the loop starts - a
+// forming bar is only continued when it has not closed yet
+func (t *Trader) Restore(h Handoff) {
+	t.mu.Lock()
+	t.paused = h.Paused
+	if h.BuySize > 0 && h.SellSize > 0 {
+		t.buySize = h.BuySize
+		t.sellSize = h.SellSize
+	}
+	if h.Interval > 0 {
+		t.interval = h.Interval
+	}
+	t.lastTick = h.LastTick
+	t.lastPrice = h.LastPrice
+	if h.LastSignal != "" {
+		t.lastSignal = h.LastSignal
+	}
+	if h.BreakerUntil != nil && h.BreakerUntil.After(time.Now()) {
+		t.breaker.Hold(*h.BreakerUntil)
+		t.breakerUntil = h.BreakerUntil
+	}
+	t.mu.Unlock()
+
+	t.profitLockArmed = h.ProfitLockArmed
+	if h.FormingBar != nil && t.cfg.BarInterval > 0 && h.FormingBar.Time.After(time.Now()) {
+		t.bars.bar = *h.FormingBar
+		t.bars.forming = true
+		t.intrabar.traded = h.IntrabarTraded
+	}
+	if len(h.InFlight) > 0 {
+		t.log.Warn().Msg("swaps %v were in flight when the previous process stopped - check they settled", h.InFlight)
+	}
+}
+
+// SaveHandoff writes the handoff to a temporary file and renames it into place so a crash never leaves a torn file
+func SaveHandoff(path string, h Handoff) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadHandoff reads the handoff at the path - nil when there is none
+func LoadHandoff(path string) (*Handoff, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var h Handoff
+	if err = json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
@@ -0,0 +1,91 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// Outage is a run of failed price fetches the loop is recovering from - execution stays held until enough clean
+// intervals in a row followed it and the indicators look sane again
+type Outage struct {
+	Since    time.Time `json:"since"`
+	Failures int       `json:"failures"`
+	Clean    int       `json:"clean"` // successful fetches since the last failure
+	Required int       `json:"required"`
+}
+
+// String describes the outage for the status
+func (o Outage) String() string {
+	return fmt.Sprintf("%d failed price fetches since %s, %d of %d clean intervals", o.Failures,
+		o.Since.Format(time.RFC3339), o.Clean, o.Required)
+}
+
+// priceFailed counts a failed price fetch, declaring an outage once the configured number of them ran in a row - a
+// failure during an outage starts its clean intervals over
+func (t *Trader) priceFailed(ctx context.Context, now time.Time) {
+	t.mu.Lock()
+	t.priceFailures++
+	failures, started := t.priceFailures, false
+	switch {
+	case t.outage != nil:
+		t.outage.Failures++
+		t.outage.Clean = 0
+	case t.cfg.HealthOutageFailures > 0 && failures >= t.cfg.HealthOutageFailures:
+		t.outage = &Outage{Since: now, Failures: failures, Required: t.cfg.HealthRecoveryIntervals}
+		started = true
+	}
+	t.mu.Unlock()
+
+	if started {
+		err := fmt.Errorf("%d price fetches failed in a row - execution is held until %d clean intervals pass",
+			failures, t.cfg.HealthRecoveryIntervals)
+		t.log.Warn().Err(err).Msg("price outage")
+		t.alert(ctx, notifier.KeyPriceOutage, notifier.SeverityWarning, "price outage", err)
+	}
+}
+
+// priceRecovered counts a successful price fetch towards the end of an outage, which is over once enough of them ran
+// in a row and the RSI the strategies hold, as of the last closed bar, is a sane value again
+func (t *Trader) priceRecovered(ctx context.Context) {
+	t.mu.Lock()
+	t.priceFailures = 0
+	o := t.outage
+	if o == nil {
+		t.mu.Unlock()
+		return
+	}
+	o.Clean++
+	clean, required := o.Clean, o.Required
+	rsi := t.e.Rsi()
+	sane := !math.IsNaN(rsi) && rsi >= 0 && rsi <= 100
+	if clean >= required && sane {
+		t.outage = nil
+	}
+	t.mu.Unlock()
+
+	switch {
+	case clean < required:
+		t.log.Info().Msg("recovering from the price outage - %d of %d clean intervals", clean, required)
+	case !sane:
+		t.log.Warn().Msg("indicators failed the sanity check after the price outage (RSI %f) - execution still held",
+			rsi)
+	default:
+		t.log.Info().Msg("price outage over after %d clean intervals - execution resumed", clean)
+		t.clear(ctx, notifier.KeyPriceOutage)
+	}
+}
+
+// recovering returns the outage execution is held for, or nil
+func (t *Trader) recovering() *Outage {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.outage == nil {
+		return nil
+	}
+	o := *t.outage
+	return &o
+}
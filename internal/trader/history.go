@@ -0,0 +1,80 @@
+package trader
+
+import (
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+)
+
+// The trades returned at once by default and at most
+const (
+	DefaultTradePage = 100
+	MaxTradePage     = 1000
+)
+
+// TradeQuery filters and pages the trade history - zero values leave a filter open
+type TradeQuery struct {
+	Pair   string // BASE/QUOTE mints
+	From   time.Time
+	To     time.Time
+	Status string // of the order the trade filled, e.g. FILLED or PARTIALLY_FILLED
+	Offset int
+	Limit  int
+}
+
+// TradeRecord is a fill from the ledger with the pair it traded and the status of the order it belongs to - the
+// status is empty for trades made outside an order, like manual and simulated ones
+type TradeRecord struct {
+	ledger.Trade
+	Pair        string `json:"pair"`
+	OrderStatus string `json:"order_status,omitempty"`
+}
+
+// TradePage is a page of the trade history, oldest first - Total counts every trade the filters match
+type TradePage struct {
+	Trades []TradeRecord `json:"trades"`
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+}
+
+// TradeHistory returns the page of the ledger's trades the query asks for, across every pair unless it names one
+func (t *Trader) TradeHistory(q TradeQuery) TradePage {
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultTradePage
+	case q.Limit > MaxTradePage:
+		q.Limit = MaxTradePage
+	}
+	q.Offset = max(q.Offset, 0)
+
+	settled := make(map[string]string)
+	for _, o := range t.l.Orders() {
+		settled[o.OrderId] = o.Status
+	}
+	page := TradePage{Trades: []TradeRecord{}, Offset: q.Offset, Limit: q.Limit}
+	for _, tr := range t.l.Trades() {
+		r := TradeRecord{Trade: tr, Pair: tradePair(tr).String(), OrderStatus: settled[tr.OrderId]}
+		if o := t.om.Order(tr.OrderId); o != nil {
+			r.OrderStatus = string(o.Status)
+		}
+		if (q.Pair != "" && r.Pair != q.Pair) || (q.Status != "" && r.OrderStatus != q.Status) ||
+			!within(tr.Time, q.From, q.To) {
+			continue
+		}
+		if page.Total >= q.Offset && len(page.Trades) < q.Limit {
+			page.Trades = append(page.Trades, r)
+		}
+		page.Total++
+	}
+	return page
+}
+
+// tradePair returns the pair a trade swapped on, told apart by its side
+func tradePair(tr ledger.Trade) common.Pair {
+	if tr.Side == common.SellSignal {
+		return common.Pair{Base: tr.InputMint, Quote: tr.OutputMint}
+	}
+	return common.Pair{Base: tr.OutputMint, Quote: tr.InputMint}
+}
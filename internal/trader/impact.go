@@ -0,0 +1,52 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/orders"
+	"github.com/josephawallace/ninetyfive/internal/position"
+)
+
+// impactAttempts is how many times an order is shrunk and re-quoted before it is given up on
+const impactAttempts = 3
+
+// fitImpact shrinks the order until its quote's price impact is within the configured limit, re-quoting each time -
+// impact grows about linearly with size on an AMM, so each attempt scales the size by the limit over the impact, with
+// some headroom - an order that would have to shrink below the minimum share of its size is refused
+func (t *Trader) fitImpact(ctx context.Context, req orders.Request, q *jupiter.Quote,
+	pos position.Position) (orders.Request, *jupiter.Quote, error) {
+	limit := t.cfg.PriceImpactMaxBps
+	if limit <= 0 {
+		return req, q, nil
+	}
+	original := req.Size
+	floor := original * t.cfg.PriceImpactMinSizePct / 100
+	for attempt := 0; ; attempt++ {
+		impact := q.PriceImpactPct * 10_000
+		if impact <= limit {
+			if req.Size < original {
+				t.log.Info().Msg("shrank %s order from %f to %f to keep the price impact at %.1f bps within %.1f bps",
+					req.Side, original, req.Size, impact, limit)
+			}
+			return req, q, nil
+		}
+		if attempt == impactAttempts {
+			return req, q, fmt.Errorf("price impact %.1f bps still exceeds %.1f bps after %d re-quotes", impact, limit,
+				impactAttempts)
+		}
+
+		size := req.Size * limit / impact * 0.9
+		if size < floor {
+			return req, q, fmt.Errorf("price impact %.1f bps exceeds %.1f bps, and fitting it would shrink the order "+
+				"from %f to %f, below %.0f%% of its size", impact, limit, original, size, t.cfg.PriceImpactMinSizePct)
+		}
+		req.Size = size
+		req.Effect = pos.Classify(req.Side, size, req.Price)
+		var err error
+		if q, err = t.quote(ctx, req); err != nil {
+			return req, q, fmt.Errorf("could not re-quote the shrunk order: %w", err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// checkMints compares the pair's mints with the metadata first recorded for them every configured interval, halting
+// trading while one was closed or changed its decimals, symbol, or name - the usual signs of a token migrating to a
+// new mint - until the pair is remapped or the change accepted with the remap-mint command
+func (t *Trader) checkMints(ctx context.Context, now time.Time) {
+	if t.cfg.MintCheckInterval <= 0 || now.Sub(t.mintsChecked) < t.cfg.MintCheckInterval {
+		return
+	}
+	t.mintsChecked = now
+
+	if t.mintReasons == nil {
+		t.mintReasons = make(map[string]string)
+	}
+	var reasons []string
+	for _, mint := range []string{t.pair.Base, t.pair.Quote} {
+		info, err := t.j.MintInfo(ctx, mint)
+		if err != nil {
+			// A mint that could not be read keeps the halt it had, only a read matching the record lifts it
+			t.log.Warn().Err(err).Msg("failed to read the metadata of mint %s", mint)
+			if reason := t.mintReasons[mint]; reason != "" {
+				reasons = append(reasons, reason)
+			}
+			continue
+		}
+		rec, ok := t.l.MintRecord(mint)
+		if !ok && info.Exists {
+			rec = ledger.MintRecord{Decimals: info.Decimals, Symbol: info.Symbol, Name: info.Name, SeenAt: now}
+			if err = t.l.SetMintRecord(mint, rec); err != nil {
+				t.log.Error().Err(err).Msg("failed to record the metadata of mint %s", mint)
+			}
+			delete(t.mintReasons, mint)
+			continue
+		}
+		reason := mintChange(rec, info)
+		t.mintReasons[mint] = reason
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	t.mu.Lock()
+	was := t.mintHalt
+	t.mintHalt = strings.Join(reasons, "; ")
+	halt := t.mintHalt
+	t.mu.Unlock()
+	switch {
+	case halt != "":
+		err := fmt.Errorf("%s - if the token migrated, stop the bot, run `ninetyfive remap-mint -from OLD -to NEW "+
+			"-ratio NEW_UNITS_PER_OLD` and point the config at the new mint, or if the change is expected run "+
+			"`ninetyfive remap-mint -accept MINT`", halt)
+		if was == "" {
+			t.log.Error().Err(err).Msg("trading on %s halted by a mint change", t.pair)
+		}
+		t.alert(ctx, notifier.KeyMintChange, notifier.SeverityCritical, "trading halted by a mint change", err)
+	case was != "":
+		t.log.Info().Msg("the pair's mints match their recorded metadata again - trading resumed")
+		t.clear(ctx, notifier.KeyMintChange)
+	}
+}
+
+// mintChange describes how the mint differs from its recorded metadata, empty when it does not - the token list is
+// best effort, so a symbol or name it did not return is not a change
+func mintChange(rec ledger.MintRecord, info jupiter.MintInfo) string {
+	switch {
+	case !info.Exists:
+		return fmt.Sprintf("mint %s no longer exists", info.Mint)
+	case info.Decimals != rec.Decimals:
+		return fmt.Sprintf("mint %s changed from %d to %d decimals", info.Mint, rec.Decimals, info.Decimals)
+	case info.Symbol != "" && rec.Symbol != "" && info.Symbol != rec.Symbol:
+		return fmt.Sprintf("mint %s changed its symbol from %s to %s", info.Mint, rec.Symbol, info.Symbol)
+	case info.Name != "" && rec.Name != "" && info.Name != rec.Name:
+		return fmt.Sprintf("mint %s changed its name from %q to %q", info.Mint, rec.Name, info.Name)
+	}
+	return ""
+}
+
+// mintHalted returns why trading is halted by a mint change, empty when it is not
+func (t *Trader) mintHalted() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.mintHalt
+}
@@ -0,0 +1,100 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/orders"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+)
+
+// WalletValue is the watched wallet's holdings in the pair, marked to market in USD
+type WalletValue struct {
+	Address  string    `json:"address"`
+	Base     float64   `json:"base"`
+	Quote    float64   `json:"quote"`
+	ValueUsd float64   `json:"value_usd"`
+	Time     time.Time `json:"time"`
+}
+
+// observing reports whether the loop watches a wallet instead of trading
+func (t *Trader) observing() bool {
+	return t.cfg.ObserveWallet != ""
+}
+
+// fillsAtOnce reports whether the orders of an observer are recorded as filled at once instead of being worked
+// through the paper swapper's synthetic confirmations
+func (t *Trader) fillsAtOnce() bool {
+	return t.observing() && !t.cfg.PaperConfirmations
+}
+
+// markToMarket values the watched wallet's holdings of both assets of the pair at the current prices - price is the
+// pair price the tick already fetched, converted to USD through the quote's price when the pair is priced in it
+func (t *Trader) markToMarket(ctx context.Context, price float64) error {
+	base, err := t.j.TokenBalance(ctx, t.pair.Base)
+	if err != nil {
+		return fmt.Errorf("failed to get the watched wallet's base balance: %w", err)
+	}
+	quote, err := t.j.TokenBalance(ctx, t.pair.Quote)
+	if err != nil {
+		return fmt.Errorf("failed to get the watched wallet's quote balance: %w", err)
+	}
+	basePrice := price
+	var quotePrice float64
+	if t.cfg.PairPricing == pricing.PricingQuote {
+		t.mu.RLock()
+		quotePrice = t.quoteUsd
+		t.mu.RUnlock()
+		basePrice = price * quotePrice
+	} else if quotePrice, err = t.ps.Price(ctx, t.pair.Quote); err != nil {
+		return fmt.Errorf("failed to get the quote currency price: %w", err)
+	}
+
+	w := &WalletValue{
+		Address:  t.cfg.ObserveWallet,
+		Base:     base,
+		Quote:    quote,
+		ValueUsd: base*basePrice + quote*quotePrice,
+		Time:     time.Now(),
+	}
+	t.mu.Lock()
+	t.wallet = w
+	t.mu.Unlock()
+	t.log.Info().Msg("watched wallet %s holds %f base and %f quote, worth $%f", w.Address, w.Base, w.Quote, w.ValueUsd)
+	return nil
+}
+
+// simulate records the order as if it had filled in full at the quoted price, so the ledger of an observer holds what
+// the bot would have done - the paper ledger refuses to open a live one, so simulated fills never mix with real ones
+func (t *Trader) simulate(req orders.Request, quotePrice float64) error {
+	inputMint, outputMint, err := req.Pair.Mints(req.Side)
+	if err != nil {
+		return err
+	}
+	output := req.Size * quotePrice
+	if req.Side == common.BuySignal {
+		output = req.Size / quotePrice
+	}
+
+	now := time.Now()
+	if err = t.l.RecordTrade(ledger.Trade{
+		OrderId:      fmt.Sprintf("sim-%s-%d", req.Side, now.UnixNano()),
+		Side:         req.Side,
+		InputMint:    inputMint,
+		OutputMint:   outputMint,
+		InputAmount:  req.Size,
+		OutputAmount: output,
+		Price:        quotePrice,
+		Time:         now,
+		Simulated:    true,
+		Snapshot:     req.Snapshot,
+	}); err != nil {
+		return err
+	}
+	t.log.Info().Msg("observer mode - would have swapped %f for %f (%s %s at $%f)", req.Size, output, req.Effect,
+		req.Side, quotePrice)
+	return nil
+}
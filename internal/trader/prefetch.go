@@ -0,0 +1,61 @@
+package trader
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+)
+
+// prefetchQuotes quotes both sides for the swap a signal would send alongside the tick's price fetch when the tick
+// will reach a decision, so the signal is swapped without a quote round trip after it - the returned channel is
+// closed once the quotes are in, right away when none are due
+func (t *Trader) prefetchQuotes(ctx context.Context, now time.Time) <-chan struct{} {
+	done := make(chan struct{})
+	for _, m := range t.j.TakePrefetchMisses() {
+		t.log.Info().Msg("prefetched quote for %f %s missed - the swap was of %f", m.Prefetched, m.InputMint, m.Amount)
+	}
+	if !t.cfg.QuotePrefetch || t.cfg.SignalOnly || !t.decisionDue(now) {
+		close(done)
+		return done
+	}
+	amounts := t.prefetchAmounts()
+	if amounts == nil {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		if err := t.j.Prefetch(ctx, t.pair, amounts); err != nil {
+			t.log.Warn().Err(err).Msg("failed to prefetch quotes - a signal this bar is quoted after the decision")
+		}
+	}()
+	return done
+}
+
+// prefetchAmounts returns the first swap of the order each side's signal would be sized at, or nil when the size
+// depends on what the bar decides - the ensemble's vote share or the grid level signalled at - an order later shrunk
+// by the capital budget or price impact still misses the prefetched quote
+func (t *Trader) prefetchAmounts() map[common.Signal]float64 {
+	if !t.e.FullStrength() || len(t.cfg.GridLevelSizeWeights) > 0 {
+		return nil
+	}
+	t.mu.RLock()
+	buySize, sellSize := t.buySize, t.sellSize
+	t.mu.RUnlock()
+	amounts := make(map[common.Signal]float64)
+	for _, side := range []common.Signal{common.BuySignal, common.SellSignal} {
+		size := t.orderSize(ensemble.Decision{Signal: side, Strength: 1}, 0, buySize, sellSize)
+		if children := t.algo.Schedule(size); len(children) > 0 {
+			amounts[side] = children[0].Size
+		}
+	}
+	return amounts
+}
+
+// decisionDue reports whether a tick at now evaluates the strategies - every tick when ticks are bars of their own
+// or signals are evaluated intrabar, otherwise only the tick that completes a bar
+func (t *Trader) decisionDue(now time.Time) bool {
+	return t.cfg.BarInterval <= 0 || t.cfg.SignalEvaluation == "intrabar" || t.bars.closes(now)
+}
@@ -0,0 +1,60 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// checkProfitLock ratchets the profit floor up as the PnL reaches each milestone, and flattens the position and
+// pauses trading when the PnL falls from the floor to below it - the floor is persisted in the ledger, so a restart
+// keeps it
+func (t *Trader) checkProfitLock(ctx context.Context, price float64) {
+	if len(t.cfg.ProfitLockMilestones) == 0 || t.cfg.SignalOnly {
+		return
+	}
+	pnl := t.Position().Pnl(price)
+
+	lock := t.l.ProfitLock()
+	var reached float64
+	for _, m := range t.cfg.ProfitLockMilestones {
+		if pnl >= m.Float() {
+			reached = max(reached, m.Float())
+		}
+	}
+	if reached > 0 && (lock == nil || reached > lock.Milestone) {
+		lock = &ledger.ProfitLock{
+			Milestone: reached,
+			Floor:     reached * (1 - t.cfg.ProfitLockGiveback/100),
+			RaisedAt:  time.Now(),
+		}
+		if err := t.l.SetProfitLock(*lock); err != nil {
+			t.log.Error().Err(err).Msg("failed to persist the profit lock")
+		}
+		t.log.Warn().Msg("PnL %f reached the %f milestone - profit floor raised to %f", pnl, lock.Milestone, lock.Floor)
+		t.notify(ctx, "profit floor raised", fmt.Sprintf("PnL %s reached the %s milestone - the floor is now %s",
+			t.fx.Format(pnl), t.fx.Format(lock.Milestone), t.fx.Format(lock.Floor)))
+	}
+	if lock == nil {
+		return
+	}
+
+	// Only a fall through the floor triggers, so a flat position resting below it after a trigger does not keep
+	// triggering once trading is resumed
+	armed := t.profitLockArmed
+	t.profitLockArmed = pnl >= lock.Floor
+	if !armed || pnl >= lock.Floor {
+		return
+	}
+	t.log.Warn().Msg("PnL %f fell below the %f profit floor - flattening and pausing", pnl, lock.Floor)
+	t.Pause()
+	err := t.flatten(ctx)
+	if err == nil {
+		err = fmt.Errorf("PnL %f fell below the %f floor locked in at the %f milestone - trading is paused",
+			pnl, lock.Floor, lock.Milestone)
+	}
+	t.alert(ctx, notifier.KeyProfitLock, notifier.SeverityCritical, "profit lock triggered", err)
+}
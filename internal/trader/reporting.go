@@ -0,0 +1,74 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+)
+
+// Reporting is the bot's PnL and value in the reporting currency, converted from the quote asset through its USD price
+type Reporting struct {
+	Currency    string    `json:"currency"`
+	Rate        float64   `json:"rate"`                // units of the currency per USD
+	QuoteUsd    float64   `json:"quote_usd,omitempty"` // the quote asset's USD price, when the pair is priced in it
+	RateAt      time.Time `json:"rate_at"`
+	Pnl         float64   `json:"pnl"`
+	Equity      float64   `json:"equity,omitempty"`       // the latest equity curve value
+	WalletValue float64   `json:"wallet_value,omitempty"` // of the watched wallet in observer mode
+}
+
+// String summarizes the converted figures on one line
+func (r Reporting) String() string {
+	s := fmt.Sprintf("PnL %.2f %s", r.Pnl, r.Currency)
+	if r.Equity != 0 {
+		s += fmt.Sprintf(", equity %.2f %s", r.Equity, r.Currency)
+	}
+	if r.WalletValue != 0 {
+		s += fmt.Sprintf(", wallet %.2f %s", r.WalletValue, r.Currency)
+	}
+	if r.QuoteUsd != 0 {
+		s += fmt.Sprintf(", quote at $%f", r.QuoteUsd)
+	}
+	return s + fmt.Sprintf(" at %.4f %s per USD as of %s", r.Rate, r.Currency, r.RateAt.Format(time.RFC3339))
+}
+
+// refreshRate fetches the reporting currency's rate when it is due
+func (t *Trader) refreshRate(ctx context.Context) {
+	if err := t.fx.Refresh(ctx); err != nil {
+		t.log.Warn().Err(err).Msg("failed to refresh the %s exchange rate", t.fx.Currency())
+	}
+}
+
+// reporting converts the status's figures into the reporting currency - USD when the pair is priced in a quote asset
+// and no other currency is configured - or returns nil when there is nothing to convert or no rate has been fetched
+func (t *Trader) reporting(s Status) *Reporting {
+	inQuote := t.cfg.PairPricing == pricing.PricingQuote
+	r := &Reporting{Currency: "USD", Rate: 1, RateAt: s.LastTick}
+	if t.fx != nil {
+		rate, at, ok := t.fx.Rate()
+		if !ok {
+			return nil
+		}
+		r.Currency, r.Rate, r.RateAt = t.fx.Currency(), rate, at
+	} else if !inQuote {
+		return nil
+	}
+	// Figures in the quote asset take its USD price first - PnL each trade's price when it filled, equity the current
+	// one - the wallet's value already being in USD
+	toCurrency := r.Rate
+	r.Pnl = s.Position.Pnl(s.LastPrice) * r.Rate
+	if inQuote {
+		r.QuoteUsd = s.QuoteUsd
+		toCurrency *= s.QuoteUsd
+		r.Pnl = s.Position.PnlUsd(s.LastPrice, s.QuoteUsd) * r.Rate
+	}
+	if latest := t.curve.Latest(); latest != nil {
+		r.Equity = latest.Equity * toCurrency
+	}
+	if s.Wallet != nil {
+		r.WalletValue = s.Wallet.ValueUsd * r.Rate
+	}
+	return r
+}
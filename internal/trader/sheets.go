@@ -0,0 +1,87 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/sheets"
+)
+
+// ExportSheets exports the position, risk state, and recent trades to the spreadsheet, for stakeholders who follow
+// the bot in a spreadsheet rather than a dashboard - it is run by the scheduler
+func (t *Trader) ExportSheets(ctx context.Context, s *sheets.Spreadsheet) error {
+	if err := s.Write(ctx, t.sheetTabs()); err != nil {
+		return fmt.Errorf("could not export to the spreadsheet: %w", err)
+	}
+	return nil
+}
+
+// sheetTabs lays the status out as the exported tabs - a row for the position in the pair, a key-value list for the
+// risk state, and the latest fills newest first
+func (t *Trader) sheetTabs() []sheets.Tab {
+	s := t.Status()
+	now := time.Now().UTC().Format(time.DateTime)
+
+	positions := sheets.Tab{Title: "Positions", Rows: [][]interface{}{
+		{"pair", "base", "quote spent", "last price", "value", "pnl", "updated (UTC)"},
+		{s.Position.Pair.String(), s.Position.Base, s.Position.Quote, s.LastPrice, s.Position.Base * s.LastPrice,
+			s.Position.Pnl(s.LastPrice), now},
+	}}
+
+	state := "trading"
+	switch {
+	case s.Paused:
+		state = "paused"
+	case s.Maintenance != nil:
+		state = "maintenance"
+	case s.BreakerUntil != nil && s.BreakerUntil.After(time.Now()):
+		state = "circuit breaker until " + s.BreakerUntil.UTC().Format(time.DateTime)
+	}
+	risk := sheets.Tab{Title: "Risk", Rows: [][]interface{}{
+		{"metric", "value"},
+		{"state", state},
+		{"last tick (UTC)", s.LastTick.UTC().Format(time.DateTime)},
+		{"last signal", string(s.LastSignal)},
+		{"SOL balance", s.SolBalance},
+		{"open orders", len(s.Orders)},
+		{"in-flight transactions", len(s.InFlight)},
+	}}
+	if s.ProfitLock != nil {
+		risk.Rows = append(risk.Rows, []interface{}{"profit lock floor", s.ProfitLock.Floor})
+	}
+	if s.CapitalBudget > 0 {
+		risk.Rows = append(risk.Rows, []interface{}{"capital budget", s.CapitalBudget})
+	}
+	if s.Equity != nil {
+		risk.Rows = append(risk.Rows,
+			[]interface{}{"drawdown %", s.Equity.DrawdownPct},
+			[]interface{}{"max drawdown %", s.Equity.MaxDrawdownPct},
+			[]interface{}{"sharpe", s.Equity.Sharpe})
+	}
+	if s.Benchmark != nil {
+		risk.Rows = append(risk.Rows, []interface{}{"excess over buy-and-hold %", s.Benchmark.ExcessPct})
+	}
+	if s.Reporting != nil {
+		risk.Rows = append(risk.Rows, []interface{}{"pnl (" + s.Reporting.Currency + ")", s.Reporting.Pnl})
+	}
+	risk.Rows = append(risk.Rows, []interface{}{"updated (UTC)", now})
+
+	trades := sheets.Tab{Title: "Trades", Rows: [][]interface{}{
+		{"time (UTC)", "side", "input amount", "input mint", "output amount", "output mint", "price", "order", "tx"},
+	}}
+	for _, tr := range t.recentTrades(t.cfg.SheetsTradeRows) {
+		trades.Rows = append(trades.Rows, []interface{}{tr.Time.UTC().Format(time.DateTime), string(tr.Side),
+			tr.InputAmount, tr.InputMint, tr.OutputAmount, tr.OutputMint, tr.Price, tr.OrderId, tr.TxId})
+	}
+	return []sheets.Tab{positions, risk, trades}
+}
+
+// recentTrades returns the latest fills in the pair, newest first
+func (t *Trader) recentTrades(n int) []ledger.Trade {
+	trades := t.Trades(time.Time{}, time.Time{})
+	slices.Reverse(trades)
+	return trades[:min(n, len(trades))]
+}
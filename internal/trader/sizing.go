@@ -0,0 +1,30 @@
+package trader
+
+import (
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+)
+
+// orderSize sizes the order for the decision - the side's configured size, scaled by the share of strategies that
+// agree and by the weight of the grid level the signal was taken at - zero when there is nothing to trade
+func (t *Trader) orderSize(decision ensemble.Decision, level int, buySize float64, sellSize float64) float64 {
+	var size float64
+	switch decision.Signal {
+	case common.BuySignal:
+		size = buySize
+	case common.SellSignal:
+		size = sellSize
+	default:
+		return 0
+	}
+	return size * decision.Strength * t.levelWeight(level)
+}
+
+// levelWeight returns the size multiplier configured for the grid level, 1 for levels without one
+func (t *Trader) levelWeight(level int) float64 {
+	weights := t.cfg.GridLevelSizeWeights
+	if level < 0 || level >= len(weights) {
+		return 1
+	}
+	return weights[level]
+}
@@ -0,0 +1,1075 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/abtest"
+	"github.com/josephawallace/ninetyfive/internal/allocator"
+	"github.com/josephawallace/ninetyfive/internal/anomaly"
+	"github.com/josephawallace/ninetyfive/internal/broadcast"
+	"github.com/josephawallace/ninetyfive/internal/candles"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/equity"
+	"github.com/josephawallace/ninetyfive/internal/execution"
+	"github.com/josephawallace/ninetyfive/internal/filters"
+	"github.com/josephawallace/ninetyfive/internal/fx"
+	"github.com/josephawallace/ninetyfive/internal/hooks"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/latency"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+	"github.com/josephawallace/ninetyfive/internal/orders"
+	"github.com/josephawallace/ninetyfive/internal/position"
+	"github.com/josephawallace/ninetyfive/internal/pricing"
+	"github.com/josephawallace/ninetyfive/internal/risk"
+	"github.com/josephawallace/ninetyfive/internal/volatility"
+)
+
+// Status is a point-in-time view of the trading loop for operators
+type Status struct {
+	Labels         common.Labels              `json:"labels"`
+	StartedAt      time.Time                  `json:"started_at"`
+	Uptime         string                     `json:"uptime"`
+	Paused         bool                       `json:"paused"`
+	LastTick       time.Time                  `json:"last_tick"`
+	LastPrice      float64                    `json:"last_price"`
+	LastQuotePrice float64                    `json:"last_quote_price"`
+	QuoteUsd       float64                    `json:"quote_usd"` // the quote asset's USD price behind the tick
+	LastSignal     common.Signal              `json:"last_signal"`
+	Votes          []ensemble.Vote            `json:"votes"`
+	Metrics        volatility.Metrics         `json:"metrics"`
+	Strategies     []ensemble.MemberSnapshot  `json:"strategies"`
+	Position       position.Position          `json:"position"`
+	Rsi            float64                    `json:"rsi"`
+	SolBalance     float64                    `json:"sol_balance"`
+	Maintenance    *configs.MaintenanceWindow `json:"maintenance,omitempty"`
+	Congestion     []string                   `json:"congestion,omitempty"` // why the network is taken as congested
+	Outage         *Outage                    `json:"outage,omitempty"`
+	StalePrices    int                        `json:"stale_prices,omitempty"` // ticks skipped on a stale price
+	Triggers       []Trigger                  `json:"triggers,omitempty"`
+	Delegations    []jupiter.Delegation       `json:"delegations,omitempty"`
+	MintHalt       string                     `json:"mint_halt,omitempty"` // why a mint change halts trading
+	ProfitLock     *ledger.ProfitLock         `json:"profit_lock,omitempty"`
+	BreakerUntil   *time.Time                 `json:"breaker_until,omitempty"`
+	Wallet         *WalletValue               `json:"wallet,omitempty"`
+	AbTest         *abtest.Report             `json:"ab_test,omitempty"`
+	CapitalBudget  float64                    `json:"capital_budget,omitempty"`
+	Benchmark      *Benchmark                 `json:"benchmark,omitempty"`
+	Equity         *equity.Stats              `json:"equity,omitempty"`
+	Reporting      *Reporting                 `json:"reporting,omitempty"`      // in the reporting currency
+	PlatformFees   map[string]float64         `json:"platform_fees,omitempty"`  // collected, keyed by mint
+	ExternalFlows  map[string]float64         `json:"external_flows,omitempty"` // net, keyed by mint
+	Dust           []Dust                     `json:"dust,omitempty"`
+	PriceSources   []pricing.SourceStats      `json:"price_sources"`
+	Dependencies   []latency.Stats            `json:"dependencies"`
+	InFlight       []string                   `json:"in_flight"`
+	Orders         []orders.Order             `json:"orders"`
+	Interval       string                     `json:"interval"`
+	BuySize        float64                    `json:"buy_size"`
+	SellSize       float64                    `json:"sell_size"`
+}
+
+// Trader owns the main loop that feeds prices into the Grid Manager and executes the resulting signals
+type Trader struct {
+	cfg   *configs.Config
+	j     *jupiter.Jupiter
+	ps    *pricing.Composite
+	pair  common.Pair
+	e     *ensemble.Ensemble
+	l     *ledger.Ledger
+	om    *orders.Manager
+	algo  execution.Algo
+	fc    filters.Chain
+	guard *risk.Guard
+	watch *anomaly.Detector
+	hooks *hooks.Dispatcher
+	ab    *abtest.Experiment
+	alloc *allocator.Allocator
+	curve *equity.Curve
+	fx    *fx.Rates
+	n     *notifier.Aggregator
+	pub   broadcast.Publisher
+	log   logger.Logger
+
+	// Price conditioning, bar aggregation, intrabar evaluation, regime tracking, and the candle cache are only
+	// touched by the loop
+	precision          *pricing.Precision
+	profitLockArmed    bool
+	bars               barBuilder
+	intrabar           intrabarState
+	regime             *volatility.Regime
+	candles            *candles.Cache
+	breaker            *risk.CircuitBreaker
+	balancesChecked    time.Time
+	drawdownTripped    bool
+	reportedAt         time.Time // when the last report was sent, the start of the next one's period
+	congestionChecked  time.Time
+	mintsChecked       time.Time
+	mintReasons        map[string]string // why each mint halts trading as last read, empty when it does not
+	delegationsChecked time.Time
+	fired              chan firedTrigger
+
+	mu             sync.RWMutex
+	startedAt      time.Time
+	paused         bool
+	lastTick       time.Time
+	lastPrice      float64
+	quoteUsd       float64 // the quote asset's USD price, 1 unless the pair is priced in the quote asset
+	lastSignal     common.Signal
+	lastVotes      []ensemble.Vote
+	strategies     []ensemble.MemberSnapshot
+	lastRsi        float64
+	metrics        volatility.Metrics
+	lastQuotePrice float64
+	solBalance     float64
+	maintenance    *configs.MaintenanceWindow
+	congestion     []string // why the network is taken as congested, empty when it is not
+	priceFailures  int      // in a row
+	stalePrices    int      // since start
+	mintHalt       string   // why a mint change halts trading, empty when it does not
+	outage         *Outage
+	triggers       []Trigger // armed, consumed by the watcher as they are touched
+	delegations    []jupiter.Delegation
+	dust           []Dust
+	sweeps         map[string]bool // dust conversions sent but not recorded yet
+	breakerUntil   *time.Time
+	wallet         *WalletValue
+	buySize        float64
+	sellSize       float64
+	interval       time.Duration
+	vol            *volatility.Window
+}
+
+// NewTrader creates a new trading loop around the configured Jupiter client and ensemble of Grid Managers - signals
+// that pass the filters are also handed to pub
+func NewTrader(cfg *configs.Config, j *jupiter.Jupiter, e *ensemble.Ensemble, l *ledger.Ledger,
+	n *notifier.Aggregator, pub broadcast.Publisher, log logger.Logger) (*Trader, error) {
+	pair, err := common.NewPairFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	algo, err := execution.NewAlgo(cfg, pair.String(), j)
+	if err != nil {
+		return nil, err
+	}
+	fc, err := filters.NewChain(cfg, pair)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := pricing.NewCompositeFromConfig(cfg, pricing.Timed(j.PriceSample))
+	if err != nil {
+		return nil, err
+	}
+	watch := anomaly.NewDetector(cfg, n, log)
+	ab, err := abtest.NewExperiment(cfg, pair, log)
+	if err != nil {
+		return nil, err
+	}
+	alloc, err := allocator.NewAllocator(cfg, pair, l, log)
+	if err != nil {
+		return nil, err
+	}
+	var curve *equity.Curve
+	if cfg.EquityFile != "" {
+		if curve, err = equity.NewCurve(cfg.EquityFile, cfg.EquityStatsWindow, cfg.EquityRetention); err != nil {
+			return nil, fmt.Errorf("could not open the equity curve: %w", err)
+		}
+	}
+
+	dispatcher := hooks.NewDispatcher(cfg, log)
+
+	// Paper swaps go through the order pipeline with synthetic confirmations when asked to
+	var swapper orders.Swapper = j
+	if cfg.PaperConfirmations {
+		swapper = jupiter.NewPaper(j, cfg)
+	}
+
+	return &Trader{
+		cfg:        cfg,
+		j:          j,
+		ps:         ps,
+		pair:       pair,
+		e:          e,
+		l:          l,
+		hooks:      dispatcher,
+		om:         orders.NewManager(swapper, l, n, log, orders.NewPolicy(cfg), watch, dispatcher),
+		algo:       algo,
+		fc:         fc,
+		guard:      risk.NewGuard(cfg),
+		watch:      watch,
+		ab:         ab,
+		alloc:      alloc,
+		curve:      curve,
+		fx:         fx.NewRates(cfg),
+		n:          n,
+		pub:        pub,
+		log:        log,
+		startedAt:  time.Now(),
+		reportedAt: time.Now(),
+		lastSignal: common.DoNothingSignal,
+		buySize:    cfg.BuyOrderSize.Float(),
+		sellSize:   cfg.SellOrderSize.Float(),
+		interval:   cfg.Interval,
+		quoteUsd:   1,
+		precision:  pricing.NewPrecisionFromConfig(cfg, pair.String()),
+		bars:       barBuilder{interval: cfg.BarInterval},
+		regime:     volatility.NewRegime(cfg.RegimeWindow, cfg.RegimeCalmRsiStd, cfg.RegimeVolatileRsiStd),
+		breaker: risk.NewCircuitBreaker(cfg.CircuitBreakerWindow, cfg.CircuitBreakerMaxVol, cfg.CircuitBreakerMaxMoveBps,
+			cfg.CircuitBreakerCooldown),
+		vol:    volatility.NewWindow(cfg.VolatilityWindow),
+		sweeps: make(map[string]bool),
+		fired:  make(chan firedTrigger, 2),
+	}, nil
+}
+
+// Run enters the main loop for feeding price data into the Grid Manager until the context is cancelled - with price
+// triggers on, the triggers touched between ticks are acted on as they fire
+func (t *Trader) Run(ctx context.Context) {
+	if t.cfg.PriceTriggers {
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go t.watchTriggers(watchCtx)
+	}
+	next := time.After(t.currentInterval())
+	for {
+		// Sleep at the top of the loop to allow a log and a `continue` statement for errors while maintaining the
+		// configured data interval
+		select {
+		case <-ctx.Done():
+			return
+		case f := <-t.fired:
+			if err := t.fireTrigger(ctx, f); err != nil {
+				t.log.Error().Err(err).Msg("failed to act on price trigger %s", f.Trigger)
+			}
+			continue
+		case <-next:
+		}
+
+		if err := t.Tick(ctx); err != nil {
+			t.log.Error().Err(err).Msg("failed to complete interval")
+		}
+		t.mu.RLock()
+		price := t.lastPrice
+		t.mu.RUnlock()
+		if price > 0 {
+			t.armTriggers(time.Now(), price)
+		}
+		next = time.After(t.currentInterval())
+	}
+}
+
+// Tick performs a single iteration of the loop: fetch the price, process it, and act on the signal
+func (t *Trader) Tick(ctx context.Context) error {
+	// Quote both sides while the price is fetched when the tick will reach a decision
+	prefetched := t.prefetchQuotes(ctx, time.Now())
+	for _, u := range t.j.TakeUntaggedSwaps() {
+		t.log.Warn().Err(u.Err).Msg("swap for order %s was sent without its memo", u.Tag)
+	}
+
+	// Retrieve the price for the traded asset, to be used as the next data point in our grid strategy - a fetch that
+	// outlives the interval is abandoned rather than delaying the next iteration
+	priceCtx, cancel := context.WithTimeout(ctx, t.currentInterval())
+	sample, err := t.ps.PairPrice(priceCtx, t.pair.Base, t.pair.Quote)
+	cancel()
+	now := time.Now()
+	if err != nil {
+		t.alert(ctx, notifier.KeyPriceFetch, notifier.SeverityWarning, "failed to get base currency price", err)
+		t.priceFailed(ctx, now)
+		return err
+	}
+	t.clear(ctx, notifier.KeyPriceFetch)
+	// An old price fed to the strategies would move the RSI on data the market has moved past
+	if age := now.Sub(sample.Time); t.cfg.PriceMaxStaleness > 0 && age > t.cfg.PriceMaxStaleness {
+		t.countStalePrice()
+		err = fmt.Errorf("base currency price is %s old, over the %s limit - skipping signal processing",
+			age.Round(time.Second), t.cfg.PriceMaxStaleness)
+		t.alert(ctx, notifier.KeyPriceStale, notifier.SeverityWarning, "stale base currency price", err)
+		t.priceFailed(ctx, now)
+		return err
+	}
+	t.clear(ctx, notifier.KeyPriceStale)
+	price := sample.Price
+	t.priceRecovered(ctx)
+	if t.precision != nil {
+		price = t.precision.Apply(price)
+	}
+	if t.cfg.PairPricing == pricing.PricingQuote {
+		t.log.Info().Msg("base currency price - %f quote ($%f per quote)", price, sample.QuoteUsd)
+	} else {
+		t.log.Info().Msg("base currency price - $%f", price)
+	}
+
+	// Suspend execution while the price is moving too violently to fill well, resuming once the cooldown passes
+	t.mu.RLock()
+	wasOpen := t.breakerUntil != nil
+	t.mu.RUnlock()
+	if err = t.breaker.Observe(now, price); err != nil {
+		t.log.Warn().Msg("circuit breaker tripped, suspending execution until %s: %s",
+			t.breaker.Until().Format(time.RFC3339), err)
+		t.alert(ctx, notifier.KeyCircuitBreaker, notifier.SeverityWarning, "execution suspended by the circuit breaker", err)
+	} else if wasOpen && !t.breaker.Open(now) {
+		t.log.Info().Msg("circuit breaker cooldown over - execution resumed")
+		t.clear(ctx, notifier.KeyCircuitBreaker)
+	}
+
+	t.recordTick(now, price, sample.QuoteUsd)
+
+	// Stop giving back gains once a floor has been locked in under them
+	t.checkProfitLock(ctx, price)
+
+	// Measure the bot against simply holding the asset from its first tick
+	t.startBenchmark(ctx, price)
+	t.refreshRate(ctx)
+
+	// Make sure the wallet only changes with the bot's own trades - the activity it did not initiate is reconciled by
+	// the scheduler
+	t.checkBalances(ctx, now)
+	t.checkCongestion(ctx, now)
+	t.checkMints(ctx, now)
+	t.checkDelegations(ctx, now)
+
+	// Follow the watched wallet's value whatever the strategy does
+	if t.observing() {
+		if err = t.markToMarket(ctx, price); err != nil {
+			t.log.Error().Err(err).Msg("failed to mark the watched wallet to market")
+		}
+	}
+
+	// Every tick is a bar of its own unless ticks are aggregated into longer bars
+	<-prefetched
+	if t.cfg.BarInterval <= 0 {
+		return t.closeBar(ctx, common.PriceBar(now, price), price)
+	}
+	if completed, ok := t.bars.add(now, price); ok {
+		if err = t.closeBar(ctx, completed, price); err != nil {
+			return err
+		}
+	}
+	if t.cfg.SignalEvaluation == "intrabar" {
+		return t.evaluateIntrabar(ctx, now, price)
+	}
+	return nil
+}
+
+// Settle waits until every order the loop placed has settled and its lifecycle events were posted, or the context is
+// done - a one-shot tick calls it so its fills are in the ledger before the process exits
+func (t *Trader) Settle(ctx context.Context) error {
+	for len(t.om.Open()) > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("orders still open: %w", ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+	return t.hooks.Flush(ctx)
+}
+
+// closeBar commits a complete bar to the strategies and acts on their signal, unless an intrabar signal of the same
+// side was already acted on during the bar
+func (t *Trader) closeBar(ctx context.Context, bar common.Bar, price float64) error {
+	// Receive the strategies' combined signal to dictate the bot's action
+	decision, err := t.commitBar(bar)
+	if err != nil {
+		t.alert(ctx, notifier.KeyProcess, notifier.SeverityWarning, "failed to process interval", err)
+		return err
+	}
+	t.clear(ctx, notifier.KeyProcess)
+	t.auditStrategies(ctx, bar)
+	t.auditSignal(bar, decision)
+	signal := decision.Signal
+	t.log.Info().Msg("%s signal received with %.0f%% of the votes", signal, decision.Strength*100)
+	if err = t.ab.Observe(bar, decision); err != nil {
+		t.log.Error().Err(err).Msg("failed to process the A/B challenger")
+	}
+
+	t.recordEquity(ctx, bar)
+
+	if t.candles != nil {
+		if err = t.candles.Append(t.pair, candles.TimeframeFromConfig(t.cfg), bar); err != nil {
+			t.log.Error().Err(err).Msg("failed to cache bar")
+		}
+	}
+
+	traded := t.intrabar.traded
+	t.intrabar = intrabarState{}
+	if traded != "" {
+		if signal == traded {
+			t.log.Info().Msg("%s signal confirmed at bar close - already acted on intrabar", signal)
+			return nil
+		}
+		t.log.Warn().Msg("intrabar %s signal was not confirmed at bar close, which signalled %s", traded, signal)
+	}
+	return t.act(ctx, decision, price, bar.Time)
+}
+
+// commitBar feeds a complete bar to the strategies, the regime tracker, and the filters
+func (t *Trader) commitBar(bar common.Bar) (ensemble.Decision, error) {
+	if t.cfg.InventorySkew > 0 {
+		t.e.SetSkew(t.inventorySkew())
+	}
+	decision, err := t.e.Process(bar)
+	if err != nil {
+		return decision, err
+	}
+
+	rsi := t.e.Rsi()
+	metrics := t.regime.Add(bar, rsi)
+	t.log.Info().Msg("%s regime - realized vol %.4f, ATR %f, RSI std %.2f", metrics.Regime, metrics.RealizedVol,
+		metrics.Atr, metrics.RsiStd)
+
+	t.recordBar(bar, decision, rsi, metrics)
+	return decision, nil
+}
+
+// recordBar keeps what the strategies and the regime tracker made of a complete bar, and shows it to the filters
+func (t *Trader) recordBar(bar common.Bar, decision ensemble.Decision, rsi float64, metrics volatility.Metrics) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSignal = decision.Signal
+	t.lastVotes = decision.Votes
+	t.lastRsi = rsi
+	t.metrics = metrics
+	t.strategies = t.e.Snapshot()
+	t.fc.Observe(filters.Bar{Price: bar.Close, Rsi: rsi, Metrics: metrics})
+}
+
+// recordTick keeps the tick's price, adapts the interval to it, and notes whether the circuit breaker is open
+func (t *Trader) recordTick(now time.Time, price float64, quoteUsd float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastTick = now
+	t.lastPrice = price
+	t.quoteUsd = quoteUsd
+	t.adaptInterval(price)
+	t.breakerUntil = nil
+	if t.breaker.Open(now) {
+		until := t.breaker.Until()
+		t.breakerUntil = &until
+	}
+}
+
+// countStalePrice counts a price too old to be fed to the strategies
+func (t *Trader) countStalePrice() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stalePrices++
+}
+
+// inventorySkew returns the RSI points to shift the thresholds by - scaled linearly from 0 at the target inventory
+// to the full skew at none or twice the target
+func (t *Trader) inventorySkew() float64 {
+	target := t.cfg.InventoryTarget.Float()
+	deviation := (t.Position().Base - target) / target
+	return max(-1, min(1, deviation)) * t.cfg.InventorySkew
+}
+
+// intrabarState tracks the signal of the forming bar across ticks - it is only touched by the loop
+type intrabarState struct {
+	side   common.Signal // the signal of the latest tick
+	ticks  int           // consecutive ticks side has persisted for
+	traded common.Signal // the signal acted on during the bar, if any
+}
+
+// evaluateIntrabar evaluates the forming bar on copies of the strategies and acts on a signal once it has persisted
+// for the configured number of ticks - at most once per side per bar
+func (t *Trader) evaluateIntrabar(ctx context.Context, now time.Time, price float64) error {
+	decision, _, err := t.e.Simulate(t.bars.bar)
+	if err != nil {
+		return err
+	}
+	if decision.Signal != t.intrabar.side {
+		t.intrabar.side = decision.Signal
+		t.intrabar.ticks = 0
+	}
+	t.intrabar.ticks++
+
+	signal := decision.Signal
+	if signal == common.DoNothingSignal || signal == t.intrabar.traded || t.intrabar.ticks < t.cfg.IntrabarConfirmTicks {
+		return nil
+	}
+	t.intrabar.traded = signal
+	t.log.Info().Msg("intrabar %s signal persisted for %d tick(s) with %.0f%% of the votes", signal,
+		t.intrabar.ticks, decision.Strength*100)
+	return t.act(ctx, decision, price, now)
+}
+
+// act takes the strategies' decision through the pause, maintenance, balance, filter, and risk checks and executes it
+func (t *Trader) act(ctx context.Context, decision ensemble.Decision, price float64, signalTime time.Time) error {
+	signal := decision.Signal
+	t.mu.RLock()
+	paused, maintenance, buySize, sellSize := t.paused, t.maintenance, t.buySize, t.sellSize
+	t.mu.RUnlock()
+
+	// Keep the indicators warm while paused or in maintenance, but never trade
+	if paused {
+		t.log.Info().Msg("trading is paused - ignoring %s signal", signal)
+		return nil
+	}
+	if maintenance != nil {
+		t.log.Info().Msg("in maintenance (%s) until %s - ignoring %s signal", maintenance.Reason,
+			maintenance.End.Format(time.RFC3339), signal)
+		return nil
+	}
+	if t.breaker.Open(time.Now()) {
+		t.log.Info().Msg("circuit breaker open until %s - ignoring %s signal",
+			t.breaker.Until().Format(time.RFC3339), signal)
+		return nil
+	}
+	if halt := t.mintHalted(); halt != "" {
+		t.log.Warn().Msg("trading halted by a mint change (%s) - ignoring %s signal", halt, signal)
+		return nil
+	}
+	if o := t.recovering(); o != nil {
+		t.log.Info().Msg("recovering from a price outage (%s) - ignoring %s signal", o, signal)
+		return nil
+	}
+
+	// Stop trading before fees can no longer be paid, rather than failing transactions with opaque errors - there are
+	// no fees to pay when only publishing signals or observing
+	if t.cfg.NeedsSecretKey() && !t.checkSolBalance(ctx) {
+		t.log.Warn().Msg("SOL balance below the minimum - ignoring %s signal", signal)
+		return nil
+	}
+
+	// Swap the configured amount of the assets, scaled by how many strategies agree and by the grid level signalled
+	// at - since this is an LP and not an orderbook, there aren't technically buy/sell orders, but instead only swaps -
+	// the pair decides which mint is spent for each side
+	size := t.orderSize(decision, t.gridLevel(), buySize, sellSize)
+	if size == 0 {
+		t.log.Info().Msg("no action taken this interval")
+		return nil
+	}
+
+	// Require the optional entry filters to confirm the signal
+	if err := t.fc.Check(ctx, signal); err != nil {
+		t.veto(ctx, stageEntryFilter, "", signal, price, signalTime, err)
+		return nil
+	}
+
+	// Hand the signal to external consumers before acting on it, so they are not held up by execution
+	t.publish(ctx, broadcast.Event{
+		Pair:     t.pair.String(),
+		Base:     t.pair.Base,
+		Quote:    t.pair.Quote,
+		Side:     signal,
+		Price:    price,
+		Rsi:      t.e.Rsi(),
+		Size:     size,
+		Strength: decision.Strength,
+		Time:     signalTime,
+		Traded:   !t.cfg.SignalOnly,
+	})
+	if t.cfg.SignalOnly {
+		t.log.Info().Msg("%s signal published - not trading in signal-only mode", signal)
+		return nil
+	}
+
+	// Classify the order against the inventory the bot has built so far, and in spot-only mode refuse to sell
+	// more than is held once the SELLs already being worked are accounted for
+	pos := t.Position()
+	if err := t.guard.CheckInventory(signal, size, pos.Base-t.om.Pending(common.SellSignal)); err != nil {
+		t.veto(ctx, stageRisk, "inventory", signal, price, signalTime, err)
+		return nil
+	}
+
+	// Keep the strategy within its share of the wallet when several trade it
+	size, err := t.alloc.Size(signal, size, pos.Quote+t.om.Pending(common.BuySignal), time.Now())
+	if err != nil {
+		t.veto(ctx, stageRisk, "capital_budget", signal, price, signalTime, err)
+		return nil
+	}
+	// Funded by delegation, the order cannot spend more than the funding wallet still allows
+	if spendable, ok := t.delegatedSpendable(signal); ok && size > spendable {
+		t.veto(ctx, stageRisk, "delegation", signal, price, signalTime,
+			fmt.Errorf("order of %f exceeds the %f left of the delegated allowance", size, spendable))
+		return nil
+	}
+	req := orders.Request{
+		Side:       signal,
+		Pair:       t.pair,
+		Size:       size,
+		Effect:     pos.Classify(signal, size, price),
+		Price:      price,
+		SignalTime: signalTime,
+	}
+
+	// Hold back new exposure while the network is too congested to land swaps reliably, still letting the position
+	// be reduced
+	if req.Effect != position.EffectClose && t.pausingEntries() {
+		t.veto(ctx, stageRisk, "congestion", signal, price, signalTime,
+			fmt.Errorf("entries are paused while the network is congested"))
+		return nil
+	}
+
+	// Quote the swap before committing so a signal can be vetoed when the achievable price has already moved past
+	// the level that generated it
+	q, err := t.quote(ctx, req)
+	if errors.Is(err, jupiter.ErrDustOrder) {
+		t.veto(ctx, stageRisk, "dust", signal, price, signalTime, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Shrink an order too large for the pool's liquidity rather than trading through the impact or skipping it
+	if req, q, err = t.fitImpact(ctx, req, q, pos); err != nil {
+		t.veto(ctx, stageRisk, "price_impact", signal, price, signalTime, err)
+		return nil
+	}
+	quotePrice := q.Price
+	t.mu.Lock()
+	t.lastQuotePrice = quotePrice
+	t.mu.Unlock()
+	if err = t.guard.CheckQuote(signal, price, quotePrice); err != nil {
+		t.veto(ctx, stageRisk, "quote", signal, price, signalTime, err)
+		return nil
+	}
+
+	// Journal what the strategy knew alongside the fills
+	if req.Snapshot, err = t.snapshot(signalTime, price, quotePrice); err != nil {
+		return err
+	}
+
+	// Observers record what they would have done instead of trading
+	if t.fillsAtOnce() {
+		return t.simulate(req, quotePrice)
+	}
+
+	o, err := t.om.Execute(ctx, req, t.algo)
+	if err != nil {
+		return err
+	}
+	t.log.Info().Msg("working %s order %s with %s execution in %d slice(s)", o.Effect, o.Id, o.Algo, len(o.Slices))
+	return nil
+}
+
+// checkSolBalance reports whether the wallet holds at least the configured minimum of SOL, alerting for a top-up
+// when it does not - a balance that cannot be read does not stop trading
+func (t *Trader) checkSolBalance(ctx context.Context) bool {
+	if t.cfg.MinSolBalance <= 0 {
+		return true
+	}
+	balance, err := t.j.SolBalance(ctx)
+	if err != nil {
+		t.log.Error().Err(err).Msg("failed to get SOL balance")
+		return true
+	}
+	t.mu.Lock()
+	t.solBalance = balance
+	t.mu.Unlock()
+
+	if balance < t.cfg.MinSolBalance.Float() {
+		t.alert(ctx, notifier.KeyLowSol, notifier.SeverityCritical, "SOL balance too low - top up the wallet to resume trading",
+			fmt.Errorf("balance %f SOL is below the %f SOL minimum needed for fees and rent", balance, t.cfg.MinSolBalance.Float()))
+		return false
+	}
+	t.clear(ctx, notifier.KeyLowSol)
+	return true
+}
+
+// quote returns what the order would achieve right now, including the effective price of the base currency
+func (t *Trader) quote(ctx context.Context, req orders.Request) (*jupiter.Quote, error) {
+	q, err := t.j.Quote(ctx, req.Pair, req.Side, req.Size)
+	if err != nil {
+		return nil, err
+	}
+	t.log.Info().Msg("%s quote for %f - effective price $%f, price impact %.4f%%, route %s", req.Side, req.Size,
+		q.Price, q.PriceImpactPct*100, q.RouteSummary())
+	return q, nil
+}
+
+// publish delivers a signal to the configured outlets, alerting rather than failing when delivery does not work
+func (t *Trader) publish(ctx context.Context, event broadcast.Event) {
+	if err := t.pub.Publish(ctx, event); err != nil {
+		t.alert(ctx, notifier.KeySignalPublish, notifier.SeverityWarning, "failed to publish signal", err)
+		return
+	}
+	t.clear(ctx, notifier.KeySignalPublish)
+}
+
+// alert sends a deduplicated notification, logging rather than failing when delivery does not work
+func (t *Trader) alert(ctx context.Context, key string, severity notifier.Severity, title string, err error) {
+	if nErr := t.n.Notify(ctx, notifier.Alert{Key: key, Severity: severity, Title: title, Body: err.Error()}); nErr != nil {
+		t.log.Error().Err(nErr).Msg("failed to send notification")
+	}
+}
+
+// clear resolves a previously alerted condition
+func (t *Trader) clear(ctx context.Context, key string) {
+	if err := t.n.Clear(ctx, key); err != nil {
+		t.log.Error().Err(err).Msg("failed to send notification")
+	}
+}
+
+// Report sends a summary of the period since the previous one through the low-urgency report notifier - it is run
+// by the scheduler
+func (t *Trader) Report(ctx context.Context, n notifier.Notifier) error {
+	now := time.Now()
+	if err := n.Notify(ctx, t.report(now.Sub(t.reportedAt))); err != nil {
+		return fmt.Errorf("could not send report: %w", err)
+	}
+	t.reportedAt = now
+	return nil
+}
+
+// report summarizes the loop's state for the report covering the period
+func (t *Trader) report(period time.Duration) notifier.Alert {
+	s := t.Status()
+	body := fmt.Sprintf("uptime: %s\npaused: %t\nlast tick: %s\nlast price: %f\nlast signal: %s\nin-flight transactions: %d",
+		s.Uptime, s.Paused, s.LastTick.Format(time.RFC3339), s.LastPrice, s.LastSignal, len(s.InFlight))
+	if s.Benchmark != nil {
+		body += "\nbenchmark: " + s.Benchmark.String()
+	}
+	if s.Equity != nil {
+		body += "\nequity: " + s.Equity.String()
+	}
+	if s.Reporting != nil {
+		body += "\nin " + s.Reporting.Currency + ": " + s.Reporting.String()
+	}
+	for _, mint := range slices.Sorted(maps.Keys(s.PlatformFees)) {
+		body += fmt.Sprintf("\nplatform fees collected: %f %s", s.PlatformFees[mint], mint)
+	}
+	if s.AbTest != nil {
+		body += "\nA/B test: " + s.AbTest.String()
+	}
+	// Tell which grid levels made or lost money this session
+	for _, level := range t.Attribution(t.startedAt).Levels {
+		if level.RoundTrips > 0 {
+			body += "\n" + level.String()
+		}
+	}
+	if s.Wallet != nil {
+		body += fmt.Sprintf("\nwatched wallet: %s worth $%f\nsimulated position: %f base for %f quote",
+			s.Wallet.Address, s.Wallet.ValueUsd, s.Position.Base, s.Position.Quote)
+	}
+	return notifier.Alert{
+		Severity: notifier.SeverityInfo,
+		Title:    fmt.Sprintf("summary for the last %s", period.Round(time.Minute)),
+		Body:     body,
+	}
+}
+
+// CheckMaintenance stops trading while inside one of the configured maintenance windows - entering a window flattens
+// the position first when the window asks for it, and leaving it resumes trading - it is run by the scheduler
+func (t *Trader) CheckMaintenance(ctx context.Context) error {
+	t.checkMaintenance(ctx, time.Now())
+	return nil
+}
+
+// checkMaintenance enters or leaves maintenance depending on whether now falls inside a configured window
+func (t *Trader) checkMaintenance(ctx context.Context, now time.Time) {
+	var active *configs.MaintenanceWindow
+	for i := range t.cfg.MaintenanceWindows {
+		if t.cfg.MaintenanceWindows[i].Contains(now) {
+			active = &t.cfg.MaintenanceWindows[i]
+			break
+		}
+	}
+
+	t.mu.Lock()
+	previous := t.maintenance
+	t.maintenance = active
+	t.mu.Unlock()
+	if active == previous {
+		return
+	}
+
+	if active == nil {
+		t.log.Warn().Msg("maintenance (%s) ended - trading resumed", previous.Reason)
+		t.notify(ctx, "maintenance ended", fmt.Sprintf("%s - trading resumed", previous.Reason))
+		return
+	}
+	t.log.Warn().Msg("maintenance (%s) started - trading stopped until %s", active.Reason, active.End.Format(time.RFC3339))
+	t.notify(ctx, "maintenance started", fmt.Sprintf("%s - trading stopped until %s", active.Reason,
+		active.End.Format(time.RFC3339)))
+	if active.Flatten {
+		if err := t.flatten(ctx); err != nil {
+			t.alert(ctx, notifier.KeyFlatten, notifier.SeverityCritical, "failed to flatten the position for maintenance", err)
+		}
+	}
+}
+
+// flatten works an order that brings the position back to flat at market
+func (t *Trader) flatten(ctx context.Context) error {
+	pos := t.Position()
+	side, base := pos.Flatten()
+	if side == common.DoNothingSignal {
+		return nil
+	}
+
+	// SELL sizes are in the base asset already, BUY sizes are in the quote asset so the buy-back is sized at market
+	t.mu.RLock()
+	price := t.lastPrice
+	t.mu.RUnlock()
+	if price <= 0 {
+		return fmt.Errorf("no price to size the order with")
+	}
+	size := base
+	if side == common.BuySignal {
+		size = base * price
+	}
+
+	now := time.Now()
+	snapshot, err := t.snapshot(now, price, 0)
+	if err != nil {
+		return err
+	}
+	req := orders.Request{
+		Side:       side,
+		Pair:       t.pair,
+		Size:       size,
+		Effect:     position.EffectClose,
+		Price:      price,
+		SignalTime: now,
+		Snapshot:   snapshot,
+	}
+	if t.fillsAtOnce() {
+		return t.simulate(req, price)
+	}
+	o, err := t.om.Execute(ctx, req, execution.Immediate{})
+	if err != nil {
+		return err
+	}
+	t.log.Warn().Msg("flattening %f base with %s order %s", pos.Base, side, o.Id)
+	return nil
+}
+
+// snapshot captures the Grid Manager's inputs and memory with the config in force, to be journaled with the fills of
+// the order decided on them
+func (t *Trader) snapshot(signalTime time.Time, price float64, quotePrice float64) (*ledger.Snapshot, error) {
+	strategy, err := json.Marshal(struct {
+		Rule       string                 `json:"rule"`
+		Strategies []ensemble.MemberState `json:"strategies"`
+	}{t.cfg.EnsembleRule, t.e.State()})
+	if err != nil {
+		return nil, err
+	}
+	s := &ledger.Snapshot{
+		SignalTime: signalTime,
+		Price:      price,
+		QuotePrice: quotePrice,
+		Strategy:   strategy,
+		Config:     t.cfg.Redacted(),
+	}
+	if t.cfg.PairPricing == pricing.PricingQuote {
+		t.mu.RLock()
+		s.QuoteUsd = t.quoteUsd
+		t.mu.RUnlock()
+	}
+	return s, nil
+}
+
+// notify sends a one-off informational notification
+func (t *Trader) notify(ctx context.Context, title string, body string) {
+	if err := t.n.Notify(ctx, notifier.Alert{Severity: notifier.SeverityInfo, Title: title, Body: body}); err != nil {
+		t.log.Error().Err(err).Msg("failed to send notification")
+	}
+}
+
+// Pause stops the loop from executing swaps while continuing to process prices
+func (t *Trader) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = true
+	t.log.Warn().Msg("trading paused")
+}
+
+// Resume allows the loop to execute swaps again
+func (t *Trader) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = false
+	t.log.Warn().Msg("trading resumed")
+}
+
+// SetOrderSizes changes the fixed swap amounts used for BUY and SELL signals at runtime
+func (t *Trader) SetOrderSizes(buySize, sellSize float64) error {
+	if buySize <= 0 || sellSize <= 0 {
+		return fmt.Errorf("order sizes must be positive")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buySize = buySize
+	t.sellSize = sellSize
+	t.log.Warn().Msg("order sizes changed to buy=%f sell=%f", buySize, sellSize)
+	return nil
+}
+
+// Status returns a snapshot of the loop's state
+func (t *Trader) Status() Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	s := Status{
+		Labels:         common.NewLabels(t.cfg),
+		StartedAt:      t.startedAt,
+		Uptime:         time.Since(t.startedAt).Round(time.Second).String(),
+		Paused:         t.paused,
+		LastTick:       t.lastTick,
+		LastPrice:      t.lastPrice,
+		LastQuotePrice: t.lastQuotePrice,
+		QuoteUsd:       t.quoteUsd,
+		LastSignal:     t.lastSignal,
+		Votes:          t.lastVotes,
+		Metrics:        t.metrics,
+		Strategies:     t.strategies,
+		Position:       t.Position(),
+		Rsi:            t.lastRsi,
+		SolBalance:     t.solBalance,
+		Maintenance:    t.maintenance,
+		Congestion:     t.congestion,
+		MintHalt:       t.mintHalt,
+		Triggers:       slices.Clone(t.triggers),
+		StalePrices:    t.stalePrices,
+		Delegations:    t.delegations,
+		ProfitLock:     t.l.ProfitLock(),
+		BreakerUntil:   t.breakerUntil,
+		Wallet:         t.wallet,
+		AbTest:         t.ab.Report(),
+		CapitalBudget:  t.alloc.Budget(time.Now()),
+		Benchmark:      t.benchmark(t.lastPrice),
+		Equity:         t.curve.Stats(),
+		PlatformFees:   t.l.PlatformFees(),
+		ExternalFlows:  t.l.ExternalFlows(),
+		Dust:           t.dust,
+		PriceSources:   t.ps.Stats(),
+		Dependencies:   t.j.Latency(),
+		InFlight:       t.om.InFlight(),
+		Orders:         t.om.Open(),
+		Interval:       t.interval.String(),
+		BuySize:        t.buySize,
+		SellSize:       t.sellSize,
+	}
+	if t.outage != nil {
+		o := *t.outage
+		s.Outage = &o
+	}
+	s.Reporting = t.reporting(s)
+	return s
+}
+
+// Strategies returns every strategy's indicator, grid, and filter states as of the last tick - the ensemble itself is
+// only touched by the loop, so this is a copy taken on the tick
+func (t *Trader) Strategies() []ensemble.MemberSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.strategies
+}
+
+// Simulation is what the strategies would make of a hypothetical price on the next bar
+type Simulation struct {
+	Price    float64                   `json:"price"`
+	Decision ensemble.Decision         `json:"decision"`
+	Size     float64                   `json:"size"` // the order size the decision would be sized to, before filters
+	Before   []ensemble.MemberSnapshot `json:"before"`
+	After    []ensemble.MemberSnapshot `json:"after"`
+}
+
+// Simulate evaluates a hypothetical price on copies of the strategies, leaving the live ones untouched - the entry
+// filters, risk checks, and quoting are not applied
+func (t *Trader) Simulate(price float64) (Simulation, error) {
+	if price <= 0 {
+		return Simulation{}, fmt.Errorf("price must be positive")
+	}
+	decision, after, err := t.e.Simulate(common.PriceBar(time.Now(), price))
+	if err != nil {
+		return Simulation{}, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	level := 0
+	if len(after) > 0 {
+		level = after[0].Snapshot.LastSignalIndex
+	}
+	return Simulation{
+		Price:    price,
+		Decision: decision,
+		Size:     t.orderSize(decision, level, t.buySize, t.sellSize),
+		Before:   t.strategies,
+		After:    after,
+	}, nil
+}
+
+// Pair returns the pair the loop trades
+func (t *Trader) Pair() common.Pair {
+	return t.pair
+}
+
+// Position returns the bot's inventory in the traded pair as recorded by its fills
+func (t *Trader) Position() position.Position {
+	return position.FromTrades(t.pair, t.l.Trades())
+}
+
+// Trades returns the fills in the pair between from and to, oldest first - a zero bound leaves that side open
+func (t *Trader) Trades(from time.Time, to time.Time) []ledger.Trade {
+	var trades []ledger.Trade
+	mints := []string{t.pair.Base, t.pair.Quote}
+	for _, tr := range t.l.Trades() {
+		if slices.Contains(mints, tr.InputMint) && slices.Contains(mints, tr.OutputMint) && within(tr.Time, from, to) {
+			trades = append(trades, tr)
+		}
+	}
+	return trades
+}
+
+// Signals returns the audited signals in the pair between from and to, oldest first - a zero bound leaves that side
+// open
+func (t *Trader) Signals(from time.Time, to time.Time) []ledger.SignalRecord {
+	var signals []ledger.SignalRecord
+	for _, s := range t.l.Signals() {
+		if s.Pair == t.pair.String() && within(s.Time, from, to) {
+			signals = append(signals, s)
+		}
+	}
+	return signals
+}
+
+// within tells whether the time falls between the bounds, a zero bound being open
+func within(at time.Time, from time.Time, to time.Time) bool {
+	return (from.IsZero() || !at.Before(from)) && (to.IsZero() || !at.After(to))
+}
+
+// currentInterval returns the delay before the next tick
+func (t *Trader) currentInterval() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.interval
+}
+
+// adaptInterval halves the polling interval while realized volatility is above the threshold and doubles it once
+// volatility falls below half the threshold, staying within the configured bounds - callers must hold the lock
+func (t *Trader) adaptInterval(price float64) {
+	t.vol.Add(price)
+	minInterval := t.cfg.IntervalMin
+	maxInterval := t.cfg.IntervalMax
+	if minInterval <= 0 || maxInterval < minInterval || t.cfg.VolatilityThreshold <= 0 || !t.vol.Full() {
+		return
+	}
+
+	next := t.interval
+	vol := t.vol.RealizedVol()
+	switch {
+	case vol > t.cfg.VolatilityThreshold:
+		next = t.interval / 2
+	case vol < t.cfg.VolatilityThreshold/2:
+		next = t.interval * 2
+	}
+	next = max(minInterval, min(maxInterval, next))
+
+	if next != t.interval {
+		t.log.Info().Msg("realized volatility %.5f - polling interval changed from %s to %s", vol, t.interval, next)
+		t.interval = next
+	}
+}
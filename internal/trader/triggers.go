@@ -0,0 +1,193 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// triggerSamples is how many prices across the trigger range are evaluated when arming, before the crossing found
+// nearest the price on each side is narrowed down
+const triggerSamples = 40
+
+// Trigger is a resting grid intent - the price at which the strategies would signal the side on the forming bar,
+// armed so the signal executes the moment the price touches it instead of on the next tick
+type Trigger struct {
+	Side    common.Signal `json:"side"`
+	Price   float64       `json:"price"`
+	Above   bool          `json:"above"` // fires at or above the price, otherwise at or below it
+	ArmedAt time.Time     `json:"armed_at"`
+}
+
+// String describes the trigger, e.g. "BUY at or below 142.1250"
+func (tr Trigger) String() string {
+	dir := "below"
+	if tr.Above {
+		dir = "above"
+	}
+	return fmt.Sprintf("%s at or %s %.4f", tr.Side, dir, tr.Price)
+}
+
+// hit reports whether the price touches the trigger
+func (tr Trigger) hit(price float64) bool {
+	if tr.Above {
+		return price >= tr.Price
+	}
+	return price <= tr.Price
+}
+
+// firedTrigger is a trigger the watcher saw touched, handed to the loop to act on
+type firedTrigger struct {
+	Trigger
+	price float64
+	at    time.Time
+}
+
+// armTriggers replaces the armed triggers with the resting ones at the price - it runs on the loop after every tick
+func (t *Trader) armTriggers(now time.Time, price float64) {
+	if !t.cfg.PriceTriggers {
+		return
+	}
+	armed := t.restingTriggers(now, price)
+	t.mu.Lock()
+	t.triggers = armed
+	t.mu.Unlock()
+	for _, tr := range armed {
+		t.log.Info().Msg("armed price trigger %s", tr)
+	}
+}
+
+// restingTriggers finds the nearest price below and above the price at which the forming bar would signal, within
+// the configured range - none rest while the price itself signals, as the next tick acts on it
+func (t *Trader) restingTriggers(now time.Time, price float64) []Trigger {
+	if side, err := t.signalAt(now, price); err != nil || side != common.DoNothingSignal {
+		return nil
+	}
+	var resting []Trigger
+	span := price * t.cfg.PriceTriggerRangePct / 100
+	for _, above := range []bool{false, true} {
+		prev := price
+		for i := 1; i <= triggerSamples; i++ {
+			p := price - span*float64(i)/triggerSamples
+			if above {
+				p = price + span*float64(i)/triggerSamples
+			}
+			side, err := t.signalAt(now, p)
+			if err != nil {
+				t.log.Error().Err(err).Msg("failed to evaluate a trigger price")
+				break
+			}
+			if side == common.DoNothingSignal {
+				prev = p
+				continue
+			}
+			resting = append(resting, Trigger{Side: side, Price: t.narrowTrigger(now, prev, p, side), Above: above,
+				ArmedAt: now})
+			break
+		}
+	}
+	return resting
+}
+
+// narrowTrigger bisects between a price that does not signal and one that signals the side, returning the price
+// nearest the former that still signals
+func (t *Trader) narrowTrigger(now time.Time, quiet float64, signalled float64, side common.Signal) float64 {
+	for i := 0; i < 20; i++ {
+		mid := (quiet + signalled) / 2
+		if s, err := t.signalAt(now, mid); err == nil && s == side {
+			signalled = mid
+		} else {
+			quiet = mid
+		}
+	}
+	return signalled
+}
+
+// signalAt returns what the strategies would signal if the price moved to price on the forming bar
+func (t *Trader) signalAt(now time.Time, price float64) (common.Signal, error) {
+	decision, _, err := t.e.Simulate(t.formingBar(now, price))
+	return decision.Signal, err
+}
+
+// formingBar returns the forming bar as it would be with the price folded in, or a bar of its own when ticks are not
+// aggregated
+func (t *Trader) formingBar(now time.Time, price float64) common.Bar {
+	if t.cfg.BarInterval <= 0 || !t.bars.forming {
+		return common.PriceBar(now, price)
+	}
+	bar := t.bars.bar
+	bar.High = max(bar.High, price)
+	bar.Low = min(bar.Low, price)
+	bar.Close = price
+	return bar
+}
+
+// watchTriggers polls the price at the trigger poll interval while triggers are armed and hands the loop each one
+// the price touches, disarming it so it fires once - it runs alongside the loop until the context is done
+func (t *Trader) watchTriggers(ctx context.Context) {
+	tick := time.NewTicker(t.cfg.PriceTriggerPoll)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		t.mu.RLock()
+		armed := len(t.triggers) > 0
+		t.mu.RUnlock()
+		if !armed {
+			continue
+		}
+
+		pq, err := t.ps.PairPrice(ctx, t.pair.Base, t.pair.Quote)
+		price := pq.Price
+		if err != nil {
+			t.log.Debug().Err(err).Msg("price trigger poll failed")
+			continue
+		}
+		// The hysteresis of Apply belongs to the loop's price path, so polls only round
+		if t.precision != nil {
+			price = t.precision.Round(price)
+		}
+		var hits []Trigger
+		t.mu.Lock()
+		for i := 0; i < len(t.triggers); i++ {
+			if t.triggers[i].hit(price) {
+				hits = append(hits, t.triggers[i])
+				t.triggers = append(t.triggers[:i], t.triggers[i+1:]...)
+				i--
+			}
+		}
+		t.mu.Unlock()
+		for _, tr := range hits {
+			select {
+			case t.fired <- firedTrigger{Trigger: tr, price: price, at: time.Now()}:
+			default:
+			}
+		}
+	}
+}
+
+// fireTrigger acts on a touched trigger when the forming bar still signals its side at the touching price, at most
+// once per side per bar as with intrabar signals - it runs on the loop
+func (t *Trader) fireTrigger(ctx context.Context, f firedTrigger) error {
+	decision, _, err := t.e.Simulate(t.formingBar(f.at, f.price))
+	if err != nil {
+		return err
+	}
+	if decision.Signal != f.Side {
+		t.log.Info().Msg("price trigger %s touched at %.4f, but the strategies now signal %s", f.Trigger, f.price,
+			decision.Signal)
+		return nil
+	}
+	if f.Side == t.intrabar.traded {
+		return nil
+	}
+	t.intrabar.traded = f.Side
+	t.log.Info().Msg("price trigger %s touched at %.4f after %s - acting with %.0f%% of the votes", f.Trigger,
+		f.price, f.at.Sub(f.ArmedAt).Round(time.Second), decision.Strength*100)
+	return t.act(ctx, decision, f.price, f.at)
+}
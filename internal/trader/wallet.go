@@ -0,0 +1,108 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/ledger"
+	"github.com/josephawallace/ninetyfive/internal/notifier"
+)
+
+// ReconcileWallet reads the wallet's history for transactions the bot did not send - manual transfers, airdrops, or a
+// compromised key - recording what they did to the pair's balances in the ledger as external adjustments and notifying
+// about each, so the accounting can tell them from trading - it is run by the scheduler
+func (t *Trader) ReconcileWallet(ctx context.Context) error {
+	if !t.cfg.NeedsSecretKey() {
+		return nil
+	}
+
+	cursor := t.l.WalletCursor()
+	activity, newest, err := t.j.WalletActivity(ctx, cursor, []string{t.pair.Base, t.pair.Quote})
+	if err != nil {
+		return fmt.Errorf("could not read the wallet's activity: %w", err)
+	}
+	if cursor == "" && newest != "" {
+		t.log.Info().Msg("watching the wallet's activity from transaction %s", newest)
+	}
+
+	known := t.botTxIds()
+	var adjustments []ledger.Adjustment
+	var external []jupiter.Activity
+	for _, a := range activity {
+		t.mu.RLock()
+		sweeping := t.sweeps[a.TxId]
+		t.mu.RUnlock()
+		if known[a.TxId] || sweeping || t.om.Sent(a.TxId) || jupiter.IsSwapMemo(a.Memo) || len(a.Changes) == 0 {
+			continue
+		}
+		external = append(external, a)
+		for _, mint := range slices.Sorted(maps.Keys(a.Changes)) {
+			adjustments = append(adjustments, ledger.Adjustment{TxId: a.TxId, Mint: mint, Amount: a.Changes[mint],
+				Time: a.Time, Memo: a.Memo})
+		}
+	}
+	if newest == cursor {
+		return nil
+	}
+	if err = t.l.RecordAdjustments(newest, adjustments); err != nil {
+		return fmt.Errorf("could not record the wallet's external activity: %w", err)
+	}
+	for _, a := range external {
+		t.notifyActivity(ctx, a)
+	}
+	return nil
+}
+
+// botTxIds returns the ids of the swaps and adjustments recorded in the ledger and of the swaps in the ledgers of the
+// strategies sharing the wallet
+func (t *Trader) botTxIds() map[string]bool {
+	ids := make(map[string]bool)
+	ledgers := []*ledger.Ledger{t.l}
+	for strategy, path := range t.cfg.CapitalLedgers {
+		if strategy == t.cfg.StrategyId {
+			continue
+		}
+		peer, err := t.l.OpenPeer(path)
+		if err != nil {
+			t.log.Warn().Err(err).Msg("failed to open the ledger of strategy %s - its swaps will look external", strategy)
+			continue
+		}
+		ledgers = append(ledgers, peer)
+	}
+	for _, a := range t.l.Adjustments() {
+		ids[a.TxId] = true
+	}
+	for _, l := range ledgers {
+		for _, tr := range l.Trades() {
+			ids[tr.TxId] = true
+		}
+	}
+	return ids
+}
+
+// notifyActivity tells about an external transaction - funds leaving the wallet are alerted as critical, since the
+// key may be compromised, and funds arriving are only reported
+func (t *Trader) notifyActivity(ctx context.Context, a jupiter.Activity) {
+	var changes []string
+	outflow := false
+	for _, mint := range slices.Sorted(maps.Keys(a.Changes)) {
+		amount, asset := a.Changes[mint], "base"
+		if mint == t.pair.Quote {
+			asset = "quote"
+		}
+		changes = append(changes, fmt.Sprintf("%+f %s (%s)", amount, asset, mint))
+		outflow = outflow || amount < 0
+	}
+	body := fmt.Sprintf("transaction %s: %s", a.TxId, strings.Join(changes, ", "))
+	t.log.Warn().Msg("external wallet activity - %s", body)
+	if outflow {
+		t.alert(ctx, notifier.KeyWalletActivity, notifier.SeverityCritical, "funds left the wallet without a trade",
+			fmt.Errorf("%s - recorded as an external adjustment, check the wallet has not been compromised", body))
+		return
+	}
+	t.notify(ctx, "funds received by the wallet", body+" - recorded as an external adjustment")
+}
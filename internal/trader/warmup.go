@@ -0,0 +1,91 @@
+package trader
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/candles"
+)
+
+// WarmUp attaches the candle cache every complete bar is recorded in, fills it from the feed as configured, and
+// replays the most recent cached bars into the strategies without executing so they trade warm - a nil feed skips
+// the fill, and the loop must only start once WarmUp returns
+func (t *Trader) WarmUp(ctx context.Context, cache *candles.Cache, feed candles.Feed) error {
+	t.candles = cache
+	timeframe := candles.TimeframeFromConfig(t.cfg)
+
+	bars, err := cache.Load(t.pair, timeframe)
+	if err != nil {
+		return err
+	}
+	var newest time.Time
+	if len(bars) > 0 {
+		newest = bars[len(bars)-1].Time
+	}
+
+	// Fetch the configured trailing period and everything missed since the newest cached bar - only complete bars are
+	// fetched, so the bar forming now is left to the loop
+	now := time.Now()
+	if feed != nil {
+		to := now.Truncate(t.cfg.BarInterval)
+		from := to.Add(-t.cfg.CandleBackfill)
+		if t.cfg.CandleGapFill && !newest.IsZero() && newest.Before(from) {
+			from = newest
+		}
+		if from.Before(to) {
+			added, err := cache.Backfill(ctx, feed, t.pair, timeframe, t.cfg.BarInterval, from, to)
+			if err != nil {
+				t.log.Error().Err(err).Msg("failed to backfill the candle cache")
+			} else {
+				t.log.Info().Msg("backfilled %d %s bar(s) of %s since %s", added, timeframe, t.pair,
+					from.Format(time.RFC3339))
+			}
+			if bars, err = cache.Load(t.pair, timeframe); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.cfg.CandleWarmupBars <= 0 || len(bars) == 0 {
+		return nil
+	}
+
+	// A gap left unfilled would be replayed as one jump from the last bar before the outage to the first live one,
+	// signalling on a move that happened while offline - starting cold is safer
+	if gap := now.Sub(bars[len(bars)-1].Time); gap > 2*t.barLength() {
+		t.log.Warn().Msg("candle cache ends %s ago - starting cold rather than replaying across the gap",
+			gap.Round(time.Second))
+		return nil
+	}
+
+	// Replay at least every bar fetched for the outage, so no missed bar is skipped
+	start := max(len(bars)-t.cfg.CandleWarmupBars, 0)
+	if !newest.IsZero() {
+		for start > 0 && bars[start-1].Time.After(newest) {
+			start--
+		}
+	}
+	missed := 0
+	for _, bar := range bars[start:] {
+		if _, err = t.commitBar(bar); err != nil {
+			return err
+		}
+		if err = t.ab.WarmUp(bar); err != nil {
+			return err
+		}
+		if !newest.IsZero() && bar.Time.After(newest) {
+			missed++
+		}
+	}
+	t.log.Info().Msg("warmed up on %d cached %s bar(s), %d of them missed while offline - RSI %.2f",
+		len(bars)-start, timeframe, missed, t.e.Rsi())
+	return nil
+}
+
+// barLength is how long a bar lasts - a tick's bar lasts as long as the longest polling interval
+func (t *Trader) barLength() time.Duration {
+	if t.cfg.BarInterval > 0 {
+		return t.cfg.BarInterval
+	}
+	return max(t.cfg.Interval, t.cfg.IntervalMax)
+}
@@ -0,0 +1,118 @@
+package volatility
+
+import (
+	"math"
+
+	"github.com/josephawallace/ninetyfive/internal/common"
+)
+
+// Regime labels, from the spread of RSI over the window
+const (
+	RegimeWarmup   = "warmup" // not enough bars yet
+	RegimeCalm     = "calm"
+	RegimeNormal   = "normal"
+	RegimeVolatile = "volatile"
+)
+
+// Metrics describe how volatile the market has been over the most recent bars
+type Metrics struct {
+	RealizedVol float64 `json:"realized_vol"` // standard deviation of the per-bar log returns
+	Atr         float64 `json:"atr"`          // average true range, in quote per base
+	RsiStd      float64 `json:"rsi_std"`      // standard deviation of RSI
+	Regime      string  `json:"regime"`
+}
+
+// Regime tracks rolling volatility metrics bar by bar and labels the regime by how widely RSI has been swinging -
+// calm below calmRsiStd, volatile above volatileRsiStd
+type Regime struct {
+	window         int
+	calmRsiStd     float64
+	volatileRsiStd float64
+
+	closes    *Window
+	rsis      []float64
+	atr       float64
+	prevClose float64
+	bars      int
+	metrics   Metrics
+}
+
+// NewRegime creates a tracker over window bars, which is also the ATR length
+func NewRegime(window int, calmRsiStd float64, volatileRsiStd float64) *Regime {
+	if window < 2 {
+		window = 2
+	}
+	return &Regime{
+		window:         window,
+		calmRsiStd:     calmRsiStd,
+		volatileRsiStd: volatileRsiStd,
+		closes:         NewWindow(window + 1),
+		rsis:           make([]float64, 0, window),
+		metrics:        Metrics{Regime: RegimeWarmup},
+	}
+}
+
+// Add records a complete bar and the RSI computed on it, returning the updated metrics
+func (r *Regime) Add(bar common.Bar, rsi float64) Metrics {
+	r.closes.Add(bar.Close)
+
+	// Wilder's ATR, seeded with the simple average of the first window true ranges - the first bar has no previous
+	// close, so its range is its own
+	tr := bar.High - bar.Low
+	if r.bars > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(bar.High-r.prevClose), math.Abs(bar.Low-r.prevClose)))
+	}
+	r.prevClose = bar.Close
+	r.bars++
+	n := float64(r.window)
+	if r.bars <= r.window {
+		r.atr += tr / n
+	} else {
+		r.atr = (r.atr*(n-1) + tr) / n
+	}
+
+	if len(r.rsis) == r.window {
+		copy(r.rsis, r.rsis[1:])
+		r.rsis = r.rsis[:r.window-1]
+	}
+	r.rsis = append(r.rsis, rsi)
+
+	r.metrics = Metrics{RealizedVol: r.closes.RealizedVol(), RsiStd: stdDev(r.rsis), Regime: RegimeWarmup}
+	if r.bars < r.window {
+		return r.metrics
+	}
+	r.metrics.Atr = r.atr
+	switch {
+	case r.metrics.RsiStd < r.calmRsiStd:
+		r.metrics.Regime = RegimeCalm
+	case r.metrics.RsiStd > r.volatileRsiStd:
+		r.metrics.Regime = RegimeVolatile
+	default:
+		r.metrics.Regime = RegimeNormal
+	}
+	return r.metrics
+}
+
+// Metrics returns the metrics as of the latest bar
+func (r *Regime) Metrics() Metrics {
+	return r.metrics
+}
+
+// stdDev returns the sample standard deviation of the values
+func stdDev(values []float64) float64 {
+	n := len(values)
+	if n < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(n)
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(n-1))
+}
@@ -0,0 +1,67 @@
+package volatility
+
+import (
+	"math"
+)
+
+// Window keeps the most recent prices and derives realized volatility from their log returns
+type Window struct {
+	size   int
+	prices []float64
+}
+
+// NewWindow creates a rolling window holding up to size prices
+func NewWindow(size int) *Window {
+	if size < 2 {
+		size = 2
+	}
+	return &Window{
+		size:   size,
+		prices: make([]float64, 0, size),
+	}
+}
+
+// Add appends a price, evicting the oldest once the window is full - non-positive prices are ignored since they have
+// no log return
+func (w *Window) Add(price float64) {
+	if price <= 0 {
+		return
+	}
+	if len(w.prices) == w.size {
+		copy(w.prices, w.prices[1:])
+		w.prices = w.prices[:w.size-1]
+	}
+	w.prices = append(w.prices, price)
+}
+
+// Len returns the number of prices currently held
+func (w *Window) Len() int {
+	return len(w.prices)
+}
+
+// Full reports whether the window holds enough prices to be representative
+func (w *Window) Full() bool {
+	return len(w.prices) == w.size
+}
+
+// RealizedVol returns the standard deviation of the per-sample log returns in the window
+func (w *Window) RealizedVol() float64 {
+	n := len(w.prices) - 1
+	if n < 2 {
+		return 0
+	}
+
+	returns := make([]float64, n)
+	mean := 0.0
+	for i := 1; i < len(w.prices); i++ {
+		returns[i-1] = math.Log(w.prices[i] / w.prices[i-1])
+		mean += returns[i-1]
+	}
+	mean /= float64(n)
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance / float64(n-1))
+}
@@ -0,0 +1,115 @@
+// Package backtest replays bars through a strategy and trades its signals at each bar's close, for judging a set of
+// inputs before running it live. Its exported identifiers follow semantic versioning.
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/internal/parity"
+	"github.com/josephawallace/ninetyfive/internal/position"
+	"github.com/josephawallace/ninetyfive/pkg/strategy"
+)
+
+// Options size the simulated trades as the bot's config does
+type Options struct {
+	BuySize  float64 // quote spent on each BUY
+	SellSize float64 // base sold on each SELL
+	SpotOnly bool    // never sell more base than has been bought
+	// Slippage moves each fill away from the bar's close - nil fills at the close, which flatters grids on thin pools
+	Slippage SlippageModel
+}
+
+// Trade is a signal filled at its bar's close
+type Trade struct {
+	Time        time.Time       `json:"time"`
+	Side        strategy.Signal `json:"side"`
+	Price       float64         `json:"price"` // the fill price, after slippage
+	Base        float64         `json:"base"`  // base bought (positive) or sold (negative)
+	Quote       float64         `json:"quote"` // quote spent (positive) or received (negative)
+	SlippageBps float64         `json:"slippage_bps"`
+}
+
+// Result is the outcome of a backtest
+type Result struct {
+	Bars   int     `json:"bars"`
+	Trades []Trade `json:"trades"`
+	Base   float64 `json:"base"`  // net base acquired
+	Quote  float64 `json:"quote"` // net quote spent
+	Pnl    float64 `json:"pnl"`   // realized and unrealized, marked at the last close, in the quote asset
+	// SlippageCost is what the fills lost to slippage against the closes, in the quote asset
+	SlippageCost float64 `json:"slippage_cost"`
+}
+
+// Run feeds the bars, oldest first, to the strategy and fills every BUY and SELL at the bar's close, moved against the
+// order by the slippage model
+func Run(s strategy.Strategy, bars []strategy.Bar, opts Options) (Result, error) {
+	r := Result{Bars: len(bars)}
+	var pos position.Position
+	for i, bar := range bars {
+		signal, err := s.ProcessBar(bar)
+		if err != nil {
+			return r, fmt.Errorf("bar %d: %w", i, err)
+		}
+
+		t := Trade{Time: bar.Time, Side: signal}
+		switch signal {
+		case strategy.Buy:
+			if bar.Close <= 0 {
+				continue
+			}
+			slip := slippage(opts.Slippage, bar, signal, opts.BuySize)
+			t.Price = bar.Close * (1 + slip)
+			t.Quote = opts.BuySize
+			t.Base = opts.BuySize / t.Price
+			t.SlippageBps = slip * 10_000
+			r.SlippageCost += (opts.BuySize/bar.Close - t.Base) * bar.Close
+		case strategy.Sell:
+			size := opts.SellSize
+			if opts.SpotOnly {
+				size = min(size, max(0, pos.Base))
+			}
+			if size <= 0 {
+				continue
+			}
+			slip := slippage(opts.Slippage, bar, signal, size*bar.Close)
+			t.Price = bar.Close * (1 - slip)
+			t.Base = -size
+			t.Quote = -size * t.Price
+			t.SlippageBps = slip * 10_000
+			r.SlippageCost += size * (bar.Close - t.Price)
+		default:
+			continue
+		}
+		pos.Base += t.Base
+		pos.Quote += t.Quote
+		r.Trades = append(r.Trades, t)
+	}
+
+	r.Base, r.Quote = pos.Base, pos.Quote
+	if len(bars) > 0 {
+		r.Pnl = pos.Pnl(bars[len(bars)-1].Close)
+	}
+	return r, nil
+}
+
+// slippage returns the model's slippage for the order, or none without a model
+func slippage(m SlippageModel, bar strategy.Bar, side strategy.Signal, quoteSize float64) float64 {
+	if m == nil {
+		return 0
+	}
+	return m.Slippage(bar, side, quoteSize)
+}
+
+// LoadBars reads bars from a TradingView CSV export or a candle cache file (.jsonl)
+func LoadBars(path string) ([]strategy.Bar, error) {
+	loaded, err := parity.LoadBars(path)
+	if err != nil {
+		return nil, err
+	}
+	bars := make([]strategy.Bar, len(loaded))
+	for i, b := range loaded {
+		bars[i] = strategy.Bar(b)
+	}
+	return bars, nil
+}
@@ -0,0 +1,62 @@
+package backtest
+
+import (
+	"math"
+
+	"github.com/josephawallace/ninetyfive/pkg/strategy"
+)
+
+// SlippageModel estimates how much worse than the bar's close an order fills, as a fraction of the close - quoteSize
+// is the order's value in the quote asset
+type SlippageModel interface {
+	Slippage(bar strategy.Bar, side strategy.Signal, quoteSize float64) float64
+}
+
+// ConstantBps slips every fill by the same number of basis points
+type ConstantBps float64
+
+// Slippage implements SlippageModel
+func (c ConstantBps) Slippage(strategy.Bar, strategy.Signal, float64) float64 {
+	return float64(c) / 10_000
+}
+
+// SquareRootImpact is the square-root market impact model - an order moves the price by Coefficient times the square
+// root of its size relative to the pool's liquidity, so slippage grows quickly for orders large against a thin pool
+type SquareRootImpact struct {
+	Coefficient float64 // impact of an order the size of the pool, e.g. 1
+	Liquidity   float64 // the pool's depth in the quote asset
+}
+
+// Slippage implements SlippageModel - a pool without liquidity fills nothing well, so it slips the whole price
+func (s SquareRootImpact) Slippage(_ strategy.Bar, _ strategy.Signal, quoteSize float64) float64 {
+	if s.Liquidity <= 0 {
+		return 1
+	}
+	return min(s.Coefficient*math.Sqrt(max(quoteSize, 0)/s.Liquidity), 1)
+}
+
+// HistoricalSpread pays half the spread of each bar, estimated as Share of its high-low range - wide bars on small
+// caps are when AMM prices are least reliable
+type HistoricalSpread struct {
+	Share float64 // of the bar's range taken as its spread, e.g. 0.5
+}
+
+// Slippage implements SlippageModel
+func (h HistoricalSpread) Slippage(bar strategy.Bar, _ strategy.Signal, _ float64) float64 {
+	if bar.Close <= 0 || bar.High < bar.Low {
+		return 0
+	}
+	return h.Share * (bar.High - bar.Low) / bar.Close / 2
+}
+
+// Combined adds up the slippage of several models, e.g. the spread and the impact of the order
+type Combined []SlippageModel
+
+// Slippage implements SlippageModel
+func (c Combined) Slippage(bar strategy.Bar, side strategy.Signal, quoteSize float64) float64 {
+	var total float64
+	for _, m := range c {
+		total += m.Slippage(bar, side, quoteSize)
+	}
+	return min(total, 1)
+}
@@ -0,0 +1,33 @@
+// Package indicators exposes the oscillators the grid engine can run on, for use on their own. Its exported
+// identifiers follow semantic versioning.
+package indicators
+
+import (
+	"github.com/josephawallace/ninetyfive/internal/gridmanager"
+)
+
+// Indicator turns a price series into an oscillator between 0 and 100, fed one price at a time
+type Indicator interface {
+	Next(price float64) float64
+}
+
+// DefaultLaguerreGamma is the damping factor Ehlers suggests for the Laguerre RSI
+const DefaultLaguerreGamma = 0.5
+
+// NewRsi creates the RSI TradingView's ta.rsi computes over length prices
+func NewRsi(length int) Indicator {
+	return gridmanager.NewRsi(length)
+}
+
+// NewLaguerre creates Ehlers' Laguerre RSI with the damping factor, DefaultLaguerreGamma when it is not in (0, 1)
+func NewLaguerre(gamma float64) Indicator {
+	if gamma <= 0 || gamma >= 1 {
+		gamma = DefaultLaguerreGamma
+	}
+	return gridmanager.NewLaguerre(gamma)
+}
+
+// NewConnors creates Connors RSI with the given price RSI, streak RSI, and percent rank lengths
+func NewConnors(rsiLength int, streakLength int, rankLength int) Indicator {
+	return gridmanager.NewConnors(rsiLength, streakLength, rankLength)
+}
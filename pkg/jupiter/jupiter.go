@@ -0,0 +1,171 @@
+// Package jupiter is the public API of the ninetyfive execution client, which prices, quotes, and swaps Solana
+// tokens through Jupiter. Its exported identifiers follow semantic versioning, so its types are its own and the
+// client's internal changes are adapted here rather than passed through.
+package jupiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/jupiter"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// Pair is a base and quote mint - BUY spends the quote asset for the base asset, SELL the other way around
+type Pair struct {
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+}
+
+// Side is the direction of a swap on a pair
+type Side string
+
+// Sides of a swap
+const (
+	Buy  Side = "BUY"
+	Sell Side = "SELL"
+)
+
+// Estimate is the outcome Jupiter currently quotes for a swap, in human (not base unit) amounts
+type Estimate struct {
+	InAmount       float64 `json:"in_amount"`
+	OutAmount      float64 `json:"out_amount"`
+	PriceImpactPct float64 `json:"price_impact_pct"`
+}
+
+// RouteHop is one leg of a quote's route
+type RouteHop struct {
+	Label      string `json:"label"`
+	AmmKey     string `json:"amm_key"`
+	Percent    int    `json:"percent"` // share of the input routed through this leg
+	InputMint  string `json:"input_mint"`
+	OutputMint string `json:"output_mint"`
+}
+
+// Quote is what Jupiter currently offers for swapping an amount on a pair - its price, price impact, route, and
+// expiration
+type Quote struct {
+	Pair           Pair       `json:"pair"`
+	Side           Side       `json:"side"`
+	InputMint      string     `json:"input_mint"`
+	OutputMint     string     `json:"output_mint"`
+	InAmount       float64    `json:"in_amount"`
+	OutAmount      float64    `json:"out_amount"`
+	Price          float64    `json:"price"` // effective price of the base asset in the quote asset
+	PriceImpactPct float64    `json:"price_impact_pct"`
+	SlippageBps    int        `json:"slippage_bps"`
+	Route          []RouteHop `json:"route"`
+	QuotedAt       time.Time  `json:"quoted_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+}
+
+// ErrQuoteExpired is returned when a swap's quote aged past the configured time to live, and every re-quote did too,
+// before the swap could be sent
+var ErrQuoteExpired = jupiter.ErrQuoteExpired
+
+// ErrDustOrder is returned for a swap below its input mint's min_order_sizes entry or rounding to no base units
+var ErrDustOrder = jupiter.ErrDustOrder
+
+// Client prices, quotes, and executes swaps - it signs with the secret key of the config it was created from, or
+// only prices and quotes when the config needs no key
+type Client struct {
+	j *jupiter.Jupiter
+}
+
+// New creates a client from the config, whose secrets must already be loaded when it needs a key
+func New(cfg *configs.Config) (*Client, error) {
+	j, err := jupiter.NewJupiter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{j}, nil
+}
+
+// NewPair validates the mints and pairs them
+func NewPair(base string, quote string) (Pair, error) {
+	p, err := common.NewPair(base, quote)
+	if err != nil {
+		return Pair{}, err
+	}
+	return Pair(p), nil
+}
+
+// PublicKey returns the address of the wallet the client swaps from
+func (c *Client) PublicKey() string {
+	return c.j.PublicKey().String()
+}
+
+// Price returns the USD price of the mint
+func (c *Client) Price(ctx context.Context, mint string) (float64, error) {
+	return c.j.GetPrice(ctx, mint)
+}
+
+// SolBalance returns the wallet's native SOL balance
+func (c *Client) SolBalance(ctx context.Context) (float64, error) {
+	return c.j.SolBalance(ctx)
+}
+
+// TokenBalance returns how much of the mint the wallet holds
+func (c *Client) TokenBalance(ctx context.Context, mint string) (float64, error) {
+	return c.j.TokenBalance(ctx, mint)
+}
+
+// Estimate quotes a swap of amount on the pair without executing it
+func (c *Client) Estimate(ctx context.Context, pair Pair, side Side, amount float64) (Estimate, error) {
+	inputMint, outputMint, err := common.Pair(pair).Mints(common.Signal(side))
+	if err != nil {
+		return Estimate{}, err
+	}
+	e, err := c.j.EstimateSwap(ctx, inputMint, outputMint, amount)
+	if err != nil {
+		return Estimate{}, err
+	}
+	return Estimate{InAmount: e.InAmount, OutAmount: e.OutAmount, PriceImpactPct: e.PriceImpactPct}, nil
+}
+
+// Quote returns what Jupiter currently offers for swapping amount on the pair - BUY spends amount of the quote asset,
+// SELL amount of the base asset
+func (c *Client) Quote(ctx context.Context, pair Pair, side Side, amount float64) (Quote, error) {
+	q, err := c.j.Quote(ctx, common.Pair(pair), common.Signal(side), amount)
+	if err != nil {
+		return Quote{}, err
+	}
+	route := make([]RouteHop, len(q.Route))
+	for i, h := range q.Route {
+		route[i] = RouteHop(h)
+	}
+	return Quote{
+		Pair:           Pair(q.Pair),
+		Side:           Side(q.Side),
+		InputMint:      q.InputMint,
+		OutputMint:     q.OutputMint,
+		InAmount:       q.InAmount,
+		OutAmount:      q.OutAmount,
+		Price:          q.Price,
+		PriceImpactPct: q.PriceImpactPct,
+		SlippageBps:    q.SlippageBps,
+		Route:          route,
+		QuotedAt:       q.QuotedAt,
+		ExpiresAt:      q.ExpiresAt,
+	}, nil
+}
+
+// Execute swaps on the pair - BUY spends size of the quote asset for the base asset, SELL spends size of the base
+// asset for the quote asset - and returns the transaction's signature
+func (c *Client) Execute(ctx context.Context, pair Pair, side Side, size float64) (string, error) {
+	return c.ExecuteWithSlippage(ctx, pair, side, size, 0)
+}
+
+// ExecuteWithSlippage is Execute with a fixed slippage tolerance in basis points - zero keeps Jupiter's automatic
+// slippage
+func (c *Client) ExecuteWithSlippage(ctx context.Context, pair Pair, side Side, size float64,
+	slippageBps int) (string, error) {
+	return c.j.ExecuteWithSlippage(ctx, common.Pair(pair), common.Signal(side), size, slippageBps, "")
+}
+
+// MonitorTx waits for a submitted transaction to finalize, or returns the reason it failed
+func (c *Client) MonitorTx(ctx context.Context, txId string) error {
+	return c.j.MonitorTx(ctx, txId, logger.NopLogger{}, nil)
+}
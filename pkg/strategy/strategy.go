@@ -0,0 +1,190 @@
+// Package strategy is the public API of the ninetyfive grid engine - a port of the RSI grid TradingView script that
+// turns bars into BUY, SELL, or DO_NOTHING signals. Its exported identifiers follow semantic versioning, so its types
+// are its own and the engine's internal changes are adapted here rather than passed through.
+package strategy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/josephawallace/ninetyfive/configs"
+	"github.com/josephawallace/ninetyfive/internal/common"
+	"github.com/josephawallace/ninetyfive/internal/ensemble"
+	"github.com/josephawallace/ninetyfive/internal/gridmanager"
+	"github.com/josephawallace/ninetyfive/internal/logger"
+)
+
+// Bar is one OHLC bar - Time is its close
+type Bar struct {
+	Time  time.Time `json:"time"`
+	Open  float64   `json:"open"`
+	High  float64   `json:"high"`
+	Low   float64   `json:"low"`
+	Close float64   `json:"close"`
+}
+
+// Signal is what a strategy recommends on a bar
+type Signal string
+
+// Signals a strategy returns
+const (
+	Buy       Signal = "BUY"
+	Sell      Signal = "SELL"
+	DoNothing Signal = "DO_NOTHING"
+)
+
+// Config holds the script's inputs, as in the strategies list of the bot's config
+type Config struct {
+	Name        string `json:"name"`
+	RsiLength   int    `json:"rsi_length"`
+	Grids       int    `json:"grids"`
+	Direction   string `json:"direction"`
+	NoTradeZone string `json:"no_trade_zone"`
+	Aggression  string `json:"aggression"`
+	RsiType     string `json:"rsi_type"`
+	Source      string `json:"source"`
+	Smoothing   int    `json:"smoothing"`
+	// LaguerreGamma is the damping of the laguerre rsi_type, in (0, 1) - 0 uses 0.5
+	LaguerreGamma float64 `json:"laguerre_gamma"`
+	// The sell side's grids, no_trade_zone, and aggression - each left unset takes the buy side's
+	SellGrids       int    `json:"sell_grids,omitempty"`
+	SellNoTradeZone string `json:"sell_no_trade_zone,omitempty"`
+	SellAggression  string `json:"sell_aggression,omitempty"`
+}
+
+// Logger receives the engine's narration of each bar - level is debug, info, warn, or error
+type Logger interface {
+	Logf(level string, format string, args ...interface{})
+}
+
+// Snapshot is everything a strategy derived from the most recent bar
+type Snapshot struct {
+	Rsi           float64    `json:"rsi"`
+	PrevRsi       float64    `json:"prev_rsi"`
+	SignalLine    float64    `json:"signal_line"`
+	GridLevels    []float64  `json:"grid_levels"`
+	LastSignal    Signal     `json:"last_signal"`
+	BuyLineIndex  int        `json:"buy_line_index"`
+	SellLineIndex int        `json:"sell_line_index"`
+	NoTradeZone   [2]float64 `json:"no_trade_zone"` // RSI bounds where signals are blocked
+	// The sell side's grid levels and no-trade zone, set only when they differ from the buy side's
+	SellGridLevels  []float64   `json:"sell_grid_levels,omitempty"`
+	SellNoTradeZone *[2]float64 `json:"sell_no_trade_zone,omitempty"`
+	// BlockedBy names the filter that cleared the most recent bar's grid line crossing, empty when none did
+	BlockedBy string `json:"blocked_by,omitempty"`
+}
+
+// Strategy turns bars, fed oldest first, into signals
+type Strategy interface {
+	// ProcessBar feeds the next bar and returns the signal on it
+	ProcessBar(bar Bar) (Signal, error)
+	// Rsi returns the indicator value computed on the most recent bar
+	Rsi() float64
+	// Snapshot returns the indicator, grid, and filter states after the most recent bar
+	Snapshot() Snapshot
+}
+
+// New builds the grid strategy for the inputs - a nil log discards the narration
+func New(cfg Config, log Logger) (Strategy, error) {
+	var l logger.Logger = logger.NopLogger{}
+	if log != nil {
+		l = narration{log}
+	}
+	gm, err := ensemble.NewStrategy(cfg.internal(), l)
+	if err != nil {
+		return nil, err
+	}
+	return &engine{gm}, nil
+}
+
+// internal is the engine's configuration of the inputs
+func (c Config) internal() configs.Strategy {
+	return configs.Strategy{
+		Name:            c.Name,
+		RsiLength:       c.RsiLength,
+		Grids:           c.Grids,
+		Direction:       c.Direction,
+		NoTradeZone:     c.NoTradeZone,
+		Aggression:      c.Aggression,
+		RsiType:         c.RsiType,
+		Source:          c.Source,
+		Smoothing:       c.Smoothing,
+		LaguerreGamma:   c.LaguerreGamma,
+		SellGrids:       c.SellGrids,
+		SellNoTradeZone: c.SellNoTradeZone,
+		SellAggression:  c.SellAggression,
+	}
+}
+
+// engine adapts the Grid Manager to the public types
+type engine struct {
+	gm *gridmanager.GridManager
+}
+
+func (e *engine) ProcessBar(bar Bar) (Signal, error) {
+	signal, err := e.gm.ProcessBar(common.Bar(bar))
+	return Signal(signal), err
+}
+
+func (e *engine) Rsi() float64 {
+	return e.gm.Rsi()
+}
+
+func (e *engine) Snapshot() Snapshot {
+	s := e.gm.Snapshot()
+	_, blocked := s.Filters.Blocked()
+	return Snapshot{
+		Rsi:             s.Rsi,
+		PrevRsi:         s.PrevRsi,
+		SignalLine:      s.SignalLine,
+		GridLevels:      s.GridLevels,
+		LastSignal:      Signal(s.LastSignal),
+		BuyLineIndex:    s.BuyLineIndex,
+		SellLineIndex:   s.SellLineIndex,
+		NoTradeZone:     s.NoTradeZone,
+		SellGridLevels:  s.SellGridLevels,
+		SellNoTradeZone: s.SellNoTradeZone,
+		BlockedBy:       blocked,
+	}
+}
+
+// narration forwards the engine's log events to a Logger
+type narration struct {
+	log Logger
+}
+
+func (n narration) Debug() logger.Event {
+	return event{log: n.log, level: "debug"}
+}
+
+func (n narration) Info() logger.Event {
+	return event{log: n.log, level: "info"}
+}
+
+func (n narration) Warn() logger.Event {
+	return event{log: n.log, level: "warn"}
+}
+
+func (n narration) Error() logger.Event {
+	return event{log: n.log, level: "error"}
+}
+
+// event is one log line on its way to a Logger, with the error attached to it if any
+type event struct {
+	log   Logger
+	level string
+	err   error
+}
+
+func (e event) Msg(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	e.log.Logf(e.level, "%s", msg)
+}
+
+func (e event) Err(err error) logger.Event {
+	e.err = err
+	return e
+}